@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// regiongen turns the Kemendagri region export into the files embedded by
+// internal/pkg/region. It reads the SQL dumps published at
+// https://github.com/cahyadsn/wilayah (db/wilayah.sql and
+// db/wilayah_kodepos.sql), or any file with one ('code','value') tuple per
+// region, and writes data/wilayah.csv, data/kodepos.csv and data/VERSION:
+//
+//	go run ./cmd/regiongen -wilayah wilayah.sql -kodepos wilayah_kodepos.sql -version 2026.10.1
+func main() {
+	wilayahPath := flag.String("wilayah", "", "region export with (code, name) tuples")
+	kodeposPath := flag.String("kodepos", "", "postal code export with (village code, postal code) tuples")
+	version := flag.String("version", "", "dataset version written to data/VERSION")
+	out := flag.String("out", "internal/pkg/region/data", "directory to write the data files to")
+	flag.Parse()
+
+	if *wilayahPath == "" || *kodeposPath == "" || *version == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*wilayahPath, *kodeposPath, *version, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "regiongen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(wilayahPath, kodeposPath, version, out string) error {
+	regions, err := readTuples(wilayahPath)
+	if err != nil {
+		return err
+	}
+	postalCodes, err := readTuples(kodeposPath)
+	if err != nil {
+		return err
+	}
+
+	for code, name := range regions {
+		regions[code] = displayName(name)
+	}
+	for code := range postalCodes {
+		if _, ok := regions[code]; !ok || strings.Count(code, ".") != 3 {
+			return fmt.Errorf("%s: %q is not a village code", kodeposPath, code)
+		}
+	}
+
+	if err := writeCSV(filepath.Join(out, "wilayah.csv"), regions); err != nil {
+		return err
+	}
+	if err := writeCSV(filepath.Join(out, "kodepos.csv"), postalCodes); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(out, "VERSION"), []byte(version+"\n"), 0o644); err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %d regions and %d postal codes\n", len(regions), len(postalCodes))
+	return nil
+}
+
+var tuple = regexp.MustCompile(`\(\s*'([0-9.]+)'\s*,\s*'((?:[^'\\]|\\.|'')*)'\s*\)`)
+
+func readTuples(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		for _, m := range tuple.FindAllStringSubmatch(scanner.Text(), -1) {
+			value := strings.NewReplacer(`\'`, "'", "''", "'").Replace(m[2])
+			if _, ok := rows[m[1]]; ok {
+				return nil, fmt.Errorf("%s: duplicate code %q", path, m[1])
+			}
+			rows[m[1]] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%s: no rows found", path)
+	}
+	return rows, nil
+}
+
+var romanNumeral = regexp.MustCompile(`^(X{0,3})(IX|IV|V?I{0,3})$`)
+
+// displayName title-cases names the export spells in capitals, keeping
+// abbreviations such as DKI and roman numerals as they are.
+func displayName(name string) string {
+	if name != strings.ToUpper(name) {
+		return name
+	}
+
+	words := strings.Fields(name)
+	for i, w := range words {
+		switch {
+		case w == "DKI" || w == "DI":
+		case romanNumeral.MatchString(w):
+		default:
+			words[i] = titleWord(w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// titleWord capitalizes the first letter of a word and of each part after a
+// hyphen, slash or parenthesis, as in "Pasar Minggu-(Timur)".
+func titleWord(w string) string {
+	b := []rune(strings.ToLower(w))
+	upper := true
+	for i, r := range b {
+		if upper && unicode.IsLetter(r) {
+			b[i] = unicode.ToUpper(r)
+		}
+		upper = r == '-' || r == '/' || r == '('
+	}
+	return string(b)
+}
+
+func writeCSV(path string, rows map[string]string) error {
+	codes := make([]string, 0, len(rows))
+	for code := range rows {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	for _, code := range codes {
+		if err := w.Write([]string{code, rows[code]}); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
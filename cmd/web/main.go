@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -16,12 +17,18 @@ import (
 
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/db"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/configs"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/crons"
 	dbGenerated "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/handlers"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/authz"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/logger"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/redisclient"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/region"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/secretbox"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/sms"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/storage"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/routes"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
@@ -56,6 +63,7 @@ func main() {
 		Password:     cfg.Database.Password,
 		DatabaseName: cfg.Database.Name,
 		Port:         cfg.Database.Port,
+		TimeZone:     cfg.Database.TimeZone,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -138,16 +146,191 @@ func main() {
 	usersRepo := repositories.NewUserRepository(sqlcQueries, log)
 	jwtBlacklistRepo := repositories.NewJWTBlacklistRepository(redisClient)
 	refreshTokenRepo := repositories.NewRefreshTokenRepository(redisClient)
+	sessionRepo := repositories.NewSessionRepository(redisClient)
+	tokenVersionRepo := repositories.NewTokenVersionRepository(sqlcQueries, redisClient)
+	accountStatusRepo := repositories.NewAccountStatusRepository(sqlcQueries, redisClient)
+	oauthClientRepo := repositories.NewOAuthClientRepository(sqlcQueries)
+	authorizationCodeRepo := repositories.NewAuthorizationCodeRepository(redisClient)
+	mfaRepo := repositories.NewMFARepository(sqlcQueries)
+	mfaChallengeRepo := repositories.NewMFAChallengeRepository(redisClient)
+	webAuthnCredentialRepo := repositories.NewWebAuthnCredentialRepository(sqlcQueries)
+	webAuthnCeremonyRepo := repositories.NewWebAuthnCeremonyRepository(redisClient)
+	emailVerificationRepo := repositories.NewEmailVerificationRepository(sqlcQueries)
+	throttleRepo := repositories.NewThrottleRepository(redisClient)
+	passwordResetRepo := repositories.NewPasswordResetRepository(sqlcQueries)
+	passwordHistoryRepo := repositories.NewPasswordHistoryRepository(sqlcQueries)
+	dataExportRepo := repositories.NewDataExportRepository(sqlcQueries)
+	addressRepo := repositories.NewAddressRepository(sqlcQueries)
+	phoneOTPRepo := repositories.NewPhoneOTPRepository(redisClient)
+	preferencesRepo := repositories.NewPreferencesRepository(sqlcQueries)
+	sellerApplicationRepo := repositories.NewSellerApplicationRepository(sqlcQueries)
+	roleRepo := repositories.NewRoleRepository(sqlcQueries)
+	roleGrantRepo := repositories.NewRoleGrantRepository(sqlcQueries)
+	auditLogRepo := repositories.NewAuditLogRepository(sqlcQueries)
+	signInEventRepo := repositories.NewSignInEventRepository(sqlcQueries)
 
 	validate := validator.New()
 
 	// Setup Service
+	keyRing, err := token.LoadKeyRing(cfg.Server.JWTKeysDir, cfg.Server.JWTActiveKeyID)
+	if err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+	log.Infof("JWT key ring loaded, active key: %s (%s)", keyRing.ActiveKey().ID, keyRing.ActiveKey().Method.Alg())
+
 	audiences := strings.Split(cfg.Server.JWTAudience, ",")
-	tokenService := token.NewJWTTokenService(cfg.Server.JWTSecret, cfg.Server.JWTIssuer, audiences, jwtBlacklistRepo)
-	userService := services.NewUserService(usersRepo, validate, tokenService, jwtBlacklistRepo, eventPublisher, kafkaProducer, log)
+	tokenService := token.NewJWTTokenService(keyRing, cfg.Server.JWTIssuer, audiences, jwtBlacklistRepo, tokenVersionRepo, accountStatusRepo, roleRepo)
+	emailVerificationService := services.NewEmailVerificationService(emailVerificationRepo, throttleRepo, eventPublisher, services.EmailVerificationConfig{
+		URL:            cfg.EmailVerification.URL,
+		TokenTTL:       cfg.EmailVerification.TokenTTL,
+		ResendCooldown: cfg.EmailVerification.ResendCooldown,
+	}, log)
+	passwordPolicy := services.PasswordPolicy{
+		MinLength:           cfg.PasswordPolicy.MinLength,
+		MinCharacterClasses: cfg.PasswordPolicy.MinCharacterClasses,
+	}
+	phonePolicy := services.PhonePolicy{DefaultCountryCode: cfg.Phone.DefaultCountryCode}
+
+	userService := services.NewUserService(usersRepo, emailVerificationService, passwordPolicy, phonePolicy, validate, tokenService, jwtBlacklistRepo, eventPublisher, kafkaProducer, log)
+	sessionService := services.NewSessionService(usersRepo, refreshTokenRepo, sessionRepo, signInEventRepo, tokenService, eventPublisher, log)
+	statusService := services.NewUserStatusService(usersRepo, accountStatusRepo, sessionService, eventPublisher, log)
+	passwordService := services.NewPasswordService(usersRepo, passwordResetRepo, passwordHistoryRepo, throttleRepo, sessionService, validate, eventPublisher, passwordPolicy, services.PasswordConfig{
+		ResetURL:             cfg.PasswordReset.URL,
+		ResetTokenTTL:        cfg.PasswordReset.TokenTTL,
+		ResetRequestCooldown: cfg.PasswordReset.RequestCooldown,
+		HistorySize:          cfg.PasswordPolicy.HistorySize,
+	}, log)
+	oidcService := services.NewOIDCService(oauthClientRepo, authorizationCodeRepo, userService, sessionService, tokenService, validate, services.OIDCConfig{
+		IssuerURL:   cfg.OIDC.IssuerURL,
+		AuthCodeTTL: cfg.OIDC.AuthCodeTTL,
+		IDTokenTTL:  cfg.OIDC.IDTokenTTL,
+	}, log)
+
+	mfaSecretBox, err := secretbox.New(cfg.MFA.SecretKey)
+	if err != nil {
+		log.Fatalf("Invalid MFA_SECRET_KEY: %v", err)
+	}
+	mfaService := services.NewMFAService(mfaRepo, mfaChallengeRepo, usersRepo, mfaSecretBox, services.MFAConfig{
+		Issuer:       cfg.MFA.Issuer,
+		ChallengeTTL: cfg.MFA.ChallengeTTL,
+		MaxAttempts:  cfg.MFA.MaxAttempts,
+	}, log)
+
+	webAuthnService, err := services.NewWebAuthnService(webAuthnCredentialRepo, webAuthnCeremonyRepo, usersRepo, services.WebAuthnConfig{
+		RPID:          cfg.WebAuthn.RPID,
+		RPDisplayName: cfg.WebAuthn.RPDisplayName,
+		RPOrigins:     cfg.WebAuthn.RPOrigins,
+		CeremonyTTL:   cfg.WebAuthn.CeremonyTTL,
+	}, log)
+	if err != nil {
+		log.Fatalf("Invalid WebAuthn config: %v", err)
+	}
+
+	dataExportService, err := services.NewDataExportService(dataExportRepo, usersRepo, sessionRepo, signInEventRepo, auditLogRepo, mfaRepo, webAuthnCredentialRepo, oauthClientRepo, addressRepo, preferencesRepo, sellerApplicationRepo, throttleRepo, keyRing, eventPublisher, services.DataExportConfig{
+		Dir:             cfg.DataExport.Dir,
+		DownloadURL:     cfg.DataExport.DownloadURL,
+		LinkSecret:      []byte(cfg.DataExport.LinkSecret),
+		LinkTTL:         cfg.DataExport.LinkTTL,
+		RequestCooldown: cfg.DataExport.RequestCooldown,
+		Issuer:          cfg.Server.JWTIssuer,
+	}, log)
+	if err != nil {
+		log.Fatalf("Invalid data export config: %v", err)
+	}
+
+	smsSender, err := sms.New(cfg.SMS.Provider, cfg.SMS.FilePath, log)
+	if err != nil {
+		log.Fatalf("Invalid SMS config: %v", err)
+	}
+	phoneService := services.NewPhoneService(usersRepo, phoneOTPRepo, throttleRepo, smsSender, phonePolicy, services.PhoneConfig{
+		OTPLength:      cfg.Phone.OTPLength,
+		OTPTTL:         cfg.Phone.OTPTTL,
+		MaxAttempts:    cfg.Phone.OTPMaxAttempts,
+		MaxFailures:    cfg.Phone.OTPMaxFailures,
+		FailureWindow:  cfg.Phone.OTPFailureWindow,
+		ResendCooldown: cfg.Phone.OTPResendCooldown,
+	}, log)
+
+	var mediaStorage storage.Storage
+	switch cfg.Storage.Driver {
+	case "local":
+		mediaStorage = storage.NewLocal(cfg.Storage.LocalDir, cfg.Storage.PublicURL)
+	case "s3":
+		mediaStorage, err = storage.NewS3(storage.S3Config{
+			Endpoint:        cfg.Storage.S3Endpoint,
+			Region:          cfg.Storage.S3Region,
+			Bucket:          cfg.Storage.S3Bucket,
+			AccessKeyID:     cfg.Storage.S3AccessKeyID,
+			SecretAccessKey: cfg.Storage.S3SecretAccessKey,
+			PathStyle:       cfg.Storage.S3PathStyle,
+			PublicURL:       cfg.Storage.PublicURL,
+		})
+		if err != nil {
+			log.Fatalf("Invalid storage config: %v", err)
+		}
+	default:
+		log.Fatalf("Invalid storage config: unknown driver %q", cfg.Storage.Driver)
+	}
+	avatarService := services.NewAvatarService(usersRepo, mediaStorage, services.AvatarConfig{
+		MaxBytes:  cfg.Avatar.MaxBytes,
+		MaxPixels: cfg.Avatar.MaxPixels,
+	}, log)
+	accountDeletionService := services.NewAccountDeletionService(usersRepo, accountStatusRepo, sessionService, dataExportService, avatarService, validate, eventPublisher, services.AccountDeletionConfig{
+		GracePeriod:     cfg.AccountDeletion.GracePeriod,
+		RetentionPeriod: cfg.AccountDeletion.RetentionPeriod,
+		BatchSize:       cfg.AccountDeletion.PurgeBatchSize,
+	}, log)
+
+	preferencesPolicy := services.PreferencesPolicy{
+		Locales:         cfg.Preferences.Locales,
+		Currencies:      cfg.Preferences.Currencies,
+		DefaultLocale:   cfg.Preferences.DefaultLocale,
+		DefaultTimezone: cfg.Preferences.DefaultTimezone,
+		DefaultCurrency: cfg.Preferences.DefaultCurrency,
+	}
+	if err := preferencesPolicy.Validate(); err != nil {
+		log.Fatalf("Invalid preferences config: %v", err)
+	}
+	preferencesService := services.NewPreferencesService(preferencesRepo, usersRepo, preferencesPolicy, log)
+
+	regions, err := region.Load()
+	if err != nil {
+		log.Fatalf("Failed to load region dataset: %v", err)
+	}
+	log.Infof("Region dataset %s loaded", regions.Version)
+	if !regions.Complete() {
+		log.Warn("Region dataset is partial: address levels it does not cover are stored unchecked. Generate the full dataset with cmd/regiongen")
+	}
+	addressService := services.NewAddressService(addressRepo, regions, validate, log)
+
+	kycSecretBox, err := secretbox.New(cfg.Seller.KYCKey)
+	if err != nil {
+		log.Fatalf("Invalid SELLER_KYC_KEY: %v", err)
+	}
+	sellerApplicationService := services.NewSellerApplicationService(sellerApplicationRepo, usersRepo, kycSecretBox, regions, eventPublisher, services.SellerApplicationConfig{
+		MaxDocumentBytes: cfg.Seller.MaxDocumentBytes,
+	}, log)
+	roleService := services.NewRoleService(roleRepo, usersRepo, tokenService, log)
+	roleAssignmentService := services.NewRoleAssignmentService(roleGrantRepo, roleRepo, usersRepo, tokenService, log)
+	auditLogService := services.NewAuditLogService(auditLogRepo, log)
+	impersonationService := services.NewImpersonationService(usersRepo, roleRepo, auditLogRepo, tokenService, log)
+
+	policies, err := authz.Load(cfg.Authorization.PolicyFile, log)
+	if err != nil {
+		log.Fatalf("Failed to load authorization policy: %v", err)
+	}
+	log.Infof("Authorization policy loaded, version %s", policies.Policy().Version)
+	authorizationService := services.NewAuthorizationService(policies, usersRepo, roleRepo, log)
 
 	// Setup Handler
-	handler := handlers.NewHandler(usersRepo, userService, tokenService, jwtBlacklistRepo, refreshTokenRepo, log)
+	handler := handlers.NewHandler(usersRepo, userService, sessionService, oidcService, mfaService, webAuthnService, emailVerificationService, phoneService, avatarService, preferencesService, passwordService, statusService, accountDeletionService, dataExportService, addressService, sellerApplicationService, roleService, roleAssignmentService, auditLogService, impersonationService, regions, tokenService, jwtBlacklistRepo, eventPublisher, log)
+
+	// Setup Crons
+	cronCtx, stopCrons := context.WithCancel(context.Background())
+	defer stopCrons()
+	crons.NewAccountDeletionJob(accountDeletionService, cfg.AccountDeletion.PurgeInterval, log).Start(cronCtx)
+	crons.NewDataExportCleanupJob(dataExportService, cfg.DataExport.CleanupInterval, log).Start(cronCtx)
+	policies.Watch(cronCtx, cfg.Authorization.ReloadInterval)
 
 	// Setup gRPC
 	lis, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
@@ -156,8 +339,8 @@ func main() {
 	}
 
 	s := grpc.NewServer()
-	authpb.RegisterAuthServiceServer(s, grpcServer.NewAuthServer(tokenService))
-	accountpb.RegisterAccountServiceServer(s, grpcServer.NewAccountServer(userService))
+	authpb.RegisterAuthServiceServer(s, grpcServer.NewAuthServer(tokenService, authorizationService, auditLogService))
+	accountpb.RegisterAccountServiceServer(s, grpcServer.NewAccountServer(userService, addressService, preferencesService))
 	reflection.Register(s)
 
 	go func() {
@@ -171,14 +354,24 @@ func main() {
 	e.Use(middleware.RequestID())
 	e.Use(middlewareApp.LoggingMiddleware(log))
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"}, // Nginx will handle stricter CORS
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowOrigins:  []string{"*"}, // Nginx will handle stricter CORS
+		AllowMethods:  []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, "If-Match"},
+		ExposeHeaders: []string{"ETag"},
 	}))
 
 	// Setup Route
 	routes.InitRoutes(e, handler, tokenService)
 
+	// Files in local storage are served from the path of their public URL.
+	if cfg.Storage.Driver == "local" {
+		publicURL, err := url.Parse(cfg.Storage.PublicURL)
+		if err != nil || publicURL.Path == "" || publicURL.Path == "/" {
+			log.Fatalf("Invalid storage config: STORAGE_PUBLIC_URL needs a path, such as http://localhost:8080/media")
+		}
+		e.Static(publicURL.Path, cfg.Storage.LocalDir)
+	}
+
 	// Start Echo API REST Server (Block main goroutine)
 	e.Logger.Fatal(e.Start(":" + cfg.Server.Port))
 
@@ -8,13 +8,21 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/gommon/log"
 	"github.com/sirupsen/logrus"
 
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/db"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/configs"
+	dbGenerated "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/messaging/rabbitmq"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/logger"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
 	rabbitmqpkg "github.com/RehanAthallahAzhar/tokohobby-messaging/rabbitmq"
+
+	_ "github.com/lib/pq"
 )
 
 func main() {
@@ -34,6 +42,44 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// The database is only read, for the preferences of notification emails
+	dbCredential := models.Credential{
+		Host:         cfg.Database.Host,
+		Username:     cfg.Database.User,
+		Password:     cfg.Database.Password,
+		DatabaseName: cfg.Database.Name,
+		Port:         cfg.Database.Port,
+		TimeZone:     cfg.Database.TimeZone,
+	}
+
+	connectCtx, cancelConnect := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelConnect()
+
+	conn, err := db.Connect(connectCtx, &dbCredential)
+	if err != nil {
+		log.Fatalf("DB connection error: %v", err)
+	}
+	defer conn.Close()
+
+	sqlcQueries := dbGenerated.New(conn)
+
+	preferencesPolicy := services.PreferencesPolicy{
+		Locales:         cfg.Preferences.Locales,
+		Currencies:      cfg.Preferences.Currencies,
+		DefaultLocale:   cfg.Preferences.DefaultLocale,
+		DefaultTimezone: cfg.Preferences.DefaultTimezone,
+		DefaultCurrency: cfg.Preferences.DefaultCurrency,
+	}
+	if err := preferencesPolicy.Validate(); err != nil {
+		log.Fatalf("Invalid preferences config: %v", err)
+	}
+	preferencesService := services.NewPreferencesService(
+		repositories.NewPreferencesRepository(sqlcQueries),
+		repositories.NewUserRepository(sqlcQueries, log),
+		preferencesPolicy,
+		log,
+	)
+
 	rmqConfig := &rabbitmqpkg.RabbitMQConfig{
 		URL:            cfg.RabbitMQ.URL,
 		MaxRetries:     cfg.RabbitMQ.MaxRetries,
@@ -63,8 +109,11 @@ func main() {
 	// Create email service
 	emailService := NewEmailService()
 
-	// Create message handler
-	handler := func(ctx context.Context, body []byte) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Welcome email, with the verification link of the new account
+	startConsumer(ctx, rmq, "email.user.welcome", "user.registered", func(ctx context.Context, body []byte) error {
 		var event rabbitmq.UserRegisteredEvent
 
 		if err := rabbitmqpkg.UnmarshalMessage(body, &event); err != nil {
@@ -75,42 +124,123 @@ func main() {
 			event.Username, event.Email)
 
 		// Send email
-		return emailService.SendWelcomeEmail(event.Email, event.Username, event.UserID)
-	}
+		return emailService.SendWelcomeEmail(event.Email, event.Username, event.UserID, event.VerificationURL)
+	})
 
-	// Create consumer with options
-	consumerOpts := rabbitmqpkg.ConsumerOptions{
-		QueueName:   "email.user.welcome",
-		WorkerCount: 3, // 3 concurrent workers
-		AutoAck:     false,
-	}
+	// New verification links: resend requests and email changes
+	startConsumer(ctx, rmq, "email.user.verification", "user.email_verification_requested", func(ctx context.Context, body []byte) error {
+		var event rabbitmq.EmailVerificationRequestedEvent
 
-	consumer := rabbitmqpkg.NewConsumer(rmq, consumerOpts, handler)
+		if err := rabbitmqpkg.UnmarshalMessage(body, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
 
-	// Declare queue
-	if err := consumer.DeclareQueue(true, false); err != nil {
-		log.Fatalf("Failed to declare queue: %v", err)
-	}
+		logrus.Infof("Processing verification email for user: %s (%s)",
+			event.Username, event.Email)
 
-	log.Info("Queue declared: email.user.welcome")
+		return emailService.SendVerificationEmail(event.Email, event.Username, event.VerificationURL, event.ExpiresAt)
+	})
 
-	// Bind queue to exchange
-	if err := consumer.BindQueue("user.events", "user.registered"); err != nil {
-		log.Fatalf("Failed to bind queue: %v", err)
+	// Password reset links
+	startConsumer(ctx, rmq, "email.user.password_reset", "user.password_reset_requested", func(ctx context.Context, body []byte) error {
+		var event rabbitmq.PasswordResetRequestedEvent
+
+		if err := rabbitmqpkg.UnmarshalMessage(body, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		logrus.Infof("Processing password reset email for user: %s (%s)",
+			event.Username, event.Email)
+
+		return emailService.SendPasswordResetEmail(event.Email, event.Username, event.ResetURL, event.ExpiresAt)
+	})
+
+	// "Your password was changed" notices
+	startConsumer(ctx, rmq, "email.user.password_changed", "user.password_changed", func(ctx context.Context, body []byte) error {
+		var event rabbitmq.PasswordChangedEvent
+
+		if err := rabbitmqpkg.UnmarshalMessage(body, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		logrus.Infof("Processing password changed email for user: %s (%s)",
+			event.Username, event.Email)
+
+		return emailService.SendPasswordChangedEmail(event.Email, event.Username, event.IPAddress, event.ChangedAt)
+	})
+
+	// "Your account will be deleted" notices
+	startConsumer(ctx, rmq, "email.user.deletion_scheduled", "user.deletion_scheduled", func(ctx context.Context, body []byte) error {
+		var event rabbitmq.AccountDeletionScheduledEvent
+
+		if err := rabbitmqpkg.UnmarshalMessage(body, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		logrus.Infof("Processing account deletion email for user: %s (%s)",
+			event.Username, event.Email)
+
+		return emailService.SendAccountDeletionScheduledEmail(event.Email, event.Username, event.ScheduledFor)
+	})
+
+	// Personal data exports ready to download
+	startConsumer(ctx, rmq, "email.user.data_export_ready", "user.data_export_ready", func(ctx context.Context, body []byte) error {
+		var event rabbitmq.DataExportReadyEvent
+
+		if err := rabbitmqpkg.UnmarshalMessage(body, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		logrus.Infof("Processing data export email for user: %s (%s)",
+			event.Username, event.Email)
+
+		return emailService.SendDataExportReadyEmail(event.Email, event.Username, event.DownloadURL, event.ExpiresAt)
+	})
+
+	// Seller application decisions, approved or rejected with the admin's notes
+	for _, routingKey := range []string{"user.seller_application_approved", "user.seller_application_rejected"} {
+		startConsumer(ctx, rmq, "email."+routingKey, routingKey, func(ctx context.Context, body []byte) error {
+			var event rabbitmq.SellerApplicationDecidedEvent
+
+			if err := rabbitmqpkg.UnmarshalMessage(body, &event); err != nil {
+				return fmt.Errorf("failed to unmarshal: %w", err)
+			}
+
+			logrus.Infof("Processing seller application email for user: %s (%s)",
+				event.Username, event.Email)
+
+			return emailService.SendSellerApplicationDecidedEmail(event.Email, event.Username, event.ShopName, event.Status, event.Notes)
+		})
 	}
 
-	log.Info("Queue bound to exchange: user.events (routing key: user.registered)")
+	// Newsletters, promotions and recommendations, only for users who opted in
+	startConsumer(ctx, rmq, "email.user.notification", "user.notification_email_requested", func(ctx context.Context, body []byte) error {
+		var event rabbitmq.NotificationEmailRequestedEvent
 
-	// Start consuming with context
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+		if err := rabbitmqpkg.UnmarshalMessage(body, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
 
-	// Start consumer in goroutine
-	go func() {
-		if err := consumer.Start(ctx); err != nil {
-			log.Warnf("Consumer error: %v", err)
+		userID, err := uuid.Parse(event.UserID)
+		if err != nil {
+			logrus.Warnf("Dropping %s email with invalid user ID %q", event.Category, event.UserID)
+			return nil
 		}
-	}()
+
+		user, preferences, err := preferencesService.EmailRecipient(ctx, userID, event.Category)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			logrus.Infof("Skipping %s email for user %s: not opted in or not reachable", event.Category, event.UserID)
+			return nil
+		}
+
+		logrus.Infof("Processing %s email for user: %s (%s)",
+			event.Category, user.Username, user.Email)
+
+		return emailService.SendNotificationEmail(user.Email, user.Username, preferences.Locale, event.Subject, event.Body)
+	})
 
 	log.Info("Email worker is running. Waiting for messages... (Press Ctrl+C to exit)")
 
@@ -128,6 +258,38 @@ func main() {
 	log.Info("Email worker stopped gracefully")
 }
 
+// startConsumer declares a queue bound to the user.events exchange and consumes
+// it in the background until ctx is cancelled.
+func startConsumer(ctx context.Context, rmq *rabbitmqpkg.RabbitMQ, queue, routingKey string, handler func(ctx context.Context, body []byte) error) {
+	consumerOpts := rabbitmqpkg.ConsumerOptions{
+		QueueName:   queue,
+		WorkerCount: 3, // 3 concurrent workers
+		AutoAck:     false,
+	}
+
+	consumer := rabbitmqpkg.NewConsumer(rmq, consumerOpts, handler)
+
+	// Declare queue
+	if err := consumer.DeclareQueue(true, false); err != nil {
+		log.Fatalf("Failed to declare queue: %v", err)
+	}
+
+	log.Infof("Queue declared: %s", queue)
+
+	// Bind queue to exchange
+	if err := consumer.BindQueue("user.events", routingKey); err != nil {
+		log.Fatalf("Failed to bind queue: %v", err)
+	}
+
+	log.Infof("Queue bound to exchange: user.events (routing key: %s)", routingKey)
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil {
+			log.Warnf("Consumer error: %v", err)
+		}
+	}()
+}
+
 // EmailService handles email sending logic
 type EmailService struct{}
 
@@ -135,7 +297,7 @@ func NewEmailService() *EmailService {
 	return &EmailService{}
 }
 
-func (s *EmailService) SendWelcomeEmail(email, username, userID string) error {
+func (s *EmailService) SendWelcomeEmail(email, username, userID, verificationURL string) error {
 	// TODO: Implement actual SMTP email sending
 	// For now, just log the email
 
@@ -143,6 +305,7 @@ func (s *EmailService) SendWelcomeEmail(email, username, userID string) error {
 	log.Infof("   To: %s", email)
 	log.Infof("   Username: %s", username)
 	log.Infof("   User ID: %s", userID)
+	log.Infof("   Verify email: %s", verificationURL)
 
 	// Simulate email sending delay
 	time.Sleep(500 * time.Millisecond)
@@ -175,8 +338,8 @@ func (s *EmailService) SendWelcomeEmail(email, username, userID string) error {
 					<div class="content">
 						<h2>Hi %s!</h2>
 						<p>Thank you for joining TokoHobby, your one-stop shop for all hobby needs.</p>
-						<p>Your account is now active and ready to use. Start exploring our amazing collection of products!</p>
-						<a href="https://tokohobby.shop" class="button">Start Shopping</a>
+						<p>Please confirm your email address to start checking out.</p>
+						<a href="%s" class="button">Verify Email</a>
 						<p style="margin-top: 30px; color: #666; font-size: 14px;">
 							If you didn't create this account, please ignore this email.
 						</p>
@@ -184,7 +347,7 @@ func (s *EmailService) SendWelcomeEmail(email, username, userID string) error {
 				</div>
 			</body>
 			</html>
-		`, username))
+		`, username, verificationURL))
 
 		d := gomail.NewDialer(
 			os.Getenv("SMTP_HOST"),
@@ -201,3 +364,82 @@ func (s *EmailService) SendWelcomeEmail(email, username, userID string) error {
 	logrus.Infof("[MOCK] Welcome email sent to %s", username)
 	return nil
 }
+
+func (s *EmailService) SendVerificationEmail(email, username, verificationURL string, expiresAt time.Time) error {
+	// TODO: Implement actual SMTP email sending, like SendWelcomeEmail
+
+	log.Infof("[📨 EMAIL] Sending verification email to: %s", email)
+	log.Infof("   Username: %s", username)
+	log.Infof("   Verify email: %s", verificationURL)
+	log.Infof("   Link expires: %s", expiresAt.Format(time.RFC1123))
+
+	logrus.Infof("[MOCK] Verification email sent to %s", username)
+	return nil
+}
+
+func (s *EmailService) SendPasswordResetEmail(email, username, resetURL string, expiresAt time.Time) error {
+	// TODO: Implement actual SMTP email sending, like SendWelcomeEmail
+
+	log.Infof("[📨 EMAIL] Sending password reset email to: %s", email)
+	log.Infof("   Username: %s", username)
+	log.Infof("   Reset password: %s", resetURL)
+	log.Infof("   Link expires: %s", expiresAt.Format(time.RFC1123))
+
+	logrus.Infof("[MOCK] Password reset email sent to %s", username)
+	return nil
+}
+
+func (s *EmailService) SendPasswordChangedEmail(email, username, ipAddress string, changedAt time.Time) error {
+	// TODO: Implement actual SMTP email sending, like SendWelcomeEmail
+
+	log.Infof("[📨 EMAIL] Sending password changed notice to: %s", email)
+	log.Infof("   Username: %s", username)
+	log.Infof("   Changed at: %s from %s", changedAt.Format(time.RFC1123), ipAddress)
+
+	logrus.Infof("[MOCK] Password changed email sent to %s", username)
+	return nil
+}
+
+func (s *EmailService) SendAccountDeletionScheduledEmail(email, username string, scheduledFor time.Time) error {
+	// TODO: Implement actual SMTP email sending, like SendWelcomeEmail
+
+	log.Infof("[📨 EMAIL] Sending account deletion notice to: %s", email)
+	log.Infof("   Username: %s", username)
+	log.Infof("   Deleted on: %s unless they log in before then", scheduledFor.Format(time.RFC1123))
+
+	logrus.Infof("[MOCK] Account deletion email sent to %s", username)
+	return nil
+}
+
+func (s *EmailService) SendDataExportReadyEmail(email, username, downloadURL string, expiresAt time.Time) error {
+	log.Infof("[📨 EMAIL] Sending data export link to: %s", email)
+	log.Infof("   Username: %s", username)
+	log.Infof("   Download: %s", downloadURL)
+	log.Infof("   Expires: %s", expiresAt.Format(time.RFC1123))
+
+	logrus.Infof("[MOCK] Data export email sent to %s", username)
+	return nil
+}
+
+func (s *EmailService) SendSellerApplicationDecidedEmail(email, username, shopName, status, notes string) error {
+	log.Infof("[📨 EMAIL] Sending seller application %s email to: %s", status, email)
+	log.Infof("   Username: %s", username)
+	log.Infof("   Shop: %s", shopName)
+	if notes != "" {
+		log.Infof("   Notes: %s", notes)
+	}
+
+	logrus.Infof("[MOCK] Seller application email sent to %s", username)
+	return nil
+}
+
+func (s *EmailService) SendNotificationEmail(email, username, locale, subject, body string) error {
+	log.Infof("[📨 EMAIL] Sending notification email to: %s", email)
+	log.Infof("   Username: %s", username)
+	log.Infof("   Locale: %s", locale)
+	log.Infof("   Subject: %s", subject)
+	log.Infof("   Body: %d bytes", len(body))
+
+	logrus.Infof("[MOCK] Notification email sent to %s", username)
+	return nil
+}
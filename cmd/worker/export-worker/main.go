@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/configs"
+	dbGenerated "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/messaging/rabbitmq"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/logger"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/redisclient"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
+	rabbitmqpkg "github.com/RehanAthallahAzhar/tokohobby-messaging/rabbitmq"
+
+	_ "github.com/lib/pq"
+)
+
+// The export worker builds personal data exports. It writes the archives to
+// DATA_EXPORT_DIR, which the web service serves the downloads from.
+func main() {
+	log := logger.NewLogger()
+	log.SetFormatter(&logrus.JSONFormatter{
+		TimestampFormat: time.RFC3339,
+	})
+	log.SetOutput(os.Stdout)
+	log.SetLevel(logrus.InfoLevel)
+
+	log.Info("Starting Export Worker")
+
+	cfg, err := configs.LoadConfig(log)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	dbCredential := models.Credential{
+		Host:         cfg.Database.Host,
+		Username:     cfg.Database.User,
+		Password:     cfg.Database.Password,
+		DatabaseName: cfg.Database.Name,
+		Port:         cfg.Database.Port,
+		TimeZone:     cfg.Database.TimeZone,
+	}
+
+	connectCtx, cancelConnect := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelConnect()
+
+	conn, err := db.Connect(connectCtx, &dbCredential)
+	if err != nil {
+		log.Fatalf("DB connection error: %v", err)
+	}
+	defer conn.Close()
+
+	sqlcQueries := dbGenerated.New(conn)
+
+	redisClient, err := redisclient.NewRedisClient(&cfg.Redis, log)
+	if err != nil {
+		log.Fatalf("Failed to Inilialization redis client : %v", err)
+	}
+	defer redisClient.Close()
+
+	rmqConfig := &rabbitmqpkg.RabbitMQConfig{
+		URL:            cfg.RabbitMQ.URL,
+		MaxRetries:     cfg.RabbitMQ.MaxRetries,
+		RetryDelay:     cfg.RabbitMQ.RetryDelay,
+		PrefetchCount:  cfg.RabbitMQ.PrefetchCount,
+		ReconnectDelay: cfg.RabbitMQ.ReconnectDelay,
+	}
+
+	log.Infof("Connecting to RabbitMQ: %s", cfg.RabbitMQ.URL)
+
+	rmq, err := rabbitmqpkg.NewRabbitMQ(rmqConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
+	}
+	defer rmq.Close()
+
+	if err := rabbitmqpkg.SetupUserExchange(rmq); err != nil {
+		log.Fatalf("Failed to setup user exchange: %v", err)
+	}
+
+	eventPublisher := rabbitmq.NewEventPublisher(rmq, log)
+
+	keyRing, err := token.LoadKeyRing(cfg.Server.JWTKeysDir, cfg.Server.JWTActiveKeyID)
+	if err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
+	exportService, err := services.NewDataExportService(
+		repositories.NewDataExportRepository(sqlcQueries),
+		repositories.NewUserRepository(sqlcQueries, log),
+		repositories.NewSessionRepository(redisClient),
+		repositories.NewSignInEventRepository(sqlcQueries),
+		repositories.NewAuditLogRepository(sqlcQueries),
+		repositories.NewMFARepository(sqlcQueries),
+		repositories.NewWebAuthnCredentialRepository(sqlcQueries),
+		repositories.NewOAuthClientRepository(sqlcQueries),
+		repositories.NewAddressRepository(sqlcQueries),
+		repositories.NewPreferencesRepository(sqlcQueries),
+		repositories.NewSellerApplicationRepository(sqlcQueries),
+		repositories.NewThrottleRepository(redisClient),
+		keyRing,
+		eventPublisher,
+		services.DataExportConfig{
+			Dir:         cfg.DataExport.Dir,
+			DownloadURL: cfg.DataExport.DownloadURL,
+			LinkSecret:  []byte(cfg.DataExport.LinkSecret),
+			LinkTTL:     cfg.DataExport.LinkTTL,
+			Issuer:      cfg.Server.JWTIssuer,
+		},
+		log,
+	)
+	if err != nil {
+		log.Fatalf("Invalid data export config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	consumer := rabbitmqpkg.NewConsumer(rmq, rabbitmqpkg.ConsumerOptions{
+		QueueName:   "accounts.data_export",
+		WorkerCount: 1, // archives are built one at a time
+		AutoAck:     false,
+	}, func(ctx context.Context, body []byte) error {
+		var event rabbitmq.DataExportRequestedEvent
+
+		if err := rabbitmqpkg.UnmarshalMessage(body, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal: %w", err)
+		}
+
+		exportID, err := uuid.Parse(event.ExportID)
+		if err != nil {
+			return fmt.Errorf("invalid export id: %w", err)
+		}
+
+		log.Infof("Building data export %s for user: %s", event.ExportID, event.UserID)
+
+		return exportService.Build(ctx, exportID)
+	})
+
+	if err := consumer.DeclareQueue(true, false); err != nil {
+		log.Fatalf("Failed to declare queue: %v", err)
+	}
+	if err := consumer.BindQueue("user.events", "user.data_export_requested"); err != nil {
+		log.Fatalf("Failed to bind queue: %v", err)
+	}
+
+	go func() {
+		if err := consumer.Start(ctx); err != nil {
+			log.Warnf("Consumer error: %v", err)
+		}
+	}()
+
+	log.Info("Export worker is running. Waiting for messages... (Press Ctrl+C to exit)")
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Info("Shutting down export worker...")
+	cancel()
+
+	// Give the current export time to finish
+	time.Sleep(2 * time.Second)
+
+	log.Info("Export worker stopped gracefully")
+}
@@ -0,0 +1,3 @@
+-- Per-user token epoch, embedded in access tokens as "ver". Bumping it
+-- invalidates every outstanding access token of the user at once.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+-- Passkeys / security keys registered through WebAuthn. id is the credential
+-- id chosen by the authenticator.
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id BYTEA PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "name" TEXT NOT NULL DEFAULT '',
+    public_key BYTEA NOT NULL,
+    attestation_type TEXT NOT NULL,
+    transports TEXT[] NOT NULL DEFAULT '{}',
+    -- raw authenticator data flags (UP, UV, BE, BS) from registration / last use
+    flags SMALLINT NOT NULL,
+    aaguid BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    attachment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials (user_id);
@@ -0,0 +1,16 @@
+-- Account lifecycle. deleted_at stays the soft-delete marker; status says why
+-- an account can or cannot be used.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMPTZ;
+-- Only set while suspended; NULL means until lifted.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMPTZ;
+
+UPDATE users SET status = 'deleted' WHERE deleted_at IS NOT NULL;
+UPDATE users SET status = 'pending_verification' WHERE deleted_at IS NULL AND email_verified_at IS NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users ADD CONSTRAINT users_status_check
+    CHECK (status IN ('pending_verification', 'active', 'suspended', 'banned', 'deleted'));
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON users (status);
@@ -0,0 +1,9 @@
+-- Fails if a deleted account shares a username or email with a live one.
+DROP INDEX IF EXISTS users_email_unique;
+DROP INDEX IF EXISTS users_username_unique;
+ALTER TABLE users ADD CONSTRAINT users_username_unique UNIQUE (username);
+ALTER TABLE users ADD CONSTRAINT users_email_unique UNIQUE (email);
+
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_at;
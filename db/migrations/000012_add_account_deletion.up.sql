@@ -0,0 +1,15 @@
+-- Self-service deletion waits out a grace period before the account is
+-- soft-deleted; logging in again clears it.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users (deletion_scheduled_at)
+    WHERE deletion_scheduled_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+-- Usernames and emails only have to be unique among live accounts, so a
+-- deleted account does not hold on to them.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_unique;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_unique;
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_unique ON users (username) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_unique ON users (email) WHERE deleted_at IS NULL;
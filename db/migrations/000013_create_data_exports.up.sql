@@ -0,0 +1,15 @@
+-- Personal data exports. The worker writes the archive to disk and fills in
+-- file_path; expires_at is when the download link stops working.
+CREATE TABLE IF NOT EXISTS data_exports (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'ready', 'failed', 'expired')),
+    file_path TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_data_exports_expires_at ON data_exports (expires_at) WHERE status = 'ready';
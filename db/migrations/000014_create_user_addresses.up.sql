@@ -0,0 +1,31 @@
+-- Structured shipping addresses. A user has at most one default; the service
+-- keeps exactly one as long as the user has any address. The constraint is
+-- deferred so a single statement can move the default from one row to another.
+CREATE TABLE IF NOT EXISTS user_addresses (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_name TEXT NOT NULL,
+    phone_number TEXT NOT NULL DEFAULT '',
+    street TEXT NOT NULL,
+    district TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    province TEXT NOT NULL DEFAULT '',
+    postal_code TEXT NOT NULL DEFAULT '',
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_addresses_one_default EXCLUDE USING btree (user_id WITH =) WHERE (is_default)
+        DEFERRABLE INITIALLY DEFERRED
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_addresses_user_id ON user_addresses (user_id, created_at);
+
+-- The free-text users.address becomes each user's default address. Only the
+-- street is known; the owner fills in the rest when editing it. users.address
+-- itself stays for clients that still read it.
+INSERT INTO user_addresses (id, user_id, recipient_name, phone_number, street, is_default, created_at, updated_at)
+SELECT gen_random_uuid(), u.id, u."name", u.phone_number, btrim(u."address"), TRUE, NOW(), NOW()
+FROM users u
+WHERE btrim(u."address") <> ''
+    AND u.deleted_at IS NULL
+    AND NOT EXISTS (SELECT 1 FROM user_addresses a WHERE a.user_id = u.id);
@@ -0,0 +1,5 @@
+ALTER TABLE user_addresses DROP COLUMN IF EXISTS village_code;
+ALTER TABLE user_addresses DROP COLUMN IF EXISTS district_code;
+ALTER TABLE user_addresses DROP COLUMN IF EXISTS city_code;
+ALTER TABLE user_addresses DROP COLUMN IF EXISTS province_code;
+ALTER TABLE user_addresses DROP COLUMN IF EXISTS village;
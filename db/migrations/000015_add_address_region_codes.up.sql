@@ -0,0 +1,8 @@
+-- Kemendagri region codes of each address level, next to the display names.
+-- They are empty for levels the embedded region dataset does not cover and for
+-- addresses saved before this migration.
+ALTER TABLE user_addresses ADD COLUMN IF NOT EXISTS village TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_addresses ADD COLUMN IF NOT EXISTS province_code TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_addresses ADD COLUMN IF NOT EXISTS city_code TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_addresses ADD COLUMN IF NOT EXISTS district_code TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_addresses ADD COLUMN IF NOT EXISTS village_code TEXT NOT NULL DEFAULT '';
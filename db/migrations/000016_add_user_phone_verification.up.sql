@@ -0,0 +1,27 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMPTZ;
+
+-- Bring existing numbers to E.164 where the intent is clear, assuming
+-- Indonesia (+62) for numbers without a country code. Anything else is kept
+-- as typed and has to be corrected before it can be verified.
+WITH cleaned AS (
+    SELECT id, regexp_replace(phone_number, '[[:space:]()./-]', '', 'g') AS n
+    FROM users
+    WHERE phone_number <> ''
+)
+UPDATE users
+SET phone_number = CASE
+        WHEN cleaned.n ~ '^\+[1-9][0-9]{7,14}$' THEN cleaned.n
+        WHEN cleaned.n ~ '^00[1-9][0-9]{7,14}$' THEN '+' || substr(cleaned.n, 3)
+        WHEN cleaned.n ~ '^0[1-9][0-9]{6,12}$' THEN '+62' || substr(cleaned.n, 2)
+        WHEN cleaned.n ~ '^62[1-9][0-9]{5,12}$' THEN '+' || cleaned.n
+        WHEN cleaned.n ~ '^8[0-9]{7,12}$' THEN '+62' || cleaned.n
+        ELSE users.phone_number
+    END
+FROM cleaned
+WHERE users.id = cleaned.id;
+
+-- A verified number signs in to one account only. Unverified numbers may
+-- repeat, since anyone can type any number into their profile.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_verified_phone_number
+    ON users (phone_number)
+    WHERE phone_verified_at IS NOT NULL AND deleted_at IS NULL;
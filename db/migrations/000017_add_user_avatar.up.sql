@@ -0,0 +1,6 @@
+-- avatar_key is the storage prefix of the current avatar's variants and
+-- avatar_url the public address of the default one. Both are empty for users
+-- without an avatar.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS avatar_key TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS avatar_url TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,14 @@
+-- A row is written the first time a user changes a preference; until then the
+-- configured defaults apply. Email opt-ins only cover non-transactional mail
+-- and start out off.
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    locale TEXT NOT NULL,
+    timezone TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    email_newsletter BOOLEAN NOT NULL DEFAULT FALSE,
+    email_promotions BOOLEAN NOT NULL DEFAULT FALSE,
+    email_recommendations BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
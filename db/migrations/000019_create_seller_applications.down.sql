@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS seller_application_documents;
+DROP TABLE IF EXISTS seller_applications;
@@ -0,0 +1,31 @@
+-- Applications to sell on the marketplace. NIK, NPWP and the document files
+-- are sealed with SELLER_KYC_KEY before they are stored. A user has at most
+-- one pending application; rejected users may apply again.
+CREATE TABLE IF NOT EXISTS seller_applications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    shop_name TEXT NOT NULL,
+    nik TEXT NOT NULL,
+    npwp TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    review_notes TEXT NOT NULL DEFAULT '',
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_seller_applications_pending_user
+    ON seller_applications(user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_seller_applications_user_id ON seller_applications(user_id);
+CREATE INDEX IF NOT EXISTS idx_seller_applications_status ON seller_applications(status, created_at);
+
+CREATE TABLE IF NOT EXISTS seller_application_documents (
+    application_id UUID NOT NULL REFERENCES seller_applications(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    data TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (application_id, kind)
+);
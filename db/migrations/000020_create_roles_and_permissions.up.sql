@@ -0,0 +1,73 @@
+-- Roles group permissions; a user has the roles in user_roles plus the
+-- primary role in users.role, which stays for services that still read it.
+-- Permissions are named resource:action and embedded in access tokens.
+CREATE TABLE IF NOT EXISTS roles (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    -- System roles are assigned by the service itself and cannot be deleted.
+    is_system BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    "role" TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    permission TEXT NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY ("role", permission)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "role" TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    granted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, "role")
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_role ON user_roles("role");
+
+INSERT INTO roles (name, description, is_system) VALUES
+    ('user', 'Every registered account', TRUE),
+    ('seller', 'Approved marketplace sellers', TRUE),
+    ('admin', 'Full access to account administration', TRUE),
+    ('support', 'Customer support staff', FALSE),
+    ('moderator', 'Reviews sellers and content', FALSE)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read', 'View accounts in the user directory'),
+    ('users:write', 'Change account status, delete and restore accounts'),
+    ('sessions:manage', 'List and revoke the sessions of other users'),
+    ('mfa:manage', 'Choose the roles that must use two-factor authentication'),
+    ('oauth_clients:manage', 'Register and remove OAuth clients'),
+    ('seller_applications:review', 'Review, approve and reject seller applications'),
+    ('roles:manage', 'Manage roles and permissions'),
+    ('products:write', 'List and edit products in the catalog')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions ("role", permission)
+SELECT 'admin', name FROM permissions
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions ("role", permission) VALUES
+    ('seller', 'products:write'),
+    ('support', 'users:read'),
+    ('support', 'sessions:manage'),
+    ('moderator', 'users:read'),
+    ('moderator', 'seller_applications:review')
+ON CONFLICT DO NOTHING;
+
+-- Registration used to accept any role, so keep whatever is in use.
+INSERT INTO roles (name)
+SELECT DISTINCT "role" FROM users
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO user_roles (user_id, "role")
+SELECT id, "role" FROM users
+ON CONFLICT DO NOTHING;
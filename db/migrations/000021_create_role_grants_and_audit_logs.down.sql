@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'audit_log:read';
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS role_grant_requests;
+ALTER TABLE roles DROP COLUMN IF EXISTS is_privileged;
@@ -0,0 +1,50 @@
+-- Granting a privileged role takes a request and a second admin's approval.
+-- Roles that can manage roles are always privileged, otherwise anyone holding
+-- roles:manage could make themselves admin through them.
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS is_privileged BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE roles
+SET is_privileged = TRUE
+WHERE name = 'admin'
+    OR name IN (SELECT "role" FROM role_permissions WHERE permission = 'roles:manage');
+
+CREATE TABLE IF NOT EXISTS role_grant_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    "role" TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    decision_notes TEXT NOT NULL DEFAULT '',
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_role_grant_requests_pending
+    ON role_grant_requests (user_id, "role")
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_role_grant_requests_status ON role_grant_requests (status, created_at);
+
+-- The audit trail outlives the accounts it mentions, so it has no foreign
+-- keys to users.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id UUID,
+    target_user_id UUID,
+    action TEXT NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target_user_id ON audit_logs (target_user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id, id DESC);
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit_log:read', 'Read the audit log')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions ("role", permission) VALUES
+    ('admin', 'audit_log:read')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+-- Impersonation tokens carry the target's permissions, so only admins may
+-- issue them until another role is given the permission.
+INSERT INTO permissions (name, description) VALUES
+    ('users:impersonate', 'Act as a user to see what they see')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions ("role", permission) VALUES
+    ('admin', 'users:impersonate')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+-- The status an account had when it was deleted, so a restore cannot lift a
+-- ban. Accounts deleted before this migration have none and restore as before.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_before_deletion TEXT;
@@ -0,0 +1,15 @@
+-- Sign-in history for the user's data export. Sessions only show the sign-ins
+-- that are still live; these rows outlive them and go with the account when
+-- it is purged. Only the newest entries per user are kept.
+CREATE TABLE IF NOT EXISTS sign_in_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL,
+    client_id TEXT NOT NULL DEFAULT '',
+    device TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sign_in_events_user_id ON sign_in_events (user_id, id DESC);
@@ -0,0 +1,3 @@
+UPDATE roles
+SET is_privileged = FALSE
+WHERE name IN ('support', 'moderator');
@@ -0,0 +1,13 @@
+-- Every role with a staff permission needs a second admin to grant, not just
+-- those with roles:manage. This covers the seeded support and moderator roles.
+UPDATE roles
+SET is_privileged = TRUE, updated_at = NOW()
+WHERE name IN (
+    SELECT "role"
+    FROM role_permissions
+    WHERE permission IN (
+        'users:read', 'users:write', 'sessions:manage', 'mfa:manage',
+        'oauth_clients:manage', 'seller_applications:review', 'roles:manage',
+        'audit_log:read', 'users:impersonate'
+    )
+);
@@ -0,0 +1,11 @@
+-- System permissions are checked by this service's routes and code, so they
+-- cannot be deleted. Without roles:manage nobody could even create it again.
+ALTER TABLE permissions ADD COLUMN IF NOT EXISTS is_system BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE permissions
+SET is_system = TRUE
+WHERE name IN (
+    'users:read', 'users:write', 'sessions:manage', 'mfa:manage',
+    'oauth_clients:manage', 'seller_applications:review', 'roles:manage',
+    'audit_log:read', 'users:impersonate', 'products:write'
+);
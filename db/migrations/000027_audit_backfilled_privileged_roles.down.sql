@@ -0,0 +1 @@
+DELETE FROM audit_logs WHERE action = 'role.backfilled';
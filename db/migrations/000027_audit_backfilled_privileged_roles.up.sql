@@ -0,0 +1,18 @@
+-- Migration 20 copied every users.role into user_roles, including admin roles
+-- users gave themselves while registration still accepted any role. Record
+-- the privileged ones, which nobody approved, so an admin can review them with
+-- GET /api/accounts/audit-log?action=role.backfilled and revoke the rest.
+INSERT INTO audit_logs (actor_id, target_user_id, action, details)
+SELECT NULL, user_roles.user_id, 'role.backfilled',
+    jsonb_build_object('role', user_roles."role", 'reason', 'copied from users.role by migration 20, never approved')
+FROM user_roles
+JOIN roles ON roles.name = user_roles."role"
+WHERE user_roles.granted_by IS NULL
+    AND roles.is_privileged
+    AND NOT EXISTS (
+        SELECT 1
+        FROM audit_logs
+        WHERE audit_logs.action = 'role.backfilled'
+            AND audit_logs.target_user_id = user_roles.user_id
+            AND audit_logs.details->>'role' = user_roles."role"
+    );
@@ -14,13 +14,21 @@ import (
 type Postgres struct{}
 
 func Connect(ctx context.Context, credential *models.Credential) (*sql.DB, error) {
+	// Every column is TIMESTAMPTZ, so the session zone only decides how
+	// timestamps come back; UTC keeps it out of the data.
+	timeZone := credential.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+
 	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Jakarta",
+		"host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=%s",
 		credential.Host,
 		credential.Username,
 		credential.Password,
 		credential.DatabaseName,
 		credential.Port,
+		timeZone,
 	)
 
 	db, err := sql.Open("postgres", dsn)
@@ -0,0 +1,106 @@
+-- name: CreateUserAddress :one
+-- The first address a user saves becomes the default. Saving another one as
+-- the default takes the flag from the previous default.
+WITH cleared AS (
+    UPDATE user_addresses
+    SET is_default = FALSE, updated_at = now()
+    WHERE user_id = sqlc.arg('user_id') AND is_default AND sqlc.arg('is_default')::boolean
+)
+INSERT INTO user_addresses (
+    id,
+    user_id,
+    recipient_name,
+    phone_number,
+    street,
+    district,
+    city,
+    province,
+    postal_code,
+    village,
+    province_code,
+    city_code,
+    district_code,
+    village_code,
+    is_default
+) VALUES (
+    sqlc.arg('id'),
+    sqlc.arg('user_id'),
+    sqlc.arg('recipient_name'),
+    sqlc.arg('phone_number'),
+    sqlc.arg('street'),
+    sqlc.arg('district'),
+    sqlc.arg('city'),
+    sqlc.arg('province'),
+    sqlc.arg('postal_code'),
+    sqlc.arg('village'),
+    sqlc.arg('province_code'),
+    sqlc.arg('city_code'),
+    sqlc.arg('district_code'),
+    sqlc.arg('village_code'),
+    sqlc.arg('is_default')::boolean OR NOT EXISTS (SELECT 1 FROM user_addresses WHERE user_id = sqlc.arg('user_id'))
+) RETURNING *;
+
+-- name: GetUserAddress :one
+SELECT *
+FROM user_addresses
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListUserAddresses :many
+SELECT *
+FROM user_addresses
+WHERE user_id = $1
+ORDER BY is_default DESC, created_at;
+
+-- name: CountUserAddresses :one
+SELECT count(*)
+FROM user_addresses
+WHERE user_id = $1;
+
+-- name: UpdateUserAddress :one
+UPDATE user_addresses
+SET
+    recipient_name = $3,
+    phone_number = $4,
+    street = $5,
+    district = $6,
+    city = $7,
+    province = $8,
+    postal_code = $9,
+    village = $10,
+    province_code = $11,
+    city_code = $12,
+    district_code = $13,
+    village_code = $14,
+    updated_at = now()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: SetDefaultUserAddress :execrows
+-- Moves the default flag in one statement; nothing changes when the address
+-- does not belong to the user.
+UPDATE user_addresses
+SET is_default = (id = sqlc.arg('id')), updated_at = now()
+WHERE user_id = sqlc.arg('user_id')
+    AND (id = sqlc.arg('id') OR is_default)
+    AND EXISTS (
+        SELECT 1 FROM user_addresses a
+        WHERE a.id = sqlc.arg('id') AND a.user_id = sqlc.arg('user_id')
+    );
+
+-- name: DeleteUserAddress :execrows
+-- Deleting the default promotes the most recently added remaining address.
+WITH promoted AS (
+    UPDATE user_addresses
+    SET is_default = TRUE, updated_at = now()
+    WHERE user_addresses.id = (
+        SELECT a.id FROM user_addresses a
+        WHERE a.user_id = sqlc.arg('user_id') AND a.id <> sqlc.arg('id')
+        ORDER BY a.created_at DESC
+        LIMIT 1
+    ) AND EXISTS (
+        SELECT 1 FROM user_addresses d
+        WHERE d.id = sqlc.arg('id') AND d.user_id = sqlc.arg('user_id') AND d.is_default
+    )
+)
+DELETE FROM user_addresses
+WHERE id = sqlc.arg('id') AND user_id = sqlc.arg('user_id');
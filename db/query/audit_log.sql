@@ -0,0 +1,21 @@
+-- name: ListAuditLogs :many
+-- Newest first; before_id pages back through older entries.
+SELECT *
+FROM audit_logs
+WHERE (sqlc.narg('target_user_id')::uuid IS NULL OR target_user_id = sqlc.narg('target_user_id')::uuid)
+    AND (sqlc.narg('actor_id')::uuid IS NULL OR actor_id = sqlc.narg('actor_id')::uuid)
+    AND (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action')::text)
+    AND (sqlc.narg('before_id')::bigint IS NULL OR id < sqlc.narg('before_id')::bigint)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CreateAuditLog :exec
+INSERT INTO audit_logs (actor_id, target_user_id, action, details)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListUserAuditLogs :many
+-- Every entry about the user or by them, oldest first, for their data export.
+SELECT *
+FROM audit_logs
+WHERE target_user_id = sqlc.arg('user_id')::uuid OR actor_id = sqlc.arg('user_id')::uuid
+ORDER BY id;
@@ -0,0 +1,47 @@
+-- name: CreateDataExport :one
+INSERT INTO data_exports (
+    id,
+    user_id
+) VALUES ($1, $2) RETURNING *;
+
+-- name: GetDataExport :one
+SELECT *
+FROM data_exports
+WHERE id = $1;
+
+-- name: ListDataExports :many
+SELECT *
+FROM data_exports
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 20;
+
+-- name: StartDataExport :one
+-- processing is accepted too, so a job redelivered after a worker crash is
+-- picked up again.
+UPDATE data_exports
+SET status = 'processing'
+WHERE id = $1 AND status IN ('pending', 'processing')
+RETURNING *;
+
+-- name: CompleteDataExport :exec
+UPDATE data_exports
+SET status = 'ready', file_path = $2, completed_at = now(), expires_at = $3
+WHERE id = $1;
+
+-- name: FailDataExport :exec
+UPDATE data_exports
+SET status = 'failed', completed_at = now()
+WHERE id = $1;
+
+-- name: ListExpiredDataExports :many
+SELECT *
+FROM data_exports
+WHERE status = 'ready' AND expires_at < $1
+ORDER BY expires_at
+LIMIT $2;
+
+-- name: ExpireDataExport :exec
+UPDATE data_exports
+SET status = 'expired', file_path = ''
+WHERE id = $1;
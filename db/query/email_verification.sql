@@ -0,0 +1,27 @@
+-- name: CreateEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (
+    token_hash,
+    user_id,
+    email,
+    expires_at
+) VALUES ($1, $2, $3, $4);
+
+-- name: DeleteEmailVerificationTokens :exec
+DELETE FROM email_verification_tokens
+WHERE user_id = $1;
+
+-- name: UseEmailVerificationToken :one
+UPDATE email_verification_tokens
+SET used_at = now()
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+RETURNING user_id, email;
+
+-- name: MarkEmailVerified :execrows
+UPDATE users
+SET email_verified_at = COALESCE(email_verified_at, now()), updated_at = now()
+WHERE id = $1 AND email = $2 AND deleted_at IS NULL;
+
+-- name: ActivatePendingUser :execrows
+UPDATE users
+SET status = 'active', status_reason = '', status_changed_at = now(), updated_at = now()
+WHERE id = $1 AND status = 'pending_verification' AND deleted_at IS NULL;
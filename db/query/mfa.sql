@@ -0,0 +1,72 @@
+-- name: GetUserMFA :one
+SELECT *
+FROM user_mfa
+WHERE user_id = $1;
+
+-- name: UpsertPendingUserMFA :one
+INSERT INTO user_mfa (
+    user_id,
+    secret
+) VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret, last_used_step = 0, updated_at = now()
+WHERE user_mfa.enabled_at IS NULL
+RETURNING *;
+
+-- name: EnableUserMFA :one
+UPDATE user_mfa
+SET enabled_at = now(), last_used_step = $2, updated_at = now()
+WHERE user_id = $1 AND enabled_at IS NULL RETURNING *;
+
+-- name: UseTOTPStep :execrows
+UPDATE user_mfa
+SET last_used_step = $2, updated_at = now()
+WHERE user_id = $1 AND last_used_step < $2;
+
+-- name: DeleteUserMFA :exec
+DELETE FROM user_mfa
+WHERE user_id = $1;
+
+-- name: CreateRecoveryCodes :exec
+INSERT INTO user_recovery_codes (user_id, code_hash)
+SELECT $1, unnest(sqlc.arg(code_hashes)::text[]);
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM user_recovery_codes
+WHERE user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE user_recovery_codes
+SET used_at = now()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT COUNT(*)
+FROM user_recovery_codes
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: ListMFARequiredRoles :many
+SELECT "role"
+FROM mfa_role_requirements
+ORDER BY "role";
+
+-- name: AddMFARequiredRole :exec
+INSERT INTO mfa_role_requirements ("role")
+VALUES ($1)
+ON CONFLICT ("role") DO NOTHING;
+
+-- name: DeleteMFARequiredRole :execrows
+DELETE FROM mfa_role_requirements
+WHERE "role" = $1;
+
+-- name: IsMFARequiredForUser :one
+-- True when any of the user's roles, granted or primary, requires MFA.
+SELECT EXISTS (
+    SELECT 1
+    FROM mfa_role_requirements
+    WHERE "role" IN (
+        SELECT "role" FROM user_roles WHERE user_id = $1
+        UNION
+        SELECT "role" FROM users WHERE id = $1
+    )
+);
@@ -0,0 +1,46 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (
+    id,
+    "name",
+    secret_hash,
+    redirect_uris,
+    allowed_scopes,
+    is_first_party
+) VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: GetOAuthClient :one
+SELECT *
+FROM oauth_clients
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ListOAuthClients :many
+SELECT *
+FROM oauth_clients
+WHERE deleted_at IS NULL
+ORDER BY created_at;
+
+-- name: DeleteOAuthClient :one
+UPDATE oauth_clients
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL RETURNING *;
+
+-- name: GetOAuthConsent :one
+SELECT *
+FROM oauth_consents
+WHERE user_id = $1 AND client_id = $2;
+
+-- name: UpsertOAuthConsent :one
+INSERT INTO oauth_consents (
+    user_id,
+    client_id,
+    scopes
+) VALUES ($1, $2, $3)
+ON CONFLICT (user_id, client_id) DO UPDATE
+SET scopes = EXCLUDED.scopes, updated_at = now()
+RETURNING *;
+
+-- name: ListOAuthConsents :many
+SELECT *
+FROM oauth_consents
+WHERE user_id = $1
+ORDER BY created_at;
@@ -0,0 +1,34 @@
+-- name: GetUserPreferences :one
+SELECT *
+FROM user_preferences
+WHERE user_id = $1;
+
+-- name: UpsertUserPreferences :one
+-- Only the preferences given change. A user's first change also stores the
+-- defaults for the rest, so later default changes do not affect them.
+INSERT INTO user_preferences (
+    user_id,
+    locale,
+    timezone,
+    currency,
+    email_newsletter,
+    email_promotions,
+    email_recommendations
+) VALUES (
+    sqlc.arg('user_id'),
+    COALESCE(sqlc.narg('locale')::text, sqlc.arg('default_locale')::text),
+    COALESCE(sqlc.narg('timezone')::text, sqlc.arg('default_timezone')::text),
+    COALESCE(sqlc.narg('currency')::text, sqlc.arg('default_currency')::text),
+    COALESCE(sqlc.narg('email_newsletter')::boolean, FALSE),
+    COALESCE(sqlc.narg('email_promotions')::boolean, FALSE),
+    COALESCE(sqlc.narg('email_recommendations')::boolean, FALSE)
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    locale = COALESCE(sqlc.narg('locale')::text, user_preferences.locale),
+    timezone = COALESCE(sqlc.narg('timezone')::text, user_preferences.timezone),
+    currency = COALESCE(sqlc.narg('currency')::text, user_preferences.currency),
+    email_newsletter = COALESCE(sqlc.narg('email_newsletter')::boolean, user_preferences.email_newsletter),
+    email_promotions = COALESCE(sqlc.narg('email_promotions')::boolean, user_preferences.email_promotions),
+    email_recommendations = COALESCE(sqlc.narg('email_recommendations')::boolean, user_preferences.email_recommendations),
+    updated_at = now()
+RETURNING *;
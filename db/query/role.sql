@@ -0,0 +1,171 @@
+-- name: ListRoles :many
+SELECT
+    r.name,
+    r.description,
+    r.is_system,
+    r.is_privileged,
+    r.created_at,
+    r.updated_at,
+    COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')::text[] AS permissions
+FROM roles r
+LEFT JOIN role_permissions rp ON rp."role" = r.name
+GROUP BY r.name
+ORDER BY r.name;
+
+-- name: GetRole :one
+SELECT
+    r.name,
+    r.description,
+    r.is_system,
+    r.is_privileged,
+    r.created_at,
+    r.updated_at,
+    COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}')::text[] AS permissions
+FROM roles r
+LEFT JOIN role_permissions rp ON rp."role" = r.name
+WHERE r.name = $1
+GROUP BY r.name;
+
+-- name: CreateRole :one
+-- Inserts the role with its permissions and records it in one statement.
+WITH created AS (
+    INSERT INTO roles (name, description, is_privileged)
+    VALUES (sqlc.arg('name'), sqlc.arg('description'), sqlc.arg('is_privileged'))
+    RETURNING *
+), granted AS (
+    INSERT INTO role_permissions ("role", permission)
+    SELECT created.name, unnest(sqlc.arg('permissions')::text[])
+    FROM created
+), audited AS (
+    INSERT INTO audit_logs (actor_id, action, details)
+    SELECT sqlc.arg('actor_id')::uuid, 'role.created',
+        jsonb_build_object('role', created.name, 'privileged', created.is_privileged, 'permissions', sqlc.arg('permissions')::text[])
+    FROM created
+)
+SELECT name, description, is_system, created_at, updated_at, is_privileged
+FROM created;
+
+-- name: UpdateRole :one
+-- Replaces the description and permission set of a role and records it.
+-- Permissions kept across the update are left in place rather than deleted
+-- and inserted again, which a single statement cannot do.
+WITH updated AS (
+    UPDATE roles
+    SET description = sqlc.arg('description'), is_privileged = sqlc.arg('is_privileged'), updated_at = now()
+    WHERE roles.name = sqlc.arg('name')
+    RETURNING *
+), revoked AS (
+    DELETE FROM role_permissions
+    USING updated
+    WHERE role_permissions."role" = updated.name
+        AND NOT (role_permissions.permission = ANY(sqlc.arg('permissions')::text[]))
+), granted AS (
+    INSERT INTO role_permissions ("role", permission)
+    SELECT updated.name, unnest(sqlc.arg('permissions')::text[])
+    FROM updated
+    ON CONFLICT DO NOTHING
+), audited AS (
+    INSERT INTO audit_logs (actor_id, action, details)
+    SELECT sqlc.arg('actor_id')::uuid, 'role.updated',
+        jsonb_build_object('role', updated.name, 'description', updated.description, 'privileged', updated.is_privileged, 'permissions', sqlc.arg('permissions')::text[])
+    FROM updated
+)
+SELECT name, description, is_system, created_at, updated_at, is_privileged
+FROM updated;
+
+-- name: DeleteRole :execrows
+-- System roles and roles still used as a primary role are kept. The audit
+-- row is the statement's own, so the row count says whether the role went.
+WITH deleted AS (
+    DELETE FROM roles
+    WHERE roles.name = sqlc.arg('name')
+        AND NOT roles.is_system
+        AND NOT EXISTS (SELECT 1 FROM users WHERE users."role" = roles.name)
+    RETURNING roles.name
+)
+INSERT INTO audit_logs (actor_id, action, details)
+SELECT sqlc.arg('actor_id')::uuid, 'role.deleted', jsonb_build_object('role', deleted.name)
+FROM deleted;
+
+-- name: ListPermissions :many
+SELECT *
+FROM permissions
+ORDER BY name;
+
+-- name: GetPermission :one
+SELECT *
+FROM permissions
+WHERE name = $1;
+
+-- name: CreatePermission :one
+-- New permissions are granted to the admin role straight away, so admins
+-- never lose access to something they can see.
+WITH created AS (
+    INSERT INTO permissions (name, description)
+    VALUES (sqlc.arg('name'), sqlc.arg('description'))
+    RETURNING *
+), granted AS (
+    INSERT INTO role_permissions ("role", permission)
+    SELECT 'admin', created.name
+    FROM created
+), audited AS (
+    INSERT INTO audit_logs (actor_id, action, details)
+    SELECT sqlc.arg('actor_id')::uuid, 'permission.created', jsonb_build_object('permission', created.name)
+    FROM created
+)
+SELECT name, description, created_at, is_system
+FROM created;
+
+-- name: DeletePermission :execrows
+-- System permissions are checked by the service itself and are never deleted.
+WITH deleted AS (
+    DELETE FROM permissions
+    WHERE permissions.name = sqlc.arg('name') AND NOT permissions.is_system
+    RETURNING permissions.name
+)
+INSERT INTO audit_logs (actor_id, action, details)
+SELECT sqlc.arg('actor_id')::uuid, 'permission.deleted', jsonb_build_object('permission', deleted.name)
+FROM deleted;
+
+-- name: ListRoleHolders :many
+-- Users holding the role, granted or as their primary role.
+SELECT user_id
+FROM user_roles
+WHERE user_roles."role" = $1
+UNION
+SELECT id
+FROM users
+WHERE users."role" = $1;
+
+-- name: ListPermissionHolders :many
+-- Users holding the permission through any of their roles.
+SELECT ur.user_id
+FROM user_roles ur
+JOIN role_permissions rp ON rp."role" = ur."role"
+WHERE rp.permission = $1
+UNION
+SELECT u.id
+FROM users u
+JOIN role_permissions rp ON rp."role" = u."role"
+WHERE rp.permission = $1;
+
+-- name: ListUserRoles :many
+-- The roles granted to a user together with their primary role.
+SELECT "role"
+FROM user_roles
+WHERE user_id = $1
+UNION
+SELECT "role"
+FROM users
+WHERE id = $1
+ORDER BY "role";
+
+-- name: ListUserPermissions :many
+SELECT DISTINCT rp.permission
+FROM role_permissions rp
+WHERE rp."role" IN (
+    SELECT ur."role" FROM user_roles ur WHERE ur.user_id = $1
+    UNION
+    SELECT u."role" FROM users u WHERE u.id = $1
+)
+ORDER BY rp.permission;
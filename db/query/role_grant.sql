@@ -0,0 +1,111 @@
+-- name: GrantUserRole :one
+-- Grants a role and records it in the audit log in one statement. Returns no
+-- row when the user already has the role.
+WITH granted AS (
+    INSERT INTO user_roles (user_id, "role", granted_by)
+    VALUES (sqlc.arg('user_id'), sqlc.arg('role'), sqlc.arg('granted_by'))
+    ON CONFLICT (user_id, "role") DO NOTHING
+    RETURNING *
+), audited AS (
+    INSERT INTO audit_logs (actor_id, target_user_id, action, details)
+    SELECT granted.granted_by, granted.user_id, 'role.granted',
+        jsonb_build_object('role', granted."role", 'reason', sqlc.arg('reason')::text)
+    FROM granted
+)
+SELECT user_id, "role", granted_by, created_at
+FROM granted;
+
+-- name: RevokeUserRole :one
+-- Removes a granted role and records it. Revoking the primary role sets it
+-- back to user. Returns how many rows changed, zero when the user did not
+-- have the role.
+WITH revoked AS (
+    DELETE FROM user_roles
+    WHERE user_roles.user_id = sqlc.arg('user_id') AND user_roles."role" = sqlc.arg('role')
+    RETURNING user_roles.user_id
+), demoted AS (
+    UPDATE users
+    SET "role" = 'user', updated_at = now()
+    WHERE users.id = sqlc.arg('user_id') AND users."role" = sqlc.arg('role')
+    RETURNING users.id
+), audited AS (
+    INSERT INTO audit_logs (actor_id, target_user_id, action, details)
+    SELECT sqlc.arg('actor_id')::uuid, sqlc.arg('user_id')::uuid, 'role.revoked',
+        jsonb_build_object('role', sqlc.arg('role')::text, 'reason', sqlc.arg('reason')::text)
+    WHERE EXISTS (SELECT 1 FROM revoked) OR EXISTS (SELECT 1 FROM demoted)
+)
+SELECT ((SELECT COUNT(*) FROM revoked) + (SELECT COUNT(*) FROM demoted))::bigint AS changes;
+
+-- name: CreateRoleGrantRequest :one
+WITH requested AS (
+    INSERT INTO role_grant_requests (user_id, "role", requested_by, reason)
+    VALUES (sqlc.arg('user_id'), sqlc.arg('role'), sqlc.arg('requested_by'), sqlc.arg('reason'))
+    RETURNING *
+), audited AS (
+    INSERT INTO audit_logs (actor_id, target_user_id, action, details)
+    SELECT requested.requested_by, requested.user_id, 'role.grant_requested',
+        jsonb_build_object('role', requested."role", 'request_id', requested.id, 'reason', requested.reason)
+    FROM requested
+)
+SELECT id, user_id, "role", requested_by, reason, status, decision_notes, decided_by, decided_at, created_at, updated_at
+FROM requested;
+
+-- name: GetRoleGrantRequest :one
+SELECT *
+FROM role_grant_requests
+WHERE id = $1;
+
+-- name: ListRoleGrantRequests :many
+-- Oldest first, optionally only one status.
+SELECT *
+FROM role_grant_requests
+WHERE sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ApproveRoleGrantRequest :one
+-- Approves a pending request, grants the role and records it in one
+-- statement.
+WITH approved AS (
+    UPDATE role_grant_requests
+    SET
+        status = 'approved',
+        decision_notes = sqlc.arg('decision_notes'),
+        decided_by = sqlc.arg('decided_by'),
+        decided_at = now(),
+        updated_at = now()
+    WHERE role_grant_requests.id = sqlc.arg('id') AND role_grant_requests.status = 'pending'
+    RETURNING *
+), granted AS (
+    INSERT INTO user_roles (user_id, "role", granted_by)
+    SELECT approved.user_id, approved."role", approved.decided_by
+    FROM approved
+    ON CONFLICT (user_id, "role") DO NOTHING
+), audited AS (
+    INSERT INTO audit_logs (actor_id, target_user_id, action, details)
+    SELECT approved.decided_by, approved.user_id, 'role.granted',
+        jsonb_build_object('role', approved."role", 'request_id', approved.id, 'requested_by', approved.requested_by, 'reason', approved.reason)
+    FROM approved
+)
+SELECT id, user_id, "role", requested_by, reason, status, decision_notes, decided_by, decided_at, created_at, updated_at
+FROM approved;
+
+-- name: RejectRoleGrantRequest :one
+WITH rejected AS (
+    UPDATE role_grant_requests
+    SET
+        status = 'rejected',
+        decision_notes = sqlc.arg('decision_notes'),
+        decided_by = sqlc.arg('decided_by'),
+        decided_at = now(),
+        updated_at = now()
+    WHERE role_grant_requests.id = sqlc.arg('id') AND role_grant_requests.status = 'pending'
+    RETURNING *
+), audited AS (
+    INSERT INTO audit_logs (actor_id, target_user_id, action, details)
+    SELECT rejected.decided_by, rejected.user_id, 'role.grant_rejected',
+        jsonb_build_object('role', rejected."role", 'request_id', rejected.id, 'notes', rejected.decision_notes)
+    FROM rejected
+)
+SELECT id, user_id, "role", requested_by, reason, status, decision_notes, decided_by, decided_at, created_at, updated_at
+FROM rejected;
@@ -0,0 +1,81 @@
+-- name: CreateSellerApplication :one
+-- Inserts the application with its documents in one statement, so an
+-- application never exists without them.
+WITH application AS (
+    INSERT INTO seller_applications (
+        user_id,
+        shop_name,
+        nik,
+        npwp
+    ) VALUES (
+        sqlc.arg('user_id'),
+        sqlc.arg('shop_name'),
+        sqlc.arg('nik'),
+        sqlc.arg('npwp')
+    )
+    RETURNING *
+), documents AS (
+    INSERT INTO seller_application_documents (application_id, kind, content_type, size_bytes, data)
+    SELECT application.id, d.kind, d.content_type, d.size_bytes, d.data
+    FROM application, unnest(
+        sqlc.arg('document_kinds')::text[],
+        sqlc.arg('document_content_types')::text[],
+        sqlc.arg('document_sizes')::int[],
+        sqlc.arg('document_data')::text[]
+    ) AS d(kind, content_type, size_bytes, data)
+)
+SELECT id, user_id, shop_name, nik, npwp, status, review_notes, reviewed_by, reviewed_at, created_at, updated_at
+FROM application;
+
+-- name: GetSellerApplication :one
+SELECT *
+FROM seller_applications
+WHERE id = $1;
+
+-- name: ListUserSellerApplications :many
+SELECT *
+FROM seller_applications
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListSellerApplications :many
+-- The review queue: oldest first, optionally only one status.
+SELECT *
+FROM seller_applications
+WHERE sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListSellerApplicationDocuments :many
+SELECT kind, content_type, size_bytes, created_at
+FROM seller_application_documents
+WHERE application_id = $1
+ORDER BY kind;
+
+-- name: GetSellerApplicationDocument :one
+SELECT *
+FROM seller_application_documents
+WHERE application_id = $1 AND kind = $2;
+
+-- name: DecideSellerApplication :one
+-- Moves a pending application to approved or rejected. Approving also makes
+-- the applicant a seller in the same statement; accounts whose role is not
+-- user, such as admins, keep theirs.
+WITH decided AS (
+    UPDATE seller_applications
+    SET
+        status = sqlc.arg('status'),
+        review_notes = sqlc.arg('review_notes'),
+        reviewed_by = sqlc.arg('reviewed_by'),
+        reviewed_at = now(),
+        updated_at = now()
+    WHERE seller_applications.id = sqlc.arg('id') AND seller_applications.status = 'pending'
+    RETURNING *
+), promoted AS (
+    UPDATE users
+    SET "role" = 'seller', updated_at = now()
+    FROM decided
+    WHERE users.id = decided.user_id AND decided.status = 'approved' AND users."role" = 'user'
+)
+SELECT id, user_id, shop_name, nik, npwp, status, review_notes, reviewed_by, reviewed_at, created_at, updated_at
+FROM decided;
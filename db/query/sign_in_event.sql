@@ -0,0 +1,24 @@
+-- name: CreateSignInEvent :exec
+INSERT INTO sign_in_events (
+    user_id,
+    session_id,
+    client_id,
+    device,
+    ip_address,
+    user_agent
+) VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListSignInEvents :many
+SELECT *
+FROM sign_in_events
+WHERE user_id = $1
+ORDER BY id DESC;
+
+-- name: PruneSignInEvents :exec
+DELETE FROM sign_in_events
+WHERE user_id = $1 AND id NOT IN (
+    SELECT id FROM sign_in_events
+    WHERE user_id = $1
+    ORDER BY id DESC
+    LIMIT $2
+);
@@ -7,26 +7,38 @@ INSERT INTO users (
     password,
     phone_number, 
     "address", 
-    role
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
+    role,
+    status
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
 
 -- name: GetAllUsers :many
-SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at
+SELECT id, "name", username, email, phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, avatar_url
 FROM users
 WHERE deleted_at IS NULL;
 
 -- name: GetUserByUsername :one
-SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at
+SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_url
 FROM users
 WHERE username = $1 AND deleted_at IS NULL;
 
+-- name: GetUserByEmail :one
+SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_url
+FROM users
+WHERE email = $1 AND deleted_at IS NULL;
+
 -- name: GetUserByID :one
-SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at
+SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_url
 FROM users
 WHERE id = $1 AND deleted_at IS NULL;
 
+-- name: GetUserByPhoneNumber :one
+-- Only verified numbers identify a user.
+SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_url
+FROM users
+WHERE phone_number = $1 AND phone_verified_at IS NOT NULL AND deleted_at IS NULL;
+
 -- name: GetUserByIDs :many
-SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at
+SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, avatar_url
 FROM users
 WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL;
 
@@ -42,14 +54,159 @@ SET
     "name" = $2,
     username = $3,
     email = $4,
-    "password" = $5,
-    "role" = $6,
-    phone_number = $7,
-    "address" = $8,
+    phone_number = $5,
+    "address" = $6,
+    -- a new address or number has to be verified again
+    email_verified_at = CASE WHEN email = $4 THEN email_verified_at ELSE NULL END,
+    phone_verified_at = CASE WHEN phone_number = $5 THEN phone_verified_at ELSE NULL END,
     updated_at = now()
 WHERE id = $1 AND deleted_at IS NULL RETURNING *;
 
+-- name: PatchUser :one
+-- Only the non-NULL fields change. With if_updated_at set, the row must still
+-- have that updated_at, so concurrent edits do not overwrite each other.
+UPDATE users
+SET
+    "name" = COALESCE(sqlc.narg('name'), "name"),
+    username = COALESCE(sqlc.narg('username'), username),
+    email = COALESCE(sqlc.narg('email'), email),
+    phone_number = COALESCE(sqlc.narg('phone_number'), phone_number),
+    "address" = COALESCE(sqlc.narg('address'), "address"),
+    email_verified_at = CASE WHEN sqlc.narg('email')::text IS NULL OR email = sqlc.narg('email') THEN email_verified_at ELSE NULL END,
+    phone_verified_at = CASE WHEN sqlc.narg('phone_number')::text IS NULL OR phone_number = sqlc.narg('phone_number') THEN phone_verified_at ELSE NULL END,
+    updated_at = now()
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
+    AND (sqlc.narg('if_updated_at')::timestamptz IS NULL OR updated_at = sqlc.narg('if_updated_at'))
+RETURNING *;
+
+-- name: MarkPhoneVerified :one
+-- The number must still be the one on the profile, so a code sent before a
+-- change cannot verify the new number.
+UPDATE users
+SET phone_verified_at = now(), updated_at = now()
+WHERE id = $1 AND phone_number = $2 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SetUserAvatar :one
+-- Returns the key of the avatar it replaces, so its files can be removed.
+WITH previous AS (
+    SELECT id, avatar_key FROM users
+    WHERE id = $1 AND deleted_at IS NULL
+    FOR UPDATE
+)
+UPDATE users
+SET avatar_key = $2, avatar_url = $3, updated_at = now()
+FROM previous
+WHERE users.id = previous.id
+RETURNING previous.avatar_key;
+
+-- name: UpdateUserPassword :execrows
+UPDATE users
+SET "password" = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL;
+
 -- name: DeleteUser :one
 UPDATE users
-SET deleted_at = now()
+SET deleted_at = now(), status = 'deleted', status_before_deletion = status, status_changed_at = now(), deletion_scheduled_at = NULL
 WHERE id = $1 AND deleted_at IS NULL RETURNING *;
+
+-- name: ScheduleUserDeletion :execrows
+UPDATE users
+SET deletion_scheduled_at = $2
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: CancelUserDeletion :execrows
+UPDATE users
+SET deletion_scheduled_at = NULL
+WHERE id = $1 AND deletion_scheduled_at IS NOT NULL AND deleted_at IS NULL;
+
+-- name: DeleteScheduledUsers :many
+-- Soft-deletes up to limit accounts whose grace period ended before now.
+WITH due AS (
+    SELECT id, status
+    FROM users
+    WHERE deletion_scheduled_at <= sqlc.arg('now')::timestamptz AND deleted_at IS NULL
+    ORDER BY deletion_scheduled_at
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE users
+SET
+    deleted_at = now(),
+    status = 'deleted',
+    status_before_deletion = due.status,
+    status_reason = 'deletion requested by the user',
+    status_changed_at = now(),
+    deletion_scheduled_at = NULL
+FROM due
+WHERE users.id = due.id
+RETURNING users.id, due.status AS previous_status;
+
+-- name: RestoreUser :one
+-- The restored account gets back a ban, or a suspension that has not run out.
+-- Otherwise it is active again, or pending_verification if its email was never
+-- verified.
+UPDATE users
+SET
+    deleted_at = NULL,
+    status = CASE
+        WHEN status_before_deletion = 'banned' THEN 'banned'
+        WHEN status_before_deletion = 'suspended' AND suspended_until > now() THEN 'suspended'
+        WHEN email_verified_at IS NULL THEN 'pending_verification'
+        ELSE 'active'
+    END,
+    status_before_deletion = NULL,
+    status_reason = $2,
+    status_changed_at = now(),
+    updated_at = now()
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: ListPurgeableUsers :many
+-- Lists up to limit accounts soft-deleted before deleted_before, so their
+-- files can be removed before PurgeDeletedUsers removes the rows.
+SELECT id, avatar_key
+FROM users
+WHERE deleted_at < sqlc.arg('deleted_before')::timestamptz
+ORDER BY deleted_at
+LIMIT sqlc.arg('limit');
+
+-- name: PurgeDeletedUsers :many
+-- Removes the listed accounts that are still soft-deleted before
+-- deleted_before. Their credentials, tokens and consents go with them through
+-- ON DELETE CASCADE.
+DELETE FROM users
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+    AND deleted_at < sqlc.arg('deleted_before')::timestamptz
+RETURNING id;
+
+-- name: GetUserStatus :one
+SELECT status, suspended_until
+FROM users
+WHERE id = $1;
+
+-- name: UpdateUserStatus :one
+-- The row only changes if it is still in from_status, so concurrent
+-- transitions cannot skip the state machine.
+UPDATE users
+SET
+    status = sqlc.arg('status'),
+    status_reason = sqlc.arg('status_reason'),
+    suspended_until = sqlc.narg('suspended_until'),
+    status_changed_at = now(),
+    deleted_at = CASE WHEN sqlc.arg('status') = 'deleted' THEN now() ELSE deleted_at END,
+    status_before_deletion = CASE WHEN sqlc.arg('status') = 'deleted' THEN status ELSE status_before_deletion END,
+    updated_at = now()
+WHERE id = sqlc.arg('id') AND status = sqlc.arg('from_status') AND deleted_at IS NULL
+RETURNING *;
+
+
+-- name: GetUserTokenVersion :one
+SELECT token_version
+FROM users
+WHERE id = $1;
+
+-- name: IncrementUserTokenVersion :one
+UPDATE users
+SET token_version = token_version + 1
+WHERE id = $1 RETURNING token_version;
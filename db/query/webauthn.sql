@@ -0,0 +1,28 @@
+-- name: CreateWebAuthnCredential :one
+INSERT INTO webauthn_credentials (
+    id,
+    user_id,
+    "name",
+    public_key,
+    attestation_type,
+    transports,
+    flags,
+    aaguid,
+    sign_count,
+    attachment
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING *;
+
+-- name: ListWebAuthnCredentialsByUser :many
+SELECT *
+FROM webauthn_credentials
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: UpdateWebAuthnCredentialUsage :exec
+UPDATE webauthn_credentials
+SET sign_count = $2, flags = $3, last_used_at = now()
+WHERE id = $1;
+
+-- name: DeleteWebAuthnCredential :execrows
+DELETE FROM webauthn_credentials
+WHERE id = $1 AND user_id = $2;
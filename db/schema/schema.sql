@@ -9,5 +9,227 @@ CREATE TABLE users (
     "role" TEXT NOT NULL,
     created_at TIMESTAMP NOT NULL,
     updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    token_version INTEGER NOT NULL DEFAULT 0,
+    email_verified_at TIMESTAMP,
+    status TEXT NOT NULL DEFAULT 'active',
+    status_reason TEXT NOT NULL DEFAULT '',
+    status_changed_at TIMESTAMP,
+    suspended_until TIMESTAMP,
+    deletion_scheduled_at TIMESTAMP,
+    phone_verified_at TIMESTAMP,
+    avatar_key TEXT NOT NULL DEFAULT '',
+    avatar_url TEXT NOT NULL DEFAULT '',
+    status_before_deletion TEXT
+);
+CREATE TABLE oauth_clients (
+    id TEXT PRIMARY KEY,
+    "name" TEXT NOT NULL,
+    secret_hash TEXT NOT NULL DEFAULT '',
+    redirect_uris TEXT[] NOT NULL,
+    allowed_scopes TEXT[] NOT NULL,
+    is_first_party BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
     deleted_at TIMESTAMP
-);
\ No newline at end of file
+);
+
+CREATE TABLE oauth_consents (
+    user_id UUID NOT NULL REFERENCES users(id),
+    client_id TEXT NOT NULL REFERENCES oauth_clients(id),
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, client_id)
+);
+
+CREATE TABLE user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(id),
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMP,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE user_recovery_codes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE mfa_role_requirements (
+    "role" TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE webauthn_credentials (
+    id BYTEA PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    "name" TEXT NOT NULL DEFAULT '',
+    public_key BYTEA NOT NULL,
+    attestation_type TEXT NOT NULL,
+    transports TEXT[] NOT NULL DEFAULT '{}',
+    flags SMALLINT NOT NULL,
+    aaguid BYTEA NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    attachment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP
+);
+
+CREATE TABLE email_verification_tokens (
+    token_hash TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    email TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE password_reset_tokens (
+    token_hash TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE password_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    status TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+CREATE TABLE user_addresses (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    recipient_name TEXT NOT NULL,
+    phone_number TEXT NOT NULL,
+    street TEXT NOT NULL,
+    district TEXT NOT NULL,
+    city TEXT NOT NULL,
+    province TEXT NOT NULL,
+    postal_code TEXT NOT NULL,
+    is_default BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    village TEXT NOT NULL,
+    province_code TEXT NOT NULL,
+    city_code TEXT NOT NULL,
+    district_code TEXT NOT NULL,
+    village_code TEXT NOT NULL
+);
+
+CREATE TABLE user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id),
+    locale TEXT NOT NULL,
+    timezone TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    email_newsletter BOOLEAN NOT NULL DEFAULT FALSE,
+    email_promotions BOOLEAN NOT NULL DEFAULT FALSE,
+    email_recommendations BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE seller_applications (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    shop_name TEXT NOT NULL,
+    nik TEXT NOT NULL,
+    npwp TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    review_notes TEXT NOT NULL DEFAULT '',
+    reviewed_by UUID REFERENCES users(id),
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE seller_application_documents (
+    application_id UUID NOT NULL REFERENCES seller_applications(id),
+    kind TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (application_id, kind)
+);
+
+CREATE TABLE roles (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    is_system BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    is_privileged BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE permissions (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    is_system BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE role_permissions (
+    "role" TEXT NOT NULL REFERENCES roles(name),
+    permission TEXT NOT NULL REFERENCES permissions(name),
+    PRIMARY KEY ("role", permission)
+);
+
+CREATE TABLE user_roles (
+    user_id UUID NOT NULL REFERENCES users(id),
+    "role" TEXT NOT NULL REFERENCES roles(name),
+    granted_by UUID REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, "role")
+);
+
+CREATE TABLE role_grant_requests (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    "role" TEXT NOT NULL REFERENCES roles(name),
+    requested_by UUID REFERENCES users(id),
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    decision_notes TEXT NOT NULL DEFAULT '',
+    decided_by UUID REFERENCES users(id),
+    decided_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id UUID,
+    target_user_id UUID,
+    action TEXT NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE sign_in_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id TEXT NOT NULL,
+    client_id TEXT NOT NULL DEFAULT '',
+    device TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,14 @@
+package configs
+
+import "time"
+
+type AccountDeletionConfig struct {
+	// GracePeriod is how long a self-service deletion waits before the account
+	// is deleted. Logging in during it cancels the deletion.
+	GracePeriod time.Duration `env:"ACCOUNT_DELETION_GRACE_PERIOD" envDefault:"336h"`
+	// RetentionPeriod is how long a deleted account can still be restored
+	// before the purge job removes it for good.
+	RetentionPeriod time.Duration `env:"ACCOUNT_RETENTION_PERIOD" envDefault:"720h"`
+	PurgeInterval   time.Duration `env:"ACCOUNT_PURGE_INTERVAL" envDefault:"1h"`
+	PurgeBatchSize  int           `env:"ACCOUNT_PURGE_BATCH_SIZE" envDefault:"100"`
+}
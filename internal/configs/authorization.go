@@ -0,0 +1,10 @@
+package configs
+
+import "time"
+
+type AuthorizationConfig struct {
+	// PolicyFile is the policy evaluated by the Authorize and CheckPermissions
+	// RPCs. It is reloaded when it changes on disk.
+	PolicyFile     string        `env:"AUTHZ_POLICY_FILE" envDefault:"policies/authorization.json"`
+	ReloadInterval time.Duration `env:"AUTHZ_POLICY_RELOAD_INTERVAL" envDefault:"10s"`
+}
@@ -7,14 +7,29 @@ import (
 )
 
 type AppConfig struct {
-	Database  DatabaseConfig
-	Migration MigrationConfig
-	Redis     RedisConfig
-	GRPC      GrpcConfig
-	Server    ServerConfig
-	RabbitMQ  RabbitMQConfig
-	Kafka     KafkaConfig
-	Logrus    LogrusConfig
+	Database          DatabaseConfig
+	Migration         MigrationConfig
+	Redis             RedisConfig
+	GRPC              GrpcConfig
+	Server            ServerConfig
+	RabbitMQ          RabbitMQConfig
+	Kafka             KafkaConfig
+	Logrus            LogrusConfig
+	OIDC              OIDCConfig
+	MFA               MFAConfig
+	WebAuthn          WebAuthnConfig
+	EmailVerification EmailVerificationConfig
+	PasswordReset     PasswordResetConfig
+	PasswordPolicy    PasswordPolicyConfig
+	AccountDeletion   AccountDeletionConfig
+	DataExport        DataExportConfig
+	Phone             PhoneConfig
+	SMS               SMSConfig
+	Storage           StorageConfig
+	Avatar            AvatarConfig
+	Preferences       PreferencesConfig
+	Seller            SellerConfig
+	Authorization     AuthorizationConfig
 }
 
 func LoadConfig(log *logrus.Logger) (*AppConfig, error) {
@@ -0,0 +1,18 @@
+package configs
+
+import "time"
+
+type DataExportConfig struct {
+	// Dir holds the finished archives. The export worker writes them and the
+	// web service serves the downloads, so both must see the same directory.
+	Dir string `env:"DATA_EXPORT_DIR" envDefault:"./data/exports"`
+	// DownloadURL is the public address of the download endpoint; the signed
+	// query is appended to it.
+	DownloadURL string `env:"DATA_EXPORT_DOWNLOAD_URL" envDefault:"http://localhost:8080/api/exports/download"`
+	// LinkSecret signs download links: openssl rand -base64 32
+	LinkSecret string `env:"DATA_EXPORT_LINK_SECRET"`
+	// LinkTTL is how long an archive can be downloaded before it is deleted.
+	LinkTTL         time.Duration `env:"DATA_EXPORT_LINK_TTL" envDefault:"72h"`
+	RequestCooldown time.Duration `env:"DATA_EXPORT_REQUEST_COOLDOWN" envDefault:"24h"`
+	CleanupInterval time.Duration `env:"DATA_EXPORT_CLEANUP_INTERVAL" envDefault:"1h"`
+}
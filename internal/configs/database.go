@@ -7,6 +7,9 @@ type DatabaseConfig struct {
 	Name     string `env:"DB_NAME,required"`
 	Port     int    `env:"DB_PORT,required"`
 	SslMode  string `env:"DB_SSL_MODE,required"`
+	// TimeZone is the session time zone, which only affects how timestamps
+	// are rendered. Users see times in the timezone of their preferences.
+	TimeZone string `env:"DB_TIMEZONE" envDefault:"UTC"`
 }
 
 type MigrationConfig struct {
@@ -0,0 +1,10 @@
+package configs
+
+import "time"
+
+type EmailVerificationConfig struct {
+	// URL is the page the emailed link opens; the token is appended as ?token=.
+	URL            string        `env:"EMAIL_VERIFICATION_URL" envDefault:"http://localhost:8080/static/verify-email.html"`
+	TokenTTL       time.Duration `env:"EMAIL_VERIFICATION_TOKEN_TTL" envDefault:"24h"`
+	ResendCooldown time.Duration `env:"EMAIL_VERIFICATION_RESEND_COOLDOWN" envDefault:"1m"`
+}
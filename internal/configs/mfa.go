@@ -0,0 +1,12 @@
+package configs
+
+import "time"
+
+type MFAConfig struct {
+	// Issuer is the account name shown in authenticator apps.
+	Issuer string `env:"MFA_ISSUER" envDefault:"TokoHobby"`
+	// SecretKey is a base64 encoded 32 byte key used to encrypt TOTP secrets at rest.
+	SecretKey    string        `env:"MFA_SECRET_KEY,required"`
+	ChallengeTTL time.Duration `env:"MFA_CHALLENGE_TTL" envDefault:"5m"`
+	MaxAttempts  int64         `env:"MFA_MAX_ATTEMPTS" envDefault:"5"`
+}
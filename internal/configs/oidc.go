@@ -0,0 +1,10 @@
+package configs
+
+import "time"
+
+type OIDCConfig struct {
+	// IssuerURL is the public base URL of this service, e.g. https://accounts.tokohobby.id.
+	IssuerURL   string        `env:"OIDC_ISSUER_URL,required"`
+	AuthCodeTTL time.Duration `env:"OIDC_AUTH_CODE_TTL" envDefault:"1m"`
+	IDTokenTTL  time.Duration `env:"OIDC_ID_TOKEN_TTL" envDefault:"1h"`
+}
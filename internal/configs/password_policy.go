@@ -0,0 +1,9 @@
+package configs
+
+type PasswordPolicyConfig struct {
+	MinLength int `env:"PASSWORD_MIN_LENGTH" envDefault:"8"`
+	// MinCharacterClasses counts lowercase, uppercase, digits and symbols.
+	MinCharacterClasses int `env:"PASSWORD_MIN_CHARACTER_CLASSES" envDefault:"3"`
+	// HistorySize is how many previous passwords cannot be reused.
+	HistorySize int `env:"PASSWORD_HISTORY_SIZE" envDefault:"5"`
+}
@@ -0,0 +1,11 @@
+package configs
+
+import "time"
+
+type PasswordResetConfig struct {
+	// URL is the page the emailed link opens; the token is appended as ?token=.
+	URL      string        `env:"PASSWORD_RESET_URL" envDefault:"http://localhost:8080/static/reset-password.html"`
+	TokenTTL time.Duration `env:"PASSWORD_RESET_TOKEN_TTL" envDefault:"30m"`
+	// RequestCooldown limits how often a reset email goes to the same address.
+	RequestCooldown time.Duration `env:"PASSWORD_RESET_REQUEST_COOLDOWN" envDefault:"1m"`
+}
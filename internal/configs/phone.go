@@ -0,0 +1,24 @@
+package configs
+
+import "time"
+
+type PhoneConfig struct {
+	// DefaultCountryCode is assumed for numbers written without one.
+	DefaultCountryCode string        `env:"PHONE_DEFAULT_COUNTRY_CODE" envDefault:"62"`
+	OTPLength          int           `env:"PHONE_OTP_LENGTH" envDefault:"6"`
+	OTPTTL             time.Duration `env:"PHONE_OTP_TTL" envDefault:"5m"`
+	OTPMaxAttempts     int64         `env:"PHONE_OTP_MAX_ATTEMPTS" envDefault:"5"`
+	// OTPMaxFailures wrong codes within OTPFailureWindow lock the number, even
+	// across new codes.
+	OTPMaxFailures   int64         `env:"PHONE_OTP_MAX_FAILURES" envDefault:"10"`
+	OTPFailureWindow time.Duration `env:"PHONE_OTP_FAILURE_WINDOW" envDefault:"1h"`
+	// OTPResendCooldown is the wait between two codes to the same number.
+	OTPResendCooldown time.Duration `env:"PHONE_OTP_RESEND_COOLDOWN" envDefault:"60s"`
+}
+
+type SMSConfig struct {
+	// Provider is "log" or "file".
+	Provider string `env:"SMS_PROVIDER" envDefault:"log"`
+	// FilePath is where the file provider appends messages.
+	FilePath string `env:"SMS_FILE_PATH" envDefault:"./data/sms.log"`
+}
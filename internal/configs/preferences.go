@@ -0,0 +1,12 @@
+package configs
+
+type PreferencesConfig struct {
+	// Locales and Currencies are the values users may choose from.
+	Locales    []string `env:"PREFERENCES_LOCALES" envSeparator:"," envDefault:"id-ID,en-US"`
+	Currencies []string `env:"PREFERENCES_CURRENCIES" envSeparator:"," envDefault:"IDR,USD,SGD,MYR"`
+	// The defaults apply to users who have not chosen, and are stored once
+	// they change any preference.
+	DefaultLocale   string `env:"PREFERENCES_DEFAULT_LOCALE" envDefault:"id-ID"`
+	DefaultTimezone string `env:"PREFERENCES_DEFAULT_TIMEZONE" envDefault:"Asia/Jakarta"`
+	DefaultCurrency string `env:"PREFERENCES_DEFAULT_CURRENCY" envDefault:"IDR"`
+}
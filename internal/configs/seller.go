@@ -0,0 +1,9 @@
+package configs
+
+type SellerConfig struct {
+	// KYCKey is a base64 encoded 32 byte key used to encrypt NIK, NPWP and
+	// the documents of seller applications at rest.
+	KYCKey string `env:"SELLER_KYC_KEY,required"`
+	// MaxDocumentBytes limits each uploaded document.
+	MaxDocumentBytes int64 `env:"SELLER_DOCUMENT_MAX_BYTES" envDefault:"5242880"`
+}
@@ -0,0 +1,27 @@
+package configs
+
+type StorageConfig struct {
+	// Driver is "local" or "s3".
+	Driver string `env:"STORAGE_DRIVER" envDefault:"local"`
+	// LocalDir is served by the web service at PublicURL when Driver is local.
+	LocalDir string `env:"STORAGE_LOCAL_DIR" envDefault:"./data/media"`
+	// PublicURL is where stored files are downloaded from. With S3 it is the
+	// bucket or a CDN in front of it; set it empty to use the bucket address.
+	PublicURL string `env:"STORAGE_PUBLIC_URL" envDefault:"http://localhost:8080/media"`
+
+	S3Endpoint        string `env:"STORAGE_S3_ENDPOINT"`
+	S3Region          string `env:"STORAGE_S3_REGION" envDefault:"us-east-1"`
+	S3Bucket          string `env:"STORAGE_S3_BUCKET"`
+	S3AccessKeyID     string `env:"STORAGE_S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `env:"STORAGE_S3_SECRET_ACCESS_KEY"`
+	// S3PathStyle is needed by MinIO and most self-hosted servers.
+	S3PathStyle bool `env:"STORAGE_S3_PATH_STYLE" envDefault:"false"`
+}
+
+type AvatarConfig struct {
+	// MaxBytes limits the upload itself.
+	MaxBytes int64 `env:"AVATAR_MAX_BYTES" envDefault:"5242880"`
+	// MaxPixels limits the decoded image, so a small file cannot expand into
+	// gigabytes of pixels.
+	MaxPixels int `env:"AVATAR_MAX_PIXELS" envDefault:"40000000"`
+}
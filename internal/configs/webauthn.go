@@ -0,0 +1,12 @@
+package configs
+
+import "time"
+
+type WebAuthnConfig struct {
+	// RPID is the registrable domain passkeys are bound to, e.g. tokohobby.id.
+	RPID          string   `env:"WEBAUTHN_RP_ID,required"`
+	RPDisplayName string   `env:"WEBAUTHN_RP_DISPLAY_NAME" envDefault:"TokoHobby"`
+	RPOrigins     []string `env:"WEBAUTHN_RP_ORIGINS,required" envSeparator:","`
+	// CeremonyTTL bounds the time between the begin and finish calls.
+	CeremonyTTL time.Duration `env:"WEBAUTHN_CEREMONY_TTL" envDefault:"5m"`
+}
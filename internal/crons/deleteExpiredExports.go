@@ -0,0 +1,59 @@
+package crons
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+// DataExportCleanupJob deletes the data export archives whose download link
+// has expired. It runs in the web service, next to the downloads.
+type DataExportCleanupJob struct {
+	service  services.DataExportService
+	interval time.Duration
+	log      *logrus.Logger
+}
+
+func NewDataExportCleanupJob(service services.DataExportService, interval time.Duration, log *logrus.Logger) *DataExportCleanupJob {
+	return &DataExportCleanupJob{service: service, interval: interval, log: log}
+}
+
+// Start runs the job now and then every interval until ctx is cancelled.
+func (j *DataExportCleanupJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			j.run(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (j *DataExportCleanupJob) run(ctx context.Context) {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := j.service.DeleteExpired(ctx)
+		if err != nil {
+			j.log.WithError(err).Error("Data export cleanup job failed")
+			break
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+	}
+
+	if total > 0 {
+		j.log.Infof("Data export cleanup job: %d archives deleted", total)
+	}
+}
@@ -0,0 +1,67 @@
+package crons
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+// AccountDeletionJob soft-deletes accounts whose deletion grace period is over
+// and purges the ones past the retention period. Several instances may run it
+// at once; each row is claimed by only one of them.
+type AccountDeletionJob struct {
+	service  services.AccountDeletionService
+	interval time.Duration
+	log      *logrus.Logger
+}
+
+func NewAccountDeletionJob(service services.AccountDeletionService, interval time.Duration, log *logrus.Logger) *AccountDeletionJob {
+	return &AccountDeletionJob{service: service, interval: interval, log: log}
+}
+
+// Start runs the job now and then every interval until ctx is cancelled.
+func (j *AccountDeletionJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			j.run(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (j *AccountDeletionJob) run(ctx context.Context) {
+	deleted := j.drain(ctx, j.service.DeleteScheduled)
+	purged := j.drain(ctx, j.service.Purge)
+
+	if deleted > 0 || purged > 0 {
+		j.log.Infof("Account deletion job: %d accounts deleted, %d purged", deleted, purged)
+	}
+}
+
+// drain calls step, which handles one batch, until nothing is left.
+func (j *AccountDeletionJob) drain(ctx context.Context, step func(context.Context) (int, error)) int {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := step(ctx)
+		if err != nil {
+			j.log.WithError(err).Error("Account deletion job failed")
+			break
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+	}
+	return total
+}
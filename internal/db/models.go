@@ -6,21 +6,241 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-type User struct {
+type AuditLog struct {
+	ID           int64
+	ActorID      uuid.NullUUID
+	TargetUserID uuid.NullUUID
+	Action       string
+	Details      json.RawMessage
+	CreatedAt    time.Time
+}
+
+type DataExport struct {
 	ID          uuid.UUID
+	UserID      uuid.UUID
+	Status      string
+	FilePath    string
+	CreatedAt   time.Time
+	CompletedAt sql.NullTime
+	ExpiresAt   sql.NullTime
+}
+
+type EmailVerificationToken struct {
+	TokenHash string
+	UserID    uuid.UUID
+	Email     string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type MfaRoleRequirement struct {
+	Role      string
+	CreatedAt time.Time
+}
+
+type OauthClient struct {
+	ID            string
+	Name          string
+	SecretHash    string
+	RedirectUris  []string
+	AllowedScopes []string
+	IsFirstParty  bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     sql.NullTime
+}
+
+type OauthConsent struct {
+	UserID    uuid.UUID
+	ClientID  string
+	Scopes    []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type PasswordHistory struct {
+	ID           int64
+	UserID       uuid.UUID
+	PasswordHash string
+	CreatedAt    time.Time
+}
+
+type PasswordResetToken struct {
+	TokenHash string
+	UserID    uuid.UUID
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type Permission struct {
 	Name        string
-	Username    string
-	Email       string
-	PhoneNumber string
-	Address     string
-	Password    string
-	Role        string
+	Description string
+	CreatedAt   time.Time
+	IsSystem    bool
+}
+
+type Role struct {
+	Name         string
+	Description  string
+	IsSystem     bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	IsPrivileged bool
+}
+
+type RoleGrantRequest struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	Role          string
+	RequestedBy   uuid.NullUUID
+	Reason        string
+	Status        string
+	DecisionNotes string
+	DecidedBy     uuid.NullUUID
+	DecidedAt     sql.NullTime
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type RolePermission struct {
+	Role       string
+	Permission string
+}
+
+type SellerApplication struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	ShopName    string
+	Nik         string
+	Npwp        string
+	Status      string
+	ReviewNotes string
+	ReviewedBy  uuid.NullUUID
+	ReviewedAt  sql.NullTime
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
-	DeletedAt   sql.NullTime
+}
+
+type SellerApplicationDocument struct {
+	ApplicationID uuid.UUID
+	Kind          string
+	ContentType   string
+	SizeBytes     int32
+	Data          string
+	CreatedAt     time.Time
+}
+
+type SignInEvent struct {
+	ID        int64
+	UserID    uuid.UUID
+	SessionID string
+	ClientID  string
+	Device    string
+	IpAddress string
+	UserAgent string
+	CreatedAt time.Time
+}
+
+type User struct {
+	ID                   uuid.UUID
+	Name                 string
+	Username             string
+	Email                string
+	PhoneNumber          string
+	Address              string
+	Password             string
+	Role                 string
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	DeletedAt            sql.NullTime
+	TokenVersion         int32
+	EmailVerifiedAt      sql.NullTime
+	Status               string
+	StatusReason         string
+	StatusChangedAt      sql.NullTime
+	SuspendedUntil       sql.NullTime
+	DeletionScheduledAt  sql.NullTime
+	PhoneVerifiedAt      sql.NullTime
+	AvatarKey            string
+	AvatarUrl            string
+	StatusBeforeDeletion sql.NullString
+}
+
+type UserAddress struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	RecipientName string
+	PhoneNumber   string
+	Street        string
+	District      string
+	City          string
+	Province      string
+	PostalCode    string
+	IsDefault     bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Village       string
+	ProvinceCode  string
+	CityCode      string
+	DistrictCode  string
+	VillageCode   string
+}
+
+type UserMfa struct {
+	UserID       uuid.UUID
+	Secret       string
+	EnabledAt    sql.NullTime
+	LastUsedStep int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type UserPreference struct {
+	UserID               uuid.UUID
+	Locale               string
+	Timezone             string
+	Currency             string
+	EmailNewsletter      bool
+	EmailPromotions      bool
+	EmailRecommendations bool
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+type UserRecoveryCode struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CodeHash  string
+	UsedAt    sql.NullTime
+	CreatedAt time.Time
+}
+
+type UserRole struct {
+	UserID    uuid.UUID
+	Role      string
+	GrantedBy uuid.NullUUID
+	CreatedAt time.Time
+}
+
+type WebauthnCredential struct {
+	ID              []byte
+	UserID          uuid.UUID
+	Name            string
+	PublicKey       []byte
+	AttestationType string
+	Transports      []string
+	Flags           int16
+	Aaguid          []byte
+	SignCount       int64
+	Attachment      string
+	CreatedAt       time.Time
+	LastUsedAt      sql.NullTime
 }
@@ -7,12 +7,27 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
+const cancelUserDeletion = `-- name: CancelUserDeletion :execrows
+UPDATE users
+SET deletion_scheduled_at = NULL
+WHERE id = $1 AND deletion_scheduled_at IS NOT NULL AND deleted_at IS NULL
+`
+
+func (q *Queries) CancelUserDeletion(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelUserDeletion, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     id, 
@@ -22,8 +37,9 @@ INSERT INTO users (
     password,
     phone_number, 
     "address", 
-    role
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, name, username, email, phone_number, address, password, role, created_at, updated_at, deleted_at
+    role,
+    status
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, name, username, email, phone_number, address, password, role, created_at, updated_at, deleted_at, token_version, email_verified_at, status, status_reason, status_changed_at, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_key, avatar_url, status_before_deletion
 `
 
 type CreateUserParams struct {
@@ -35,6 +51,7 @@ type CreateUserParams struct {
 	PhoneNumber string
 	Address     string
 	Role        string
+	Status      string
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -47,6 +64,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.PhoneNumber,
 		arg.Address,
 		arg.Role,
+		arg.Status,
 	)
 	var i User
 	err := row.Scan(
@@ -61,14 +79,81 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+		&i.DeletionScheduledAt,
+		&i.PhoneVerifiedAt,
+		&i.AvatarKey,
+		&i.AvatarUrl,
+		&i.StatusBeforeDeletion,
 	)
 	return i, err
 }
 
+const deleteScheduledUsers = `-- name: DeleteScheduledUsers :many
+WITH due AS (
+    SELECT id, status
+    FROM users
+    WHERE deletion_scheduled_at <= $1::timestamptz AND deleted_at IS NULL
+    ORDER BY deletion_scheduled_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE users
+SET
+    deleted_at = now(),
+    status = 'deleted',
+    status_before_deletion = due.status,
+    status_reason = 'deletion requested by the user',
+    status_changed_at = now(),
+    deletion_scheduled_at = NULL
+FROM due
+WHERE users.id = due.id
+RETURNING users.id, due.status AS previous_status
+`
+
+type DeleteScheduledUsersParams struct {
+	Now   time.Time
+	Limit int32
+}
+
+type DeleteScheduledUsersRow struct {
+	ID             uuid.UUID
+	PreviousStatus string
+}
+
+// Soft-deletes up to limit accounts whose grace period ended before now.
+func (q *Queries) DeleteScheduledUsers(ctx context.Context, arg DeleteScheduledUsersParams) ([]DeleteScheduledUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, deleteScheduledUsers, arg.Now, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeleteScheduledUsersRow
+	for rows.Next() {
+		var i DeleteScheduledUsersRow
+		if err := rows.Scan(&i.ID, &i.PreviousStatus); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const deleteUser = `-- name: DeleteUser :one
 UPDATE users
-SET deleted_at = now()
-WHERE id = $1 AND deleted_at IS NULL RETURNING id, name, username, email, phone_number, address, password, role, created_at, updated_at, deleted_at
+SET deleted_at = now(), status = 'deleted', status_before_deletion = status, status_changed_at = now(), deletion_scheduled_at = NULL
+WHERE id = $1 AND deleted_at IS NULL RETURNING id, name, username, email, phone_number, address, password, role, created_at, updated_at, deleted_at, token_version, email_verified_at, status, status_reason, status_changed_at, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_key, avatar_url, status_before_deletion
 `
 
 func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (User, error) {
@@ -86,6 +171,17 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+		&i.DeletionScheduledAt,
+		&i.PhoneVerifiedAt,
+		&i.AvatarKey,
+		&i.AvatarUrl,
+		&i.StatusBeforeDeletion,
 	)
 	return i, err
 }
@@ -115,22 +211,26 @@ func (q *Queries) ExistUsernameorEmail(ctx context.Context, arg ExistUsernameorE
 }
 
 const getAllUsers = `-- name: GetAllUsers :many
-SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at
+SELECT id, "name", username, email, phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, avatar_url
 FROM users
 WHERE deleted_at IS NULL
 `
 
 type GetAllUsersRow struct {
-	ID          uuid.UUID
-	Name        string
-	Username    string
-	Email       string
-	Password    string
-	PhoneNumber string
-	Address     string
-	Role        string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID              uuid.UUID
+	Name            string
+	Username        string
+	Email           string
+	PhoneNumber     string
+	Address         string
+	Role            string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	EmailVerifiedAt sql.NullTime
+	Status          string
+	StatusReason    string
+	SuspendedUntil  sql.NullTime
+	AvatarUrl       string
 }
 
 func (q *Queries) GetAllUsers(ctx context.Context) ([]GetAllUsersRow, error) {
@@ -147,12 +247,16 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]GetAllUsersRow, error) {
 			&i.Name,
 			&i.Username,
 			&i.Email,
-			&i.Password,
 			&i.PhoneNumber,
 			&i.Address,
 			&i.Role,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.SuspendedUntil,
+			&i.AvatarUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -167,23 +271,81 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]GetAllUsersRow, error) {
 	return items, nil
 }
 
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_url
+FROM users
+WHERE email = $1 AND deleted_at IS NULL
+`
+
+type GetUserByEmailRow struct {
+	ID                  uuid.UUID
+	Name                string
+	Username            string
+	Email               string
+	Password            string
+	PhoneNumber         string
+	Address             string
+	Role                string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	EmailVerifiedAt     sql.NullTime
+	Status              string
+	StatusReason        string
+	SuspendedUntil      sql.NullTime
+	DeletionScheduledAt sql.NullTime
+	PhoneVerifiedAt     sql.NullTime
+	AvatarUrl           string
+}
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i GetUserByEmailRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.PhoneNumber,
+		&i.Address,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedUntil,
+		&i.DeletionScheduledAt,
+		&i.PhoneVerifiedAt,
+		&i.AvatarUrl,
+	)
+	return i, err
+}
+
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at
+SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_url
 FROM users
 WHERE id = $1 AND deleted_at IS NULL
 `
 
 type GetUserByIDRow struct {
-	ID          uuid.UUID
-	Name        string
-	Username    string
-	Email       string
-	Password    string
-	PhoneNumber string
-	Address     string
-	Role        string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID                  uuid.UUID
+	Name                string
+	Username            string
+	Email               string
+	Password            string
+	PhoneNumber         string
+	Address             string
+	Role                string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	EmailVerifiedAt     sql.NullTime
+	Status              string
+	StatusReason        string
+	SuspendedUntil      sql.NullTime
+	DeletionScheduledAt sql.NullTime
+	PhoneVerifiedAt     sql.NullTime
+	AvatarUrl           string
 }
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow, error) {
@@ -200,27 +362,39 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow
 		&i.Role,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedUntil,
+		&i.DeletionScheduledAt,
+		&i.PhoneVerifiedAt,
+		&i.AvatarUrl,
 	)
 	return i, err
 }
 
 const getUserByIDs = `-- name: GetUserByIDs :many
-SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at
+SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, avatar_url
 FROM users
 WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
 `
 
 type GetUserByIDsRow struct {
-	ID          uuid.UUID
-	Name        string
-	Username    string
-	Email       string
-	Password    string
-	PhoneNumber string
-	Address     string
-	Role        string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID              uuid.UUID
+	Name            string
+	Username        string
+	Email           string
+	Password        string
+	PhoneNumber     string
+	Address         string
+	Role            string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	EmailVerifiedAt sql.NullTime
+	Status          string
+	StatusReason    string
+	SuspendedUntil  sql.NullTime
+	AvatarUrl       string
 }
 
 func (q *Queries) GetUserByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]GetUserByIDsRow, error) {
@@ -243,6 +417,11 @@ func (q *Queries) GetUserByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]Get
 			&i.Role,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.SuspendedUntil,
+			&i.AvatarUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -257,23 +436,82 @@ func (q *Queries) GetUserByIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]Get
 	return items, nil
 }
 
+const getUserByPhoneNumber = `-- name: GetUserByPhoneNumber :one
+SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_url
+FROM users
+WHERE phone_number = $1 AND phone_verified_at IS NOT NULL AND deleted_at IS NULL
+`
+
+type GetUserByPhoneNumberRow struct {
+	ID                  uuid.UUID
+	Name                string
+	Username            string
+	Email               string
+	Password            string
+	PhoneNumber         string
+	Address             string
+	Role                string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	EmailVerifiedAt     sql.NullTime
+	Status              string
+	StatusReason        string
+	SuspendedUntil      sql.NullTime
+	DeletionScheduledAt sql.NullTime
+	PhoneVerifiedAt     sql.NullTime
+	AvatarUrl           string
+}
+
+// Only verified numbers identify a user.
+func (q *Queries) GetUserByPhoneNumber(ctx context.Context, phoneNumber string) (GetUserByPhoneNumberRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserByPhoneNumber, phoneNumber)
+	var i GetUserByPhoneNumberRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.PhoneNumber,
+		&i.Address,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedUntil,
+		&i.DeletionScheduledAt,
+		&i.PhoneVerifiedAt,
+		&i.AvatarUrl,
+	)
+	return i, err
+}
+
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at
+SELECT id, "name", username, email, "password",phone_number, "address", "role", created_at, updated_at, email_verified_at, status, status_reason, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_url
 FROM users
 WHERE username = $1 AND deleted_at IS NULL
 `
 
 type GetUserByUsernameRow struct {
-	ID          uuid.UUID
-	Name        string
-	Username    string
-	Email       string
-	Password    string
-	PhoneNumber string
-	Address     string
-	Role        string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID                  uuid.UUID
+	Name                string
+	Username            string
+	Email               string
+	Password            string
+	PhoneNumber         string
+	Address             string
+	Role                string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	EmailVerifiedAt     sql.NullTime
+	Status              string
+	StatusReason        string
+	SuspendedUntil      sql.NullTime
+	DeletionScheduledAt sql.NullTime
+	PhoneVerifiedAt     sql.NullTime
+	AvatarUrl           string
 }
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error) {
@@ -290,22 +528,367 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (GetUs
 		&i.Role,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.SuspendedUntil,
+		&i.DeletionScheduledAt,
+		&i.PhoneVerifiedAt,
+		&i.AvatarUrl,
 	)
 	return i, err
 }
 
+const getUserStatus = `-- name: GetUserStatus :one
+SELECT status, suspended_until
+FROM users
+WHERE id = $1
+`
+
+type GetUserStatusRow struct {
+	Status         string
+	SuspendedUntil sql.NullTime
+}
+
+func (q *Queries) GetUserStatus(ctx context.Context, id uuid.UUID) (GetUserStatusRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserStatus, id)
+	var i GetUserStatusRow
+	err := row.Scan(&i.Status, &i.SuspendedUntil)
+	return i, err
+}
+
+const getUserTokenVersion = `-- name: GetUserTokenVersion :one
+SELECT token_version
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserTokenVersion(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getUserTokenVersion, id)
+	var token_version int32
+	err := row.Scan(&token_version)
+	return token_version, err
+}
+
+const incrementUserTokenVersion = `-- name: IncrementUserTokenVersion :one
+UPDATE users
+SET token_version = token_version + 1
+WHERE id = $1 RETURNING token_version
+`
+
+func (q *Queries) IncrementUserTokenVersion(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, incrementUserTokenVersion, id)
+	var token_version int32
+	err := row.Scan(&token_version)
+	return token_version, err
+}
+
+const listPurgeableUsers = `-- name: ListPurgeableUsers :many
+SELECT id, avatar_key
+FROM users
+WHERE deleted_at < $1::timestamptz
+ORDER BY deleted_at
+LIMIT $2
+`
+
+type ListPurgeableUsersParams struct {
+	DeletedBefore time.Time
+	Limit         int32
+}
+
+type ListPurgeableUsersRow struct {
+	ID        uuid.UUID
+	AvatarKey string
+}
+
+// Lists up to limit accounts soft-deleted before deleted_before, so their
+// files can be removed before PurgeDeletedUsers removes the rows.
+func (q *Queries) ListPurgeableUsers(ctx context.Context, arg ListPurgeableUsersParams) ([]ListPurgeableUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPurgeableUsers, arg.DeletedBefore, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPurgeableUsersRow
+	for rows.Next() {
+		var i ListPurgeableUsersRow
+		if err := rows.Scan(&i.ID, &i.AvatarKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markPhoneVerified = `-- name: MarkPhoneVerified :one
+UPDATE users
+SET phone_verified_at = now(), updated_at = now()
+WHERE id = $1 AND phone_number = $2 AND deleted_at IS NULL
+RETURNING id, name, username, email, phone_number, address, password, role, created_at, updated_at, deleted_at, token_version, email_verified_at, status, status_reason, status_changed_at, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_key, avatar_url, status_before_deletion
+`
+
+type MarkPhoneVerifiedParams struct {
+	ID          uuid.UUID
+	PhoneNumber string
+}
+
+// The number must still be the one on the profile, so a code sent before a
+// change cannot verify the new number.
+func (q *Queries) MarkPhoneVerified(ctx context.Context, arg MarkPhoneVerifiedParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, markPhoneVerified, arg.ID, arg.PhoneNumber)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Username,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Address,
+		&i.Password,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+		&i.DeletionScheduledAt,
+		&i.PhoneVerifiedAt,
+		&i.AvatarKey,
+		&i.AvatarUrl,
+		&i.StatusBeforeDeletion,
+	)
+	return i, err
+}
+
+const patchUser = `-- name: PatchUser :one
+UPDATE users
+SET
+    "name" = COALESCE($1, "name"),
+    username = COALESCE($2, username),
+    email = COALESCE($3, email),
+    phone_number = COALESCE($4, phone_number),
+    "address" = COALESCE($5, "address"),
+    email_verified_at = CASE WHEN $3::text IS NULL OR email = $3 THEN email_verified_at ELSE NULL END,
+    phone_verified_at = CASE WHEN $4::text IS NULL OR phone_number = $4 THEN phone_verified_at ELSE NULL END,
+    updated_at = now()
+WHERE id = $6 AND deleted_at IS NULL
+    AND ($7::timestamptz IS NULL OR updated_at = $7)
+RETURNING id, name, username, email, phone_number, address, password, role, created_at, updated_at, deleted_at, token_version, email_verified_at, status, status_reason, status_changed_at, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_key, avatar_url, status_before_deletion
+`
+
+type PatchUserParams struct {
+	Name        sql.NullString
+	Username    sql.NullString
+	Email       sql.NullString
+	PhoneNumber sql.NullString
+	Address     sql.NullString
+	ID          uuid.UUID
+	IfUpdatedAt sql.NullTime
+}
+
+// Only the non-NULL fields change. With if_updated_at set, the row must still
+// have that updated_at, so concurrent edits do not overwrite each other.
+func (q *Queries) PatchUser(ctx context.Context, arg PatchUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, patchUser,
+		arg.Name,
+		arg.Username,
+		arg.Email,
+		arg.PhoneNumber,
+		arg.Address,
+		arg.ID,
+		arg.IfUpdatedAt,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Username,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Address,
+		&i.Password,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+		&i.DeletionScheduledAt,
+		&i.PhoneVerifiedAt,
+		&i.AvatarKey,
+		&i.AvatarUrl,
+		&i.StatusBeforeDeletion,
+	)
+	return i, err
+}
+
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :many
+DELETE FROM users
+WHERE id = ANY($1::uuid[])
+    AND deleted_at < $2::timestamptz
+RETURNING id
+`
+
+type PurgeDeletedUsersParams struct {
+	Ids           []uuid.UUID
+	DeletedBefore time.Time
+}
+
+// Removes the listed accounts that are still soft-deleted before
+// deleted_before. Their credentials, tokens and consents go with them through
+// ON DELETE CASCADE.
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, arg PurgeDeletedUsersParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, purgeDeletedUsers, pq.Array(arg.Ids), arg.DeletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreUser = `-- name: RestoreUser :one
+UPDATE users
+SET
+    deleted_at = NULL,
+    status = CASE
+        WHEN status_before_deletion = 'banned' THEN 'banned'
+        WHEN status_before_deletion = 'suspended' AND suspended_until > now() THEN 'suspended'
+        WHEN email_verified_at IS NULL THEN 'pending_verification'
+        ELSE 'active'
+    END,
+    status_before_deletion = NULL,
+    status_reason = $2,
+    status_changed_at = now(),
+    updated_at = now()
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, name, username, email, phone_number, address, password, role, created_at, updated_at, deleted_at, token_version, email_verified_at, status, status_reason, status_changed_at, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_key, avatar_url, status_before_deletion
+`
+
+type RestoreUserParams struct {
+	ID           uuid.UUID
+	StatusReason string
+}
+
+// The restored account gets back a ban, or a suspension that has not run out.
+// Otherwise it is active again, or pending_verification if its email was never
+// verified.
+func (q *Queries) RestoreUser(ctx context.Context, arg RestoreUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, restoreUser, arg.ID, arg.StatusReason)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Username,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Address,
+		&i.Password,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+		&i.DeletionScheduledAt,
+		&i.PhoneVerifiedAt,
+		&i.AvatarKey,
+		&i.AvatarUrl,
+		&i.StatusBeforeDeletion,
+	)
+	return i, err
+}
+
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :execrows
+UPDATE users
+SET deletion_scheduled_at = $2
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+type ScheduleUserDeletionParams struct {
+	ID                  uuid.UUID
+	DeletionScheduledAt sql.NullTime
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, scheduleUserDeletion, arg.ID, arg.DeletionScheduledAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setUserAvatar = `-- name: SetUserAvatar :one
+WITH previous AS (
+    SELECT id, avatar_key FROM users
+    WHERE id = $1 AND deleted_at IS NULL
+    FOR UPDATE
+)
+UPDATE users
+SET avatar_key = $2, avatar_url = $3, updated_at = now()
+FROM previous
+WHERE users.id = previous.id
+RETURNING previous.avatar_key
+`
+
+type SetUserAvatarParams struct {
+	ID        uuid.UUID
+	AvatarKey string
+	AvatarUrl string
+}
+
+// Returns the key of the avatar it replaces, so its files can be removed.
+func (q *Queries) SetUserAvatar(ctx context.Context, arg SetUserAvatarParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, setUserAvatar, arg.ID, arg.AvatarKey, arg.AvatarUrl)
+	var avatar_key string
+	err := row.Scan(&avatar_key)
+	return avatar_key, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET
     "name" = $2,
     username = $3,
     email = $4,
-    "password" = $5,
-    "role" = $6,
-    phone_number = $7,
-    "address" = $8,
+    phone_number = $5,
+    "address" = $6,
+    -- a new address or number has to be verified again
+    email_verified_at = CASE WHEN email = $4 THEN email_verified_at ELSE NULL END,
+    phone_verified_at = CASE WHEN phone_number = $5 THEN phone_verified_at ELSE NULL END,
     updated_at = now()
-WHERE id = $1 AND deleted_at IS NULL RETURNING id, name, username, email, phone_number, address, password, role, created_at, updated_at, deleted_at
+WHERE id = $1 AND deleted_at IS NULL RETURNING id, name, username, email, phone_number, address, password, role, created_at, updated_at, deleted_at, token_version, email_verified_at, status, status_reason, status_changed_at, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_key, avatar_url, status_before_deletion
 `
 
 type UpdateUserParams struct {
@@ -313,8 +896,6 @@ type UpdateUserParams struct {
 	Name        string
 	Username    string
 	Email       string
-	Password    string
-	Role        string
 	PhoneNumber string
 	Address     string
 }
@@ -325,8 +906,6 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.Name,
 		arg.Username,
 		arg.Email,
-		arg.Password,
-		arg.Role,
 		arg.PhoneNumber,
 		arg.Address,
 	)
@@ -343,6 +922,96 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+		&i.DeletionScheduledAt,
+		&i.PhoneVerifiedAt,
+		&i.AvatarKey,
+		&i.AvatarUrl,
+		&i.StatusBeforeDeletion,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :execrows
+UPDATE users
+SET "password" = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+type UpdateUserPasswordParams struct {
+	ID       uuid.UUID
+	Password string
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserPassword, arg.ID, arg.Password)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUserStatus = `-- name: UpdateUserStatus :one
+UPDATE users
+SET
+    status = $1,
+    status_reason = $2,
+    suspended_until = $3,
+    status_changed_at = now(),
+    deleted_at = CASE WHEN $1 = 'deleted' THEN now() ELSE deleted_at END,
+    status_before_deletion = CASE WHEN $1 = 'deleted' THEN status ELSE status_before_deletion END,
+    updated_at = now()
+WHERE id = $4 AND status = $5 AND deleted_at IS NULL
+RETURNING id, name, username, email, phone_number, address, password, role, created_at, updated_at, deleted_at, token_version, email_verified_at, status, status_reason, status_changed_at, suspended_until, deletion_scheduled_at, phone_verified_at, avatar_key, avatar_url, status_before_deletion
+`
+
+type UpdateUserStatusParams struct {
+	Status         string
+	StatusReason   string
+	SuspendedUntil sql.NullTime
+	ID             uuid.UUID
+	FromStatus     string
+}
+
+// The row only changes if it is still in from_status, so concurrent
+// transitions cannot skip the state machine.
+func (q *Queries) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserStatus,
+		arg.Status,
+		arg.StatusReason,
+		arg.SuspendedUntil,
+		arg.ID,
+		arg.FromStatus,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Username,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Address,
+		&i.Password,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.SuspendedUntil,
+		&i.DeletionScheduledAt,
+		&i.PhoneVerifiedAt,
+		&i.AvatarKey,
+		&i.AvatarUrl,
+		&i.StatusBeforeDeletion,
 	)
 	return i, err
 }
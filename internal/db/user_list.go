@@ -0,0 +1,163 @@
+package db
+
+// ListUsers is written by hand: sqlc cannot generate a query whose filters and
+// ORDER BY change from call to call.
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSortColumns maps the sort keys ListUsers accepts to their columns.
+var UserSortColumns = map[string]string{
+	"created_at": "created_at",
+	"name":       `"name"`,
+	"username":   "username",
+	"email":      "email",
+}
+
+type ListUsersParams struct {
+	// Role matches users holding the role, granted or primary.
+	Role string
+	// Status filters on users.status. Deleted users are only listed when it is
+	// "deleted".
+	Status        string
+	CreatedAfter  sql.NullTime
+	CreatedBefore sql.NullTime
+	EmailDomain   string
+	// Search matches name, username, email and phone number as a substring.
+	Search string
+	// SortBy is a key of UserSortColumns. Ties are broken by id.
+	SortBy     string
+	Descending bool
+	// AfterValue and AfterID are the sort value and id of the last row of the
+	// previous page. AfterValue is RFC 3339 for created_at.
+	AfterValue string
+	AfterID    uuid.NullUUID
+	Limit      int32
+}
+
+type ListUsersRow struct {
+	ID              uuid.UUID
+	Name            string
+	Username        string
+	Email           string
+	PhoneNumber     string
+	Address         string
+	Role            string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       sql.NullTime
+	EmailVerifiedAt sql.NullTime
+	Status          string
+	StatusReason    string
+	SuspendedUntil  sql.NullTime
+	AvatarUrl       string
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	column, ok := UserSortColumns[arg.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort column %q", arg.SortBy)
+	}
+
+	var (
+		where []string
+		args  []interface{}
+	)
+	bind := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if arg.Status == "deleted" {
+		where = append(where, "deleted_at IS NOT NULL")
+	} else {
+		where = append(where, "deleted_at IS NULL")
+		if arg.Status != "" {
+			where = append(where, "status = "+bind(arg.Status))
+		}
+	}
+	if arg.Role != "" {
+		role := bind(arg.Role)
+		where = append(where, fmt.Sprintf(`("role" = %[1]s OR EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = users.id AND ur."role" = %[1]s))`, role))
+	}
+	if arg.CreatedAfter.Valid {
+		where = append(where, "created_at >= "+bind(arg.CreatedAfter.Time))
+	}
+	if arg.CreatedBefore.Valid {
+		where = append(where, "created_at < "+bind(arg.CreatedBefore.Time))
+	}
+	if arg.EmailDomain != "" {
+		where = append(where, `email ILIKE '%@' || `+bind(escapeLike(arg.EmailDomain)))
+	}
+	if arg.Search != "" {
+		pattern := bind("%" + escapeLike(arg.Search) + "%")
+		where = append(where, fmt.Sprintf(`("name" ILIKE %[1]s OR username ILIKE %[1]s OR email ILIKE %[1]s OR phone_number ILIKE %[1]s)`, pattern))
+	}
+
+	direction, comparison := "ASC", ">"
+	if arg.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	if arg.AfterID.Valid {
+		value := bind(arg.AfterValue)
+		if arg.SortBy == "created_at" {
+			value += "::timestamptz"
+		}
+		where = append(where, fmt.Sprintf("(%s, id) %s (%s, %s)", column, comparison, value, bind(arg.AfterID.UUID)))
+	}
+
+	query := fmt.Sprintf(`SELECT id, "name", username, email, phone_number, "address", "role", created_at, updated_at, deleted_at, email_verified_at, status, status_reason, suspended_until, avatar_url
+FROM users
+WHERE %s
+ORDER BY %s %s, id %s
+LIMIT %s`, strings.Join(where, " AND "), column, direction, direction, bind(arg.Limit))
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsersRow
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Username,
+			&i.Email,
+			&i.PhoneNumber,
+			&i.Address,
+			&i.Role,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.EmailVerifiedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.SuspendedUntil,
+			&i.AvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// escapeLike makes % and _ in user input match literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Address is a shipping address in a user's address book. Exactly one of a
+// user's addresses is the default. The region codes are the Kemendagri codes
+// of the names next to them, empty where the region dataset has no entry.
+type Address struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	RecipientName string
+	PhoneNumber   string
+	Street        string
+	Village       string
+	VillageCode   string
+	District      string
+	DistrictCode  string
+	City          string
+	CityCode      string
+	Province      string
+	ProvinceCode  string
+	PostalCode    string
+	IsDefault     bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
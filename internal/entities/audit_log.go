@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit log actions.
+const (
+	AuditRoleGranted          = "role.granted"
+	AuditRoleRevoked          = "role.revoked"
+	AuditRoleGrantRequested   = "role.grant_requested"
+	AuditRoleGrantRejected    = "role.grant_rejected"
+	AuditRoleCreated          = "role.created"
+	AuditRoleUpdated          = "role.updated"
+	AuditRoleDeleted          = "role.deleted"
+	AuditPermissionCreated    = "permission.created"
+	AuditPermissionDeleted    = "permission.deleted"
+	AuditImpersonationStarted = "impersonation.started"
+	AuditImpersonatedRequest  = "impersonation.request"
+	// AuditRoleBackfilled marks a privileged role copied from users.role by
+	// migration 20, which nobody approved.
+	AuditRoleBackfilled = "role.backfilled"
+)
+
+// AuditLogEntry records a change made by an admin. Actor and target are nil
+// when the account has since been deleted or the change was made by the
+// service itself.
+type AuditLogEntry struct {
+	ID           int64
+	ActorID      *uuid.UUID
+	TargetUserID *uuid.UUID
+	Action       string
+	Details      json.RawMessage
+	CreatedAt    time.Time
+}
@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// MFAChallenge is handed out instead of tokens when a login needs a second
+// factor. EnrollmentRequired is set when the user's role demands MFA but the
+// user has not set it up yet.
+type MFAChallenge struct {
+	Token              string
+	ExpiresAt          time.Time
+	EnrollmentRequired bool
+}
+
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+type MFAStatus struct {
+	Enabled                bool
+	EnabledAt              *time.Time
+	Required               bool
+	RecoveryCodesRemaining int64
+}
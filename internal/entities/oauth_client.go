@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+type OAuthClient struct {
+	ID            string
+	Name          string
+	RedirectURIs  []string
+	AllowedScopes []string
+	IsFirstParty  bool
+	// IsConfidential is true when the client authenticates with a secret.
+	IsConfidential bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Categories of non-transactional email a user can opt into. Transactional
+// mail, such as verification links and security notices, is always sent.
+const (
+	EmailCategoryNewsletter      = "newsletter"
+	EmailCategoryPromotions      = "promotions"
+	EmailCategoryRecommendations = "recommendations"
+)
+
+// UserPreferences are a user's display and notification settings.
+type UserPreferences struct {
+	UserID uuid.UUID
+	// Locale is a BCP 47 tag such as id-ID.
+	Locale string
+	// Timezone is an IANA zone such as Asia/Jakarta. Timestamps are stored
+	// and served in UTC; clients render them in this zone.
+	Timezone string
+	// Currency is the ISO 4217 code prices are displayed in.
+	Currency string
+
+	EmailNewsletter      bool
+	EmailPromotions      bool
+	EmailRecommendations bool
+
+	// UpdatedAt is nil while the user is on the defaults.
+	UpdatedAt *time.Time
+}
+
+// AllowsEmail reports whether the user opted into email of category.
+// Unknown categories are never allowed.
+func (p *UserPreferences) AllowsEmail(category string) bool {
+	switch category {
+	case EmailCategoryNewsletter:
+		return p.EmailNewsletter
+	case EmailCategoryPromotions:
+		return p.EmailPromotions
+	case EmailCategoryRecommendations:
+		return p.EmailRecommendations
+	default:
+		return false
+	}
+}
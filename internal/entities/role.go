@@ -0,0 +1,85 @@
+package entities
+
+import (
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Permissions checked by this service. Other services define their own in the
+// permissions table and read them from access tokens.
+const (
+	PermissionUsersRead                = "users:read"
+	PermissionUsersWrite               = "users:write"
+	PermissionSessionsManage           = "sessions:manage"
+	PermissionMFAManage                = "mfa:manage"
+	PermissionOAuthClientsManage       = "oauth_clients:manage"
+	PermissionSellerApplicationsReview = "seller_applications:review"
+	PermissionRolesManage              = "roles:manage"
+	PermissionAuditLogRead             = "audit_log:read"
+	PermissionUsersImpersonate         = "users:impersonate"
+)
+
+// StaffPermissions are the permissions of this service's staff endpoints.
+// Users holding any of them cannot be impersonated.
+var StaffPermissions = []string{
+	PermissionUsersRead,
+	PermissionUsersWrite,
+	PermissionSessionsManage,
+	PermissionMFAManage,
+	PermissionOAuthClientsManage,
+	PermissionSellerApplicationsReview,
+	PermissionRolesManage,
+	PermissionAuditLogRead,
+	PermissionUsersImpersonate,
+}
+
+// GrantsStaffAccess reports whether a permission set includes any staff
+// permission. Roles that do are always privileged.
+func GrantsStaffAccess(permissions []string) bool {
+	for _, p := range permissions {
+		if slices.Contains(StaffPermissions, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Role is a named set of permissions. System roles are assigned by the
+// service itself, as the primary role of an account, and cannot be deleted.
+// Granting a privileged role needs the approval of a second admin.
+type Role struct {
+	Name         string
+	Description  string
+	IsSystem     bool
+	IsPrivileged bool
+	Permissions  []string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Permission is one resource:action name. System permissions are checked by
+// this service and cannot be deleted.
+type Permission struct {
+	Name        string
+	Description string
+	IsSystem    bool
+	CreatedAt   time.Time
+}
+
+var (
+	roleNamePattern       = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+	permissionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}:[a-z][a-z0-9_]{0,49}$`)
+)
+
+// ValidRoleName accepts lower case names of 2 to 50 letters, digits and
+// underscores, starting with a letter.
+func ValidRoleName(name string) bool {
+	return roleNamePattern.MatchString(name)
+}
+
+// ValidPermissionName accepts resource:action names, each part following the
+// rules for role names.
+func ValidPermissionName(name string) bool {
+	return permissionNamePattern.MatchString(name)
+}
@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role grant request states. Only pending requests can be decided.
+const (
+	RoleGrantPending  = "pending"
+	RoleGrantApproved = "approved"
+	RoleGrantRejected = "rejected"
+)
+
+// RoleGrantRequest is a grant of a privileged role waiting for a second
+// admin. The role only takes effect once the request is approved.
+type RoleGrantRequest struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	Role          string
+	RequestedBy   *uuid.UUID
+	Reason        string
+	Status        string
+	DecisionNotes string
+	DecidedBy     *uuid.UUID
+	DecidedAt     *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Seller application states. Only pending applications can be decided.
+const (
+	SellerApplicationPending  = "pending"
+	SellerApplicationApproved = "approved"
+	SellerApplicationRejected = "rejected"
+)
+
+// Documents of a seller application. NPWP is only asked for when the
+// applicant gives an NPWP number.
+const (
+	SellerDocumentKTP    = "ktp"
+	SellerDocumentSelfie = "selfie"
+	SellerDocumentNPWP   = "npwp"
+)
+
+// SellerApplication is a user's request to sell on the marketplace. NIK and
+// NPWP are masked to their last digits except in the reviewer's view.
+type SellerApplication struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	ShopName    string
+	NIK         string
+	NPWP        string
+	Status      string
+	ReviewNotes string
+	ReviewedBy  *uuid.UUID
+	ReviewedAt  *time.Time
+	Documents   []SellerApplicationDocument
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// SellerApplicationDocument describes an uploaded document. Data is only
+// filled when the document itself is downloaded.
+type SellerApplicationDocument struct {
+	Kind        string
+	ContentType string
+	Size        int
+	Data        []byte
+	CreatedAt   time.Time
+}
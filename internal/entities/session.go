@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one signed-in device. Its ID is the refresh token family ID.
+type Session struct {
+	ID         string
+	UserID     uuid.UUID
+	ClientID   string
+	Device     string
+	IPAddress  string
+	UserAgent  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
@@ -7,6 +7,14 @@ import (
 	"gorm.io/gorm"
 )
 
+// Roles an account can have. Public registration always creates RoleUser;
+// an approved seller application turns it into RoleSeller.
+const (
+	RoleUser   = "user"
+	RoleSeller = "seller"
+	RoleAdmin  = "admin"
+)
+
 type User struct {
 	ID          uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	Name        string         `json:"name"`
@@ -18,4 +26,27 @@ type User struct {
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	// AvatarURL is the public address of the default avatar size, empty
+	// without one.
+	AvatarURL string `json:"avatar_url"`
+
+	// EmailVerifiedAt is nil until the current email has been confirmed.
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// PhoneVerifiedAt is nil until the current phone number has been confirmed
+	// with a code sent to it.
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"`
+
+	Status       string `json:"status"`
+	StatusReason string `json:"status_reason"`
+	// SuspendedUntil is only set while suspended; nil means until lifted.
+	SuspendedUntil *time.Time `json:"suspended_until"`
+
+	// DeletionScheduledAt is set while a self-service deletion waits out its
+	// grace period.
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+}
+
+// BlocksAccess reports whether the account's status keeps it from signing in.
+func (u *User) BlocksAccess(now time.Time) bool {
+	return StatusBlocksAccess(u.Status, u.SuspendedUntil, now)
 }
@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+// Account lifecycle states. See services.statusTransitions for the moves
+// between them.
+const (
+	UserStatusPendingVerification = "pending_verification"
+	UserStatusActive              = "active"
+	UserStatusSuspended           = "suspended"
+	UserStatusBanned              = "banned"
+	UserStatusDeleted             = "deleted"
+)
+
+// StatusBlocksAccess reports whether an account in status may not sign in or
+// use its tokens at now. A suspension with an end date lapses on its own.
+func StatusBlocksAccess(status string, suspendedUntil *time.Time, now time.Time) bool {
+	switch status {
+	case UserStatusBanned, UserStatusDeleted:
+		return true
+	case UserStatusSuspended:
+		return suspendedUntil == nil || now.Before(*suspendedUntil)
+	default:
+		return false
+	}
+}
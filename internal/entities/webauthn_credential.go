@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebAuthnCredential struct {
+	ID         []byte
+	UserID     uuid.UUID
+	Name       string
+	Transports []string
+	// Synced is true for passkeys backed up to a cloud keychain.
+	Synced     bool
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
@@ -2,11 +2,15 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
 
 	accountpb "github.com/RehanAthallahAzhar/tokohobby-protos/pb/account"
@@ -14,11 +18,13 @@ import (
 
 type AccountServer struct {
 	accountpb.UnimplementedAccountServiceServer
-	UserService services.UserService
+	UserService        services.UserService
+	AddressService     services.AddressService
+	PreferencesService services.PreferencesService
 }
 
-func NewAccountServer(userService services.UserService) *AccountServer {
-	return &AccountServer{UserService: userService}
+func NewAccountServer(userService services.UserService, addressService services.AddressService, preferencesService services.PreferencesService) *AccountServer {
+	return &AccountServer{UserService: userService, AddressService: addressService, PreferencesService: preferencesService}
 }
 
 func (s *AccountServer) GetUser(ctx context.Context, req *accountpb.GetUserRequest) (*accountpb.User, error) {
@@ -37,6 +43,11 @@ func (s *AccountServer) GetUser(ctx context.Context, req *accountpb.GetUserReque
 		return nil, status.Errorf(codes.Internal, "failed to get user: %v", err)
 	}
 
+	preferences, err := s.PreferencesService.GetPreferences(ctx, uuid)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get preferences: %v", err)
+	}
+
 	return &accountpb.User{
 		Id:          user.ID.String(),
 		Name:        user.Name,
@@ -44,9 +55,19 @@ func (s *AccountServer) GetUser(ctx context.Context, req *accountpb.GetUserReque
 		Email:       user.Email,
 		PhoneNumber: user.PhoneNumber,
 		Address:     user.Address,
+		AvatarUrl:   user.AvatarURL,
+		Preferences: &accountpb.UserPreferences{
+			Locale:               preferences.Locale,
+			Timezone:             preferences.Timezone,
+			Currency:             preferences.Currency,
+			EmailNewsletter:      preferences.EmailNewsletter,
+			EmailPromotions:      preferences.EmailPromotions,
+			EmailRecommendations: preferences.EmailRecommendations,
+		},
 	}, nil
 }
 
+// GetUsers returns every user at once. Deprecated: use ListUsers.
 func (s *AccountServer) GetUsers(ctx context.Context, req *accountpb.GetUsersRequest) (*accountpb.GetUsersResponse, error) {
 	users, err := s.UserService.GetAllUsers(ctx)
 	if err != nil {
@@ -64,6 +85,7 @@ func (s *AccountServer) GetUsers(ctx context.Context, req *accountpb.GetUsersReq
 			PhoneNumber: user.PhoneNumber,
 			Address:     user.Address,
 			Role:        user.Role,
+			AvatarUrl:   user.AvatarURL,
 		})
 	}
 
@@ -71,3 +93,126 @@ func (s *AccountServer) GetUsers(ctx context.Context, req *accountpb.GetUsersReq
 		Users: pbUsers,
 	}, nil
 }
+
+func (s *AccountServer) ListUsers(ctx context.Context, req *accountpb.ListUsersRequest) (*accountpb.ListUsersResponse, error) {
+	filter := services.UserListFilter{
+		Role:        req.GetRole(),
+		Status:      req.GetStatus(),
+		EmailDomain: req.GetEmailDomain(),
+		Search:      req.GetQuery(),
+		SortBy:      req.GetOrderBy(),
+		Descending:  req.GetDescending(),
+		Cursor:      req.GetPageToken(),
+		Limit:       int(req.GetPageSize()),
+	}
+
+	var err error
+	if filter.CreatedAfter, err = parseTimestamp(req.GetCreatedAfter()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid created_after: %v", err)
+	}
+	if filter.CreatedBefore, err = parseTimestamp(req.GetCreatedBefore()); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid created_before: %v", err)
+	}
+
+	page, err := s.UserService.ListUsers(ctx, filter)
+	if errors.Is(err, apperrors.ErrInvalidRequestPayload) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list users: %v", err)
+	}
+
+	pbUsers := make([]*accountpb.User, 0, len(page.Users))
+	for _, user := range page.Users {
+		pbUsers = append(pbUsers, &accountpb.User{
+			Id:          user.ID.String(),
+			Name:        user.Name,
+			Username:    user.Username,
+			Email:       user.Email,
+			PhoneNumber: user.PhoneNumber,
+			Address:     user.Address,
+			Role:        user.Role,
+			AvatarUrl:   user.AvatarURL,
+		})
+	}
+
+	return &accountpb.ListUsersResponse{
+		Users:         pbUsers,
+		NextPageToken: page.NextCursor,
+	}, nil
+}
+
+func (s *AccountServer) ListAddresses(ctx context.Context, req *accountpb.ListAddressesRequest) (*accountpb.ListAddressesResponse, error) {
+	userID, err := helpers.StringToUUID(req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user_id format")
+	}
+
+	addresses, err := s.AddressService.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list addresses: %v", err)
+	}
+
+	pbAddresses := make([]*accountpb.Address, 0, len(addresses))
+	for i := range addresses {
+		pbAddresses = append(pbAddresses, toPBAddress(&addresses[i]))
+	}
+
+	return &accountpb.ListAddressesResponse{Addresses: pbAddresses}, nil
+}
+
+// GetAddress only finds the address when it belongs to user_id, so checkout
+// cannot ship to another user's address by passing its id.
+func (s *AccountServer) GetAddress(ctx context.Context, req *accountpb.GetAddressRequest) (*accountpb.Address, error) {
+	userID, err := helpers.StringToUUID(req.GetUserId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user_id format")
+	}
+	id, err := helpers.StringToUUID(req.GetId())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid id format")
+	}
+
+	address, err := s.AddressService.GetAddress(ctx, userID, id)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "address not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get address: %v", err)
+	}
+
+	return toPBAddress(address), nil
+}
+
+func toPBAddress(address *entities.Address) *accountpb.Address {
+	return &accountpb.Address{
+		Id:            address.ID.String(),
+		UserId:        address.UserID.String(),
+		RecipientName: address.RecipientName,
+		PhoneNumber:   address.PhoneNumber,
+		Street:        address.Street,
+		Village:       address.Village,
+		VillageCode:   address.VillageCode,
+		District:      address.District,
+		DistrictCode:  address.DistrictCode,
+		City:          address.City,
+		CityCode:      address.CityCode,
+		Province:      address.Province,
+		ProvinceCode:  address.ProvinceCode,
+		PostalCode:    address.PostalCode,
+		IsDefault:     address.IsDefault,
+	}
+}
+
+// parseTimestamp parses an optional RFC 3339 field.
+func parseTimestamp(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
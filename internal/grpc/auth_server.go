@@ -2,43 +2,222 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
 	authpb "github.com/RehanAthallahAzhar/tokohobby-protos/pb/auth"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/authz"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
 )
 
 type AuthServer struct {
 	authpb.UnimplementedAuthServiceServer
-	TokenService token.TokenService
+	TokenService         token.TokenService
+	AuthorizationService services.AuthorizationService
+	AuditLogService      services.AuditLogService
 }
 
-func NewAuthServer(tokenService token.TokenService) *AuthServer {
-	return &AuthServer{TokenService: tokenService}
+func NewAuthServer(tokenService token.TokenService, authorizationService services.AuthorizationService, auditLogService services.AuditLogService) *AuthServer {
+	return &AuthServer{TokenService: tokenService, AuthorizationService: authorizationService, AuditLogService: auditLogService}
 }
 
 func (s *AuthServer) ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.ValidateTokenResponse, error) {
 	tokenString := req.GetToken()
 
-	isValid, userID, username, role, errMsg, err := s.TokenService.ValidateToken(ctx, tokenString)
+	claims, errMsg, err := s.TokenService.ValidateToken(ctx, tokenString)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "Internal server error during token validation: %v", err)
 	}
 
-	if !isValid {
+	if claims == nil {
 		return &authpb.ValidateTokenResponse{
 			IsValid:      false,
 			ErrorMessage: errMsg,
 		}, status.Errorf(codes.Unauthenticated, "Token validation failed: %s", errMsg)
 	}
 
-	return &authpb.ValidateTokenResponse{
-		IsValid:      true,
-		UserId:       userID.String(),
-		Username:     username,
-		Role:         role,
-		ErrorMessage: "",
+	// Tokens issued to OAuth clients are limited to their scopes, which the
+	// response cannot express, so they are not valid for other services.
+	if claims.Scope != "" {
+		return &authpb.ValidateTokenResponse{
+			IsValid:      false,
+			ErrorMessage: "tokens issued to OAuth clients are not accepted",
+		}, status.Error(codes.PermissionDenied, "tokens issued to OAuth clients are not accepted")
+	}
+
+	res := &authpb.ValidateTokenResponse{
+		IsValid:       true,
+		UserId:        claims.UserID.String(),
+		Username:      claims.Username,
+		Role:          claims.Role,
+		EmailVerified: claims.EmailVerified,
+		Roles:         claims.Roles,
+		Permissions:   claims.Permissions,
+		ErrorMessage:  "",
+	}
+	// Impersonation tokens name the admin acting as the user.
+	if claims.Act != nil {
+		if err := s.recordImpersonation(ctx, claims, nil); err != nil {
+			return nil, err
+		}
+		res.ActorId = claims.Act.UserID.String()
+		res.ActorUsername = claims.Act.Subject
+	}
+	return res, nil
+}
+
+func (s *AuthServer) GetJWKS(ctx context.Context, req *authpb.GetJWKSRequest) (*authpb.GetJWKSResponse, error) {
+	set := s.TokenService.JWKS()
+
+	keys := make([]*authpb.JWK, 0, len(set.Keys))
+	for _, key := range set.Keys {
+		keys = append(keys, &authpb.JWK{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Use: key.Use,
+			Alg: key.Alg,
+			N:   key.N,
+			E:   key.E,
+			Crv: key.Crv,
+			X:   key.X,
+		})
+	}
+
+	return &authpb.GetJWKSResponse{Keys: keys}, nil
+}
+
+// Authorize decides one action for the caller identified by token or
+// user_id, or for an anonymous caller when both are empty.
+func (s *AuthServer) Authorize(ctx context.Context, req *authpb.AuthorizeRequest) (*authpb.AuthorizeResponse, error) {
+	userID, err := s.subjectID(ctx, req.GetToken(), req.GetUserId(), []string{req.GetAction()})
+	if err != nil {
+		return nil, err
+	}
+
+	decisions, version, err := s.AuthorizationService.Authorize(ctx, userID, []services.AuthorizationCheck{
+		{Action: req.GetAction(), Resource: toAuthzResource(req.GetResource())},
+	})
+	if err != nil {
+		return nil, authorizationError(err)
+	}
+
+	return &authpb.AuthorizeResponse{
+		Allowed:       decisions[0].Allowed,
+		Reason:        decisions[0].Reason,
+		RuleId:        decisions[0].RuleID,
+		PolicyVersion: version,
 	}, nil
 }
+
+// CheckPermissions decides a batch of actions for one caller, in order.
+func (s *AuthServer) CheckPermissions(ctx context.Context, req *authpb.CheckPermissionsRequest) (*authpb.CheckPermissionsResponse, error) {
+	actions := make([]string, 0, len(req.GetChecks()))
+	for _, check := range req.GetChecks() {
+		actions = append(actions, check.GetAction())
+	}
+
+	userID, err := s.subjectID(ctx, req.GetToken(), req.GetUserId(), actions)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]services.AuthorizationCheck, 0, len(req.GetChecks()))
+	for _, check := range req.GetChecks() {
+		checks = append(checks, services.AuthorizationCheck{
+			Action:   check.GetAction(),
+			Resource: toAuthzResource(check.GetResource()),
+		})
+	}
+
+	decisions, version, err := s.AuthorizationService.Authorize(ctx, userID, checks)
+	if err != nil {
+		return nil, authorizationError(err)
+	}
+
+	res := &authpb.CheckPermissionsResponse{
+		Decisions:     make([]*authpb.Decision, 0, len(decisions)),
+		PolicyVersion: version,
+	}
+	for _, d := range decisions {
+		res.Decisions = append(res.Decisions, &authpb.Decision{
+			Allowed: d.Allowed,
+			Reason:  d.Reason,
+			RuleId:  d.RuleID,
+		})
+	}
+	return res, nil
+}
+
+// subjectID resolves who is asking. A token is validated like
+// ValidateToken does, and an impersonation token is recorded with the actions
+// asked about; a user_id is trusted as sent, for calls a service makes on a
+// user's behalf without their token.
+func (s *AuthServer) subjectID(ctx context.Context, tokenString, userID string, actions []string) (uuid.UUID, error) {
+	switch {
+	case tokenString != "" && userID != "":
+		return uuid.Nil, status.Error(codes.InvalidArgument, "send either token or user_id, not both")
+	case tokenString != "":
+		claims, errMsg, err := s.TokenService.ValidateToken(ctx, tokenString)
+		if err != nil {
+			return uuid.Nil, status.Errorf(codes.Internal, "Internal server error during token validation: %v", err)
+		}
+		if claims == nil {
+			return uuid.Nil, status.Errorf(codes.Unauthenticated, "Token validation failed: %s", errMsg)
+		}
+		if claims.Scope != "" {
+			return uuid.Nil, status.Error(codes.PermissionDenied, "tokens issued to OAuth clients cannot be authorized")
+		}
+		if claims.Act != nil {
+			if err := s.recordImpersonation(ctx, claims, actions); err != nil {
+				return uuid.Nil, err
+			}
+		}
+		return claims.UserID, nil
+	case userID != "":
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid user_id format")
+		}
+		return id, nil
+	default:
+		return uuid.Nil, nil
+	}
+}
+
+// recordImpersonation writes a call made with an impersonation token to the
+// audit log, like the HTTP AuditImpersonation middleware does. A call that
+// cannot be recorded is refused.
+func (s *AuthServer) recordImpersonation(ctx context.Context, claims *token.JWTClaims, actions []string) error {
+	method, _ := grpc.Method(ctx)
+	details := map[string]any{"method": method}
+	if len(actions) > 0 {
+		details["actions"] = actions
+	}
+
+	if err := s.AuditLogService.Record(ctx, claims.Act.UserID, claims.UserID, entities.AuditImpersonatedRequest, details); err != nil {
+		return status.Error(codes.Internal, "could not record the impersonated request")
+	}
+	return nil
+}
+
+func toAuthzResource(resource *authpb.Resource) authz.Resource {
+	return authz.Resource{ID: resource.GetId(), OwnerID: resource.GetOwnerId()}
+}
+
+func authorizationError(err error) error {
+	var validationErrs apperrors.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return status.Errorf(codes.InvalidArgument, "%s: %s", validationErrs.Errors[0].Field, validationErrs.Errors[0].Message)
+	}
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return status.Error(codes.NotFound, "user not found")
+	}
+	return status.Errorf(codes.Internal, "failed to authorize: %v", err)
+}
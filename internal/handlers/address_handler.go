@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+func (h *UserHandler) ListAddresses(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	addresses, err := h.AddressService.ListAddresses(ctx, userID)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := make([]models.AddressResponse, 0, len(addresses))
+	for i := range addresses {
+		res = append(res, toAddressResponse(&addresses[i]))
+	}
+	return respondSuccess(c, http.StatusOK, MsgAddressesRetrieved, res)
+}
+
+func (h *UserHandler) GetAddress(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	address, err := h.AddressService.GetAddress(ctx, userID, id)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgAddressRetrieved, toAddressResponse(address))
+}
+
+func (h *UserHandler) CreateAddress(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	var req models.AddressRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	address, err := h.AddressService.CreateAddress(ctx, userID, &req)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusCreated, MsgAddressCreated, toAddressResponse(address))
+}
+
+func (h *UserHandler) UpdateAddress(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	var req models.AddressRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	address, err := h.AddressService.UpdateAddress(ctx, userID, id, &req)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgAddressUpdated, toAddressResponse(address))
+}
+
+func (h *UserHandler) SetDefaultAddress(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	address, err := h.AddressService.SetDefaultAddress(ctx, userID, id)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgAddressUpdated, toAddressResponse(address))
+}
+
+func (h *UserHandler) DeleteAddress(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.AddressService.DeleteAddress(ctx, userID, id); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgAddressDeleted, nil)
+}
+
+func toAddressResponse(address *entities.Address) models.AddressResponse {
+	return models.AddressResponse{
+		ID:            address.ID.String(),
+		RecipientName: address.RecipientName,
+		PhoneNumber:   address.PhoneNumber,
+		Street:        address.Street,
+		Village:       address.Village,
+		VillageCode:   address.VillageCode,
+		District:      address.District,
+		DistrictCode:  address.DistrictCode,
+		City:          address.City,
+		CityCode:      address.CityCode,
+		Province:      address.Province,
+		ProvinceCode:  address.ProvinceCode,
+		PostalCode:    address.PostalCode,
+		IsDefault:     address.IsDefault,
+		CreatedAt:     address.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     address.UpdatedAt.Format(time.RFC3339),
+	}
+}
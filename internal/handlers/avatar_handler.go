@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+// UploadAvatar takes the image in the multipart field "avatar". The declared
+// content type is ignored; the service looks at the data itself.
+func (h *UserHandler) UploadAvatar(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		return h.handleServiceError(c, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "avatar", Message: "is required"}}})
+	}
+	file, err := header.Open()
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+	defer file.Close()
+
+	user, err := h.AvatarService.Upload(ctx, userID, file)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgAvatarUpdated, toUserResponse(user))
+}
+
+func (h *UserHandler) DeleteAvatar(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	user, err := h.AvatarService.Delete(ctx, userID)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgAvatarDeleted, toUserResponse(user))
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+func (h *UserHandler) RequestDataExport(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	export, err := h.ExportService.RequestExport(ctx, userID)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusAccepted, MsgDataExportRequested, toDataExportResponse(export))
+}
+
+func (h *UserHandler) ListDataExports(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	exports, err := h.ExportService.ListExports(ctx, userID)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := make([]models.DataExportResponse, 0, len(exports))
+	for i := range exports {
+		res = append(res, toDataExportResponse(&exports[i]))
+	}
+	return respondSuccess(c, http.StatusOK, MsgDataExportsRetrieved, res)
+}
+
+// DownloadDataExport is public: the signed link from the email is the
+// credential.
+func (h *UserHandler) DownloadDataExport(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	path, err := h.ExportService.OpenDownload(ctx, c.QueryParam("id"), c.QueryParam("expires"), c.QueryParam("signature"))
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return c.Attachment(path, "tokohobby-data-export.zip")
+}
+
+func toDataExportResponse(export *entities.DataExport) models.DataExportResponse {
+	res := models.DataExportResponse{
+		ID:          export.ID.String(),
+		Status:      export.Status,
+		CreatedAt:   export.CreatedAt.Format(time.RFC3339),
+		DownloadURL: export.DownloadURL,
+	}
+	if export.CompletedAt != nil {
+		res.CompletedAt = export.CompletedAt.Format(time.RFC3339)
+	}
+	if export.ExpiresAt != nil {
+		res.ExpiresAt = export.ExpiresAt.Format(time.RFC3339)
+	}
+	return res
+}
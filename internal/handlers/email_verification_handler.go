@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+// VerifyEmail redeems the token from the verification link. It needs no
+// session, since the link is usually opened in another browser.
+func (h *UserHandler) VerifyEmail(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	if err := h.EmailVerifier.Verify(ctx, req.Token); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgEmailVerified, nil)
+}
+
+func (h *UserHandler) ResendVerificationEmail(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	if err := h.EmailVerifier.Resend(ctx, user); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusAccepted, MsgVerificationEmailSent, nil)
+}
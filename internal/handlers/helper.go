@@ -11,18 +11,99 @@ import (
 	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
 
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
 )
 
 // ------- HELPERS -------
 
 const (
-	MsgUserRetrieved  = "User retrieved successfully"
-	MsgUserCreated    = "User created successfully"
-	MsgUserUpdated    = "User updated successfully"
-	MsgUserDeleted    = "User deleted successfully"
-	MsgUsersRetrieved = "Users retrieved successfully"
-	MsgLogin          = "Login successful"
-	MsgLogout         = "Logout successful"
+	MsgUserRetrieved     = "User retrieved successfully"
+	MsgUserCreated       = "User created successfully"
+	MsgUserUpdated       = "User updated successfully"
+	MsgUserDeleted       = "User deleted successfully"
+	MsgUsersRetrieved    = "Users retrieved successfully"
+	MsgLogin             = "Login successful"
+	MsgLogout            = "Logout successful"
+	MsgSessionRefreshed  = "Session refreshed"
+	MsgSessionsRetrieved = "Sessions retrieved successfully"
+	MsgSessionRevoked    = "Session revoked successfully"
+	MsgSessionsRevoked   = "Sessions revoked successfully"
+	MsgUserStatusChanged = "User status changed successfully"
+	MsgUserRestored      = "User restored successfully"
+
+	MsgAvatarUpdated = "Avatar updated successfully"
+	MsgAvatarDeleted = "Avatar deleted successfully"
+
+	MsgPreferencesRetrieved = "Preferences retrieved successfully"
+	MsgPreferencesUpdated   = "Preferences updated successfully"
+
+	MsgSellerApplicationSubmitted  = "Seller application submitted. We will email you once it has been reviewed"
+	MsgSellerApplicationsRetrieved = "Seller applications retrieved successfully"
+	MsgSellerApplicationRetrieved  = "Seller application retrieved successfully"
+	MsgSellerApplicationApproved   = "Seller application approved"
+	MsgSellerApplicationRejected   = "Seller application rejected"
+
+	MsgRolesRetrieved       = "Roles retrieved successfully"
+	MsgRoleRetrieved        = "Role retrieved successfully"
+	MsgRoleCreated          = "Role created successfully"
+	MsgRoleUpdated          = "Role updated successfully"
+	MsgRoleDeleted          = "Role deleted successfully"
+	MsgPermissionsRetrieved = "Permissions retrieved successfully"
+	MsgPermissionCreated    = "Permission created successfully"
+	MsgPermissionDeleted    = "Permission deleted successfully"
+	MsgUserAccessRetrieved  = "User roles and permissions retrieved successfully"
+
+	MsgRoleGranted              = "Role granted successfully"
+	MsgRoleGrantRequested       = "Role grant requested. A second admin has to approve it"
+	MsgRoleRevoked              = "Role revoked successfully"
+	MsgRoleGrantRequestsFetched = "Role grant requests retrieved successfully"
+	MsgRoleGrantApproved        = "Role grant approved"
+	MsgRoleGrantRejected        = "Role grant rejected"
+	MsgAuditLogRetrieved        = "Audit log retrieved successfully"
+	MsgImpersonationStarted     = "Impersonation token issued"
+
+	MsgAccountDeletionScheduled = "Your account will be deleted. Log in again before then to keep it"
+
+	MsgAddressesRetrieved = "Addresses retrieved successfully"
+	MsgAddressRetrieved   = "Address retrieved successfully"
+	MsgAddressCreated     = "Address created successfully"
+	MsgAddressUpdated     = "Address updated successfully"
+	MsgAddressDeleted     = "Address deleted successfully"
+	MsgRegionsRetrieved   = "Regions retrieved successfully"
+
+	MsgDataExportRequested  = "Your data export has been requested. We will email you when it is ready"
+	MsgDataExportsRetrieved = "Data exports retrieved successfully"
+
+	MsgEmailVerified         = "Email verified successfully"
+	MsgVerificationEmailSent = "Verification email sent"
+
+	MsgPhoneCodeSent      = "Verification code sent to your phone"
+	MsgPhoneVerified      = "Phone number verified successfully"
+	MsgPhoneLoginCodeSent = "If an account uses that phone number, a login code has been sent to it"
+
+	MsgPasswordResetRequested = "If an account uses that email, a password reset link has been sent to it"
+	MsgPasswordReset          = "Password reset successfully. Please log in with your new password"
+	MsgPasswordChanged        = "Password changed successfully"
+
+	MsgMFARequired              = "Two-factor verification required"
+	MsgMFAStatusRetrieved       = "Two-factor status retrieved successfully"
+	MsgMFAEnrollmentStarted     = "Scan the QR code with your authenticator app, then confirm with a code"
+	MsgMFAEnabled               = "Two-factor authentication enabled"
+	MsgMFADisabled              = "Two-factor authentication disabled"
+	MsgRecoveryCodesRegenerated = "Recovery codes regenerated"
+	MsgMFARolesRetrieved        = "MFA required roles retrieved successfully"
+	MsgMFARoleUpdated           = "MFA role requirement updated successfully"
+
+	MsgPasskeyCeremonyStarted = "Passkey ceremony started"
+	MsgPasskeyRegistered      = "Passkey registered successfully"
+	MsgPasskeysRetrieved      = "Passkeys retrieved successfully"
+	MsgPasskeyDeleted         = "Passkey deleted successfully"
+
+	MsgConsentRetrieved      = "Consent request retrieved successfully"
+	MsgConsentRecorded       = "Consent recorded successfully"
+	MsgOAuthClientCreated    = "OAuth client created successfully"
+	MsgOAuthClientsRetrieved = "OAuth clients retrieved successfully"
+	MsgOAuthClientDeleted    = "OAuth client deleted successfully"
 )
 
 func extractUserID(c echo.Context) (uuid.UUID, error) {
@@ -39,6 +120,27 @@ func extractUserID(c echo.Context) (uuid.UUID, error) {
 	return uuid.Nil, errors.New("invalid user session: userID in context is not of type uuid.UUID")
 }
 
+func extractSessionID(c echo.Context) string {
+	sessionID, _ := c.Get("sessionID").(string)
+	return sessionID
+}
+
+// activityMetadata collects the request details stored with a session and sent
+// with activity events.
+func activityMetadata(c echo.Context) *services.ActivityMetadata {
+	metadata := &services.ActivityMetadata{
+		SessionID: c.Request().Header.Get("X-Session-Id"),
+		Device:    c.Request().Header.Get("X-Device-Name"),
+		IPAddress: c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+	// Fallback to request ID if no session ID
+	if metadata.SessionID == "" {
+		metadata.SessionID = c.Request().Header.Get("X-Request-Id")
+	}
+	return metadata
+}
+
 func respondSuccess(c echo.Context, status int, message string, data interface{}) error {
 	return c.JSON(status, models.SuccessResponse{
 		Message: message,
@@ -71,14 +173,44 @@ func (h *UserHandler) handleServiceError(c echo.Context, err error) error {
 	if errors.Is(err, apperrors.ErrInvalidToken) {
 		return respondError(c, http.StatusUnauthorized, err)
 	}
+	if errors.Is(err, apperrors.ErrRefreshTokenReused) {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+	if errors.Is(err, apperrors.ErrInvalidMFACode) {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+	if errors.Is(err, apperrors.ErrInvalidOTP) {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+	if errors.Is(err, apperrors.ErrPasskeyVerification) {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrPasskeyVerification)
+	}
+	if errors.Is(err, apperrors.ErrMFARequiredByRole) {
+		return respondError(c, http.StatusForbidden, err)
+	}
+	if errors.Is(err, apperrors.ErrEmailNotVerified) {
+		return respondError(c, http.StatusForbidden, err)
+	}
+	if errors.Is(err, apperrors.ErrAccountSuspended) || errors.Is(err, apperrors.ErrAccountBanned) || errors.Is(err, apperrors.ErrAccountDeleted) {
+		return respondError(c, http.StatusForbidden, err)
+	}
 	if errors.Is(err, apperrors.ErrForbidden) {
 		return respondError(c, http.StatusForbidden, err)
 	}
+	if errors.Is(err, apperrors.ErrSelfApproval) {
+		return respondError(c, http.StatusForbidden, err)
+	}
+	if errors.Is(err, apperrors.ErrCannotImpersonate) {
+		return respondError(c, http.StatusForbidden, err)
+	}
 
 	// not found
 	if errors.Is(err, apperrors.ErrNotFound) {
 		return respondError(c, http.StatusNotFound, err)
 	}
+	if errors.Is(err, apperrors.ErrSessionNotFound) {
+		return respondError(c, http.StatusNotFound, err)
+	}
 
 	// Data Conflict
 	if errors.Is(err, apperrors.ErrUserAlreadyExists) {
@@ -90,6 +222,86 @@ func (h *UserHandler) handleServiceError(c echo.Context, err error) error {
 	if errors.Is(err, apperrors.ErrEmailAlreadyExists) {
 		return respondError(c, http.StatusConflict, err)
 	}
+	if errors.Is(err, apperrors.ErrMFAAlreadyEnabled) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrMFANotEnrolled) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrEmailAlreadyVerified) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrPhoneAlreadyVerified) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrPhoneNumberTaken) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrStatusTransition) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrAddressLimitReached) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrSellerApplicationOpen) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrAlreadySeller) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrApplicationDecided) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrRoleAlreadyExists) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrPermissionExists) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrRoleProtected) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrPermissionProtected) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrRoleInUse) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrRoleHeld) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrRolePrivilegeHeld) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrRoleAlreadyGranted) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrRoleGrantPending) {
+		return respondError(c, http.StatusConflict, err)
+	}
+	if errors.Is(err, apperrors.ErrRoleGrantDecided) {
+		return respondError(c, http.StatusConflict, err)
+	}
+
+	if errors.Is(err, apperrors.ErrPreconditionFailed) {
+		return respondError(c, http.StatusPreconditionFailed, err)
+	}
+	if errors.Is(err, apperrors.ErrPreconditionRequired) {
+		return respondError(c, http.StatusPreconditionRequired, err)
+	}
+
+	if errors.Is(err, apperrors.ErrFileTooLarge) {
+		return respondError(c, http.StatusRequestEntityTooLarge, err)
+	}
+
+	if errors.Is(err, apperrors.ErrTooManyRequests) {
+		return respondError(c, http.StatusTooManyRequests, err)
+	}
+
+	if errors.Is(err, apperrors.ErrFailedToGenerateToken) {
+		h.log.WithError(err).Error("Failed to issue tokens")
+		return respondError(c, http.StatusInternalServerError, apperrors.ErrFailedToGenerateToken)
+	}
 
 	// Out of Stock Product
 	if errors.Is(err, apperrors.ErrProductOutOfStock) {
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+// ImpersonateUser issues a short-lived token to act as the user, for support
+// staff to see what they see.
+func (h *UserHandler) ImpersonateUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+
+	var req models.ImpersonationRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	accessToken, err := h.ImpersonationService.Impersonate(ctx, id, adminID, req.Reason)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return respondSuccess(c, http.StatusOK, MsgImpersonationStarted, models.ImpersonationResponse{
+		AccessToken: accessToken.Token,
+		TokenType:   "Bearer",
+		ExpiresAt:   accessToken.ExpiresAt.Format(time.RFC3339),
+		UserID:      id.String(),
+	})
+}
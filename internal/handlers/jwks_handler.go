@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetJWKS serves the public signing keys in the raw RFC 7517 shape (not wrapped
+// in SuccessResponse) so standard JWT libraries can consume it directly.
+func (h *UserHandler) GetJWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.TokenService.JWKS())
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+// LoginMFA is the second step of a login that returned mfa_required.
+func (h *UserHandler) LoginMFA(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.MFALoginRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	user, recoveryCodes, err := h.MFAService.CompleteLogin(ctx, req.MFAToken, services.MFAVerification{
+		Code:         req.Code,
+		RecoveryCode: req.RecoveryCode,
+	})
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	tokens, err := h.SessionService.CreateSession(ctx, user, activityMetadata(c), services.SessionOptions{})
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := models.MFALoginResponse{UserResponse: *toUserResponse(user), RecoveryCodes: recoveryCodes}
+	res.Token = tokens.AccessToken
+	res.RefreshToken = tokens.RefreshToken
+
+	return respondSuccess(c, http.StatusOK, MsgLogin, res)
+}
+
+// EnrollMFAWithChallenge starts TOTP enrollment for a user whose role requires
+// MFA and who therefore only holds an MFA challenge token.
+func (h *UserHandler) EnrollMFAWithChallenge(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.MFAEnrollRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	enrollment, err := h.MFAService.StartChallengeEnrollment(ctx, req.MFAToken)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgMFAEnrollmentStarted, toTOTPEnrollmentResponse(enrollment))
+}
+
+func (h *UserHandler) GetMFAStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	status, err := h.MFAService.GetStatus(ctx, user)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := models.MFAStatusResponse{
+		Enabled:                status.Enabled,
+		Required:               status.Required,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+	}
+	if status.EnabledAt != nil {
+		res.EnabledAt = status.EnabledAt.Format(time.RFC3339)
+	}
+	return respondSuccess(c, http.StatusOK, MsgMFAStatusRetrieved, res)
+}
+
+func (h *UserHandler) EnrollTOTP(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	enrollment, err := h.MFAService.StartEnrollment(ctx, user)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgMFAEnrollmentStarted, toTOTPEnrollmentResponse(enrollment))
+}
+
+func (h *UserHandler) ConfirmTOTP(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	var req models.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	codes, err := h.MFAService.ConfirmEnrollment(ctx, userID, req.Code)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgMFAEnabled, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *UserHandler) DisableTOTP(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	var req models.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	err = h.MFAService.Disable(ctx, user, services.MFAVerification{Code: req.Code, RecoveryCode: req.RecoveryCode})
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgMFADisabled, nil)
+}
+
+func (h *UserHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	var req models.MFACodeRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	codes, err := h.MFAService.RegenerateRecoveryCodes(ctx, userID, req.Code)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgRecoveryCodesRegenerated, models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *UserHandler) ListMFARequiredRoles(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	roles, err := h.MFAService.ListRequiredRoles(ctx)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgMFARolesRetrieved, roles)
+}
+
+func (h *UserHandler) RequireMFAForRole(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	role, err := helpers.GetFromPathParam(c, "role")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.MFAService.RequireForRole(ctx, role); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgMFARoleUpdated, nil)
+}
+
+func (h *UserHandler) UnrequireMFAForRole(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	role, err := helpers.GetFromPathParam(c, "role")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.MFAService.UnrequireForRole(ctx, role); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgMFARoleUpdated, nil)
+}
+
+func (h *UserHandler) currentUser(c echo.Context) (*entities.User, error) {
+	userID, err := extractUserID(c)
+	if err != nil {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	return h.UserService.GetUserByID(c.Request().Context(), userID)
+}
+
+func toTOTPEnrollmentResponse(enrollment *entities.TOTPEnrollment) models.TOTPEnrollmentResponse {
+	return models.TOTPEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	}
+}
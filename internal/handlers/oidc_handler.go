@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+const consentPagePath = "/static/consent.html"
+
+// The discovery document, token and userinfo endpoints answer in the plain
+// OAuth / OIDC shapes (not SuccessResponse) so client libraries can use them.
+
+func (h *UserHandler) GetOpenIDConfiguration(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, h.OIDCService.Discovery())
+}
+
+// Authorize validates the request and hands the browser to the consent page,
+// which logs the user in if needed and posts the decision back.
+func (h *UserHandler) Authorize(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.AuthorizeRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, apperrors.NewOAuthError("invalid_request", "malformed authorization request"))
+	}
+
+	client, _, err := h.OIDCService.ValidateAuthorizeRequest(ctx, &req)
+	if err != nil {
+		var oauthErr *apperrors.OAuthError
+		if client != nil && errors.As(err, &oauthErr) {
+			return c.Redirect(http.StatusFound, services.AuthorizeErrorRedirect(req.RedirectURI, req.State, oauthErr))
+		}
+		return h.handleOAuthError(c, err)
+	}
+
+	return c.Redirect(http.StatusFound, consentPagePath+"?"+c.QueryString())
+}
+
+func (h *UserHandler) GetConsent(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	var req models.AuthorizeRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	res, err := h.OIDCService.GetConsent(ctx, userID, &req)
+	if err != nil {
+		return h.handleOAuthError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgConsentRetrieved, res)
+}
+
+func (h *UserHandler) DecideConsent(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	var req models.ConsentDecisionRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	redirectTo, err := h.OIDCService.DecideConsent(ctx, userID, &req)
+	if err != nil {
+		return h.handleOAuthError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgConsentRecorded, models.ConsentDecisionResponse{RedirectTo: redirectTo})
+}
+
+func (h *UserHandler) Token(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Pragma", "no-cache")
+
+	var req models.TokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, apperrors.NewOAuthError("invalid_request", "malformed token request"))
+	}
+
+	creds := services.ClientCredentials{ClientID: req.ClientID, ClientSecret: req.ClientSecret}
+	if id, secret, ok := c.Request().BasicAuth(); ok {
+		// RFC 6749 section 2.3.1: Basic credentials are form-urlencoded.
+		creds.ClientID, _ = url.QueryUnescape(id)
+		creds.ClientSecret, _ = url.QueryUnescape(secret)
+	}
+
+	res, err := h.OIDCService.Exchange(ctx, creds, &req, activityMetadata(c))
+	if err != nil {
+		return h.handleOAuthError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *UserHandler) UserInfo(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+	scope, _ := c.Get("scope").(string)
+
+	res, err := h.OIDCService.UserInfo(ctx, userID, scope)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *UserHandler) CreateOAuthClient(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.OAuthClientCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	client, secret, err := h.OIDCService.CreateClient(ctx, &req)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := toOAuthClientResponse(client)
+	res.Secret = secret
+	return respondSuccess(c, http.StatusCreated, MsgOAuthClientCreated, res)
+}
+
+func (h *UserHandler) ListOAuthClients(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	clients, err := h.OIDCService.ListClients(ctx)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := make([]models.OAuthClientResponse, 0, len(clients))
+	for i := range clients {
+		res = append(res, toOAuthClientResponse(&clients[i]))
+	}
+	return respondSuccess(c, http.StatusOK, MsgOAuthClientsRetrieved, res)
+}
+
+func (h *UserHandler) DeleteOAuthClient(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	clientID, err := helpers.GetFromPathParam(c, "clientId")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.OIDCService.DeleteClient(ctx, clientID); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgOAuthClientDeleted, nil)
+}
+
+// handleOAuthError answers OAuth protocol errors in the RFC 6749 shape and
+// leaves everything else to handleServiceError.
+func (h *UserHandler) handleOAuthError(c echo.Context, err error) error {
+	var oauthErr *apperrors.OAuthError
+	if !errors.As(err, &oauthErr) {
+		return h.handleServiceError(c, err)
+	}
+
+	if oauthErr.Code == "invalid_client" {
+		c.Response().Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		return c.JSON(http.StatusUnauthorized, oauthErr)
+	}
+	return c.JSON(http.StatusBadRequest, oauthErr)
+}
+
+func toOAuthClientResponse(client *entities.OAuthClient) models.OAuthClientResponse {
+	return models.OAuthClientResponse{
+		ID:            client.ID,
+		Name:          client.Name,
+		RedirectURIs:  client.RedirectURIs,
+		AllowedScopes: client.AllowedScopes,
+		IsFirstParty:  client.IsFirstParty,
+		Confidential:  client.IsConfidential,
+		CreatedAt:     client.CreatedAt.Format(time.RFC3339),
+	}
+}
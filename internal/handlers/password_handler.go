@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+// ForgotPassword answers 202 for any well-formed email, whether or not an
+// account uses it.
+func (h *UserHandler) ForgotPassword(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	if err := h.PasswordService.RequestReset(ctx, &req); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusAccepted, MsgPasswordResetRequested, nil)
+}
+
+func (h *UserHandler) ResetPassword(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	if err := h.PasswordService.ResetPassword(ctx, &req, activityMetadata(c)); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgPasswordReset, nil)
+}
+
+// ChangePassword signs the user out everywhere and answers with a new token
+// pair for the caller.
+func (h *UserHandler) ChangePassword(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	tokens, err := h.PasswordService.ChangePassword(ctx, userID, &req, activityMetadata(c))
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgPasswordChanged, map[string]string{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+	})
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+// SendPhoneVerificationCode texts a code to the number on the profile.
+func (h *UserHandler) SendPhoneVerificationCode(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	otp, err := h.PhoneService.SendVerificationCode(ctx, user)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusAccepted, MsgPhoneCodeSent, toPhoneOTPResponse(otp))
+}
+
+func (h *UserHandler) VerifyPhone(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	var req models.PhoneCodeRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	user, err := h.PhoneService.Verify(ctx, userID, req.Code)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgPhoneVerified, toUserResponse(user))
+}
+
+// SendPhoneLoginCode texts a login code. The answer does not say whether an
+// account uses the number.
+func (h *UserHandler) SendPhoneLoginCode(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.PhoneLoginCodeRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	otp, err := h.PhoneService.SendLoginCode(ctx, req.PhoneNumber)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusAccepted, MsgPhoneLoginCodeSent, toPhoneOTPResponse(otp))
+}
+
+// LoginPhone signs in with a verified phone number and the code texted to it.
+// Like a password login, it asks for the second factor when MFA is on.
+func (h *UserHandler) LoginPhone(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.PhoneLoginRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	user, err := h.PhoneService.Login(ctx, req.PhoneNumber, req.Code)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	challenge, err := h.MFAService.BeginLogin(ctx, user)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+	if challenge != nil {
+		return respondSuccess(c, http.StatusOK, MsgMFARequired, models.MFAChallengeResponse{
+			MFARequired:        true,
+			MFAToken:           challenge.Token,
+			EnrollmentRequired: challenge.EnrollmentRequired,
+			ExpiresAt:          challenge.ExpiresAt.Format(time.RFC3339),
+		})
+	}
+
+	tokens, err := h.SessionService.CreateSession(ctx, user, activityMetadata(c), services.SessionOptions{})
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := toUserResponse(user)
+	res.Token = tokens.AccessToken
+	res.RefreshToken = tokens.RefreshToken
+
+	return respondSuccess(c, http.StatusOK, MsgLogin, res)
+}
+
+func toPhoneOTPResponse(otp *services.PhoneOTP) models.PhoneOTPResponse {
+	return models.PhoneOTPResponse{ExpiresAt: otp.ExpiresAt.Format(time.RFC3339)}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+func (h *UserHandler) GetPreferences(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	preferences, err := h.PreferencesService.GetPreferences(ctx, userID)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgPreferencesRetrieved, toPreferencesResponse(preferences))
+}
+
+func (h *UserHandler) UpdatePreferences(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	var req models.PreferencesPatchRequest
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	preferences, err := h.PreferencesService.UpdatePreferences(ctx, userID, &req)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgPreferencesUpdated, toPreferencesResponse(preferences))
+}
+
+func toPreferencesResponse(preferences *entities.UserPreferences) *models.PreferencesResponse {
+	res := &models.PreferencesResponse{
+		Locale:   preferences.Locale,
+		Timezone: preferences.Timezone,
+		Currency: preferences.Currency,
+		Email: models.EmailPreferencesResponse{
+			Newsletter:      preferences.EmailNewsletter,
+			Promotions:      preferences.EmailPromotions,
+			Recommendations: preferences.EmailRecommendations,
+		},
+	}
+	if preferences.UpdatedAt != nil {
+		res.UpdatedAt = preferences.UpdatedAt.Format(time.RFC3339)
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/region"
+)
+
+const (
+	defaultRegionLimit = 20
+	maxRegionLimit     = 100
+)
+
+// ListRegions lists the regions below ?parent=, the provinces without it,
+// optionally narrowed by the ?q= prefix or substring.
+func (h *UserHandler) ListRegions(c echo.Context) error {
+	parent := c.QueryParam("parent")
+	if parent != "" {
+		if _, ok := h.Regions.Get(parent); !ok {
+			return respondError(c, http.StatusNotFound, apperrors.ErrNotFound)
+		}
+	}
+
+	limit, err := regionLimit(c)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	var regions []*region.Region
+	if q := c.QueryParam("q"); q != "" {
+		level := region.LevelProvince
+		if parent != "" {
+			p, _ := h.Regions.Get(parent)
+			level = p.Level + 1
+		}
+		regions = h.Regions.Search(q, level, parent, limit)
+	} else {
+		regions = h.Regions.Children(parent)
+		if len(regions) > limit {
+			regions = regions[:limit]
+		}
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgRegionsRetrieved, h.toRegionListResponse(regions))
+}
+
+// SearchRegions is the address autocomplete: it matches ?q= against every
+// level, or only ?level= (province, regency, district or village), within
+// ?parent= when given.
+func (h *UserHandler) SearchRegions(c echo.Context) error {
+	q := strings.TrimSpace(c.QueryParam("q"))
+	if len(q) < 2 {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+	}
+
+	var level region.Level
+	if raw := c.QueryParam("level"); raw != "" {
+		var ok bool
+		if level, ok = region.ParseLevel(raw); !ok {
+			return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+		}
+	}
+
+	limit, err := regionLimit(c)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	regions := h.Regions.Search(q, level, c.QueryParam("parent"), limit)
+	return respondSuccess(c, http.StatusOK, MsgRegionsRetrieved, h.toRegionListResponse(regions))
+}
+
+func regionLimit(c echo.Context) (int, error) {
+	raw := c.QueryParam("limit")
+	if raw == "" {
+		return defaultRegionLimit, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > maxRegionLimit {
+		return 0, apperrors.ErrInvalidQuery
+	}
+	return limit, nil
+}
+
+func (h *UserHandler) toRegionListResponse(regions []*region.Region) models.RegionListResponse {
+	res := models.RegionListResponse{
+		Version: h.Regions.Version,
+		Regions: make([]models.RegionResponse, 0, len(regions)),
+	}
+	for _, r := range regions {
+		path := h.Regions.Path(r.Code)
+		names := make([]string, 0, len(path))
+		for _, p := range path {
+			names = append(names, p.Name)
+		}
+
+		res.Regions = append(res.Regions, models.RegionResponse{
+			Code:       r.Code,
+			Name:       r.Name,
+			Level:      r.Level.String(),
+			ParentCode: r.ParentCode,
+			FullName:   strings.Join(names, ", "),
+			PostalCode: r.PostalCode,
+		})
+	}
+	return res
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+// GrantUserRole answers 200 when the role was granted and 202 with the
+// pending request when the role is privileged and waits for a second admin.
+func (h *UserHandler) GrantUserRole(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+
+	var req models.RoleGrantRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	pending, err := h.RoleAssignmentService.GrantRole(ctx, id, adminID, &req)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+	if pending != nil {
+		return respondSuccess(c, http.StatusAccepted, MsgRoleGrantRequested, toRoleGrantRequestResponse(pending))
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgRoleGranted, nil)
+}
+
+func (h *UserHandler) RevokeUserRole(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	role, err := helpers.GetFromPathParam(c, "role")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+
+	var req models.RoleRevokeRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	if err := h.RoleAssignmentService.RevokeRole(ctx, id, adminID, role, req.Reason); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgRoleRevoked, nil)
+}
+
+// ListRoleGrantRequests is the approval queue, filtered by ?status= and paged
+// with ?limit= and ?offset=.
+func (h *UserHandler) ListRoleGrantRequests(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	filter := services.RoleGrantRequestFilter{Status: c.QueryParam("status")}
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+		}
+		filter.Limit = limit
+	}
+	if raw := c.QueryParam("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+		}
+		filter.Offset = offset
+	}
+
+	requests, err := h.RoleAssignmentService.ListRequests(ctx, filter)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := make([]models.RoleGrantRequestResponse, 0, len(requests))
+	for i := range requests {
+		res = append(res, *toRoleGrantRequestResponse(&requests[i]))
+	}
+	return respondSuccess(c, http.StatusOK, MsgRoleGrantRequestsFetched, res)
+}
+
+func (h *UserHandler) ApproveRoleGrantRequest(c echo.Context) error {
+	return h.decideRoleGrantRequest(c, h.RoleAssignmentService.ApproveRequest, MsgRoleGrantApproved)
+}
+
+func (h *UserHandler) RejectRoleGrantRequest(c echo.Context) error {
+	return h.decideRoleGrantRequest(c, h.RoleAssignmentService.RejectRequest, MsgRoleGrantRejected)
+}
+
+func (h *UserHandler) decideRoleGrantRequest(c echo.Context, decide func(ctx context.Context, id, approverID uuid.UUID, notes string) (*entities.RoleGrantRequest, error), message string) error {
+	ctx := c.Request().Context()
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+	// The routes already refuse impersonation tokens; a decision made as
+	// someone else would defeat the four-eyes rule, so check again here.
+	if c.Get("actorID") != nil {
+		return respondError(c, http.StatusForbidden, apperrors.ErrSelfApproval)
+	}
+
+	var req models.RoleGrantDecisionRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	request, err := decide(ctx, id, adminID, req.Notes)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, message, toRoleGrantRequestResponse(request))
+}
+
+// ListAuditLog returns entries newest first, filtered by ?user_id=,
+// ?actor_id= and ?action=, and paged back with ?before_id= and ?limit=.
+func (h *UserHandler) ListAuditLog(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	filter := services.AuditLogFilter{Action: c.QueryParam("action")}
+	if raw := c.QueryParam("user_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+		}
+		filter.TargetUserID = id
+	}
+	if raw := c.QueryParam("actor_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+		}
+		filter.ActorID = id
+	}
+	if raw := c.QueryParam("before_id"); raw != "" {
+		beforeID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+		}
+		filter.BeforeID = beforeID
+	}
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.AuditLogService.List(ctx, filter)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := models.AuditLogResponse{Entries: make([]models.AuditLogEntryResponse, 0, len(entries))}
+	for _, entry := range entries {
+		item := models.AuditLogEntryResponse{
+			ID:        entry.ID,
+			Action:    entry.Action,
+			Details:   entry.Details,
+			CreatedAt: entry.CreatedAt.Format(time.RFC3339),
+		}
+		if entry.ActorID != nil {
+			item.ActorID = entry.ActorID.String()
+		}
+		if entry.TargetUserID != nil {
+			item.TargetUserID = entry.TargetUserID.String()
+		}
+		res.Entries = append(res.Entries, item)
+	}
+	if len(entries) > 0 {
+		res.NextBeforeID = entries[len(entries)-1].ID
+	}
+	return respondSuccess(c, http.StatusOK, MsgAuditLogRetrieved, res)
+}
+
+func toRoleGrantRequestResponse(request *entities.RoleGrantRequest) *models.RoleGrantRequestResponse {
+	res := &models.RoleGrantRequestResponse{
+		ID:            request.ID.String(),
+		UserID:        request.UserID.String(),
+		Role:          request.Role,
+		Reason:        request.Reason,
+		Status:        request.Status,
+		DecisionNotes: request.DecisionNotes,
+		CreatedAt:     request.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     request.UpdatedAt.Format(time.RFC3339),
+	}
+	if request.RequestedBy != nil {
+		res.RequestedBy = request.RequestedBy.String()
+	}
+	if request.DecidedBy != nil {
+		res.DecidedBy = request.DecidedBy.String()
+	}
+	if request.DecidedAt != nil {
+		res.DecidedAt = request.DecidedAt.Format(time.RFC3339)
+	}
+	return res
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+func (h *UserHandler) ListRoles(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	roles, err := h.RoleService.ListRoles(ctx)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := make([]models.RoleResponse, 0, len(roles))
+	for i := range roles {
+		res = append(res, *toRoleResponse(&roles[i]))
+	}
+	return respondSuccess(c, http.StatusOK, MsgRolesRetrieved, res)
+}
+
+func (h *UserHandler) GetRole(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	name, err := helpers.GetFromPathParam(c, "name")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	role, err := h.RoleService.GetRole(ctx, name)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgRoleRetrieved, toRoleResponse(role))
+}
+
+func (h *UserHandler) CreateRole(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+
+	var req models.RoleRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	role, err := h.RoleService.CreateRole(ctx, adminID, &req)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusCreated, MsgRoleCreated, toRoleResponse(role))
+}
+
+func (h *UserHandler) UpdateRole(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+
+	name, err := helpers.GetFromPathParam(c, "name")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	var req models.RoleUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	role, err := h.RoleService.UpdateRole(ctx, name, adminID, &req)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgRoleUpdated, toRoleResponse(role))
+}
+
+func (h *UserHandler) DeleteRole(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+
+	name, err := helpers.GetFromPathParam(c, "name")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.RoleService.DeleteRole(ctx, name, adminID); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgRoleDeleted, nil)
+}
+
+func (h *UserHandler) ListPermissions(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	permissions, err := h.RoleService.ListPermissions(ctx)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := make([]models.PermissionResponse, 0, len(permissions))
+	for i := range permissions {
+		res = append(res, *toPermissionResponse(&permissions[i]))
+	}
+	return respondSuccess(c, http.StatusOK, MsgPermissionsRetrieved, res)
+}
+
+func (h *UserHandler) CreatePermission(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+
+	var req models.PermissionRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	permission, err := h.RoleService.CreatePermission(ctx, adminID, &req)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusCreated, MsgPermissionCreated, toPermissionResponse(permission))
+}
+
+func (h *UserHandler) DeletePermission(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+
+	name, err := helpers.GetFromPathParam(c, "name")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.RoleService.DeletePermission(ctx, name, adminID); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgPermissionDeleted, nil)
+}
+
+// GetUserAccess lists the effective roles and permissions of a user, as they
+// will appear in the next access token issued to them.
+func (h *UserHandler) GetUserAccess(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	roles, permissions, err := h.RoleService.GetUserAccess(ctx, id)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+	if roles == nil {
+		roles = []string{}
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgUserAccessRetrieved, models.UserAccessResponse{
+		UserID:      id.String(),
+		Roles:       roles,
+		Permissions: permissions,
+	})
+}
+
+func toRoleResponse(role *entities.Role) *models.RoleResponse {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return &models.RoleResponse{
+		Name:         role.Name,
+		Description:  role.Description,
+		IsSystem:     role.IsSystem,
+		IsPrivileged: role.IsPrivileged,
+		Permissions:  permissions,
+		CreatedAt:    role.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    role.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toPermissionResponse(permission *entities.Permission) *models.PermissionResponse {
+	return &models.PermissionResponse{
+		Name:        permission.Name,
+		Description: permission.Description,
+		IsSystem:    permission.IsSystem,
+		CreatedAt:   permission.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+var sellerDocumentKinds = []string{entities.SellerDocumentKTP, entities.SellerDocumentSelfie, entities.SellerDocumentNPWP}
+
+// SubmitSellerApplication takes a multipart form with the fields of
+// SellerApplicationRequest and the documents as files.
+func (h *UserHandler) SubmitSellerApplication(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	var req models.SellerApplicationRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	var documents []services.SellerDocumentUpload
+	for _, kind := range sellerDocumentKinds {
+		header, err := c.FormFile(kind)
+		if err != nil {
+			continue
+		}
+		file, err := header.Open()
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+		}
+		defer file.Close()
+		documents = append(documents, services.SellerDocumentUpload{Kind: kind, File: file})
+	}
+
+	application, err := h.SellerApplicationService.Submit(ctx, userID, &req, documents)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusCreated, MsgSellerApplicationSubmitted, toSellerApplicationResponse(application, false))
+}
+
+func (h *UserHandler) ListMySellerApplications(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	applications, err := h.SellerApplicationService.ListMine(ctx, userID)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgSellerApplicationsRetrieved, toSellerApplicationResponses(applications))
+}
+
+// ListSellerApplications is the admin review queue, filtered by ?status= and
+// paged with ?limit= and ?offset=.
+func (h *UserHandler) ListSellerApplications(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	filter := services.SellerApplicationFilter{Status: c.QueryParam("status")}
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+		}
+		filter.Limit = limit
+	}
+	if raw := c.QueryParam("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+		}
+		filter.Offset = offset
+	}
+
+	applications, err := h.SellerApplicationService.List(ctx, filter)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgSellerApplicationsRetrieved, toSellerApplicationResponses(applications))
+}
+
+func (h *UserHandler) GetSellerApplication(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	application, err := h.SellerApplicationService.Review(ctx, id)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgSellerApplicationRetrieved, toSellerApplicationResponse(application, true))
+}
+
+// DownloadSellerDocument sends a document as an attachment, so a crafted
+// file is never rendered by the browser.
+func (h *UserHandler) DownloadSellerDocument(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	kind := c.Param("kind")
+
+	document, err := h.SellerApplicationService.GetDocument(ctx, id, kind)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+kind+`"`)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("X-Content-Type-Options", "nosniff")
+	return c.Blob(http.StatusOK, document.ContentType, document.Data)
+}
+
+func (h *UserHandler) ApproveSellerApplication(c echo.Context) error {
+	return h.decideSellerApplication(c, h.SellerApplicationService.Approve, MsgSellerApplicationApproved)
+}
+
+func (h *UserHandler) RejectSellerApplication(c echo.Context) error {
+	return h.decideSellerApplication(c, h.SellerApplicationService.Reject, MsgSellerApplicationRejected)
+}
+
+func (h *UserHandler) decideSellerApplication(c echo.Context, decide func(ctx context.Context, id, reviewerID uuid.UUID, notes string) (*entities.SellerApplication, error), message string) error {
+	ctx := c.Request().Context()
+
+	id, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+
+	var req models.SellerApplicationDecisionRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	application, err := decide(ctx, id, adminID, req.Notes)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, message, toSellerApplicationResponse(application, true))
+}
+
+func toSellerApplicationResponses(applications []entities.SellerApplication) []models.SellerApplicationResponse {
+	res := make([]models.SellerApplicationResponse, 0, len(applications))
+	for i := range applications {
+		res = append(res, *toSellerApplicationResponse(&applications[i], false))
+	}
+	return res
+}
+
+// toSellerApplicationResponse adds download links to the documents for
+// reviewers.
+func toSellerApplicationResponse(application *entities.SellerApplication, reviewer bool) *models.SellerApplicationResponse {
+	res := &models.SellerApplicationResponse{
+		ID:          application.ID.String(),
+		UserID:      application.UserID.String(),
+		ShopName:    application.ShopName,
+		NIK:         application.NIK,
+		NPWP:        application.NPWP,
+		Status:      application.Status,
+		ReviewNotes: application.ReviewNotes,
+		CreatedAt:   application.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   application.UpdatedAt.Format(time.RFC3339),
+	}
+	if reviewer && application.ReviewedBy != nil {
+		res.ReviewedBy = application.ReviewedBy.String()
+	}
+	if application.ReviewedAt != nil {
+		res.ReviewedAt = application.ReviewedAt.Format(time.RFC3339)
+	}
+	for _, document := range application.Documents {
+		doc := models.SellerDocumentResponse{
+			Kind:        document.Kind,
+			ContentType: document.ContentType,
+			SizeBytes:   document.Size,
+		}
+		if reviewer {
+			doc.URL = "/api/accounts/seller-applications/" + application.ID.String() + "/documents/" + document.Kind
+		}
+		res.Documents = append(res.Documents, doc)
+	}
+	return res
+}
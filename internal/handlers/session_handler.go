@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+func (h *UserHandler) ListSessions(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	sessions, err := h.SessionService.ListSessions(ctx, userID)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgSessionsRetrieved, toSessionResponses(sessions, extractSessionID(c)))
+}
+
+func (h *UserHandler) RevokeSession(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	sessionID, err := helpers.GetFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.SessionService.RevokeUserSession(ctx, userID, sessionID); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgSessionRevoked, nil)
+}
+
+// RevokeAllSessions logs the user out of every other device and keeps the
+// session making the request alive.
+func (h *UserHandler) RevokeAllSessions(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	if err := h.SessionService.RevokeAllSessions(ctx, userID, extractSessionID(c)); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgSessionsRevoked, nil)
+}
+
+func (h *UserHandler) LogoutEverywhere(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	if err := h.SessionService.LogoutEverywhere(ctx, userID); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgLogout, nil)
+}
+
+func (h *UserHandler) ListUserSessions(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	sessions, err := h.SessionService.ListSessions(ctx, userID)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgSessionsRetrieved, toSessionResponses(sessions, ""))
+}
+
+func (h *UserHandler) RevokeUserSession(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	sessionID, err := helpers.GetFromPathParam(c, "sessionId")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.SessionService.RevokeUserSession(ctx, userID, sessionID); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgSessionRevoked, nil)
+}
+
+// RevokeAllUserSessions is the admin force-logout.
+func (h *UserHandler) RevokeAllUserSessions(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	if err := h.SessionService.LogoutEverywhere(ctx, userID); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgSessionsRevoked, nil)
+}
+
+func toSessionResponses(sessions []entities.Session, currentSessionID string) []models.SessionResponse {
+	res := make([]models.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, models.SessionResponse{
+			ID:         session.ID,
+			ClientID:   session.ClientID,
+			Device:     session.Device,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			Current:    session.ID == currentSessionID,
+			CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+			LastUsedAt: session.LastUsedAt.Format(time.RFC3339),
+		})
+	}
+	return res
+}
@@ -1,17 +1,20 @@
 package handlers
 
 import (
-	"fmt"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/messaging/rabbitmq"
 	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/region"
 
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
@@ -21,30 +24,86 @@ import (
 )
 
 type UserHandler struct {
-	UserRepo         repositories.UserRepository
-	UserService      services.UserService
-	TokenService     token.TokenService
-	JWTBlacklistRepo repositories.JWTBlacklistRepository
-	RefreshTokenRepo repositories.RefreshTokenRepository
-	EventPublisher   *rabbitmq.EventPublisher
-	log              *logrus.Logger
+	UserRepo                 repositories.UserRepository
+	UserService              services.UserService
+	SessionService           services.SessionService
+	OIDCService              services.OIDCService
+	MFAService               services.MFAService
+	WebAuthnService          services.WebAuthnService
+	EmailVerifier            services.EmailVerificationService
+	PhoneService             services.PhoneService
+	AvatarService            services.AvatarService
+	PreferencesService       services.PreferencesService
+	PasswordService          services.PasswordService
+	StatusService            services.UserStatusService
+	DeletionService          services.AccountDeletionService
+	ExportService            services.DataExportService
+	AddressService           services.AddressService
+	SellerApplicationService services.SellerApplicationService
+	RoleService              services.RoleService
+	RoleAssignmentService    services.RoleAssignmentService
+	AuditLogService          services.AuditLogService
+	ImpersonationService     services.ImpersonationService
+	Regions                  *region.Dataset
+	TokenService             token.TokenService
+	JWTBlacklistRepo         repositories.JWTBlacklistRepository
+	EventPublisher           *rabbitmq.EventPublisher
+	log                      *logrus.Logger
 }
 
 func NewHandler(
 	userRepo repositories.UserRepository,
 	userService services.UserService,
+	sessionService services.SessionService,
+	oidcService services.OIDCService,
+	mfaService services.MFAService,
+	webAuthnService services.WebAuthnService,
+	emailVerifier services.EmailVerificationService,
+	phoneService services.PhoneService,
+	avatarService services.AvatarService,
+	preferencesService services.PreferencesService,
+	passwordService services.PasswordService,
+	statusService services.UserStatusService,
+	deletionService services.AccountDeletionService,
+	exportService services.DataExportService,
+	addressService services.AddressService,
+	sellerApplicationService services.SellerApplicationService,
+	roleService services.RoleService,
+	roleAssignmentService services.RoleAssignmentService,
+	auditLogService services.AuditLogService,
+	impersonationService services.ImpersonationService,
+	regions *region.Dataset,
 	tokenService token.TokenService,
 	jwtBlacklistRepo repositories.JWTBlacklistRepository,
-	refreshTokenRepo repositories.RefreshTokenRepository,
+	eventPublisher *rabbitmq.EventPublisher,
 	log *logrus.Logger,
 ) *UserHandler {
 	return &UserHandler{
-		UserRepo:         userRepo,
-		UserService:      userService,
-		TokenService:     tokenService,
-		JWTBlacklistRepo: jwtBlacklistRepo,
-		RefreshTokenRepo: refreshTokenRepo,
-		log:              log,
+		UserRepo:                 userRepo,
+		UserService:              userService,
+		SessionService:           sessionService,
+		OIDCService:              oidcService,
+		MFAService:               mfaService,
+		WebAuthnService:          webAuthnService,
+		EmailVerifier:            emailVerifier,
+		PhoneService:             phoneService,
+		AvatarService:            avatarService,
+		PreferencesService:       preferencesService,
+		PasswordService:          passwordService,
+		StatusService:            statusService,
+		DeletionService:          deletionService,
+		ExportService:            exportService,
+		AddressService:           addressService,
+		SellerApplicationService: sellerApplicationService,
+		RoleService:              roleService,
+		RoleAssignmentService:    roleAssignmentService,
+		AuditLogService:          auditLogService,
+		ImpersonationService:     impersonationService,
+		Regions:                  regions,
+		TokenService:             tokenService,
+		JWTBlacklistRepo:         jwtBlacklistRepo,
+		EventPublisher:           eventPublisher,
+		log:                      log,
 	}
 }
 
@@ -71,46 +130,34 @@ func (h *UserHandler) Login(c echo.Context) error {
 		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
 	}
 
-	// Extract activity metadata from HTTP request
-	metadata := &services.ActivityMetadata{
-		SessionID: c.Request().Header.Get("X-Session-Id"),
-		IPAddress: c.RealIP(),
-		UserAgent: c.Request().UserAgent(),
-	}
-	// Fallback to request ID if no session ID
-	if metadata.SessionID == "" {
-		metadata.SessionID = c.Request().Header.Get("X-Request-Id")
-	}
+	metadata := activityMetadata(c)
 
 	userSvc, err := h.UserService.Login(ctx, &req, metadata)
 	if err != nil {
 		return h.handleServiceError(c, err)
 	}
 
-	// 15 minutes access token
-	accessToken, err := h.TokenService.GenerateAccessToken(ctx, userSvc)
+	challenge, err := h.MFAService.BeginLogin(ctx, userSvc)
 	if err != nil {
-		h.log.WithError(err).Error("Failed to generate Access Token")
-		return respondError(c, http.StatusInternalServerError, apperrors.ErrFailedToGenerateToken)
+		return h.handleServiceError(c, err)
 	}
-
-	// 7 days refresh token
-	refreshToken, err := h.TokenService.GenerateRefreshToken(ctx)
-	if err != nil {
-		h.log.WithError(err).Error("Failed to generate Refresh Token")
-		return respondError(c, http.StatusInternalServerError, apperrors.ErrFailedToGenerateToken)
+	if challenge != nil {
+		return respondSuccess(c, http.StatusOK, MsgMFARequired, models.MFAChallengeResponse{
+			MFARequired:        true,
+			MFAToken:           challenge.Token,
+			EnrollmentRequired: challenge.EnrollmentRequired,
+			ExpiresAt:          challenge.ExpiresAt.Format(time.RFC3339),
+		})
 	}
 
-	// Store Refresh Token in Redis (e.g. 7 days)
-	err = h.RefreshTokenRepo.StoreRefreshToken(ctx, userSvc.ID.String(), refreshToken, 7*24*time.Hour)
+	tokens, err := h.SessionService.CreateSession(ctx, userSvc, metadata, services.SessionOptions{})
 	if err != nil {
-		h.log.WithError(err).Error("Failed to store Refresh Token")
-		return respondError(c, http.StatusInternalServerError, fmt.Errorf("failed to create session"))
+		return h.handleServiceError(c, err)
 	}
 
 	res := toUserResponse(userSvc)
-	res.Token = accessToken
-	res.RefreshToken = refreshToken
+	res.Token = tokens.AccessToken
+	res.RefreshToken = tokens.RefreshToken
 
 	return respondSuccess(c, http.StatusOK, MsgLogin, res)
 }
@@ -123,42 +170,14 @@ func (h *UserHandler) RefreshSession(c echo.Context) error {
 		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
 	}
 
-	userIDStr, err := h.RefreshTokenRepo.ValidateRefreshToken(ctx, req.RefreshToken)
-	if err != nil || userIDStr == "" {
-		h.log.Warnf("Invalid or expired refresh token attempt: %v", err)
-		return respondError(c, http.StatusUnauthorized, fmt.Errorf("invalid or expired refresh token"))
-	}
-
-	userID, err := uuid.Parse(userIDStr)
+	tokens, err := h.SessionService.RefreshSession(ctx, req.RefreshToken, "", activityMetadata(c))
 	if err != nil {
-		h.log.Errorf("Invalid user ID format in session: %v", err)
-		return respondError(c, http.StatusInternalServerError, fmt.Errorf("internal session error"))
-	}
-
-	userSvc, err := h.UserService.GetUserByID(ctx, userID)
-	if err != nil {
-		return respondError(c, http.StatusUnauthorized, fmt.Errorf("user not found"))
-	}
-
-	// Generate NEW Access Token
-	newAccessToken, err := h.TokenService.GenerateAccessToken(ctx, userSvc)
-	if err != nil {
-		h.log.WithError(err).Error("Failed to generate Access Token during refresh")
-		return respondError(c, http.StatusInternalServerError, apperrors.ErrFailedToGenerateToken)
+		return h.handleServiceError(c, err)
 	}
 
-	// Generate NEW Refresh Token
-	newRefreshToken, _ := h.TokenService.GenerateRefreshToken(ctx)
-
-	// Revoke old Refresh Token
-	h.RefreshTokenRepo.RevokeRefreshToken(ctx, req.RefreshToken)
-
-	// Store new Refresh Token
-	h.RefreshTokenRepo.StoreRefreshToken(ctx, userIDStr, newRefreshToken, 7*24*time.Hour)
-
-	return respondSuccess(c, http.StatusOK, "Session refreshed", map[string]string{
-		"token":         newAccessToken,
-		"refresh_token": newRefreshToken,
+	return respondSuccess(c, http.StatusOK, MsgSessionRefreshed, map[string]string{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
 	})
 }
 
@@ -170,17 +189,19 @@ func (h *UserHandler) Logout(c echo.Context) error {
 		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
 	}
 
-	// Optional: Revoke Refresh Token if provided in body?
-	// Since we can't extract Refresh Token from Access Token easily without DB lookup,
-	// Client *should* send the refresh token to revoke it.
-	// But standard logout often just kills access token.
-	// If the client deletes the refresh token on their side, it's effectively gone.
-	// Server-side revocation requires the client to send the refresh token to be blacklisted.
-
-	// Let's check if client sent Refresh Token in body for revocation
-	var req models.RefreshTokenRequest
-	if err := c.Bind(&req); err == nil && req.RefreshToken != "" {
-		_ = h.RefreshTokenRepo.RevokeRefreshToken(ctx, req.RefreshToken)
+	// Tokens carry their session id, so the whole session (refresh token family
+	// included) can be ended without the client sending the refresh token.
+	// Older tokens without a sid fall back to the refresh token in the body.
+	userID, _ := extractUserID(c)
+	if sessionID := extractSessionID(c); sessionID != "" {
+		if err := h.SessionService.RevokeUserSession(ctx, userID, sessionID); err != nil && !errors.Is(err, apperrors.ErrSessionNotFound) {
+			return h.handleServiceError(c, err)
+		}
+	} else {
+		var req models.RefreshTokenRequest
+		if err := c.Bind(&req); err == nil && req.RefreshToken != "" {
+			_ = h.SessionService.RevokeSession(ctx, req.RefreshToken)
+		}
 	}
 
 	if err := h.UserService.Logout(ctx, authHeader); err != nil {
@@ -190,15 +211,53 @@ func (h *UserHandler) Logout(c echo.Context) error {
 	return respondSuccess(c, http.StatusOK, MsgLogout, nil)
 }
 
-func (h *UserHandler) GetAllUsers(c echo.Context) error {
+// ListUsers is the admin user directory. See UserListFilter for the query
+// parameters.
+func (h *UserHandler) ListUsers(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	res, err := h.UserService.GetAllUsers(ctx)
+	filter := services.UserListFilter{
+		Role:        c.QueryParam("role"),
+		Status:      c.QueryParam("status"),
+		EmailDomain: c.QueryParam("email_domain"),
+		Search:      c.QueryParam("q"),
+		SortBy:      c.QueryParam("sort"),
+		Cursor:      c.QueryParam("cursor"),
+	}
+
+	switch c.QueryParam("order") {
+	case "", "desc":
+		filter.Descending = true
+	case "asc":
+	default:
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+	}
+
+	if raw := c.QueryParam("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+		}
+		filter.Limit = limit
+	}
+
+	var err error
+	if filter.CreatedAfter, err = timeQueryParam(c, "created_after"); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+	}
+	if filter.CreatedBefore, err = timeQueryParam(c, "created_before"); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidQuery)
+	}
+
+	page, err := h.UserService.ListUsers(ctx, filter)
 	if err != nil {
 		return h.handleServiceError(c, err)
 	}
 
-	return respondSuccess(c, http.StatusOK, MsgUsersRetrieved, toUserResponses(res))
+	return respondSuccess(c, http.StatusOK, MsgUsersRetrieved, models.UserListResponse{
+		Users:      toUserResponses(page.Users),
+		NextCursor: page.NextCursor,
+	})
 }
 
 func (h *UserHandler) GetUserByID(c echo.Context) error {
@@ -230,6 +289,7 @@ func (h *UserHandler) GetUserProfile(c echo.Context) error {
 		return h.handleServiceError(c, err)
 	}
 
+	c.Response().Header().Set("ETag", userETag(res))
 	return respondSuccess(c, http.StatusOK, MsgUserRetrieved, toUserResponse(res))
 }
 
@@ -251,6 +311,46 @@ func (h *UserHandler) UpdateUser(c echo.Context) error {
 		return h.handleServiceError(c, err)
 	}
 
+	c.Response().Header().Set("ETag", userETag(res))
+	return respondSuccess(c, http.StatusOK, MsgUserUpdated, toUserResponse(res))
+}
+
+// PatchUser takes a JSON Merge Patch of the profile. The If-Match header must
+// carry the ETag from the last read ("*" skips the check).
+func (h *UserHandler) PatchUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	ifMatch := c.Request().Header.Get("If-Match")
+	if ifMatch == "" {
+		return h.handleServiceError(c, apperrors.ErrPreconditionRequired)
+	}
+	var ifUpdatedAt *time.Time
+	if ifMatch != "*" {
+		updatedAt, ok := parseUserETag(ifMatch)
+		if !ok {
+			return h.handleServiceError(c, apperrors.ErrPreconditionFailed)
+		}
+		ifUpdatedAt = &updatedAt
+	}
+
+	var req models.UserPatchRequest
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	res, err := h.UserService.PatchUser(ctx, id, &req, ifUpdatedAt)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	c.Response().Header().Set("ETag", userETag(res))
 	return respondSuccess(c, http.StatusOK, MsgUserUpdated, toUserResponse(res))
 }
 
@@ -270,19 +370,152 @@ func (h *UserHandler) DeleteUser(c echo.Context) error {
 	return respondSuccess(c, http.StatusOK, MsgUserDeleted, toUserResponse(res))
 }
 
+// DeleteAccount schedules the deletion of the caller's own account. It is
+// confirmed with the password and can be taken back by logging in again.
+func (h *UserHandler) DeleteAccount(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	var req models.DeleteAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	scheduledFor, err := h.DeletionService.ScheduleDeletion(ctx, userID, &req)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusAccepted, MsgAccountDeletionScheduled, models.AccountDeletionResponse{
+		ScheduledFor: scheduledFor.Format(time.RFC3339),
+	})
+}
+
+// RestoreUser is the admin undo of a deletion, until the account is purged.
+func (h *UserHandler) RestoreUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+
+	var req models.RestoreAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	res, err := h.DeletionService.Restore(ctx, adminID, userID, &req)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgUserRestored, toUserResponse(res))
+}
+
+// ChangeUserStatus is the admin move of an account to another lifecycle state.
+func (h *UserHandler) ChangeUserStatus(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := helpers.GetIDFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+
+	adminID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, err)
+	}
+
+	var req models.UserStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	res, err := h.StatusService.ChangeStatus(ctx, userID, services.StatusChange{
+		Status:         req.Status,
+		Reason:         req.Reason,
+		SuspendedUntil: req.SuspendedUntil,
+		ChangedBy:      adminID,
+	})
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgUserStatusChanged, toUserResponse(res))
+}
+
 // ------- HELPERS -------
 func toUserResponse(user *entities.User) *models.UserResponse {
-	return &models.UserResponse{
-		Id:          user.ID,
-		Name:        user.Name,
-		Username:    user.Username,
-		Email:       user.Email,
-		Role:        user.Role,
-		Address:     user.Address,
-		PhoneNumber: user.PhoneNumber,
-		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   user.UpdatedAt.Format(time.RFC3339),
+	res := &models.UserResponse{
+		Id:            user.ID,
+		Name:          user.Name,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Role:          user.Role,
+		Address:       user.Address,
+		PhoneNumber:   user.PhoneNumber,
+		PhoneVerified: user.PhoneVerifiedAt != nil,
+		AvatarURL:     user.AvatarURL,
+		CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     user.UpdatedAt.Format(time.RFC3339),
+		DeletedAt:     formatDeletedAt(user),
+		Status:        user.Status,
+		StatusReason:  user.StatusReason,
+	}
+	if user.SuspendedUntil != nil {
+		res.SuspendedUntil = user.SuspendedUntil.Format(time.RFC3339)
+	}
+	if user.DeletionScheduledAt != nil {
+		res.DeletionScheduledAt = user.DeletionScheduledAt.Format(time.RFC3339)
+	}
+	return res
+}
+
+// timeQueryParam parses an optional RFC 3339 query parameter.
+func timeQueryParam(c echo.Context, name string) (*time.Time, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func formatDeletedAt(user *entities.User) string {
+	if !user.DeletedAt.Valid {
+		return ""
+	}
+	return user.DeletedAt.Time.Format(time.RFC3339)
+}
+
+// userETag is the version of the profile: updated_at in microseconds, the
+// precision Postgres stores.
+func userETag(user *entities.User) string {
+	return `"` + strconv.FormatInt(user.UpdatedAt.UnixMicro(), 10) + `"`
+}
+
+func parseUserETag(etag string) (time.Time, bool) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	micros, err := strconv.ParseInt(strings.Trim(etag, `"`), 10, 64)
+	if err != nil {
+		return time.Time{}, false
 	}
+	return time.UnixMicro(micros), true
 }
 
 func toUserResponses(users []entities.User) []models.UserResponse {
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+func (h *UserHandler) BeginPasskeyRegistration(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	challenge, err := h.WebAuthnService.BeginRegistration(ctx, user)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgPasskeyCeremonyStarted, toWebAuthnBeginResponse(challenge))
+}
+
+func (h *UserHandler) FinishPasskeyRegistration(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user, err := h.currentUser(c)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	var req models.WebAuthnFinishRequest
+	if err := c.Bind(&req); err != nil || len(req.Credential) == 0 {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	cred, err := h.WebAuthnService.FinishRegistration(ctx, user, req.CeremonyID, req.Name, req.Credential)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusCreated, MsgPasskeyRegistered, toWebAuthnCredentialResponse(cred))
+}
+
+func (h *UserHandler) BeginPasskeyLogin(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	challenge, err := h.WebAuthnService.BeginLogin(ctx)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgPasskeyCeremonyStarted, toWebAuthnBeginResponse(challenge))
+}
+
+// FinishPasskeyLogin answers exactly like Login. The passkey is verified with
+// user verification, so there is no separate MFA step.
+func (h *UserHandler) FinishPasskeyLogin(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.WebAuthnFinishRequest
+	if err := c.Bind(&req); err != nil || len(req.Credential) == 0 {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	user, err := h.WebAuthnService.FinishLogin(ctx, req.CeremonyID, req.Credential)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	tokens, err := h.SessionService.CreateSession(ctx, user, activityMetadata(c), services.SessionOptions{})
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := toUserResponse(user)
+	res.Token = tokens.AccessToken
+	res.RefreshToken = tokens.RefreshToken
+
+	return respondSuccess(c, http.StatusOK, MsgLogin, res)
+}
+
+func (h *UserHandler) ListPasskeys(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	creds, err := h.WebAuthnService.ListCredentials(ctx, userID)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	res := make([]models.WebAuthnCredentialResponse, 0, len(creds))
+	for i := range creds {
+		res = append(res, toWebAuthnCredentialResponse(&creds[i]))
+	}
+	return respondSuccess(c, http.StatusOK, MsgPasskeysRetrieved, res)
+}
+
+func (h *UserHandler) DeletePasskey(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := extractUserID(c)
+	if err != nil {
+		return respondError(c, http.StatusUnauthorized, apperrors.ErrInvalidUserSession)
+	}
+
+	id, err := helpers.GetFromPathParam(c, "id")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	credentialID, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, apperrors.ErrInvalidRequestPayload)
+	}
+
+	if err := h.WebAuthnService.DeleteCredential(ctx, userID, credentialID); err != nil {
+		return h.handleServiceError(c, err)
+	}
+
+	return respondSuccess(c, http.StatusOK, MsgPasskeyDeleted, nil)
+}
+
+func toWebAuthnBeginResponse(challenge *services.WebAuthnChallenge) models.WebAuthnBeginResponse {
+	return models.WebAuthnBeginResponse{
+		CeremonyID: challenge.CeremonyID,
+		Options:    challenge.Options,
+	}
+}
+
+func toWebAuthnCredentialResponse(cred *entities.WebAuthnCredential) models.WebAuthnCredentialResponse {
+	res := models.WebAuthnCredentialResponse{
+		ID:         base64.RawURLEncoding.EncodeToString(cred.ID),
+		Name:       cred.Name,
+		Transports: cred.Transports,
+		Synced:     cred.Synced,
+		CreatedAt:  cred.CreatedAt.Format(time.RFC3339),
+	}
+	if res.Transports == nil {
+		res.Transports = []string{}
+	}
+	if cred.LastUsedAt != nil {
+		res.LastUsedAt = cred.LastUsedAt.Format(time.RFC3339)
+	}
+	return res
+}
@@ -7,4 +7,120 @@ type UserRegisteredEvent struct {
 	Email     string    `json:"email"`
 	Username  string    `json:"username"`
 	CreatedAt time.Time `json:"created_at"`
+	// VerificationURL is the single-use link that confirms the email. It is
+	// empty if the link could not be created; the user can ask for a new one.
+	VerificationURL string `json:"verification_url,omitempty"`
+}
+
+// EmailVerificationRequestedEvent is published when a new verification link is
+// issued outside registration: on resend and after an email change.
+type EmailVerificationRequestedEvent struct {
+	UserID          string    `json:"user_id"`
+	Email           string    `json:"email"`
+	Username        string    `json:"username"`
+	VerificationURL string    `json:"verification_url"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+// PasswordResetRequestedEvent carries a single-use reset link. It is only
+// published for emails that belong to an account.
+type PasswordResetRequestedEvent struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	ResetURL  string    `json:"reset_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PasswordChangedEvent is published after a password is set, so the owner
+// hears about changes they did not make.
+type PasswordChangedEvent struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// UserStatusChangedEvent is published on every account lifecycle transition.
+// ChangedBy is empty when the system made the change, e.g. on email
+// verification.
+type UserStatusChangedEvent struct {
+	UserID         string     `json:"user_id"`
+	From           string     `json:"from"`
+	To             string     `json:"to"`
+	Reason         string     `json:"reason"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	ChangedBy      string     `json:"changed_by,omitempty"`
+	ChangedAt      time.Time  `json:"changed_at"`
+}
+
+// AccountDeletionScheduledEvent is published when a user asks to delete their
+// account. Logging in before ScheduledFor cancels the deletion.
+type AccountDeletionScheduledEvent struct {
+	UserID       string    `json:"user_id"`
+	Email        string    `json:"email"`
+	Username     string    `json:"username"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
+
+// UserPurgedEvent is published when a deleted account is removed for good, so
+// other services can drop or anonymize what they keep about the user.
+type UserPurgedEvent struct {
+	UserID   string    `json:"user_id"`
+	PurgedAt time.Time `json:"purged_at"`
+}
+
+// DataExportRequestedEvent queues a personal data export for the export worker.
+type DataExportRequestedEvent struct {
+	ExportID string `json:"export_id"`
+	UserID   string `json:"user_id"`
+}
+
+// DataExportReadyEvent is published when an export archive can be downloaded.
+type DataExportReadyEvent struct {
+	ExportID    string    `json:"export_id"`
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email"`
+	Username    string    `json:"username"`
+	DownloadURL string    `json:"download_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// RefreshTokenReusedEvent is published when an already rotated refresh token is
+// presented again. The whole token family is revoked when this happens.
+type RefreshTokenReusedEvent struct {
+	UserID     string    `json:"user_id"`
+	FamilyID   string    `json:"family_id"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// NotificationEmailRequestedEvent asks the email worker to send a
+// non-transactional email, published on user.notification_email_requested.
+// The worker only sends it when the user opted in to Category, one of the
+// entities.EmailCategory values, and looks up the address itself.
+type NotificationEmailRequestedEvent struct {
+	UserID   string `json:"user_id"`
+	Category string `json:"category"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body"`
+}
+
+// SellerApplicationDecidedEvent is published when an admin approves or
+// rejects a seller application, on user.seller_application_approved or
+// user.seller_application_rejected. An approved applicant has the seller role
+// by the time it is published.
+type SellerApplicationDecidedEvent struct {
+	ApplicationID string    `json:"application_id"`
+	UserID        string    `json:"user_id"`
+	Email         string    `json:"email"`
+	Username      string    `json:"username"`
+	ShopName      string    `json:"shop_name"`
+	Status        string    `json:"status"`
+	Notes         string    `json:"notes,omitempty"`
+	ReviewedBy    string    `json:"reviewed_by"`
+	ReviewedAt    time.Time `json:"reviewed_at"`
 }
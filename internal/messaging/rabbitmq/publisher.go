@@ -35,3 +35,174 @@ func (p *EventPublisher) PublishUserRegistered(ctx context.Context, event UserRe
 	p.log.Debugf("Published user.registered event for user: %s", event.UserID)
 	return nil
 }
+
+// publish security event refresh token reused
+func (p *EventPublisher) PublishRefreshTokenReused(ctx context.Context, event RefreshTokenReusedEvent) error {
+	opts := rabbitmq.PublishOptions{
+		Exchange:   "user.events",
+		RoutingKey: "user.security.refresh_token_reused",
+		Mandatory:  false,
+		Immediate:  false,
+	}
+	err := p.rabbitmq.Publish(ctx, opts, event)
+	if err != nil {
+		p.log.Errorf("Failed to publish user.security.refresh_token_reused event: %v", err)
+		return err
+	}
+	p.log.Debugf("Published user.security.refresh_token_reused event for user: %s", event.UserID)
+	return nil
+}
+
+// publish event email verification requested
+func (p *EventPublisher) PublishEmailVerificationRequested(ctx context.Context, event EmailVerificationRequestedEvent) error {
+	opts := rabbitmq.PublishOptions{
+		Exchange:   "user.events",
+		RoutingKey: "user.email_verification_requested",
+		Mandatory:  false,
+		Immediate:  false,
+	}
+	err := p.rabbitmq.Publish(ctx, opts, event)
+	if err != nil {
+		p.log.Errorf("Failed to publish user.email_verification_requested event: %v", err)
+		return err
+	}
+	p.log.Debugf("Published user.email_verification_requested event for user: %s", event.UserID)
+	return nil
+}
+
+// publish event password reset requested
+func (p *EventPublisher) PublishPasswordResetRequested(ctx context.Context, event PasswordResetRequestedEvent) error {
+	opts := rabbitmq.PublishOptions{
+		Exchange:   "user.events",
+		RoutingKey: "user.password_reset_requested",
+		Mandatory:  false,
+		Immediate:  false,
+	}
+	err := p.rabbitmq.Publish(ctx, opts, event)
+	if err != nil {
+		p.log.Errorf("Failed to publish user.password_reset_requested event: %v", err)
+		return err
+	}
+	p.log.Debugf("Published user.password_reset_requested event for user: %s", event.UserID)
+	return nil
+}
+
+// publish event password changed
+func (p *EventPublisher) PublishPasswordChanged(ctx context.Context, event PasswordChangedEvent) error {
+	opts := rabbitmq.PublishOptions{
+		Exchange:   "user.events",
+		RoutingKey: "user.password_changed",
+		Mandatory:  false,
+		Immediate:  false,
+	}
+	err := p.rabbitmq.Publish(ctx, opts, event)
+	if err != nil {
+		p.log.Errorf("Failed to publish user.password_changed event: %v", err)
+		return err
+	}
+	p.log.Debugf("Published user.password_changed event for user: %s", event.UserID)
+	return nil
+}
+
+// publish event user status changed
+func (p *EventPublisher) PublishUserStatusChanged(ctx context.Context, event UserStatusChangedEvent) error {
+	opts := rabbitmq.PublishOptions{
+		Exchange:   "user.events",
+		RoutingKey: "user.status_changed",
+		Mandatory:  false,
+		Immediate:  false,
+	}
+	err := p.rabbitmq.Publish(ctx, opts, event)
+	if err != nil {
+		p.log.Errorf("Failed to publish user.status_changed event: %v", err)
+		return err
+	}
+	p.log.Debugf("Published user.status_changed event for user: %s", event.UserID)
+	return nil
+}
+
+// publish event account deletion scheduled
+func (p *EventPublisher) PublishAccountDeletionScheduled(ctx context.Context, event AccountDeletionScheduledEvent) error {
+	opts := rabbitmq.PublishOptions{
+		Exchange:   "user.events",
+		RoutingKey: "user.deletion_scheduled",
+		Mandatory:  false,
+		Immediate:  false,
+	}
+	err := p.rabbitmq.Publish(ctx, opts, event)
+	if err != nil {
+		p.log.Errorf("Failed to publish user.deletion_scheduled event: %v", err)
+		return err
+	}
+	p.log.Debugf("Published user.deletion_scheduled event for user: %s", event.UserID)
+	return nil
+}
+
+// publish event user purged
+func (p *EventPublisher) PublishUserPurged(ctx context.Context, event UserPurgedEvent) error {
+	opts := rabbitmq.PublishOptions{
+		Exchange:   "user.events",
+		RoutingKey: "user.purged",
+		Mandatory:  false,
+		Immediate:  false,
+	}
+	err := p.rabbitmq.Publish(ctx, opts, event)
+	if err != nil {
+		p.log.Errorf("Failed to publish user.purged event: %v", err)
+		return err
+	}
+	p.log.Debugf("Published user.purged event for user: %s", event.UserID)
+	return nil
+}
+
+// publish event data export requested
+func (p *EventPublisher) PublishDataExportRequested(ctx context.Context, event DataExportRequestedEvent) error {
+	opts := rabbitmq.PublishOptions{
+		Exchange:   "user.events",
+		RoutingKey: "user.data_export_requested",
+		Mandatory:  false,
+		Immediate:  false,
+	}
+	err := p.rabbitmq.Publish(ctx, opts, event)
+	if err != nil {
+		p.log.Errorf("Failed to publish user.data_export_requested event: %v", err)
+		return err
+	}
+	p.log.Debugf("Published user.data_export_requested event for user: %s", event.UserID)
+	return nil
+}
+
+// publish event data export ready
+func (p *EventPublisher) PublishDataExportReady(ctx context.Context, event DataExportReadyEvent) error {
+	opts := rabbitmq.PublishOptions{
+		Exchange:   "user.events",
+		RoutingKey: "user.data_export_ready",
+		Mandatory:  false,
+		Immediate:  false,
+	}
+	err := p.rabbitmq.Publish(ctx, opts, event)
+	if err != nil {
+		p.log.Errorf("Failed to publish user.data_export_ready event: %v", err)
+		return err
+	}
+	p.log.Debugf("Published user.data_export_ready event for user: %s", event.UserID)
+	return nil
+}
+
+// publish event seller application decided, routed by the decision
+func (p *EventPublisher) PublishSellerApplicationDecided(ctx context.Context, event SellerApplicationDecidedEvent) error {
+	routingKey := "user.seller_application_" + event.Status
+	opts := rabbitmq.PublishOptions{
+		Exchange:   "user.events",
+		RoutingKey: routingKey,
+		Mandatory:  false,
+		Immediate:  false,
+	}
+	err := p.rabbitmq.Publish(ctx, opts, event)
+	if err != nil {
+		p.log.Errorf("Failed to publish %s event: %v", routingKey, err)
+		return err
+	}
+	p.log.Debugf("Published %s event for user: %s", routingKey, event.UserID)
+	return nil
+}
@@ -13,6 +13,9 @@ import (
 
 type AuthMiddlewareOptions struct {
 	TokenService token.TokenService
+	// AllowScopedTokens lets through access tokens issued to OAuth clients.
+	// Only endpoints meant for third-party apps (e.g. userinfo) should set it.
+	AllowScopedTokens bool
 }
 
 func AuthMiddleware(opts AuthMiddlewareOptions) echo.MiddlewareFunc {
@@ -24,18 +27,29 @@ func AuthMiddleware(opts AuthMiddlewareOptions) echo.MiddlewareFunc {
 			}
 			token := authHeader[7:]
 
-			isValid, userID, username, userRole, errMsg, err := opts.TokenService.ValidateToken(context.Background(), token)
+			claims, errMsg, err := opts.TokenService.ValidateToken(context.Background(), token)
 			if err != nil {
 				return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Server error while validating token"})
 			}
 
-			if !isValid {
+			if claims == nil {
 				return c.JSON(http.StatusUnauthorized, map[string]string{"message": "Invalid token: " + errMsg})
 			}
 
-			c.Set("userID", userID)
-			c.Set("username", username)
-			c.Set("role", userRole)
+			if claims.Scope != "" && !opts.AllowScopedTokens {
+				return c.JSON(http.StatusForbidden, map[string]string{"message": "Token issued to an OAuth client cannot access this endpoint"})
+			}
+
+			c.Set("userID", claims.UserID)
+			c.Set("username", claims.Username)
+			c.Set("role", claims.Role)
+			c.Set("sessionID", claims.SessionID)
+			c.Set("scope", claims.Scope)
+			c.Set("roles", claims.Roles)
+			c.Set("permissions", claims.Permissions)
+			if claims.Act != nil {
+				c.Set("actorID", claims.Act.UserID)
+			}
 
 			return next(c)
 		}
@@ -63,3 +77,24 @@ func RequireRoles(allowedRoles ...string) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// RequirePermissions lets the request through when the access token carries
+// every one of the given permissions. Tokens issued to OAuth clients carry
+// none.
+func RequirePermissions(required ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Get("userID") == nil {
+				return c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "Unauthorized"})
+			}
+
+			granted, _ := c.Get("permissions").([]string)
+
+			if !token.HasPermissions(granted, required...) {
+				return c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Access denied"})
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+// AuditImpersonation writes every request made with an impersonation token to
+// the audit log before it runs. A request that cannot be recorded is refused.
+// It has to run after AuthMiddleware.
+func AuditImpersonation(auditLog services.AuditLogService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			actorID, ok := c.Get("actorID").(uuid.UUID)
+			if !ok {
+				return next(c)
+			}
+			userID, _ := c.Get("userID").(uuid.UUID)
+
+			err := auditLog.Record(c.Request().Context(), actorID, userID, entities.AuditImpersonatedRequest, map[string]any{
+				"method":     c.Request().Method,
+				"path":       c.Request().URL.Path,
+				"query":      c.Request().URL.RawQuery,
+				"request_id": c.Response().Header().Get(echo.HeaderXRequestID),
+				"ip":         c.RealIP(),
+			})
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not record the impersonated request"})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// DenyImpersonation keeps impersonation tokens away from endpoints only the
+// user themselves may use, such as changing the password.
+func DenyImpersonation() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Get("actorID") != nil {
+				return c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Not allowed while impersonating a user"})
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package models
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type RestoreAccountRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type AccountDeletionResponse struct {
+	// ScheduledFor is when the account will be deleted unless its owner logs
+	// in before then.
+	ScheduledFor string `json:"scheduled_for"`
+}
@@ -0,0 +1,58 @@
+package models
+
+// AddressRequest names each region level, by code, by name or both. Codes
+// come from the /api/regions autocomplete and win over names; names are
+// normalized to the region dataset's spelling.
+type AddressRequest struct {
+	RecipientName string `json:"recipient_name" validate:"required,max=100"`
+	PhoneNumber   string `json:"phone_number" validate:"required,max=20"`
+	Street        string `json:"street" validate:"required,max=255"`
+	Village       string `json:"village" validate:"max=100"`
+	VillageCode   string `json:"village_code"`
+	District      string `json:"district" validate:"required,max=100"`
+	DistrictCode  string `json:"district_code"`
+	City          string `json:"city" validate:"required,max=100"`
+	CityCode      string `json:"city_code"`
+	Province      string `json:"province" validate:"required,max=100"`
+	ProvinceCode  string `json:"province_code"`
+	PostalCode    string `json:"postal_code" validate:"required,numeric,len=5"`
+	// IsDefault is only read when creating; use PUT /addresses/:id/default to
+	// change the default later.
+	IsDefault bool `json:"is_default"`
+}
+
+type AddressResponse struct {
+	ID            string `json:"id"`
+	RecipientName string `json:"recipient_name"`
+	PhoneNumber   string `json:"phone_number"`
+	Street        string `json:"street"`
+	Village       string `json:"village,omitempty"`
+	VillageCode   string `json:"village_code,omitempty"`
+	District      string `json:"district"`
+	DistrictCode  string `json:"district_code,omitempty"`
+	City          string `json:"city"`
+	CityCode      string `json:"city_code,omitempty"`
+	Province      string `json:"province"`
+	ProvinceCode  string `json:"province_code,omitempty"`
+	PostalCode    string `json:"postal_code"`
+	IsDefault     bool   `json:"is_default"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+type RegionResponse struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Level      string `json:"level"`
+	ParentCode string `json:"parent_code,omitempty"`
+	// FullName adds the parent regions, e.g. "Tebet, Kota Adm. Jakarta
+	// Selatan, DKI Jakarta".
+	FullName   string `json:"full_name"`
+	PostalCode string `json:"postal_code,omitempty"`
+}
+
+type RegionListResponse struct {
+	// Version is the region dataset release the codes come from.
+	Version string           `json:"version"`
+	Regions []RegionResponse `json:"regions"`
+}
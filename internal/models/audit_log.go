@@ -0,0 +1,19 @@
+package models
+
+import "encoding/json"
+
+type AuditLogEntryResponse struct {
+	ID           int64           `json:"id"`
+	ActorID      string          `json:"actor_id,omitempty"`
+	TargetUserID string          `json:"target_user_id,omitempty"`
+	Action       string          `json:"action"`
+	Details      json.RawMessage `json:"details"`
+	CreatedAt    string          `json:"created_at"`
+}
+
+// AuditLogResponse pages back in time: pass next_before_id as before_id to
+// get the entries before this page.
+type AuditLogResponse struct {
+	Entries      []AuditLogEntryResponse `json:"entries"`
+	NextBeforeID int64                   `json:"next_before_id,omitempty"`
+}
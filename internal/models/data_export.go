@@ -0,0 +1,11 @@
+package models
+
+type DataExportResponse struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+	CompletedAt string `json:"completed_at,omitempty"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+	// DownloadURL is only set while the export is ready.
+	DownloadURL string `json:"download_url,omitempty"`
+}
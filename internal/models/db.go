@@ -7,4 +7,5 @@ type Credential struct {
 	DatabaseName string
 	Port         int
 	Schema       string
+	TimeZone     string
 }
@@ -0,0 +1,14 @@
+package models
+
+type ImpersonationRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ImpersonationResponse carries an access token for the user with no refresh
+// token; every request made with it is audit-logged.
+type ImpersonationResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresAt   string `json:"expires_at"`
+	UserID      string `json:"user_id"`
+}
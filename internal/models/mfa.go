@@ -0,0 +1,47 @@
+package models
+
+// MFAChallengeResponse replaces the tokens in the login response when a second
+// factor is needed.
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required"`
+	MFAToken           string `json:"mfa_token"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	ExpiresAt          string `json:"expires_at"`
+}
+
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+type MFALoginResponse struct {
+	UserResponse
+	// RecoveryCodes is only set when the login finished a forced enrollment.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+type MFACodeRequest struct {
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAStatusResponse struct {
+	Enabled                bool   `json:"enabled"`
+	EnabledAt              string `json:"enabled_at,omitempty"`
+	Required               bool   `json:"required"`
+	RecoveryCodesRemaining int64  `json:"recovery_codes_remaining"`
+}
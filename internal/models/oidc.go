@@ -0,0 +1,96 @@
+package models
+
+// AuthorizeRequest holds the query parameters of /oauth/authorize. The consent
+// page sends the same fields back when the user approves or denies.
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type" query:"response_type" form:"response_type"`
+	ClientID            string `json:"client_id" query:"client_id" form:"client_id"`
+	RedirectURI         string `json:"redirect_uri" query:"redirect_uri" form:"redirect_uri"`
+	Scope               string `json:"scope" query:"scope" form:"scope"`
+	State               string `json:"state" query:"state" form:"state"`
+	Nonce               string `json:"nonce" query:"nonce" form:"nonce"`
+	CodeChallenge       string `json:"code_challenge" query:"code_challenge" form:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" query:"code_challenge_method" form:"code_challenge_method"`
+}
+
+type ConsentDecisionRequest struct {
+	AuthorizeRequest
+	Approve bool `json:"approve"`
+}
+
+type ConsentResponse struct {
+	ClientID   string   `json:"client_id"`
+	ClientName string   `json:"client_name"`
+	Scopes     []string `json:"scopes"`
+	// ConsentRequired is false when the client is first-party or the user has
+	// already granted every requested scope; the page then approves silently.
+	ConsentRequired bool `json:"consent_required"`
+}
+
+type ConsentDecisionResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+type UserInfoResponse struct {
+	Subject           string `json:"sub"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+}
+
+type OIDCDiscovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+type OAuthClientCreateRequest struct {
+	Name          string   `json:"name" validate:"required"`
+	RedirectURIs  []string `json:"redirect_uris" validate:"required,min=1,dive,url"`
+	AllowedScopes []string `json:"allowed_scopes" validate:"required,min=1"`
+	IsFirstParty  bool     `json:"is_first_party"`
+	// Confidential clients get a secret; public ones (SPA, mobile) rely on PKCE alone.
+	Confidential bool `json:"confidential"`
+}
+
+type OAuthClientResponse struct {
+	ID            string   `json:"client_id"`
+	Name          string   `json:"name"`
+	RedirectURIs  []string `json:"redirect_uris"`
+	AllowedScopes []string `json:"allowed_scopes"`
+	IsFirstParty  bool     `json:"is_first_party"`
+	Confidential  bool     `json:"confidential"`
+	// Secret is only returned once, when the client is created.
+	Secret    string `json:"client_secret,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+type PhoneCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type PhoneLoginCodeRequest struct {
+	PhoneNumber string `json:"phone_number"`
+}
+
+type PhoneLoginRequest struct {
+	PhoneNumber string `json:"phone_number"`
+	Code        string `json:"code"`
+}
+
+// PhoneOTPResponse tells the client how long the texted code works.
+type PhoneOTPResponse struct {
+	ExpiresAt string `json:"expires_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+// PreferencesPatchRequest changes only the members sent. A null or missing
+// member keeps its value.
+type PreferencesPatchRequest struct {
+	Locale   *string                  `json:"locale"`
+	Timezone *string                  `json:"timezone"`
+	Currency *string                  `json:"currency"`
+	Email    *EmailPreferencesRequest `json:"email"`
+}
+
+type EmailPreferencesRequest struct {
+	Newsletter      *bool `json:"newsletter"`
+	Promotions      *bool `json:"promotions"`
+	Recommendations *bool `json:"recommendations"`
+}
+
+type PreferencesResponse struct {
+	Locale   string                   `json:"locale"`
+	Timezone string                   `json:"timezone"`
+	Currency string                   `json:"currency"`
+	Email    EmailPreferencesResponse `json:"email"`
+	// UpdatedAt is empty while the defaults apply.
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// EmailPreferencesResponse lists the non-transactional email the user opted
+// into.
+type EmailPreferencesResponse struct {
+	Newsletter      bool `json:"newsletter"`
+	Promotions      bool `json:"promotions"`
+	Recommendations bool `json:"recommendations"`
+}
@@ -0,0 +1,77 @@
+package models
+
+// RoleRequest creates a role. Privileged roles are only granted with the
+// approval of a second admin; roles with any staff permission are always
+// privileged.
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Privileged  bool     `json:"privileged"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleUpdateRequest replaces the description, the privileged flag and the
+// whole permission set.
+type RoleUpdateRequest struct {
+	Description string   `json:"description"`
+	Privileged  bool     `json:"privileged"`
+	Permissions []string `json:"permissions"`
+}
+
+type RoleResponse struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	IsSystem     bool     `json:"is_system"`
+	IsPrivileged bool     `json:"is_privileged"`
+	Permissions  []string `json:"permissions"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedAt    string   `json:"updated_at"`
+}
+
+type PermissionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type PermissionResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsSystem    bool   `json:"is_system"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// UserAccessResponse lists the effective roles and permissions of an account.
+type UserAccessResponse struct {
+	UserID      string   `json:"user_id"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleGrantRequest grants a role to a user. Privileged roles are not granted
+// right away but wait for a second admin's approval.
+type RoleGrantRequest struct {
+	Role   string `json:"role"`
+	Reason string `json:"reason"`
+}
+
+type RoleRevokeRequest struct {
+	Reason string `json:"reason"`
+}
+
+type RoleGrantDecisionRequest struct {
+	Notes string `json:"notes"`
+}
+
+type RoleGrantRequestResponse struct {
+	ID            string `json:"id"`
+	UserID        string `json:"user_id"`
+	Role          string `json:"role"`
+	RequestedBy   string `json:"requested_by,omitempty"`
+	Reason        string `json:"reason"`
+	Status        string `json:"status"`
+	DecisionNotes string `json:"decision_notes,omitempty"`
+	DecidedBy     string `json:"decided_by,omitempty"`
+	DecidedAt     string `json:"decided_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
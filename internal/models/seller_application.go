@@ -0,0 +1,38 @@
+package models
+
+// SellerApplicationRequest holds the form fields of a seller application. The
+// documents are uploaded next to them as the multipart files "ktp", "selfie"
+// and, with an NPWP number, "npwp".
+type SellerApplicationRequest struct {
+	ShopName string `form:"shop_name"`
+	NIK      string `form:"nik"`
+	NPWP     string `form:"npwp"`
+}
+
+type SellerApplicationDecisionRequest struct {
+	// Notes are shown to the applicant. They are required when rejecting.
+	Notes string `json:"notes"`
+}
+
+type SellerApplicationResponse struct {
+	ID          string                   `json:"id"`
+	UserID      string                   `json:"user_id"`
+	ShopName    string                   `json:"shop_name"`
+	NIK         string                   `json:"nik"`
+	NPWP        string                   `json:"npwp,omitempty"`
+	Status      string                   `json:"status"`
+	ReviewNotes string                   `json:"review_notes,omitempty"`
+	ReviewedBy  string                   `json:"reviewed_by,omitempty"`
+	ReviewedAt  string                   `json:"reviewed_at,omitempty"`
+	Documents   []SellerDocumentResponse `json:"documents,omitempty"`
+	CreatedAt   string                   `json:"created_at"`
+	UpdatedAt   string                   `json:"updated_at"`
+}
+
+type SellerDocumentResponse struct {
+	Kind        string `json:"kind"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int    `json:"size_bytes"`
+	// URL downloads the document; only reviewers get it.
+	URL string `json:"url,omitempty"`
+}
@@ -13,3 +13,14 @@ type Session struct {
 	Role      string    `json:"role"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	ClientID   string `json:"client_id,omitempty"`
+	Device     string `json:"device"`
+	IPAddress  string `json:"ip_address"`
+	UserAgent  string `json:"user_agent"`
+	Current    bool   `json:"current"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+}
@@ -1,39 +1,71 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type UserRegisterRequest struct {
 	Name     string `json:"name" validate:"required"`
 	Username string `json:"username" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
-	Role     string `json:"role"`
-	Token    string `json:"token"`
+	// PhoneNumber is optional and stored in E.164 form.
+	PhoneNumber string `json:"phone_number"`
+	Token       string `json:"token"`
 }
 
 type UserResponse struct {
-	Id           uuid.UUID `json:"id"`
-	Name         string    `json:"name"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	Address      string    `json:"address"`
-	PhoneNumber  string    `json:"phone_number"`
-	Role         string    `json:"role"`
-	Token        string    `json:"token"`
-	RefreshToken string    `json:"refresh_token"`
-	CreatedAt    string    `json:"created_at"`
-	UpdatedAt    string    `json:"updated_at"`
+	Id            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Address       string    `json:"address"`
+	PhoneNumber   string    `json:"phone_number"`
+	PhoneVerified bool      `json:"phone_verified"`
+	AvatarURL     string    `json:"avatar_url,omitempty"`
+	Role          string    `json:"role"`
+	Token         string    `json:"token"`
+	RefreshToken  string    `json:"refresh_token"`
+	CreatedAt     string    `json:"created_at"`
+	UpdatedAt     string    `json:"updated_at"`
+	DeletedAt     string    `json:"deleted_at,omitempty"`
+	Status        string    `json:"status"`
+	StatusReason  string    `json:"status_reason,omitempty"`
+	// SuspendedUntil is only set for suspensions that end on their own.
+	SuspendedUntil string `json:"suspended_until,omitempty"`
+	// DeletionScheduledAt is set while a self-service deletion is pending.
+	DeletionScheduledAt string `json:"deletion_scheduled_at,omitempty"`
+}
+
+type UserStatusRequest struct {
+	Status         string     `json:"status"`
+	Reason         string     `json:"reason"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+}
+
+type UserListResponse struct {
+	Users []UserResponse `json:"users"`
+	// NextCursor is passed as ?cursor= to get the next page; it is left out on
+	// the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type UserUpdateRequest struct {
 	Name        string `json:"name" validate:"required"`
 	Username    string `json:"username" validate:"required"`
 	Email       string `json:"email" validate:"required,email"`
-	Password    string `json:"password,omitempty"`
 	Address     string `json:"address,omitempty"`
 	PhoneNumber string `json:"phone_number,omitempty"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
@@ -42,3 +74,29 @@ type UserLoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+// OptionalString is a JSON Merge Patch member. Set is false when the member was
+// left out, and Null is true when it was sent as null.
+type OptionalString struct {
+	Set   bool
+	Null  bool
+	Value string
+}
+
+func (o *OptionalString) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+// UserPatchRequest is a JSON Merge Patch (RFC 7396) of the profile.
+type UserPatchRequest struct {
+	Name        OptionalString `json:"name"`
+	Username    OptionalString `json:"username"`
+	Email       OptionalString `json:"email"`
+	Address     OptionalString `json:"address"`
+	PhoneNumber OptionalString `json:"phone_number"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "encoding/json"
+
+// WebAuthnBeginResponse carries the options for navigator.credentials.create()
+// or get(). The ceremony id must be sent back with the finish call.
+type WebAuthnBeginResponse struct {
+	CeremonyID string      `json:"ceremony_id"`
+	Options    interface{} `json:"options"`
+}
+
+// WebAuthnFinishRequest wraps the PublicKeyCredential returned by the browser,
+// serialized with the usual base64url encoding of its binary fields.
+type WebAuthnFinishRequest struct {
+	CeremonyID string          `json:"ceremony_id"`
+	Name       string          `json:"name,omitempty"`
+	Credential json.RawMessage `json:"credential"`
+}
+
+type WebAuthnCredentialResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Transports []string `json:"transports"`
+	Synced     bool     `json:"synced"`
+	CreatedAt  string   `json:"created_at"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+}
@@ -0,0 +1,189 @@
+// Package authz evaluates the declarative authorization policy that the
+// other tokohobby services query through the Authorize and CheckPermissions
+// RPCs, instead of each interpreting the role string on its own.
+//
+// A policy is a JSON document with a list of rules. A rule applies to a
+// request when one of its actions matches and every condition it sets holds.
+// Deny rules win over allow rules; a request no rule allows is denied.
+package authz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+type Policy struct {
+	// Version is echoed in every decision, so a caller can tell which policy
+	// answered.
+	Version string `json:"version"`
+	Rules   []Rule `json:"rules"`
+}
+
+type Rule struct {
+	ID string `json:"id"`
+	// Description is returned as the reason of the decisions the rule makes.
+	Description string `json:"description"`
+	Effect      string `json:"effect"`
+	// Actions are resource:verb names. "orders:*" matches every orders action
+	// and "*" every action.
+	Actions []string `json:"actions"`
+
+	// Authenticated requires a signed-in subject.
+	Authenticated bool `json:"authenticated,omitempty"`
+	// Roles requires at least one of the roles.
+	Roles []string `json:"roles,omitempty"`
+	// Permissions requires every one of the permissions.
+	Permissions []string `json:"permissions,omitempty"`
+	// Owner requires the subject to own the resource.
+	Owner bool `json:"owner,omitempty"`
+	// EmailVerified requires a verified email address.
+	EmailVerified bool `json:"email_verified,omitempty"`
+	// AccountStatuses requires the account to be in one of the statuses.
+	AccountStatuses []string `json:"account_statuses,omitempty"`
+}
+
+// Subject is who asks. A zero UserID is an anonymous caller.
+type Subject struct {
+	UserID        string
+	Roles         []string
+	Permissions   []string
+	EmailVerified bool
+	// Status is the effective account status; a lapsed suspension is active.
+	Status string
+}
+
+type Resource struct {
+	ID      string
+	OwnerID string
+}
+
+type Request struct {
+	Subject  Subject
+	Action   string
+	Resource Resource
+}
+
+type Decision struct {
+	Allowed bool
+	Reason  string
+	// RuleID is the rule that decided, empty when nothing allowed the request.
+	RuleID string
+}
+
+// Parse reads and checks a policy. Unknown fields are rejected so a
+// misspelled condition cannot silently widen a rule.
+func Parse(data []byte) (*Policy, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var p Policy
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("authz: %w", err)
+	}
+	if p.Version == "" {
+		return nil, errors.New("authz: policy has no version")
+	}
+
+	seen := make(map[string]bool, len(p.Rules))
+	for i, r := range p.Rules {
+		switch {
+		case r.ID == "":
+			return nil, fmt.Errorf("authz: rule %d has no id", i)
+		case seen[r.ID]:
+			return nil, fmt.Errorf("authz: duplicate rule id %q", r.ID)
+		case r.Description == "":
+			return nil, fmt.Errorf("authz: rule %q has no description", r.ID)
+		case r.Effect != EffectAllow && r.Effect != EffectDeny:
+			return nil, fmt.Errorf("authz: rule %q: effect must be allow or deny", r.ID)
+		case len(r.Actions) == 0:
+			return nil, fmt.Errorf("authz: rule %q has no actions", r.ID)
+		}
+		seen[r.ID] = true
+	}
+	return &p, nil
+}
+
+// Evaluate decides a request.
+func (p *Policy) Evaluate(req Request) Decision {
+	var allow *Rule
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if !r.matchesAction(req.Action) || !r.holds(req) {
+			continue
+		}
+		if r.Effect == EffectDeny {
+			return Decision{Allowed: false, Reason: r.Description, RuleID: r.ID}
+		}
+		if allow == nil {
+			allow = r
+		}
+	}
+
+	if allow == nil {
+		return Decision{Allowed: false, Reason: fmt.Sprintf("no rule allows %s", req.Action)}
+	}
+	return Decision{Allowed: true, Reason: allow.Description, RuleID: allow.ID}
+}
+
+func (r *Rule) matchesAction(action string) bool {
+	for _, pattern := range r.Actions {
+		switch {
+		case pattern == "*", pattern == action:
+			return true
+		case strings.HasSuffix(pattern, ":*") && strings.HasPrefix(action, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) holds(req Request) bool {
+	s := req.Subject
+	if r.Authenticated && s.UserID == "" {
+		return false
+	}
+	if len(r.Roles) > 0 && !containsAny(s.Roles, r.Roles) {
+		return false
+	}
+	for _, p := range r.Permissions {
+		if !contains(s.Permissions, p) {
+			return false
+		}
+	}
+	if r.Owner && (s.UserID == "" || req.Resource.OwnerID != s.UserID) {
+		return false
+	}
+	if r.EmailVerified && !s.EmailVerified {
+		return false
+	}
+	if len(r.AccountStatuses) > 0 && !contains(r.AccountStatuses, s.Status) {
+		return false
+	}
+	return true
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(list, wanted []string) bool {
+	for _, w := range wanted {
+		if contains(list, w) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Store holds the policy loaded from a file and reloads it when the file
+// changes. A file that no longer parses is logged and the previous policy
+// kept.
+type Store struct {
+	path    string
+	policy  atomic.Pointer[Policy]
+	modTime time.Time
+	size    int64
+	log     *logrus.Logger
+}
+
+// Load reads the policy at path. Unlike reloads, the first load has to
+// succeed.
+func Load(path string, log *logrus.Logger) (*Store, error) {
+	s := &Store{path: path, log: log}
+	if _, err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) Policy() *Policy {
+	return s.policy.Load()
+}
+
+// Watch checks the file every interval until ctx is cancelled.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			reloaded, err := s.reload()
+			if err != nil {
+				s.log.WithError(err).Error("Failed to reload authorization policy, keeping the previous one")
+				continue
+			}
+			if reloaded {
+				s.log.Infof("Authorization policy reloaded, version %s", s.Policy().Version)
+			}
+		}
+	}()
+}
+
+// reload parses the file when its modification time or size changed.
+func (s *Store) reload() (bool, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, fmt.Errorf("authz: %w", err)
+	}
+	if s.policy.Load() != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, fmt.Errorf("authz: %w", err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		// Remember the broken file so it is reported once, not every tick.
+		s.modTime, s.size = info.ModTime(), info.Size()
+		return false, fmt.Errorf("%w (%s)", err, s.path)
+	}
+
+	s.policy.Store(p)
+	s.modTime, s.size = info.ModTime(), info.Size()
+	return true, nil
+}
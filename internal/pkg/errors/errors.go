@@ -14,12 +14,48 @@ var (
 	ErrMissingJTI            = errors.New("missing JTI, cannot revoke token")
 	ErrFailedToRevokeToken   = errors.New("failed to revoke token")
 	ErrTokenNotFound         = errors.New("token not found")
+	ErrRefreshTokenReused    = errors.New("refresh token reuse detected, session revoked")
+	ErrSessionNotFound       = errors.New("session not found")
 	ErrInvalidCredentials    = errors.New("invalid credentials")
+	ErrInvalidMFACode        = errors.New("invalid two-factor code")
+	ErrInvalidOTP            = errors.New("invalid or expired code")
+	ErrMFAAlreadyEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled        = errors.New("two-factor authentication is not set up")
+	ErrMFARequiredByRole     = errors.New("two-factor authentication is required for your role")
+	ErrPasskeyVerification   = errors.New("passkey verification failed")
+	ErrEmailAlreadyVerified  = errors.New("email is already verified")
+	ErrPhoneAlreadyVerified  = errors.New("phone number is already verified")
+	ErrPhoneNumberTaken      = errors.New("phone number is already verified on another account")
+	ErrTooManyRequests       = errors.New("too many requests, please try again later")
+	ErrAccountSuspended      = errors.New("account is suspended")
+	ErrAccountBanned         = errors.New("account is banned")
+	ErrAccountDeleted        = errors.New("account has been deleted")
+	ErrStatusTransition      = errors.New("account cannot change to that status")
+	ErrPreconditionRequired  = errors.New("an If-Match header is required")
+	ErrPreconditionFailed    = errors.New("the resource was modified, reload it and try again")
 	ErrUserAlreadyExists     = errors.New("user already exists")
 	ErrUsernameAlreadyExists = errors.New("username already exists")
 	ErrEmailAlreadyExists    = errors.New("email already exists")
 	ErrNotFound              = errors.New("not found")
 	ErrForbidden             = errors.New("forbidden")
+	ErrAddressLimitReached   = errors.New("address book is full, delete an address first")
+	ErrFileTooLarge          = errors.New("file is too large")
+	ErrSellerApplicationOpen = errors.New("a seller application is already under review")
+	ErrAlreadySeller         = errors.New("account can already sell")
+	ErrApplicationDecided    = errors.New("seller application has already been decided")
+	ErrEmailNotVerified      = errors.New("verify your email address first")
+	ErrRoleAlreadyExists     = errors.New("role already exists")
+	ErrPermissionExists      = errors.New("permission already exists")
+	ErrRoleProtected         = errors.New("role is managed by the service and cannot be changed")
+	ErrPermissionProtected   = errors.New("permission is used by the service and cannot be deleted")
+	ErrRoleInUse             = errors.New("role is still the primary role of some accounts")
+	ErrRoleHeld              = errors.New("role is held by users; create a new role with the extra permissions and grant it instead")
+	ErrRolePrivilegeHeld     = errors.New("role is held by users and has to stay privileged")
+	ErrRoleAlreadyGranted    = errors.New("user already has this role")
+	ErrRoleGrantPending      = errors.New("a grant of this role is already waiting for approval")
+	ErrRoleGrantDecided      = errors.New("role grant request has already been decided")
+	ErrSelfApproval          = errors.New("a different admin has to approve this request")
+	ErrCannotImpersonate     = errors.New("this account cannot be impersonated")
 
 	ErrInternalServerError = errors.New("internal server error")
 
@@ -44,6 +80,24 @@ func (e ValidationErrors) Error() string {
 	return "validation failed"
 }
 
+// OAuthError carries an RFC 6749 error code. The OAuth endpoints answer with
+// these instead of ErrorResponse so standard client libraries understand them.
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func NewOAuthError(code string, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
 /*
 
 ### 📌 Error Handling Best Practice per Layer
@@ -0,0 +1,135 @@
+// Package imaging turns uploaded pictures into clean, square JPEG thumbnails.
+// Images are decoded to pixels and encoded again, so EXIF and any other
+// metadata in the upload never reach the output; the EXIF orientation of a
+// JPEG is applied to the pixels first.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"net/http"
+
+	// decoders for image.Decode
+	_ "image/gif"
+	_ "image/png"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("imaging: unsupported image format")
+	ErrTooLarge          = errors.New("imaging: image dimensions are too large")
+)
+
+// ContentTypes are the formats Decode accepts, as sniffed from the data.
+var ContentTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+// Decode sniffs the content type from the data itself, ignoring whatever the
+// client claimed, and decodes the image upright. Images with more than
+// maxPixels pixels are rejected before they are decoded.
+func Decode(data []byte, maxPixels int) (image.Image, string, error) {
+	contentType := http.DetectContentType(data)
+	supported := false
+	for _, t := range ContentTypes {
+		if contentType == t {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil, contentType, ErrUnsupportedFormat
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, contentType, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, contentType, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, contentType, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	if contentType == "image/jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, contentType, nil
+}
+
+// CropSquare copies the centre square of img, flattening transparency onto
+// white since JPEG has none.
+func CropSquare(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	offset := image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2)
+
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, offset, draw.Over)
+	return dst
+}
+
+// Square crops the centre square of img and scales it to size x size.
+func Square(img image.Image, size int) *image.RGBA {
+	return Resize(CropSquare(img), size)
+}
+
+// EncodeJPEG encodes img without any metadata.
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("imaging: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Resize scales the square src to size x size. Each output pixel is the mean
+// of the source pixels it covers, or the nearest one when enlarging.
+func Resize(src *image.RGBA, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	side := src.Bounds().Dx()
+
+	for y := 0; y < size; y++ {
+		y0, y1 := span(y, size, side)
+		for x := 0; x < size; x++ {
+			x0, x1 := span(x, size, side)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					b += uint32(src.Pix[i+2])
+					a += uint32(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(b / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// span returns the source range [from, to) that output index i of size covers,
+// never empty.
+func span(i, size, side int) (int, int) {
+	from := i * side / size
+	to := (i + 1) * side / size
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
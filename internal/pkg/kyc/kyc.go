@@ -0,0 +1,81 @@
+// Package kyc checks the Indonesian identity numbers sellers apply with: the
+// NIK of the KTP and the NPWP tax number.
+package kyc
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidNIK  = errors.New("not a valid NIK")
+	ErrInvalidNPWP = errors.New("not a valid NPWP")
+)
+
+// digits drops the spaces, dots and dashes numbers are usually written with.
+func digits(number string) (string, bool) {
+	n := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '-', '.':
+			return -1
+		}
+		return r
+	}, number)
+	for _, r := range n {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return n, true
+}
+
+// NormalizeNIK returns the 16 digits of a NIK. The digits are the region the
+// KTP was issued in (6), the birth date as DDMMYY with 40 added to the day for
+// women (6) and a serial number (4); the date has to be a possible one.
+func NormalizeNIK(nik string) (string, error) {
+	n, ok := digits(nik)
+	if !ok || len(n) != 16 {
+		return "", ErrInvalidNIK
+	}
+
+	day, _ := strconv.Atoi(n[6:8])
+	month, _ := strconv.Atoi(n[8:10])
+	if day > 40 {
+		day -= 40
+	}
+	if day < 1 || day > 31 || month < 1 || month > 12 {
+		return "", ErrInvalidNIK
+	}
+	if n[12:] == "0000" {
+		return "", ErrInvalidNIK
+	}
+	return n, nil
+}
+
+// ProvinceCode is the Kemendagri code of the province a NIK was issued in.
+func ProvinceCode(nik string) string {
+	return nik[:2]
+}
+
+// NormalizeNPWP returns the digits of an NPWP: 15 in the old
+// XX.XXX.XXX.X-XXX.XXX format, 16 since the NIK became the NPWP of
+// individuals.
+func NormalizeNPWP(npwp string) (string, error) {
+	n, ok := digits(npwp)
+	if !ok || (len(n) != 15 && len(n) != 16) {
+		return "", ErrInvalidNPWP
+	}
+	if strings.Trim(n, "0") == "" {
+		return "", ErrInvalidNPWP
+	}
+	return n, nil
+}
+
+// Mask hides all but the last four digits of number.
+func Mask(number string) string {
+	if len(number) <= 4 {
+		return number
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
@@ -0,0 +1,63 @@
+// Package phone normalizes phone numbers to E.164, the form they are stored
+// and sent to SMS providers in.
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+// E.164 numbers have at most 15 digits including the country code. Shorter
+// than 8 is not a subscriber number anywhere we ship to.
+const (
+	minDigits = 8
+	maxDigits = 15
+)
+
+var ErrInvalid = errors.New("not a valid phone number")
+
+// Normalize returns number in E.164 form, such as "+6281234567890". Spaces,
+// dashes, dots, slashes and parentheses are ignored.
+//
+// A number without an international prefix ("+" or "00") is read in
+// defaultCountryCode: a leading trunk 0 is dropped ("0812..."), a number
+// already starting with the country code gets the "+" ("62812...") and
+// anything else is prefixed with it ("812..."). A trunk 0 written after the
+// default country code ("+62 0812...") is dropped as well.
+func Normalize(number, defaultCountryCode string) (string, error) {
+	n := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '-', '.', '/', '(', ')':
+			return -1
+		}
+		return r
+	}, number)
+
+	var digits string
+	switch {
+	case strings.HasPrefix(n, "+"):
+		digits = n[1:]
+	case strings.HasPrefix(n, "00"):
+		digits = n[2:]
+	case strings.HasPrefix(n, "0"):
+		digits = defaultCountryCode + n[1:]
+	case strings.HasPrefix(n, defaultCountryCode):
+		digits = n
+	default:
+		digits = defaultCountryCode + n
+	}
+
+	if rest, ok := strings.CutPrefix(digits, defaultCountryCode+"0"); ok {
+		digits = defaultCountryCode + rest
+	}
+
+	if len(digits) < minDigits || len(digits) > maxDigits || digits[0] == '0' {
+		return "", ErrInvalid
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", ErrInvalid
+		}
+	}
+	return "+" + digits, nil
+}
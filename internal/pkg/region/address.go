@@ -0,0 +1,158 @@
+package region
+
+import "fmt"
+
+// Address is the regional part of a postal address. Each level may be given
+// by code, by name or both; a code wins over a name.
+type Address struct {
+	ProvinceCode string
+	Province     string
+	CityCode     string
+	City         string
+	DistrictCode string
+	District     string
+	VillageCode  string
+	Village      string
+	PostalCode   string
+}
+
+// FieldError reports the address field that could not be resolved. Field is
+// the name of the name field (province, city, district, village or
+// postal_code).
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Resolve checks an address against the dataset from the province down and
+// fills in the dataset's codes and display names. The village is optional.
+//
+// A partial dataset only checks a level when it lists the regions below its
+// parent; from the first level it does not cover, names are kept as given and
+// codes left empty. A complete one checks every level.
+func (d *Dataset) Resolve(in Address) (Address, error) {
+	out := in
+
+	levels := []struct {
+		field string
+		code  *string
+		name  *string
+	}{
+		{"province", &out.ProvinceCode, &out.Province},
+		{"city", &out.CityCode, &out.City},
+		{"district", &out.DistrictCode, &out.District},
+		{"village", &out.VillageCode, &out.Village},
+	}
+
+	parent := ""
+	covered := true
+	var last *Region
+	for _, level := range levels {
+		if covered && !d.complete && len(d.children[parent]) == 0 {
+			// A code from elsewhere in the dataset is still wrong here.
+			if r, ok := d.regions[*level.code]; ok && r.ParentCode != parent {
+				return in, &FieldError{Field: level.field, Message: fmt.Sprintf("region %s is not in %s", r.Code, d.describe(parent))}
+			}
+			covered = false
+		}
+		if !covered {
+			*level.code = ""
+			continue
+		}
+
+		r, err := d.resolveLevel(parent, *level.code, *level.name)
+		if err != nil {
+			return in, &FieldError{Field: level.field, Message: err.Error()}
+		}
+		if r == nil {
+			// Left empty; the caller decides whether the field is required.
+			covered = false
+			*level.code = ""
+			continue
+		}
+
+		*level.code = r.Code
+		*level.name = r.Name
+		parent = r.Code
+		last = r
+	}
+
+	if err := d.checkPostalCode(&out, last); err != nil {
+		return in, err
+	}
+	return out, nil
+}
+
+func (d *Dataset) resolveLevel(parent, code, name string) (*Region, error) {
+	if code != "" {
+		r, ok := d.regions[code]
+		if !ok {
+			return nil, fmt.Errorf("unknown region code %q", code)
+		}
+		if r.ParentCode != parent {
+			return nil, fmt.Errorf("region %s is not in %s", code, d.describe(parent))
+		}
+		return r, nil
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	matches := d.Match(parent, name)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%q is not in %s", name, d.describe(parent))
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("%q is ambiguous, send its code", name)
+	}
+}
+
+// checkPostalCode compares the postal code with the village's, or with those
+// of the district's villages when no village was given. Without a known
+// postal code it is taken from the village.
+func (d *Dataset) checkPostalCode(out *Address, last *Region) error {
+	if last == nil {
+		return nil
+	}
+
+	var allowed []string
+	switch last.Level {
+	case LevelVillage:
+		if last.PostalCode != "" {
+			allowed = []string{last.PostalCode}
+		}
+	case LevelDistrict:
+		for _, village := range d.children[last.Code] {
+			if village.PostalCode != "" {
+				allowed = append(allowed, village.PostalCode)
+			}
+		}
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	if out.PostalCode == "" && last.Level == LevelVillage {
+		out.PostalCode = last.PostalCode
+		return nil
+	}
+	for _, postalCode := range allowed {
+		if out.PostalCode == postalCode {
+			return nil
+		}
+	}
+	return &FieldError{Field: "postal_code", Message: fmt.Sprintf("does not belong to %s", last.Name)}
+}
+
+func (d *Dataset) describe(code string) string {
+	if r, ok := d.regions[code]; ok {
+		return r.Name
+	}
+	return "Indonesia"
+}
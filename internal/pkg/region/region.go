@@ -0,0 +1,317 @@
+// Package region embeds the Indonesian administrative regions (provinces,
+// regencies and cities, districts and villages) with their Kemendagri codes
+// and village postal codes, for autocomplete and address validation.
+//
+// data/wilayah.csv holds one "code,name" row per region, where the number of
+// dot separated segments in the code is the level (31, 31.74, 31.74.01,
+// 31.74.01.1001). data/kodepos.csv maps village codes to postal codes.
+// cmd/regiongen writes both files and data/VERSION from the Kemendagri
+// export.
+package region
+
+import (
+	"bytes"
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+//go:embed data/VERSION data/wilayah.csv data/kodepos.csv
+var files embed.FS
+
+type Level int
+
+const (
+	LevelProvince Level = iota + 1
+	LevelRegency
+	LevelDistrict
+	LevelVillage
+)
+
+var levelNames = map[Level]string{
+	LevelProvince: "province",
+	LevelRegency:  "regency",
+	LevelDistrict: "district",
+	LevelVillage:  "village",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel accepts the names returned by Level.String.
+func ParseLevel(name string) (Level, bool) {
+	for level, n := range levelNames {
+		if n == name {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
+type Region struct {
+	Code       string
+	Name       string
+	Level      Level
+	ParentCode string
+	// PostalCode is only set on villages.
+	PostalCode string
+}
+
+type Dataset struct {
+	// Version identifies the data files, so stored codes can be traced back to
+	// the release they came from.
+	Version  string
+	regions  map[string]*Region
+	children map[string][]*Region
+	keys     map[string]string
+	// complete is set when every region above the villages has regions
+	// below it, as in a full export.
+	complete bool
+}
+
+// Load parses the embedded data files.
+func Load() (*Dataset, error) {
+	version, err := files.ReadFile("data/VERSION")
+	if err != nil {
+		return nil, fmt.Errorf("region: %w", err)
+	}
+	wilayah, err := files.ReadFile("data/wilayah.csv")
+	if err != nil {
+		return nil, fmt.Errorf("region: %w", err)
+	}
+	kodepos, err := files.ReadFile("data/kodepos.csv")
+	if err != nil {
+		return nil, fmt.Errorf("region: %w", err)
+	}
+
+	return Parse(strings.TrimSpace(string(version)), wilayah, kodepos)
+}
+
+// Parse builds a dataset from the contents of wilayah.csv and kodepos.csv.
+func Parse(version string, wilayah, kodepos []byte) (*Dataset, error) {
+	d := &Dataset{
+		Version:  version,
+		regions:  make(map[string]*Region),
+		children: make(map[string][]*Region),
+		keys:     make(map[string]string),
+	}
+
+	err := readRows(wilayah, func(code, name string) error {
+		level := Level(strings.Count(code, ".") + 1)
+		if level > LevelVillage {
+			return fmt.Errorf("code %q has too many segments", code)
+		}
+		if _, ok := d.regions[code]; ok {
+			return fmt.Errorf("duplicate code %q", code)
+		}
+
+		r := &Region{Code: code, Name: name, Level: level}
+		if level > LevelProvince {
+			r.ParentCode = code[:strings.LastIndex(code, ".")]
+		}
+		d.regions[code] = r
+		d.keys[code] = key(name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("region: wilayah.csv: %w", err)
+	}
+
+	err = readRows(kodepos, func(code, postalCode string) error {
+		r, ok := d.regions[code]
+		if !ok || r.Level != LevelVillage {
+			return fmt.Errorf("%q is not a village code", code)
+		}
+		r.PostalCode = postalCode
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("region: kodepos.csv: %w", err)
+	}
+
+	for _, r := range d.regions {
+		if r.ParentCode != "" {
+			if _, ok := d.regions[r.ParentCode]; !ok {
+				return nil, fmt.Errorf("region: %s has no parent %s", r.Code, r.ParentCode)
+			}
+		}
+		d.children[r.ParentCode] = append(d.children[r.ParentCode], r)
+	}
+	for _, list := range d.children {
+		sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	}
+
+	d.complete = len(d.children[""]) > 0
+	for _, r := range d.regions {
+		if r.Level < LevelVillage && len(d.children[r.Code]) == 0 {
+			d.complete = false
+			break
+		}
+	}
+
+	return d, nil
+}
+
+func readRows(data []byte, row func(code, value string) error) error {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = 2
+	r.TrimLeadingSpace = true
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := row(strings.TrimSpace(record[0]), strings.TrimSpace(record[1])); err != nil {
+			return err
+		}
+	}
+}
+
+// Complete reports whether every region above the villages has regions
+// below it. Only a complete dataset checks every level of an address.
+func (d *Dataset) Complete() bool {
+	return d.complete
+}
+
+// Get returns the region with the given code.
+func (d *Dataset) Get(code string) (*Region, bool) {
+	r, ok := d.regions[code]
+	return r, ok
+}
+
+// Children lists the regions directly below parentCode, or the provinces when
+// it is empty.
+func (d *Dataset) Children(parentCode string) []*Region {
+	return d.children[parentCode]
+}
+
+// Path returns the region and its ancestors, the region first.
+func (d *Dataset) Path(code string) []*Region {
+	var path []*Region
+	for r, ok := d.regions[code]; ok; r, ok = d.regions[r.ParentCode] {
+		path = append(path, r)
+	}
+	return path
+}
+
+// Search finds up to limit regions whose name contains query, names starting
+// with it first. A zero level searches every level; parentCode limits the
+// search to the descendants of that region.
+func (d *Dataset) Search(query string, level Level, parentCode string, limit int) []*Region {
+	q := key(query)
+
+	var prefix, infix []*Region
+	for code, r := range d.regions {
+		if level != 0 && r.Level != level {
+			continue
+		}
+		if parentCode != "" && !strings.HasPrefix(code, parentCode+".") {
+			continue
+		}
+
+		k := d.keys[code]
+		switch {
+		case strings.HasPrefix(k, q) || strings.HasPrefix(stripPrefixes(k), q):
+			prefix = append(prefix, r)
+		case strings.Contains(k, q):
+			infix = append(infix, r)
+		}
+	}
+
+	byName := func(list []*Region) {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Level != list[j].Level {
+				return list[i].Level < list[j].Level
+			}
+			if list[i].Name != list[j].Name {
+				return list[i].Name < list[j].Name
+			}
+			return list[i].Code < list[j].Code
+		})
+	}
+	byName(prefix)
+	byName(infix)
+
+	res := append(prefix, infix...)
+	if limit > 0 && len(res) > limit {
+		res = res[:limit]
+	}
+	return res
+}
+
+// Match returns the children of parentCode that name refers to. An exact
+// match of the full name wins; otherwise names are compared without
+// administrative prefixes such as "Kota" or "Kab.", which can match more than
+// one region (Kab. Bogor and Kota Bogor).
+func (d *Dataset) Match(parentCode, name string) []*Region {
+	k := key(name)
+	if k == "" {
+		return nil
+	}
+
+	var loose []*Region
+	for _, r := range d.children[parentCode] {
+		rk := d.keys[r.Code]
+		if rk == k {
+			return []*Region{r}
+		}
+		if stripPrefixes(rk) == stripPrefixes(k) {
+			loose = append(loose, r)
+		}
+	}
+	return loose
+}
+
+// key folds a name for comparison: lower case, punctuation dropped and spaces
+// collapsed.
+func key(name string) string {
+	name = strings.ToLower(name)
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		default:
+			return ' '
+		}
+	}, name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// adminPrefixes are dropped from the start of keys by stripPrefixes. Longer
+// forms come first.
+var adminPrefixes = []string{
+	"provinsi ",
+	"daerah khusus ibukota ",
+	"daerah istimewa ",
+	"dki ",
+	"di ",
+	"kabupaten administrasi ",
+	"kab adm ",
+	"kabupaten ",
+	"kab ",
+	"kota administrasi ",
+	"kota adm ",
+	"kota ",
+	"kecamatan ",
+	"kec ",
+	"kelurahan ",
+	"kel ",
+	"desa ",
+}
+
+func stripPrefixes(k string) string {
+	for _, p := range adminPrefixes {
+		if strings.HasPrefix(k, p) {
+			return strings.TrimPrefix(k, p)
+		}
+	}
+	return k
+}
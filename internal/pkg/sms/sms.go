@@ -0,0 +1,91 @@
+// Package sms sends text messages through a configurable provider. Only
+// development providers ship with the service: "log" writes messages to the
+// application log and "file" appends them to a file, where tests and local
+// setups can pick up the codes.
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sender delivers a message to a phone number in E.164 form.
+type Sender interface {
+	Send(ctx context.Context, to string, message string) error
+}
+
+// New returns the sender for provider.
+func New(provider string, filePath string, log *logrus.Logger) (Sender, error) {
+	switch provider {
+	case "", "log":
+		return NewLogSender(log), nil
+	case "file":
+		if filePath == "" {
+			return nil, fmt.Errorf("sms: the file provider needs a file path")
+		}
+		return NewFileSender(filePath), nil
+	default:
+		return nil, fmt.Errorf("sms: unknown provider %q", provider)
+	}
+}
+
+type logSender struct {
+	log *logrus.Logger
+}
+
+func NewLogSender(log *logrus.Logger) Sender {
+	return &logSender{log: log}
+}
+
+func (s *logSender) Send(ctx context.Context, to string, message string) error {
+	s.log.WithField("to", to).Infof("SMS: %s", message)
+	return nil
+}
+
+// Message is a line of the file written by the file provider.
+type Message struct {
+	To      string    `json:"to"`
+	Message string    `json:"message"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+type fileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSender appends every message to path as a JSON line.
+func NewFileSender(path string) Sender {
+	return &fileSender{path: path}
+}
+
+func (s *fileSender) Send(ctx context.Context, to string, message string) error {
+	line, err := json.Marshal(Message{To: to, Message: message, SentAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("sms: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("sms: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("sms: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("sms: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// Package totp implements RFC 6238 time-based one-time passwords on top of the
+// RFC 4226 HOTP algorithm. It has no state; replay protection is up to the
+// caller, which can remember the last accepted time step returned by Validate.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Algorithm string
+
+const (
+	AlgorithmSHA1   Algorithm = "SHA1"
+	AlgorithmSHA256 Algorithm = "SHA256"
+	AlgorithmSHA512 Algorithm = "SHA512"
+)
+
+// secretSize matches the 160-bit key length recommended by RFC 4226.
+const secretSize = 20
+
+type Options struct {
+	Period    time.Duration
+	Digits    int
+	Algorithm Algorithm
+	// Skew is the number of periods accepted before and after the current one
+	// to tolerate clock drift.
+	Skew int
+}
+
+// DefaultOptions is what every mainstream authenticator app assumes when an
+// otpauth URI leaves the parameters out.
+var DefaultOptions = Options{
+	Period:    30 * time.Second,
+	Digits:    6,
+	Algorithm: AlgorithmSHA1,
+	Skew:      1,
+}
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a random base32 encoded secret.
+func GenerateSecret() (string, error) {
+	buf := make([]byte, secretSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("totp: failed to generate secret: %w", err)
+	}
+	return b32.EncodeToString(buf), nil
+}
+
+// DecodeSecret accepts base32 secrets with or without padding, in any case and
+// with the spaces authenticator apps like to show.
+func DecodeSecret(secret string) ([]byte, error) {
+	cleaned := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := b32.DecodeString(strings.TrimRight(cleaned, "="))
+	if err != nil {
+		return nil, fmt.Errorf("totp: invalid secret: %w", err)
+	}
+	return key, nil
+}
+
+// Step returns the time step counter T for t.
+func Step(t time.Time, opts Options) int64 {
+	return t.Unix() / int64(opts.Period/time.Second)
+}
+
+// GenerateCode returns the code for key at time t.
+func GenerateCode(key []byte, t time.Time, opts Options) (string, error) {
+	return hotp(key, Step(t, opts), opts)
+}
+
+// Validate checks code against the steps around t and returns the matching
+// step so the caller can reject it if it was already used.
+func Validate(key []byte, code string, t time.Time, opts Options) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != opts.Digits {
+		return 0, false
+	}
+
+	current := Step(t, opts)
+	for offset := -opts.Skew; offset <= opts.Skew; offset++ {
+		step := current + int64(offset)
+		expected, err := hotp(key, step, opts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// KeyURI builds the otpauth:// URI understood by authenticator apps, which is
+// also what gets rendered as the enrollment QR code.
+func KeyURI(issuer string, account string, secret string, opts Options) string {
+	label := url.PathEscape(issuer + ":" + account)
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", string(opts.Algorithm))
+	params.Set("digits", fmt.Sprint(opts.Digits))
+	params.Set("period", fmt.Sprint(int64(opts.Period/time.Second)))
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+func hotp(key []byte, counter int64, opts Options) (string, error) {
+	newHash, err := opts.Algorithm.hash()
+	if err != nil {
+		return "", err
+	}
+	if opts.Digits < 6 || opts.Digits > 10 {
+		return "", fmt.Errorf("totp: unsupported number of digits %d", opts.Digits)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(newHash, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// RFC 4226 section 5.3 dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	binCode := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint64(1)
+	for i := 0; i < opts.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", opts.Digits, uint64(binCode)%mod), nil
+}
+
+func (a Algorithm) hash() (func() hash.Hash, error) {
+	switch a {
+	case AlgorithmSHA1, "":
+		return sha1.New, nil
+	case AlgorithmSHA256:
+		return sha256.New, nil
+	case AlgorithmSHA512:
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("totp: unsupported algorithm %q", a)
+	}
+}
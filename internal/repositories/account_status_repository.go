@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/redisclient"
+)
+
+const accountStatusCacheTTL = 24 * time.Hour
+
+type AccountStatus struct {
+	Status         string     `json:"status"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+}
+
+// AccountStatusRepository keeps a read-through Redis copy of users.status,
+// because it is checked on every token validation.
+type AccountStatusRepository interface {
+	GetStatus(ctx context.Context, userID uuid.UUID) (*AccountStatus, error)
+	// SetStatus refreshes the cached copy after users.status changed.
+	SetStatus(ctx context.Context, userID uuid.UUID, status *AccountStatus) error
+}
+
+type accountStatusRepository struct {
+	db    *db.Queries
+	redis *redisclient.RedisClient
+}
+
+func NewAccountStatusRepository(sqlcQueries *db.Queries, redis *redisclient.RedisClient) AccountStatusRepository {
+	return &accountStatusRepository{db: sqlcQueries, redis: redis}
+}
+
+func accountStatusKey(userID uuid.UUID) string {
+	return fmt.Sprintf("user:status:%s", userID)
+}
+
+func (r *accountStatusRepository) GetStatus(ctx context.Context, userID uuid.UUID) (*AccountStatus, error) {
+	cached, err := r.redis.Get(ctx, accountStatusKey(userID))
+	if err == nil {
+		var status AccountStatus
+		if err := json.Unmarshal([]byte(cached), &status); err == nil {
+			return &status, nil
+		}
+	} else if err != redis.Nil {
+		return nil, fmt.Errorf("failed to read cached account status: %w", err)
+	}
+
+	row, err := r.db.GetUserStatus(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account status: %w", err)
+	}
+
+	status := &AccountStatus{Status: row.Status}
+	if row.SuspendedUntil.Valid {
+		status.SuspendedUntil = &row.SuspendedUntil.Time
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode account status: %w", err)
+	}
+	// SETNX so a value read before a concurrent change can't overwrite the
+	// newer one written by SetStatus.
+	if err := r.redis.Client.SetNX(ctx, accountStatusKey(userID), data, accountStatusCacheTTL).Err(); err != nil {
+		return nil, fmt.Errorf("failed to cache account status: %w", err)
+	}
+
+	return status, nil
+}
+
+func (r *accountStatusRepository) SetStatus(ctx context.Context, userID uuid.UUID, status *AccountStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to encode account status: %w", err)
+	}
+
+	if err := r.redis.Set(ctx, accountStatusKey(userID), data, accountStatusCacheTTL); err != nil {
+		return fmt.Errorf("failed to cache account status: %w", err)
+	}
+	return nil
+}
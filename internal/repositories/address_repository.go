@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+// AddressRepository scopes every lookup to the owner, so an address id of
+// another user is reported as ErrNotFound.
+type AddressRepository interface {
+	CreateAddress(ctx context.Context, param *db.CreateUserAddressParams) (*db.UserAddress, error)
+	GetAddress(ctx context.Context, userID, id uuid.UUID) (*db.UserAddress, error)
+	ListAddresses(ctx context.Context, userID uuid.UUID) ([]db.UserAddress, error)
+	CountAddresses(ctx context.Context, userID uuid.UUID) (int, error)
+	UpdateAddress(ctx context.Context, param *db.UpdateUserAddressParams) (*db.UserAddress, error)
+	SetDefaultAddress(ctx context.Context, userID, id uuid.UUID) error
+	DeleteAddress(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type addressRepository struct {
+	db *db.Queries
+}
+
+func NewAddressRepository(sqlcQueries *db.Queries) AddressRepository {
+	return &addressRepository{db: sqlcQueries}
+}
+
+func (r *addressRepository) CreateAddress(ctx context.Context, param *db.CreateUserAddressParams) (*db.UserAddress, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.CreateUserAddress(ctx, *param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create address: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *addressRepository) GetAddress(ctx context.Context, userID, id uuid.UUID) (*db.UserAddress, error) {
+	res, err := r.db.GetUserAddress(ctx, db.GetUserAddressParams{ID: id, UserID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get address: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *addressRepository) ListAddresses(ctx context.Context, userID uuid.UUID) ([]db.UserAddress, error) {
+	rows, err := r.db.ListUserAddresses(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list addresses: %w", err)
+	}
+	return rows, nil
+}
+
+func (r *addressRepository) CountAddresses(ctx context.Context, userID uuid.UUID) (int, error) {
+	count, err := r.db.CountUserAddresses(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count addresses: %w", err)
+	}
+	return int(count), nil
+}
+
+func (r *addressRepository) UpdateAddress(ctx context.Context, param *db.UpdateUserAddressParams) (*db.UserAddress, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.UpdateUserAddress(ctx, *param)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update address: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *addressRepository) SetDefaultAddress(ctx context.Context, userID, id uuid.UUID) error {
+	rows, err := r.db.SetDefaultUserAddress(ctx, db.SetDefaultUserAddressParams{ID: id, UserID: userID})
+	if err != nil {
+		return fmt.Errorf("failed to set default address: %w", err)
+	}
+	if rows == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *addressRepository) DeleteAddress(ctx context.Context, userID, id uuid.UUID) error {
+	rows, err := r.db.DeleteUserAddress(ctx, db.DeleteUserAddressParams{UserID: userID, ID: id})
+	if err != nil {
+		return fmt.Errorf("failed to delete address: %w", err)
+	}
+	if rows == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
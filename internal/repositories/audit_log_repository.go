@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+// AuditLogRepository reads the audit log. Changes to the database are audited
+// by the queries that make them; CreateAuditLog is for events that change
+// nothing, such as requests made while impersonating a user.
+type AuditLogRepository interface {
+	CreateAuditLog(ctx context.Context, param *db.CreateAuditLogParams) error
+	ListAuditLogs(ctx context.Context, param *db.ListAuditLogsParams) ([]db.AuditLog, error)
+	// ListUserAuditLogs returns every entry about the user or by them.
+	ListUserAuditLogs(ctx context.Context, userID uuid.UUID) ([]db.AuditLog, error)
+}
+
+type auditLogRepository struct {
+	db *db.Queries
+}
+
+func NewAuditLogRepository(sqlcQueries *db.Queries) AuditLogRepository {
+	return &auditLogRepository{db: sqlcQueries}
+}
+
+func (r *auditLogRepository) CreateAuditLog(ctx context.Context, param *db.CreateAuditLogParams) error {
+	if param == nil {
+		return apperrors.ErrInvalidQuery
+	}
+
+	if err := r.db.CreateAuditLog(ctx, *param); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+func (r *auditLogRepository) ListAuditLogs(ctx context.Context, param *db.ListAuditLogsParams) ([]db.AuditLog, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	rows, err := r.db.ListAuditLogs(ctx, *param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	return rows, nil
+}
+
+func (r *auditLogRepository) ListUserAuditLogs(ctx context.Context, userID uuid.UUID) ([]db.AuditLog, error) {
+	rows, err := r.db.ListUserAuditLogs(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user audit log: %w", err)
+	}
+	return rows, nil
+}
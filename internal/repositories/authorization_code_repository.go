@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/redisclient"
+)
+
+// AuthorizationCode is what an OAuth authorization code stands for until the
+// client redeems it at the token endpoint.
+type AuthorizationCode struct {
+	ClientID            string    `json:"client_id"`
+	UserID              string    `json:"user_id"`
+	RedirectURI         string    `json:"redirect_uri"`
+	Scopes              []string  `json:"scopes"`
+	Nonce               string    `json:"nonce"`
+	CodeChallenge       string    `json:"code_challenge"`
+	CodeChallengeMethod string    `json:"code_challenge_method"`
+	AuthTime            time.Time `json:"auth_time"`
+}
+
+type AuthorizationCodeRepository interface {
+	SaveCode(ctx context.Context, code string, data *AuthorizationCode, ttl time.Duration) error
+	// ConsumeCode returns the code's data and deletes it in one step, so a code
+	// can be redeemed at most once.
+	ConsumeCode(ctx context.Context, code string) (*AuthorizationCode, error)
+}
+
+type authorizationCodeRepository struct {
+	redis *redisclient.RedisClient
+}
+
+func NewAuthorizationCodeRepository(redis *redisclient.RedisClient) AuthorizationCodeRepository {
+	return &authorizationCodeRepository{redis: redis}
+}
+
+func authorizationCodeKey(code string) string {
+	return fmt.Sprintf("oauth:code:%s", code)
+}
+
+func (r *authorizationCodeRepository) SaveCode(ctx context.Context, code string, data *AuthorizationCode, ttl time.Duration) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode authorization code: %w", err)
+	}
+
+	if err := r.redis.Set(ctx, authorizationCodeKey(code), payload, ttl); err != nil {
+		return fmt.Errorf("failed to save authorization code: %w", err)
+	}
+	return nil
+}
+
+func (r *authorizationCodeRepository) ConsumeCode(ctx context.Context, code string) (*AuthorizationCode, error) {
+	payload, err := r.redis.Client.GetDel(ctx, authorizationCodeKey(code)).Bytes()
+	if err == redis.Nil {
+		return nil, apperrors.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+
+	var data AuthorizationCode
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode authorization code: %w", err)
+	}
+	return &data, nil
+}
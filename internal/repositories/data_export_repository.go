@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+type DataExportRepository interface {
+	CreateExport(ctx context.Context, id, userID uuid.UUID) (*db.DataExport, error)
+	GetExport(ctx context.Context, id uuid.UUID) (*db.DataExport, error)
+	ListExports(ctx context.Context, userID uuid.UUID) ([]db.DataExport, error)
+	// StartExport returns ErrNotFound when the export is already finished.
+	StartExport(ctx context.Context, id uuid.UUID) (*db.DataExport, error)
+	CompleteExport(ctx context.Context, id uuid.UUID, filePath string, expiresAt time.Time) error
+	FailExport(ctx context.Context, id uuid.UUID) error
+	ListExpiredExports(ctx context.Context, now time.Time, limit int) ([]db.DataExport, error)
+	ExpireExport(ctx context.Context, id uuid.UUID) error
+}
+
+type dataExportRepository struct {
+	db *db.Queries
+}
+
+func NewDataExportRepository(sqlcQueries *db.Queries) DataExportRepository {
+	return &dataExportRepository{db: sqlcQueries}
+}
+
+func (r *dataExportRepository) CreateExport(ctx context.Context, id, userID uuid.UUID) (*db.DataExport, error) {
+	res, err := r.db.CreateDataExport(ctx, db.CreateDataExportParams{ID: id, UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data export: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *dataExportRepository) GetExport(ctx context.Context, id uuid.UUID) (*db.DataExport, error) {
+	res, err := r.db.GetDataExport(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *dataExportRepository) ListExports(ctx context.Context, userID uuid.UUID) ([]db.DataExport, error) {
+	res, err := r.db.ListDataExports(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data exports: %w", err)
+	}
+	return res, nil
+}
+
+func (r *dataExportRepository) StartExport(ctx context.Context, id uuid.UUID) (*db.DataExport, error) {
+	res, err := r.db.StartDataExport(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to start data export: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *dataExportRepository) CompleteExport(ctx context.Context, id uuid.UUID, filePath string, expiresAt time.Time) error {
+	err := r.db.CompleteDataExport(ctx, db.CompleteDataExportParams{
+		ID:        id,
+		FilePath:  filePath,
+		ExpiresAt: sql.NullTime{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete data export: %w", err)
+	}
+	return nil
+}
+
+func (r *dataExportRepository) FailExport(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.FailDataExport(ctx, id); err != nil {
+		return fmt.Errorf("failed to mark data export failed: %w", err)
+	}
+	return nil
+}
+
+func (r *dataExportRepository) ListExpiredExports(ctx context.Context, now time.Time, limit int) ([]db.DataExport, error) {
+	res, err := r.db.ListExpiredDataExports(ctx, db.ListExpiredDataExportsParams{
+		ExpiresAt: sql.NullTime{Time: now, Valid: true},
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired data exports: %w", err)
+	}
+	return res, nil
+}
+
+func (r *dataExportRepository) ExpireExport(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.ExpireDataExport(ctx, id); err != nil {
+		return fmt.Errorf("failed to expire data export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+type EmailVerificationRepository interface {
+	// ReplaceToken invalidates the user's earlier verification links and
+	// stores the new one.
+	ReplaceToken(ctx context.Context, param *db.CreateEmailVerificationTokenParams) error
+	// UseToken marks an unexpired token as used and returns the user and the
+	// address it was issued for.
+	UseToken(ctx context.Context, tokenHash string) (*db.UseEmailVerificationTokenRow, error)
+	// MarkVerified returns ErrInvalidToken when the user's email is no longer
+	// the one the token was issued for.
+	MarkVerified(ctx context.Context, userID uuid.UUID, email string) error
+	// ActivatePendingUser moves a pending_verification user to active and
+	// reports whether it did.
+	ActivatePendingUser(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+type emailVerificationRepository struct {
+	db *db.Queries
+}
+
+func NewEmailVerificationRepository(sqlcQueries *db.Queries) EmailVerificationRepository {
+	return &emailVerificationRepository{db: sqlcQueries}
+}
+
+func (r *emailVerificationRepository) ReplaceToken(ctx context.Context, param *db.CreateEmailVerificationTokenParams) error {
+	if param == nil {
+		return apperrors.ErrInvalidQuery
+	}
+
+	if err := r.db.DeleteEmailVerificationTokens(ctx, param.UserID); err != nil {
+		return fmt.Errorf("failed to delete email verification tokens: %w", err)
+	}
+
+	if err := r.db.CreateEmailVerificationToken(ctx, *param); err != nil {
+		return fmt.Errorf("failed to create email verification token: %w", err)
+	}
+	return nil
+}
+
+func (r *emailVerificationRepository) UseToken(ctx context.Context, tokenHash string) (*db.UseEmailVerificationTokenRow, error) {
+	res, err := r.db.UseEmailVerificationToken(ctx, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to use email verification token: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (r *emailVerificationRepository) MarkVerified(ctx context.Context, userID uuid.UUID, email string) error {
+	rows, err := r.db.MarkEmailVerified(ctx, db.MarkEmailVerifiedParams{ID: userID, Email: email})
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	if rows == 0 {
+		return apperrors.ErrInvalidToken
+	}
+	return nil
+}
+
+func (r *emailVerificationRepository) ActivatePendingUser(ctx context.Context, userID uuid.UUID) (bool, error) {
+	rows, err := r.db.ActivatePendingUser(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to activate user: %w", err)
+	}
+	return rows > 0, nil
+}
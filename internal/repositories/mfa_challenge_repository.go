@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/redisclient"
+)
+
+// An MFA challenge is the state between a correct password and a correct
+// second factor.
+//
+//	mfa_challenge:<token> hash {user_id, attempts}
+type MFAChallengeRepository interface {
+	CreateChallenge(ctx context.Context, challengeToken string, userID string, ttl time.Duration) error
+	GetChallengeUserID(ctx context.Context, challengeToken string) (string, error)
+	// IncrementAttempts counts a verification attempt and returns the total.
+	IncrementAttempts(ctx context.Context, challengeToken string) (int64, error)
+	DeleteChallenge(ctx context.Context, challengeToken string) error
+}
+
+type mfaChallengeRepository struct {
+	redis *redisclient.RedisClient
+}
+
+func NewMFAChallengeRepository(redis *redisclient.RedisClient) MFAChallengeRepository {
+	return &mfaChallengeRepository{redis: redis}
+}
+
+func mfaChallengeKey(challengeToken string) string {
+	return fmt.Sprintf("mfa_challenge:%s", challengeToken)
+}
+
+func (r *mfaChallengeRepository) CreateChallenge(ctx context.Context, challengeToken string, userID string, ttl time.Duration) error {
+	_, err := r.redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, mfaChallengeKey(challengeToken), "user_id", userID, "attempts", 0)
+		pipe.Expire(ctx, mfaChallengeKey(challengeToken), ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create mfa challenge: %w", err)
+	}
+	return nil
+}
+
+func (r *mfaChallengeRepository) GetChallengeUserID(ctx context.Context, challengeToken string) (string, error) {
+	userID, err := r.redis.Client.HGet(ctx, mfaChallengeKey(challengeToken), "user_id").Result()
+	if err == redis.Nil {
+		return "", apperrors.ErrInvalidToken
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get mfa challenge: %w", err)
+	}
+	return userID, nil
+}
+
+// incrementAttemptsScript avoids HINCRBY recreating an expired challenge
+// without a TTL.
+var incrementAttemptsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+return redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+`)
+
+func (r *mfaChallengeRepository) IncrementAttempts(ctx context.Context, challengeToken string) (int64, error) {
+	attempts, err := incrementAttemptsScript.Run(ctx, r.redis.Client, []string{mfaChallengeKey(challengeToken)}).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count mfa attempt: %w", err)
+	}
+	if attempts < 0 {
+		return 0, apperrors.ErrInvalidToken
+	}
+	return attempts, nil
+}
+
+func (r *mfaChallengeRepository) DeleteChallenge(ctx context.Context, challengeToken string) error {
+	if err := r.redis.Del(ctx, mfaChallengeKey(challengeToken)); err != nil {
+		return fmt.Errorf("failed to delete mfa challenge: %w", err)
+	}
+	return nil
+}
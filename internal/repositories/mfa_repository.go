@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+type MFARepository interface {
+	GetMFA(ctx context.Context, userID uuid.UUID) (*db.UserMfa, error)
+	// SavePendingMFA stores a new, unconfirmed secret. It fails with
+	// ErrMFAAlreadyEnabled instead of overwriting a confirmed one.
+	SavePendingMFA(ctx context.Context, param *db.UpsertPendingUserMFAParams) (*db.UserMfa, error)
+	EnableMFA(ctx context.Context, param *db.EnableUserMFAParams) (*db.UserMfa, error)
+	// UseTOTPStep records step as used and reports false if it (or a later
+	// step) was already used, which makes every code single use.
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	DeleteMFA(ctx context.Context, userID uuid.UUID) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	ListRequiredRoles(ctx context.Context) ([]string, error)
+	AddRequiredRole(ctx context.Context, role string) error
+	RemoveRequiredRole(ctx context.Context, role string) error
+	// IsRequiredForUser checks every role the user holds, not just the
+	// primary one.
+	IsRequiredForUser(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+type mfaRepository struct {
+	db *db.Queries
+}
+
+func NewMFARepository(sqlcQueries *db.Queries) MFARepository {
+	return &mfaRepository{db: sqlcQueries}
+}
+
+func (r *mfaRepository) GetMFA(ctx context.Context, userID uuid.UUID) (*db.UserMfa, error) {
+	res, err := r.db.GetUserMFA(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user mfa: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (r *mfaRepository) SavePendingMFA(ctx context.Context, param *db.UpsertPendingUserMFAParams) (*db.UserMfa, error) {
+	res, err := r.db.UpsertPendingUserMFA(ctx, *param)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrMFAAlreadyEnabled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save user mfa: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (r *mfaRepository) EnableMFA(ctx context.Context, param *db.EnableUserMFAParams) (*db.UserMfa, error) {
+	res, err := r.db.EnableUserMFA(ctx, *param)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrMFAAlreadyEnabled
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable user mfa: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (r *mfaRepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	rows, err := r.db.UseTOTPStep(ctx, db.UseTOTPStepParams{UserID: userID, LastUsedStep: step})
+	if err != nil {
+		return false, fmt.Errorf("failed to record totp step: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (r *mfaRepository) DeleteMFA(ctx context.Context, userID uuid.UUID) error {
+	if err := r.db.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if err := r.db.DeleteUserMFA(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete user mfa: %w", err)
+	}
+	return nil
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	if err := r.db.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	err := r.db.CreateRecoveryCodes(ctx, db.CreateRecoveryCodesParams{UserID: userID, CodeHashes: codeHashes})
+	if err != nil {
+		return fmt.Errorf("failed to create recovery codes: %w", err)
+	}
+	return nil
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	rows, err := r.db.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{UserID: userID, CodeHash: codeHash})
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (r *mfaRepository) CountUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	count, err := r.db.CountUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *mfaRepository) ListRequiredRoles(ctx context.Context) ([]string, error) {
+	roles, err := r.db.ListMFARequiredRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mfa required roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (r *mfaRepository) AddRequiredRole(ctx context.Context, role string) error {
+	if err := r.db.AddMFARequiredRole(ctx, role); err != nil {
+		return fmt.Errorf("failed to add mfa required role: %w", err)
+	}
+	return nil
+}
+
+func (r *mfaRepository) RemoveRequiredRole(ctx context.Context, role string) error {
+	rows, err := r.db.DeleteMFARequiredRole(ctx, role)
+	if err != nil {
+		return fmt.Errorf("failed to remove mfa required role: %w", err)
+	}
+	if rows == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mfaRepository) IsRequiredForUser(ctx context.Context, userID uuid.UUID) (bool, error) {
+	required, err := r.db.IsMFARequiredForUser(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check mfa role requirement: %w", err)
+	}
+
+	return required, nil
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+type OAuthClientRepository interface {
+	CreateClient(ctx context.Context, param *db.CreateOAuthClientParams) (*db.OauthClient, error)
+	GetClient(ctx context.Context, clientID string) (*db.OauthClient, error)
+	ListClients(ctx context.Context) ([]db.OauthClient, error)
+	DeleteClient(ctx context.Context, clientID string) (*db.OauthClient, error)
+	GetConsent(ctx context.Context, userID uuid.UUID, clientID string) (*db.OauthConsent, error)
+	SaveConsent(ctx context.Context, param *db.UpsertOAuthConsentParams) (*db.OauthConsent, error)
+	ListConsents(ctx context.Context, userID uuid.UUID) ([]db.OauthConsent, error)
+}
+
+type oauthClientRepository struct {
+	db *db.Queries
+}
+
+func NewOAuthClientRepository(sqlcQueries *db.Queries) OAuthClientRepository {
+	return &oauthClientRepository{db: sqlcQueries}
+}
+
+func (r *oauthClientRepository) CreateClient(ctx context.Context, param *db.CreateOAuthClientParams) (*db.OauthClient, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.CreateOAuthClient(ctx, *param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create oauth client: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (r *oauthClientRepository) GetClient(ctx context.Context, clientID string) (*db.OauthClient, error) {
+	res, err := r.db.GetOAuthClient(ctx, clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oauth client: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (r *oauthClientRepository) ListClients(ctx context.Context) ([]db.OauthClient, error) {
+	rows, err := r.db.ListOAuthClients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list oauth clients: %w", err)
+	}
+
+	return rows, nil
+}
+
+func (r *oauthClientRepository) DeleteClient(ctx context.Context, clientID string) (*db.OauthClient, error) {
+	res, err := r.db.DeleteOAuthClient(ctx, clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete oauth client: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (r *oauthClientRepository) GetConsent(ctx context.Context, userID uuid.UUID, clientID string) (*db.OauthConsent, error) {
+	res, err := r.db.GetOAuthConsent(ctx, db.GetOAuthConsentParams{UserID: userID, ClientID: clientID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get oauth consent: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (r *oauthClientRepository) SaveConsent(ctx context.Context, param *db.UpsertOAuthConsentParams) (*db.OauthConsent, error) {
+	res, err := r.db.UpsertOAuthConsent(ctx, *param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save oauth consent: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (r *oauthClientRepository) ListConsents(ctx context.Context, userID uuid.UUID) ([]db.OauthConsent, error) {
+	res, err := r.db.ListOAuthConsents(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list oauth consents: %w", err)
+	}
+	return res, nil
+}
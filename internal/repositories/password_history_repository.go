@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+)
+
+type PasswordHistoryRepository interface {
+	// Recent returns up to limit of the user's previous password hashes,
+	// newest first.
+	Recent(ctx context.Context, userID uuid.UUID, limit int) ([]string, error)
+	// Add records a replaced password hash and keeps only the newest keep
+	// entries.
+	Add(ctx context.Context, userID uuid.UUID, passwordHash string, keep int) error
+}
+
+type passwordHistoryRepository struct {
+	db *db.Queries
+}
+
+func NewPasswordHistoryRepository(sqlcQueries *db.Queries) PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: sqlcQueries}
+}
+
+func (r *passwordHistoryRepository) Recent(ctx context.Context, userID uuid.UUID, limit int) ([]string, error) {
+	hashes, err := r.db.ListPasswordHistory(ctx, db.ListPasswordHistoryParams{UserID: userID, Limit: int32(limit)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list password history: %w", err)
+	}
+	return hashes, nil
+}
+
+func (r *passwordHistoryRepository) Add(ctx context.Context, userID uuid.UUID, passwordHash string, keep int) error {
+	err := r.db.CreatePasswordHistory(ctx, db.CreatePasswordHistoryParams{UserID: userID, PasswordHash: passwordHash})
+	if err != nil {
+		return fmt.Errorf("failed to add password history: %w", err)
+	}
+
+	err = r.db.PrunePasswordHistory(ctx, db.PrunePasswordHistoryParams{UserID: userID, Limit: int32(keep)})
+	if err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+type PasswordResetRepository interface {
+	// ReplaceToken invalidates the user's earlier reset links and stores the
+	// new one.
+	ReplaceToken(ctx context.Context, param *db.CreatePasswordResetTokenParams) error
+	// FindToken returns the user of an unexpired, unused token without using
+	// it up.
+	FindToken(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	// UseToken marks an unexpired token as used and returns its user.
+	UseToken(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	DeleteTokens(ctx context.Context, userID uuid.UUID) error
+}
+
+type passwordResetRepository struct {
+	db *db.Queries
+}
+
+func NewPasswordResetRepository(sqlcQueries *db.Queries) PasswordResetRepository {
+	return &passwordResetRepository{db: sqlcQueries}
+}
+
+func (r *passwordResetRepository) ReplaceToken(ctx context.Context, param *db.CreatePasswordResetTokenParams) error {
+	if param == nil {
+		return apperrors.ErrInvalidQuery
+	}
+
+	if err := r.db.DeletePasswordResetTokens(ctx, param.UserID); err != nil {
+		return fmt.Errorf("failed to delete password reset tokens: %w", err)
+	}
+
+	if err := r.db.CreatePasswordResetToken(ctx, *param); err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+	return nil
+}
+
+func (r *passwordResetRepository) FindToken(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	userID, err := r.db.GetPasswordResetToken(ctx, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, apperrors.ErrInvalidToken
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (r *passwordResetRepository) UseToken(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	userID, err := r.db.UsePasswordResetToken(ctx, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, apperrors.ErrInvalidToken
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to use password reset token: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (r *passwordResetRepository) DeleteTokens(ctx context.Context, userID uuid.UUID) error {
+	if err := r.db.DeletePasswordResetTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete password reset tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/redisclient"
+)
+
+// A phone OTP is a one-time code sent by SMS and not entered yet. The key says
+// what the code is for, such as "verify:<user_id>" or "login:<phone_number>";
+// sending a new code for the same key replaces the old one. Wrong codes are
+// also counted per phone number, across codes, until the window runs out.
+//
+//	phone_otp:<key> hash {phone_number, code_hash, attempts}
+//	phone_otp_failures:<phone_number> = count with the window as TTL
+type PhoneOTPRecord struct {
+	PhoneNumber string
+	CodeHash    string
+}
+
+type PhoneOTPRepository interface {
+	CreateCode(ctx context.Context, key string, phoneNumber string, codeHash string, ttl time.Duration) error
+	// GetCode returns ErrInvalidToken when no code is waiting.
+	GetCode(ctx context.Context, key string) (*PhoneOTPRecord, error)
+	// IncrementAttempts counts an entry of the code and returns the total.
+	IncrementAttempts(ctx context.Context, key string) (int64, error)
+	DeleteCode(ctx context.Context, key string) error
+	// CountFailure counts a wrong code for phoneNumber and returns the total
+	// in the current window, which starts with the first failure.
+	CountFailure(ctx context.Context, phoneNumber string, window time.Duration) (int64, error)
+	// Failures returns the wrong codes counted for phoneNumber in the current
+	// window.
+	Failures(ctx context.Context, phoneNumber string) (int64, error)
+}
+
+type phoneOTPRepository struct {
+	redis *redisclient.RedisClient
+}
+
+func NewPhoneOTPRepository(redis *redisclient.RedisClient) PhoneOTPRepository {
+	return &phoneOTPRepository{redis: redis}
+}
+
+func phoneOTPKey(key string) string {
+	return fmt.Sprintf("phone_otp:%s", key)
+}
+
+func phoneOTPFailuresKey(phoneNumber string) string {
+	return fmt.Sprintf("phone_otp_failures:%s", phoneNumber)
+}
+
+func (r *phoneOTPRepository) CreateCode(ctx context.Context, key string, phoneNumber string, codeHash string, ttl time.Duration) error {
+	_, err := r.redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, phoneOTPKey(key))
+		pipe.HSet(ctx, phoneOTPKey(key), "phone_number", phoneNumber, "code_hash", codeHash, "attempts", 0)
+		pipe.Expire(ctx, phoneOTPKey(key), ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create phone otp: %w", err)
+	}
+	return nil
+}
+
+func (r *phoneOTPRepository) GetCode(ctx context.Context, key string) (*PhoneOTPRecord, error) {
+	data, err := r.redis.Client.HGetAll(ctx, phoneOTPKey(key)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get phone otp: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	return &PhoneOTPRecord{PhoneNumber: data["phone_number"], CodeHash: data["code_hash"]}, nil
+}
+
+func (r *phoneOTPRepository) IncrementAttempts(ctx context.Context, key string) (int64, error) {
+	attempts, err := incrementAttemptsScript.Run(ctx, r.redis.Client, []string{phoneOTPKey(key)}).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count phone otp attempt: %w", err)
+	}
+	if attempts < 0 {
+		return 0, apperrors.ErrInvalidToken
+	}
+	return attempts, nil
+}
+
+func (r *phoneOTPRepository) DeleteCode(ctx context.Context, key string) error {
+	if err := r.redis.Del(ctx, phoneOTPKey(key)); err != nil {
+		return fmt.Errorf("failed to delete phone otp: %w", err)
+	}
+	return nil
+}
+
+func (r *phoneOTPRepository) CountFailure(ctx context.Context, phoneNumber string, window time.Duration) (int64, error) {
+	failures, err := r.redis.Client.Incr(ctx, phoneOTPFailuresKey(phoneNumber)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count phone otp failure: %w", err)
+	}
+	if failures == 1 {
+		if err := r.redis.Client.Expire(ctx, phoneOTPFailuresKey(phoneNumber), window).Err(); err != nil {
+			return 0, fmt.Errorf("failed to count phone otp failure: %w", err)
+		}
+	}
+	return failures, nil
+}
+
+func (r *phoneOTPRepository) Failures(ctx context.Context, phoneNumber string) (int64, error) {
+	failures, err := r.redis.Client.Get(ctx, phoneOTPFailuresKey(phoneNumber)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get phone otp failures: %w", err)
+	}
+	return failures, nil
+}
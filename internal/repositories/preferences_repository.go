@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+type PreferencesRepository interface {
+	// GetPreferences returns ErrNotFound for users who never changed a
+	// preference.
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*db.UserPreference, error)
+	UpsertPreferences(ctx context.Context, param *db.UpsertUserPreferencesParams) (*db.UserPreference, error)
+}
+
+type preferencesRepository struct {
+	db *db.Queries
+}
+
+func NewPreferencesRepository(sqlcQueries *db.Queries) PreferencesRepository {
+	return &preferencesRepository{db: sqlcQueries}
+}
+
+func (r *preferencesRepository) GetPreferences(ctx context.Context, userID uuid.UUID) (*db.UserPreference, error) {
+	res, err := r.db.GetUserPreferences(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *preferencesRepository) UpsertPreferences(ctx context.Context, param *db.UpsertUserPreferencesParams) (*db.UserPreference, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.UpsertUserPreferences(ctx, *param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return &res, nil
+}
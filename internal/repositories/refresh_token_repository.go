@@ -5,13 +5,31 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/go-redis/redis/v8"
+
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/redisclient"
 )
 
+// Every login starts a refresh token family. Rotating a token marks it as
+// "rotated" instead of deleting it, so presenting it again can be recognised as
+// reuse and the whole family revoked.
+//
+//	refresh_token:<token>   hash {user_id, family_id, status}
+//	refresh_family:<family> hash {user_id, current, revoked}
+const refreshTokenStatusActive = "active"
+
+type RefreshTokenRecord struct {
+	UserID   string
+	FamilyID string
+}
+
 type RefreshTokenRepository interface {
-	StoreRefreshToken(ctx context.Context, userID string, refreshToken string, ttl time.Duration) error
-	ValidateRefreshToken(ctx context.Context, refreshToken string) (string, error)
+	StoreRefreshToken(ctx context.Context, userID string, familyID string, refreshToken string, ttl time.Duration) error
+	GetRefreshToken(ctx context.Context, refreshToken string) (*RefreshTokenRecord, error)
+	RotateRefreshToken(ctx context.Context, oldToken string, newToken string, ttl time.Duration) (*RefreshTokenRecord, error)
 	RevokeRefreshToken(ctx context.Context, refreshToken string) error
+	RevokeFamily(ctx context.Context, familyID string) error
 }
 
 type refreshTokenRepo struct {
@@ -24,22 +42,116 @@ func NewRefreshTokenRepository(redis *redisclient.RedisClient) RefreshTokenRepos
 	}
 }
 
-func (r *refreshTokenRepo) StoreRefreshToken(ctx context.Context, userID string, refreshToken string, ttl time.Duration) error {
-	// Key = Token, Value = UserID
-	key := fmt.Sprintf("refresh_token:%s", refreshToken)
-	return r.redis.Set(ctx, key, userID, ttl)
+func refreshTokenKey(refreshToken string) string {
+	return fmt.Sprintf("refresh_token:%s", refreshToken)
+}
+
+func refreshFamilyKey(familyID string) string {
+	return fmt.Sprintf("refresh_family:%s", familyID)
+}
+
+// rotateRefreshTokenScript returns {result, user_id, family_id}; result is one
+// of "ok", "reused" or "invalid".
+var rotateRefreshTokenScript = redis.NewScript(`
+local data = redis.call('HMGET', KEYS[1], 'user_id', 'family_id', 'status')
+if not data[1] then
+	return {'invalid', '', ''}
+end
+
+local familyKey = 'refresh_family:' .. data[2]
+local family = redis.call('HMGET', familyKey, 'current', 'revoked')
+if not family[1] or family[2] == '1' then
+	return {'invalid', data[1], data[2]}
+end
+
+if data[3] ~= 'active' then
+	redis.call('HSET', familyKey, 'revoked', '1')
+	redis.call('DEL', 'refresh_token:' .. family[1])
+	return {'reused', data[1], data[2]}
+end
+
+local newKey = 'refresh_token:' .. ARGV[1]
+redis.call('HSET', KEYS[1], 'status', 'rotated')
+redis.call('HSET', newKey, 'user_id', data[1], 'family_id', data[2], 'status', 'active')
+redis.call('HSET', familyKey, 'current', ARGV[1])
+redis.call('EXPIRE', KEYS[1], ARGV[2])
+redis.call('EXPIRE', newKey, ARGV[2])
+redis.call('EXPIRE', familyKey, ARGV[2])
+return {'ok', data[1], data[2]}
+`)
+
+func (r *refreshTokenRepo) StoreRefreshToken(ctx context.Context, userID string, familyID string, refreshToken string, ttl time.Duration) error {
+	_, err := r.redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, refreshTokenKey(refreshToken), "user_id", userID, "family_id", familyID, "status", refreshTokenStatusActive)
+		pipe.Expire(ctx, refreshTokenKey(refreshToken), ttl)
+		pipe.HSet(ctx, refreshFamilyKey(familyID), "user_id", userID, "current", refreshToken, "revoked", "0")
+		pipe.Expire(ctx, refreshFamilyKey(familyID), ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return nil
+}
+
+func (r *refreshTokenRepo) GetRefreshToken(ctx context.Context, refreshToken string) (*RefreshTokenRecord, error) {
+	data, err := r.redis.Client.HGetAll(ctx, refreshTokenKey(refreshToken)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	return &RefreshTokenRecord{UserID: data["user_id"], FamilyID: data["family_id"]}, nil
 }
 
-func (r *refreshTokenRepo) ValidateRefreshToken(ctx context.Context, refreshToken string) (string, error) {
-	key := fmt.Sprintf("refresh_token:%s", refreshToken)
-	userID, err := r.redis.Get(ctx, key)
+func (r *refreshTokenRepo) RotateRefreshToken(ctx context.Context, oldToken string, newToken string, ttl time.Duration) (*RefreshTokenRecord, error) {
+	res, err := rotateRefreshTokenScript.Run(ctx, r.redis.Client, []string{refreshTokenKey(oldToken)}, newToken, int64(ttl.Seconds())).StringSlice()
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	record := &RefreshTokenRecord{UserID: res[1], FamilyID: res[2]}
+	switch res[0] {
+	case "ok":
+		return record, nil
+	case "reused":
+		return record, apperrors.ErrRefreshTokenReused
+	default:
+		return nil, apperrors.ErrInvalidToken
 	}
-	return userID, nil
 }
 
+// RevokeRefreshToken ends the session the token belongs to, not just the token.
 func (r *refreshTokenRepo) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
-	key := fmt.Sprintf("refresh_token:%s", refreshToken)
-	return r.redis.Del(ctx, key)
+	familyID, err := r.redis.Client.HGet(ctx, refreshTokenKey(refreshToken), "family_id").Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return r.RevokeFamily(ctx, familyID)
+}
+
+func (r *refreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	current, err := r.redis.Client.HGet(ctx, refreshFamilyKey(familyID), "current").Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	_, err = r.redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, refreshFamilyKey(familyID), "revoked", "1")
+		pipe.Del(ctx, refreshTokenKey(current))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+// RoleGrantRepository grants and revokes roles. Every change is written to the
+// audit log by the same statement that makes it.
+type RoleGrantRepository interface {
+	// GrantRole returns ErrRoleAlreadyGranted when the user already has the
+	// role.
+	GrantRole(ctx context.Context, param *db.GrantUserRoleParams) (*db.UserRole, error)
+	// RevokeRole returns false when the user did not have the role.
+	RevokeRole(ctx context.Context, param *db.RevokeUserRoleParams) (bool, error)
+
+	// CreateRequest returns ErrRoleGrantPending when the same grant is already
+	// waiting for approval.
+	CreateRequest(ctx context.Context, param *db.CreateRoleGrantRequestParams) (*db.RoleGrantRequest, error)
+	GetRequest(ctx context.Context, id uuid.UUID) (*db.RoleGrantRequest, error)
+	ListRequests(ctx context.Context, param *db.ListRoleGrantRequestsParams) ([]db.RoleGrantRequest, error)
+	// ApproveRequest and RejectRequest return ErrRoleGrantDecided when the
+	// request is no longer pending.
+	ApproveRequest(ctx context.Context, param *db.ApproveRoleGrantRequestParams) (*db.RoleGrantRequest, error)
+	RejectRequest(ctx context.Context, param *db.RejectRoleGrantRequestParams) (*db.RoleGrantRequest, error)
+}
+
+type roleGrantRepository struct {
+	db *db.Queries
+}
+
+func NewRoleGrantRepository(sqlcQueries *db.Queries) RoleGrantRepository {
+	return &roleGrantRepository{db: sqlcQueries}
+}
+
+func (r *roleGrantRepository) GrantRole(ctx context.Context, param *db.GrantUserRoleParams) (*db.UserRole, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.GrantUserRole(ctx, *param)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrRoleAlreadyGranted
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to grant role: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *roleGrantRepository) RevokeRole(ctx context.Context, param *db.RevokeUserRoleParams) (bool, error) {
+	if param == nil {
+		return false, apperrors.ErrInvalidQuery
+	}
+
+	changes, err := r.db.RevokeUserRole(ctx, *param)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke role: %w", err)
+	}
+	return changes > 0, nil
+}
+
+func (r *roleGrantRepository) CreateRequest(ctx context.Context, param *db.CreateRoleGrantRequestParams) (*db.RoleGrantRequest, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.CreateRoleGrantRequest(ctx, *param)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, apperrors.ErrRoleGrantPending
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create role grant request: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *roleGrantRepository) GetRequest(ctx context.Context, id uuid.UUID) (*db.RoleGrantRequest, error) {
+	res, err := r.db.GetRoleGrantRequest(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role grant request: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *roleGrantRepository) ListRequests(ctx context.Context, param *db.ListRoleGrantRequestsParams) ([]db.RoleGrantRequest, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	rows, err := r.db.ListRoleGrantRequests(ctx, *param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role grant requests: %w", err)
+	}
+	return rows, nil
+}
+
+func (r *roleGrantRepository) ApproveRequest(ctx context.Context, param *db.ApproveRoleGrantRequestParams) (*db.RoleGrantRequest, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.ApproveRoleGrantRequest(ctx, *param)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrRoleGrantDecided
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve role grant request: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *roleGrantRepository) RejectRequest(ctx context.Context, param *db.RejectRoleGrantRequestParams) (*db.RoleGrantRequest, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.RejectRoleGrantRequest(ctx, *param)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrRoleGrantDecided
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject role grant request: %w", err)
+	}
+	return &res, nil
+}
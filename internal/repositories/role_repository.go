@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+type RoleRepository interface {
+	ListRoles(ctx context.Context) ([]db.ListRolesRow, error)
+	GetRole(ctx context.Context, name string) (*db.GetRoleRow, error)
+	// CreateRole returns ErrRoleAlreadyExists when the name is taken.
+	CreateRole(ctx context.Context, param *db.CreateRoleParams) (*db.Role, error)
+	UpdateRole(ctx context.Context, param *db.UpdateRoleParams) (*db.Role, error)
+	// DeleteRole returns false when the role is a system role, still someone's
+	// primary role or does not exist.
+	DeleteRole(ctx context.Context, param *db.DeleteRoleParams) (bool, error)
+
+	ListPermissions(ctx context.Context) ([]db.Permission, error)
+	GetPermission(ctx context.Context, name string) (*db.Permission, error)
+	// CreatePermission returns ErrPermissionExists when the name is taken.
+	CreatePermission(ctx context.Context, param *db.CreatePermissionParams) (*db.Permission, error)
+	// DeletePermission returns false when the permission is a system
+	// permission or does not exist.
+	DeletePermission(ctx context.Context, param *db.DeletePermissionParams) (bool, error)
+
+	// ListRoleHolders and ListPermissionHolders return the users whose tokens
+	// embed the role or permission, primary roles included.
+	ListRoleHolders(ctx context.Context, role string) ([]uuid.UUID, error)
+	ListPermissionHolders(ctx context.Context, permission string) ([]uuid.UUID, error)
+
+	// ListUserRoles and ListUserPermissions include the user's primary role.
+	ListUserRoles(ctx context.Context, userID uuid.UUID) ([]string, error)
+	ListUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error)
+}
+
+type roleRepository struct {
+	db *db.Queries
+}
+
+func NewRoleRepository(sqlcQueries *db.Queries) RoleRepository {
+	return &roleRepository{db: sqlcQueries}
+}
+
+func (r *roleRepository) ListRoles(ctx context.Context) ([]db.ListRolesRow, error) {
+	rows, err := r.db.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return rows, nil
+}
+
+func (r *roleRepository) GetRole(ctx context.Context, name string) (*db.GetRoleRow, error) {
+	res, err := r.db.GetRole(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *roleRepository) CreateRole(ctx context.Context, param *db.CreateRoleParams) (*db.Role, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.CreateRole(ctx, *param)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, apperrors.ErrRoleAlreadyExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *roleRepository) UpdateRole(ctx context.Context, param *db.UpdateRoleParams) (*db.Role, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.UpdateRole(ctx, *param)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *roleRepository) DeleteRole(ctx context.Context, param *db.DeleteRoleParams) (bool, error) {
+	if param == nil {
+		return false, apperrors.ErrInvalidQuery
+	}
+
+	n, err := r.db.DeleteRole(ctx, *param)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete role: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *roleRepository) ListPermissions(ctx context.Context) ([]db.Permission, error) {
+	rows, err := r.db.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+	return rows, nil
+}
+
+func (r *roleRepository) GetPermission(ctx context.Context, name string) (*db.Permission, error) {
+	res, err := r.db.GetPermission(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permission: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *roleRepository) CreatePermission(ctx context.Context, param *db.CreatePermissionParams) (*db.Permission, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.CreatePermission(ctx, *param)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, apperrors.ErrPermissionExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create permission: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *roleRepository) DeletePermission(ctx context.Context, param *db.DeletePermissionParams) (bool, error) {
+	if param == nil {
+		return false, apperrors.ErrInvalidQuery
+	}
+
+	n, err := r.db.DeletePermission(ctx, *param)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete permission: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *roleRepository) ListRoleHolders(ctx context.Context, role string) ([]uuid.UUID, error) {
+	holders, err := r.db.ListRoleHolders(ctx, role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role holders: %w", err)
+	}
+	return holders, nil
+}
+
+func (r *roleRepository) ListPermissionHolders(ctx context.Context, permission string) ([]uuid.UUID, error) {
+	holders, err := r.db.ListPermissionHolders(ctx, permission)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permission holders: %w", err)
+	}
+	return holders, nil
+}
+
+func (r *roleRepository) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	roles, err := r.db.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (r *roleRepository) ListUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	permissions, err := r.db.ListUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user permissions: %w", err)
+	}
+	return permissions, nil
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+type SellerApplicationRepository interface {
+	// CreateApplication returns ErrSellerApplicationOpen when the user already
+	// has a pending application.
+	CreateApplication(ctx context.Context, param *db.CreateSellerApplicationParams) (*db.SellerApplication, error)
+	GetApplication(ctx context.Context, id uuid.UUID) (*db.SellerApplication, error)
+	ListUserApplications(ctx context.Context, userID uuid.UUID) ([]db.SellerApplication, error)
+	ListApplications(ctx context.Context, param *db.ListSellerApplicationsParams) ([]db.SellerApplication, error)
+	ListDocuments(ctx context.Context, applicationID uuid.UUID) ([]db.ListSellerApplicationDocumentsRow, error)
+	GetDocument(ctx context.Context, applicationID uuid.UUID, kind string) (*db.SellerApplicationDocument, error)
+	// DecideApplication returns ErrApplicationDecided when the application is
+	// no longer pending.
+	DecideApplication(ctx context.Context, param *db.DecideSellerApplicationParams) (*db.SellerApplication, error)
+}
+
+type sellerApplicationRepository struct {
+	db *db.Queries
+}
+
+func NewSellerApplicationRepository(sqlcQueries *db.Queries) SellerApplicationRepository {
+	return &sellerApplicationRepository{db: sqlcQueries}
+}
+
+func (r *sellerApplicationRepository) CreateApplication(ctx context.Context, param *db.CreateSellerApplicationParams) (*db.SellerApplication, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.CreateSellerApplication(ctx, *param)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, apperrors.ErrSellerApplicationOpen
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create seller application: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *sellerApplicationRepository) GetApplication(ctx context.Context, id uuid.UUID) (*db.SellerApplication, error) {
+	res, err := r.db.GetSellerApplication(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seller application: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *sellerApplicationRepository) ListUserApplications(ctx context.Context, userID uuid.UUID) ([]db.SellerApplication, error) {
+	rows, err := r.db.ListUserSellerApplications(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list seller applications: %w", err)
+	}
+	return rows, nil
+}
+
+func (r *sellerApplicationRepository) ListApplications(ctx context.Context, param *db.ListSellerApplicationsParams) ([]db.SellerApplication, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	rows, err := r.db.ListSellerApplications(ctx, *param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list seller applications: %w", err)
+	}
+	return rows, nil
+}
+
+func (r *sellerApplicationRepository) ListDocuments(ctx context.Context, applicationID uuid.UUID) ([]db.ListSellerApplicationDocumentsRow, error) {
+	rows, err := r.db.ListSellerApplicationDocuments(ctx, applicationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list seller application documents: %w", err)
+	}
+	return rows, nil
+}
+
+func (r *sellerApplicationRepository) GetDocument(ctx context.Context, applicationID uuid.UUID, kind string) (*db.SellerApplicationDocument, error) {
+	res, err := r.db.GetSellerApplicationDocument(ctx, db.GetSellerApplicationDocumentParams{ApplicationID: applicationID, Kind: kind})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seller application document: %w", err)
+	}
+	return &res, nil
+}
+
+func (r *sellerApplicationRepository) DecideApplication(ctx context.Context, param *db.DecideSellerApplicationParams) (*db.SellerApplication, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.DecideSellerApplication(ctx, *param)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrApplicationDecided
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decide seller application: %w", err)
+	}
+	return &res, nil
+}
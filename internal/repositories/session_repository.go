@@ -0,0 +1,229 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/redisclient"
+)
+
+// Session registry, keyed by the refresh token family ID:
+//
+//	session:<id>             hash {user_id, client_id, scope, device, ip_address, user_agent, created_at, last_used_at}
+//	session:<id>:tokens      zset jti -> access token expiry (unix)
+//	user_sessions:<user_id>  set of session ids
+type SessionRecord struct {
+	ID     string
+	UserID string
+	// ClientID and Scope are set for sessions created through the OAuth
+	// authorization code flow; first-party logins leave them empty.
+	ClientID   string
+	Scope      string
+	Device     string
+	IPAddress  string
+	UserAgent  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+type TrackedAccessToken struct {
+	JTI       string
+	ExpiresAt time.Time
+}
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *SessionRecord, ttl time.Duration) error
+	GetSession(ctx context.Context, sessionID string) (*SessionRecord, error)
+	TouchSession(ctx context.Context, sessionID string, ipAddress string, ttl time.Duration) error
+	ListSessions(ctx context.Context, userID string) ([]SessionRecord, error)
+	DeleteSession(ctx context.Context, sessionID string) error
+	TrackAccessToken(ctx context.Context, sessionID string, jti string, expiresAt time.Time) error
+	ListAccessTokens(ctx context.Context, sessionID string) ([]TrackedAccessToken, error)
+}
+
+type sessionRepository struct {
+	redis *redisclient.RedisClient
+}
+
+func NewSessionRepository(redis *redisclient.RedisClient) SessionRepository {
+	return &sessionRepository{redis: redis}
+}
+
+func sessionKey(sessionID string) string {
+	return fmt.Sprintf("session:%s", sessionID)
+}
+
+func sessionTokensKey(sessionID string) string {
+	return fmt.Sprintf("session:%s:tokens", sessionID)
+}
+
+func userSessionsKey(userID string) string {
+	return fmt.Sprintf("user_sessions:%s", userID)
+}
+
+func (r *sessionRepository) CreateSession(ctx context.Context, session *SessionRecord, ttl time.Duration) error {
+	_, err := r.redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, sessionKey(session.ID),
+			"user_id", session.UserID,
+			"client_id", session.ClientID,
+			"scope", session.Scope,
+			"device", session.Device,
+			"ip_address", session.IPAddress,
+			"user_agent", session.UserAgent,
+			"created_at", session.CreatedAt.Unix(),
+			"last_used_at", session.LastUsedAt.Unix(),
+		)
+		pipe.Expire(ctx, sessionKey(session.ID), ttl)
+		pipe.SAdd(ctx, userSessionsKey(session.UserID), session.ID)
+		pipe.Expire(ctx, userSessionsKey(session.UserID), ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) GetSession(ctx context.Context, sessionID string) (*SessionRecord, error) {
+	data, err := r.redis.Client.HGetAll(ctx, sessionKey(sessionID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, apperrors.ErrSessionNotFound
+	}
+	return toSessionRecord(sessionID, data), nil
+}
+
+func (r *sessionRepository) TouchSession(ctx context.Context, sessionID string, ipAddress string, ttl time.Duration) error {
+	userID, err := r.redis.Client.HGet(ctx, sessionKey(sessionID), "user_id").Result()
+	if err == redis.Nil {
+		return apperrors.ErrSessionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	_, err = r.redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, sessionKey(sessionID), "ip_address", ipAddress, "last_used_at", time.Now().Unix())
+		pipe.Expire(ctx, sessionKey(sessionID), ttl)
+		pipe.Expire(ctx, sessionTokensKey(sessionID), ttl)
+		pipe.Expire(ctx, userSessionsKey(userID), ttl)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+// ListSessions also prunes ids whose session hash has already expired.
+func (r *sessionRepository) ListSessions(ctx context.Context, userID string) ([]SessionRecord, error) {
+	ids, err := r.redis.Client.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	cmds := make([]*redis.StringStringMapCmd, len(ids))
+	_, err = r.redis.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.HGetAll(ctx, sessionKey(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions := make([]SessionRecord, 0, len(ids))
+	var expired []interface{}
+	for i, cmd := range cmds {
+		data := cmd.Val()
+		if len(data) == 0 {
+			expired = append(expired, ids[i])
+			continue
+		}
+		sessions = append(sessions, *toSessionRecord(ids[i], data))
+	}
+
+	if len(expired) > 0 {
+		if err := r.redis.Client.SRem(ctx, userSessionsKey(userID), expired...).Err(); err != nil {
+			return nil, fmt.Errorf("failed to prune expired sessions: %w", err)
+		}
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) DeleteSession(ctx context.Context, sessionID string) error {
+	userID, err := r.redis.Client.HGet(ctx, sessionKey(sessionID), "user_id").Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	_, err = r.redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(sessionID), sessionTokensKey(sessionID))
+		pipe.SRem(ctx, userSessionsKey(userID), sessionID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) TrackAccessToken(ctx context.Context, sessionID string, jti string, expiresAt time.Time) error {
+	_, err := r.redis.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, sessionTokensKey(sessionID), &redis.Z{Score: float64(expiresAt.Unix()), Member: jti})
+		// Drop tokens that have expired on their own, they need no blacklisting.
+		pipe.ZRemRangeByScore(ctx, sessionTokensKey(sessionID), "-inf", strconv.FormatInt(time.Now().Unix(), 10))
+		pipe.ExpireAt(ctx, sessionTokensKey(sessionID), expiresAt)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to track access token: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) ListAccessTokens(ctx context.Context, sessionID string) ([]TrackedAccessToken, error) {
+	entries, err := r.redis.Client.ZRangeByScoreWithScores(ctx, sessionTokensKey(sessionID), &redis.ZRangeBy{
+		Min: strconv.FormatInt(time.Now().Unix(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session access tokens: %w", err)
+	}
+
+	tokens := make([]TrackedAccessToken, 0, len(entries))
+	for _, entry := range entries {
+		jti, _ := entry.Member.(string)
+		tokens = append(tokens, TrackedAccessToken{JTI: jti, ExpiresAt: time.Unix(int64(entry.Score), 0)})
+	}
+	return tokens, nil
+}
+
+func toSessionRecord(sessionID string, data map[string]string) *SessionRecord {
+	createdAt, _ := strconv.ParseInt(data["created_at"], 10, 64)
+	lastUsedAt, _ := strconv.ParseInt(data["last_used_at"], 10, 64)
+
+	return &SessionRecord{
+		ID:         sessionID,
+		UserID:     data["user_id"],
+		ClientID:   data["client_id"],
+		Scope:      data["scope"],
+		Device:     data["device"],
+		IPAddress:  data["ip_address"],
+		UserAgent:  data["user_agent"],
+		CreatedAt:  time.Unix(createdAt, 0),
+		LastUsedAt: time.Unix(lastUsedAt, 0),
+	}
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+type SignInEventRepository interface {
+	// Add records a sign-in and keeps only the user's newest keep entries.
+	Add(ctx context.Context, param *db.CreateSignInEventParams, keep int) error
+	// List returns the user's sign-ins, newest first.
+	List(ctx context.Context, userID uuid.UUID) ([]db.SignInEvent, error)
+}
+
+type signInEventRepository struct {
+	db *db.Queries
+}
+
+func NewSignInEventRepository(sqlcQueries *db.Queries) SignInEventRepository {
+	return &signInEventRepository{db: sqlcQueries}
+}
+
+func (r *signInEventRepository) Add(ctx context.Context, param *db.CreateSignInEventParams, keep int) error {
+	if param == nil {
+		return apperrors.ErrInvalidQuery
+	}
+
+	if err := r.db.CreateSignInEvent(ctx, *param); err != nil {
+		return fmt.Errorf("failed to add sign-in event: %w", err)
+	}
+
+	err := r.db.PruneSignInEvents(ctx, db.PruneSignInEventsParams{UserID: param.UserID, Limit: int32(keep)})
+	if err != nil {
+		return fmt.Errorf("failed to prune sign-in events: %w", err)
+	}
+	return nil
+}
+
+func (r *signInEventRepository) List(ctx context.Context, userID uuid.UUID) ([]db.SignInEvent, error) {
+	events, err := r.db.ListSignInEvents(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sign-in events: %w", err)
+	}
+	return events, nil
+}
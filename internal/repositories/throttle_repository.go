@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/redisclient"
+)
+
+// ThrottleRepository enforces a cooldown between repeats of an action, such as
+// sending another email to the same user.
+//
+//	throttle:<key> = 1 with the cooldown as TTL
+type ThrottleRepository interface {
+	// Allow reports whether the action may run now. When it may, the action is
+	// blocked for the cooldown from this moment.
+	Allow(ctx context.Context, key string, cooldown time.Duration) (bool, error)
+}
+
+type throttleRepository struct {
+	redis *redisclient.RedisClient
+}
+
+func NewThrottleRepository(redis *redisclient.RedisClient) ThrottleRepository {
+	return &throttleRepository{redis: redis}
+}
+
+func throttleKey(key string) string {
+	return fmt.Sprintf("throttle:%s", key)
+}
+
+func (r *throttleRepository) Allow(ctx context.Context, key string, cooldown time.Duration) (bool, error) {
+	ok, err := r.redis.Client.SetNX(ctx, throttleKey(key), 1, cooldown).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check throttle: %w", err)
+	}
+	return ok, nil
+}
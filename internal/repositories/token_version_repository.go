@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/redisclient"
+)
+
+const tokenVersionCacheTTL = 24 * time.Hour
+
+// TokenVersionRepository keeps users.token_version in Postgres and a read-through
+// copy in Redis, because it is looked up on every token validation.
+type TokenVersionRepository interface {
+	GetTokenVersion(ctx context.Context, userID uuid.UUID) (int32, error)
+	IncrementTokenVersion(ctx context.Context, userID uuid.UUID) (int32, error)
+}
+
+type tokenVersionRepository struct {
+	db    *db.Queries
+	redis *redisclient.RedisClient
+}
+
+func NewTokenVersionRepository(sqlcQueries *db.Queries, redis *redisclient.RedisClient) TokenVersionRepository {
+	return &tokenVersionRepository{db: sqlcQueries, redis: redis}
+}
+
+func tokenVersionKey(userID uuid.UUID) string {
+	return fmt.Sprintf("user:token_version:%s", userID)
+}
+
+func (r *tokenVersionRepository) GetTokenVersion(ctx context.Context, userID uuid.UUID) (int32, error) {
+	cached, err := r.redis.Get(ctx, tokenVersionKey(userID))
+	if err == nil {
+		version, err := strconv.ParseInt(cached, 10, 32)
+		if err == nil {
+			return int32(version), nil
+		}
+	} else if err != redis.Nil {
+		return 0, fmt.Errorf("failed to read cached token version: %w", err)
+	}
+
+	version, err := r.db.GetUserTokenVersion(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, apperrors.ErrUserNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get token version: %w", err)
+	}
+
+	// SETNX so a value read before a concurrent bump can't overwrite the newer
+	// one written by IncrementTokenVersion.
+	if err := r.redis.Client.SetNX(ctx, tokenVersionKey(userID), version, tokenVersionCacheTTL).Err(); err != nil {
+		return 0, fmt.Errorf("failed to cache token version: %w", err)
+	}
+
+	return version, nil
+}
+
+func (r *tokenVersionRepository) IncrementTokenVersion(ctx context.Context, userID uuid.UUID) (int32, error) {
+	version, err := r.db.IncrementUserTokenVersion(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment token version: %w", err)
+	}
+
+	if err := r.redis.Set(ctx, tokenVersionKey(userID), version, tokenVersionCacheTTL); err != nil {
+		return 0, fmt.Errorf("failed to cache token version: %w", err)
+	}
+
+	return version, nil
+}
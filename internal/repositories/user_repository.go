@@ -2,8 +2,12 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
@@ -14,11 +18,39 @@ import (
 type UserRepository interface {
 	CreateUser(ctx context.Context, param *db.CreateUserParams) (*db.User, error)
 	GetAllUsers(ctx context.Context) ([]db.GetAllUsersRow, error)
+	ListUsers(ctx context.Context, param *db.ListUsersParams) ([]db.ListUsersRow, error)
 	GetUserByUsername(ctx context.Context, username string) (*db.GetUserByUsernameRow, error)
+	GetUserByEmail(ctx context.Context, email string) (*db.GetUserByEmailRow, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (*db.GetUserByIDRow, error)
+	// GetUserByPhoneNumber only finds verified numbers and returns
+	// ErrUserNotFound otherwise.
+	GetUserByPhoneNumber(ctx context.Context, phoneNumber string) (*db.GetUserByPhoneNumberRow, error)
 	GetUserByIDs(ctx context.Context, id []uuid.UUID) ([]db.GetUserByIDsRow, error)
 	UpdateUser(ctx context.Context, param *db.UpdateUserParams) (*db.User, error)
+	// PatchUser returns ErrNotFound when the user is gone or, with
+	// IfUpdatedAt set, was modified in the meantime.
+	PatchUser(ctx context.Context, param *db.PatchUserParams) (*db.User, error)
+	// MarkPhoneVerified returns ErrNotFound when phoneNumber is no longer the
+	// user's, and ErrPhoneNumberTaken when another account verified it first.
+	MarkPhoneVerified(ctx context.Context, id uuid.UUID, phoneNumber string) (*db.User, error)
+	// SetAvatar returns the key of the replaced avatar, empty when there was
+	// none, and ErrNotFound when the user is gone.
+	SetAvatar(ctx context.Context, id uuid.UUID, key string, url string) (string, error)
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+	// UpdateStatus returns ErrStatusTransition when the user is no longer in
+	// param.FromStatus.
+	UpdateStatus(ctx context.Context, param *db.UpdateUserStatusParams) (*db.User, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) (*db.User, error)
+	ScheduleDeletion(ctx context.Context, id uuid.UUID, at time.Time) error
+	// CancelDeletion reports whether a deletion was scheduled.
+	CancelDeletion(ctx context.Context, id uuid.UUID) (bool, error)
+	DeleteScheduledUsers(ctx context.Context, now time.Time, limit int) ([]db.DeleteScheduledUsersRow, error)
+	// RestoreUser returns ErrNotFound unless the user is soft-deleted, and
+	// ErrUserAlreadyExists when a live account took the username or email in
+	// the meantime.
+	RestoreUser(ctx context.Context, id uuid.UUID, reason string) (*db.User, error)
+	ListPurgeableUsers(ctx context.Context, deletedBefore time.Time, limit int) ([]db.ListPurgeableUsersRow, error)
+	PurgeDeletedUsers(ctx context.Context, ids []uuid.UUID, deletedBefore time.Time) ([]uuid.UUID, error)
 	ExistUsernameorEmail(ctx context.Context, username string, email string) (*db.ExistUsernameorEmailRow, error)
 }
 
@@ -59,6 +91,19 @@ func (u *userRepository) GetAllUsers(ctx context.Context) ([]db.GetAllUsersRow,
 	return rows, nil
 }
 
+func (u *userRepository) ListUsers(ctx context.Context, param *db.ListUsersParams) ([]db.ListUsersRow, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	rows, err := u.db.ListUsers(ctx, *param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return rows, nil
+}
+
 func (u *userRepository) GetUserByUsername(ctx context.Context, username string) (*db.GetUserByUsernameRow, error) {
 	var row db.GetUserByUsernameRow
 	row, err := u.db.GetUserByUsername(ctx, username)
@@ -70,6 +115,18 @@ func (u *userRepository) GetUserByUsername(ctx context.Context, username string)
 	return &row, nil
 }
 
+func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*db.GetUserByEmailRow, error) {
+	row, err := u.db.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	return &row, nil
+}
+
 func (u *userRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*db.GetUserByIDRow, error) {
 	var row db.GetUserByIDRow
 
@@ -81,6 +138,18 @@ func (u *userRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*db.Get
 	return &row, nil
 }
 
+func (u *userRepository) GetUserByPhoneNumber(ctx context.Context, phoneNumber string) (*db.GetUserByPhoneNumberRow, error) {
+	row, err := u.db.GetUserByPhoneNumber(ctx, phoneNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by phone number: %w", err)
+	}
+
+	return &row, nil
+}
+
 func (u *userRepository) GetUserByIDs(ctx context.Context, id []uuid.UUID) ([]db.GetUserByIDsRow, error) {
 
 	var row []db.GetUserByIDsRow
@@ -105,6 +174,77 @@ func (u *userRepository) UpdateUser(ctx context.Context, param *db.UpdateUserPar
 	return &res, nil
 }
 
+func (u *userRepository) PatchUser(ctx context.Context, param *db.PatchUserParams) (*db.User, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := u.db.PatchUser(ctx, *param)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch user: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (u *userRepository) MarkPhoneVerified(ctx context.Context, id uuid.UUID, phoneNumber string) (*db.User, error) {
+	res, err := u.db.MarkPhoneVerified(ctx, db.MarkPhoneVerifiedParams{ID: id, PhoneNumber: phoneNumber})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, apperrors.ErrPhoneNumberTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark phone verified: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (u *userRepository) SetAvatar(ctx context.Context, id uuid.UUID, key string, url string) (string, error) {
+	previous, err := u.db.SetUserAvatar(ctx, db.SetUserAvatarParams{ID: id, AvatarKey: key, AvatarUrl: url})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", apperrors.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to set avatar: %w", err)
+	}
+
+	return previous, nil
+}
+
+func (u *userRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	rows, err := u.db.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{ID: id, Password: passwordHash})
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if rows == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+func (u *userRepository) UpdateStatus(ctx context.Context, param *db.UpdateUserStatusParams) (*db.User, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := u.db.UpdateUserStatus(ctx, *param)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrStatusTransition
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	return &res, nil
+}
+
 func (u *userRepository) DeleteUser(ctx context.Context, id uuid.UUID) (*db.User, error) {
 	var res db.User
 
@@ -116,6 +256,68 @@ func (u *userRepository) DeleteUser(ctx context.Context, id uuid.UUID) (*db.User
 	return &res, nil
 }
 
+func (u *userRepository) ScheduleDeletion(ctx context.Context, id uuid.UUID, at time.Time) error {
+	rows, err := u.db.ScheduleUserDeletion(ctx, db.ScheduleUserDeletionParams{
+		ID:                  id,
+		DeletionScheduledAt: sql.NullTime{Time: at, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule user deletion: %w", err)
+	}
+	if rows == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+func (u *userRepository) CancelDeletion(ctx context.Context, id uuid.UUID) (bool, error) {
+	rows, err := u.db.CancelUserDeletion(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel user deletion: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (u *userRepository) DeleteScheduledUsers(ctx context.Context, now time.Time, limit int) ([]db.DeleteScheduledUsersRow, error) {
+	res, err := u.db.DeleteScheduledUsers(ctx, db.DeleteScheduledUsersParams{Now: now, Limit: int32(limit)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete scheduled users: %w", err)
+	}
+	return res, nil
+}
+
+func (u *userRepository) RestoreUser(ctx context.Context, id uuid.UUID, reason string) (*db.User, error) {
+	res, err := u.db.RestoreUser(ctx, db.RestoreUserParams{ID: id, StatusReason: reason})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotFound
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, apperrors.ErrUserAlreadyExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (u *userRepository) ListPurgeableUsers(ctx context.Context, deletedBefore time.Time, limit int) ([]db.ListPurgeableUsersRow, error) {
+	res, err := u.db.ListPurgeableUsers(ctx, db.ListPurgeableUsersParams{DeletedBefore: deletedBefore, Limit: int32(limit)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purgeable users: %w", err)
+	}
+	return res, nil
+}
+
+func (u *userRepository) PurgeDeletedUsers(ctx context.Context, ids []uuid.UUID, deletedBefore time.Time) ([]uuid.UUID, error) {
+	res, err := u.db.PurgeDeletedUsers(ctx, db.PurgeDeletedUsersParams{Ids: ids, DeletedBefore: deletedBefore})
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge deleted users: %w", err)
+	}
+	return res, nil
+}
+
 func (u *userRepository) ExistUsernameorEmail(ctx context.Context, username string, email string) (*db.ExistUsernameorEmailRow, error) {
 	res, err := u.db.ExistUsernameorEmail(ctx, db.ExistUsernameorEmailParams{Username: username, Email: email})
 	if err != nil {
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/redisclient"
+)
+
+const (
+	WebAuthnCeremonyRegistration = "registration"
+	WebAuthnCeremonyLogin        = "login"
+)
+
+// WebAuthnCeremony is the server side state kept between the begin and finish
+// calls of a registration or login.
+type WebAuthnCeremony struct {
+	Kind string `json:"kind"`
+	// UserID is empty for passkey logins, where the user is only known once
+	// the authenticator answers.
+	UserID  string               `json:"user_id,omitempty"`
+	Session webauthn.SessionData `json:"session"`
+}
+
+type WebAuthnCeremonyRepository interface {
+	SaveCeremony(ctx context.Context, ceremonyID string, ceremony *WebAuthnCeremony, ttl time.Duration) error
+	// ConsumeCeremony returns and deletes the ceremony so its challenge can be
+	// answered only once.
+	ConsumeCeremony(ctx context.Context, ceremonyID string) (*WebAuthnCeremony, error)
+}
+
+type webAuthnCeremonyRepository struct {
+	redis *redisclient.RedisClient
+}
+
+func NewWebAuthnCeremonyRepository(redis *redisclient.RedisClient) WebAuthnCeremonyRepository {
+	return &webAuthnCeremonyRepository{redis: redis}
+}
+
+func webAuthnCeremonyKey(ceremonyID string) string {
+	return fmt.Sprintf("webauthn:ceremony:%s", ceremonyID)
+}
+
+func (r *webAuthnCeremonyRepository) SaveCeremony(ctx context.Context, ceremonyID string, ceremony *WebAuthnCeremony, ttl time.Duration) error {
+	payload, err := json.Marshal(ceremony)
+	if err != nil {
+		return fmt.Errorf("failed to encode webauthn ceremony: %w", err)
+	}
+
+	if err := r.redis.Set(ctx, webAuthnCeremonyKey(ceremonyID), payload, ttl); err != nil {
+		return fmt.Errorf("failed to save webauthn ceremony: %w", err)
+	}
+	return nil
+}
+
+func (r *webAuthnCeremonyRepository) ConsumeCeremony(ctx context.Context, ceremonyID string) (*WebAuthnCeremony, error) {
+	payload, err := r.redis.Client.GetDel(ctx, webAuthnCeremonyKey(ceremonyID)).Bytes()
+	if err == redis.Nil {
+		return nil, apperrors.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume webauthn ceremony: %w", err)
+	}
+
+	var ceremony WebAuthnCeremony
+	if err := json.Unmarshal(payload, &ceremony); err != nil {
+		return nil, fmt.Errorf("failed to decode webauthn ceremony: %w", err)
+	}
+	return &ceremony, nil
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+type WebAuthnCredentialRepository interface {
+	CreateCredential(ctx context.Context, param *db.CreateWebAuthnCredentialParams) (*db.WebauthnCredential, error)
+	ListCredentials(ctx context.Context, userID uuid.UUID) ([]db.WebauthnCredential, error)
+	UpdateCredentialUsage(ctx context.Context, param *db.UpdateWebAuthnCredentialUsageParams) error
+	DeleteCredential(ctx context.Context, userID uuid.UUID, credentialID []byte) error
+}
+
+type webAuthnCredentialRepository struct {
+	db *db.Queries
+}
+
+func NewWebAuthnCredentialRepository(sqlcQueries *db.Queries) WebAuthnCredentialRepository {
+	return &webAuthnCredentialRepository{db: sqlcQueries}
+}
+
+func (r *webAuthnCredentialRepository) CreateCredential(ctx context.Context, param *db.CreateWebAuthnCredentialParams) (*db.WebauthnCredential, error) {
+	if param == nil {
+		return nil, apperrors.ErrInvalidQuery
+	}
+
+	res, err := r.db.CreateWebAuthnCredential(ctx, *param)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webauthn credential: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (r *webAuthnCredentialRepository) ListCredentials(ctx context.Context, userID uuid.UUID) ([]db.WebauthnCredential, error) {
+	rows, err := r.db.ListWebAuthnCredentialsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webauthn credentials: %w", err)
+	}
+
+	return rows, nil
+}
+
+func (r *webAuthnCredentialRepository) UpdateCredentialUsage(ctx context.Context, param *db.UpdateWebAuthnCredentialUsageParams) error {
+	if err := r.db.UpdateWebAuthnCredentialUsage(ctx, *param); err != nil {
+		return fmt.Errorf("failed to update webauthn credential: %w", err)
+	}
+	return nil
+}
+
+func (r *webAuthnCredentialRepository) DeleteCredential(ctx context.Context, userID uuid.UUID, credentialID []byte) error {
+	rows, err := r.db.DeleteWebAuthnCredential(ctx, db.DeleteWebAuthnCredentialParams{ID: credentialID, UserID: userID})
+	if err != nil {
+		return fmt.Errorf("failed to delete webauthn credential: %w", err)
+	}
+	if rows == 0 {
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
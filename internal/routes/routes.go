@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/handlers"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/middlewares"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
@@ -9,6 +10,8 @@ import (
 
 func InitRoutes(e *echo.Echo, handler *handlers.UserHandler, tokenService token.TokenService) {
 	e.Static("/static", "template")
+	e.GET("/.well-known/jwks.json", handler.GetJWKS)
+	e.GET("/.well-known/openid-configuration", handler.GetOpenIDConfiguration)
 
 	api := e.Group("/api")
 
@@ -16,22 +19,116 @@ func InitRoutes(e *echo.Echo, handler *handlers.UserHandler, tokenService token.
 	public.POST("/register", handler.RegisterUser)
 	public.POST("/login", handler.Login)
 	public.POST("/refresh", handler.RefreshSession)
+	public.POST("/verify-email", handler.VerifyEmail)
+	public.POST("/password/forgot", handler.ForgotPassword)
+	public.POST("/password/reset", handler.ResetPassword)
+	public.POST("/login/mfa", handler.LoginMFA)
+	public.POST("/login/mfa/enroll", handler.EnrollMFAWithChallenge)
+	public.POST("/login/phone/otp", handler.SendPhoneLoginCode)
+	public.POST("/login/phone", handler.LoginPhone)
+	public.POST("/webauthn/login/begin", handler.BeginPasskeyLogin)
+	public.POST("/webauthn/login/finish", handler.FinishPasskeyLogin)
+
+	api.GET("/exports/download", handler.DownloadDataExport)
+	api.GET("/regions", handler.ListRegions)
+	api.GET("/regions/search", handler.SearchRegions)
 
 	jwtAuthMiddleware := middlewares.AuthMiddleware(middlewares.AuthMiddlewareOptions{
 		TokenService: tokenService,
 	})
+	auditImpersonation := middlewares.AuditImpersonation(handler.AuditLogService)
+	denyImpersonation := middlewares.DenyImpersonation()
+
+	oauth := e.Group("/oauth")
+	oauth.GET("/authorize", handler.Authorize)
+	oauth.POST("/token", handler.Token)
+	oauth.GET("/authorize/consent", handler.GetConsent, jwtAuthMiddleware, denyImpersonation)
+	oauth.POST("/authorize/consent", handler.DecideConsent, jwtAuthMiddleware, denyImpersonation)
+
+	userInfoMiddleware := middlewares.AuthMiddleware(middlewares.AuthMiddlewareOptions{
+		TokenService:      tokenService,
+		AllowScopedTokens: true,
+	})
+	oauth.GET("/userinfo", handler.UserInfo, userInfoMiddleware, auditImpersonation)
+	oauth.POST("/userinfo", handler.UserInfo, userInfoMiddleware, auditImpersonation)
 
 	protected := api.Group("/accounts")
-	protected.Use(jwtAuthMiddleware)
+	protected.Use(jwtAuthMiddleware, auditImpersonation)
 	{
 		// all users
 		protected.GET("/profile", handler.GetUserProfile)
-		protected.PUT("/", handler.UpdateUser)
-		protected.DELETE("/:id", handler.DeleteUser)
+		protected.PUT("/", handler.UpdateUser, denyImpersonation)
+		protected.PATCH("/", handler.PatchUser, denyImpersonation)
+		protected.DELETE("/", handler.DeleteAccount, denyImpersonation)
+		protected.PUT("/me/avatar", handler.UploadAvatar, denyImpersonation)
+		protected.DELETE("/me/avatar", handler.DeleteAvatar, denyImpersonation)
+		protected.GET("/me/preferences", handler.GetPreferences)
+		protected.PATCH("/me/preferences", handler.UpdatePreferences, denyImpersonation)
+		protected.POST("/me/export", handler.RequestDataExport, denyImpersonation)
+		protected.GET("/me/exports", handler.ListDataExports, denyImpersonation)
+		protected.GET("/addresses", handler.ListAddresses)
+		protected.POST("/addresses", handler.CreateAddress, denyImpersonation)
+		protected.GET("/addresses/:id", handler.GetAddress)
+		protected.PUT("/addresses/:id", handler.UpdateAddress, denyImpersonation)
+		protected.DELETE("/addresses/:id", handler.DeleteAddress, denyImpersonation)
+		protected.PUT("/addresses/:id/default", handler.SetDefaultAddress, denyImpersonation)
+		protected.POST("/me/seller-applications", handler.SubmitSellerApplication, denyImpersonation)
+		protected.GET("/me/seller-applications", handler.ListMySellerApplications)
 		protected.POST("/logout", handler.Logout)
+		protected.POST("/logout-all", handler.LogoutEverywhere, denyImpersonation)
+		protected.PUT("/password", handler.ChangePassword, denyImpersonation)
+		protected.POST("/verify-email/resend", handler.ResendVerificationEmail)
+		protected.POST("/phone/otp", handler.SendPhoneVerificationCode)
+		protected.POST("/phone/verify", handler.VerifyPhone, denyImpersonation)
+		protected.GET("/sessions", handler.ListSessions)
+		protected.DELETE("/sessions", handler.RevokeAllSessions, denyImpersonation)
+		protected.DELETE("/sessions/:id", handler.RevokeSession, denyImpersonation)
+		protected.GET("/mfa", handler.GetMFAStatus)
+		protected.POST("/mfa/totp", handler.EnrollTOTP, denyImpersonation)
+		protected.POST("/mfa/totp/confirm", handler.ConfirmTOTP, denyImpersonation)
+		protected.DELETE("/mfa/totp", handler.DisableTOTP, denyImpersonation)
+		protected.POST("/mfa/recovery-codes", handler.RegenerateRecoveryCodes, denyImpersonation)
+		protected.POST("/webauthn/register/begin", handler.BeginPasskeyRegistration, denyImpersonation)
+		protected.POST("/webauthn/register/finish", handler.FinishPasskeyRegistration, denyImpersonation)
+		protected.GET("/webauthn/credentials", handler.ListPasskeys)
+		protected.DELETE("/webauthn/credentials/:id", handler.DeletePasskey, denyImpersonation)
 
-		// admin
-		protected.GET("/", handler.GetAllUsers, middlewares.RequireRoles("admin"))
-		protected.GET("/:id", handler.GetUserByID, middlewares.RequireRoles("admin"))
+		// staff, by permission; never while impersonating, so every staff action
+		// is taken under the name of the admin who took it
+		protected.GET("/mfa/required-roles", handler.ListMFARequiredRoles, denyImpersonation, middlewares.RequirePermissions(entities.PermissionMFAManage))
+		protected.PUT("/mfa/required-roles/:role", handler.RequireMFAForRole, denyImpersonation, middlewares.RequirePermissions(entities.PermissionMFAManage))
+		protected.DELETE("/mfa/required-roles/:role", handler.UnrequireMFAForRole, denyImpersonation, middlewares.RequirePermissions(entities.PermissionMFAManage))
+		protected.POST("/oauth/clients", handler.CreateOAuthClient, denyImpersonation, middlewares.RequirePermissions(entities.PermissionOAuthClientsManage))
+		protected.GET("/oauth/clients", handler.ListOAuthClients, denyImpersonation, middlewares.RequirePermissions(entities.PermissionOAuthClientsManage))
+		protected.DELETE("/oauth/clients/:clientId", handler.DeleteOAuthClient, denyImpersonation, middlewares.RequirePermissions(entities.PermissionOAuthClientsManage))
+		protected.GET("/seller-applications", handler.ListSellerApplications, denyImpersonation, middlewares.RequirePermissions(entities.PermissionSellerApplicationsReview))
+		protected.GET("/seller-applications/:id", handler.GetSellerApplication, denyImpersonation, middlewares.RequirePermissions(entities.PermissionSellerApplicationsReview))
+		protected.GET("/seller-applications/:id/documents/:kind", handler.DownloadSellerDocument, denyImpersonation, middlewares.RequirePermissions(entities.PermissionSellerApplicationsReview))
+		protected.POST("/seller-applications/:id/approve", handler.ApproveSellerApplication, denyImpersonation, middlewares.RequirePermissions(entities.PermissionSellerApplicationsReview))
+		protected.POST("/seller-applications/:id/reject", handler.RejectSellerApplication, denyImpersonation, middlewares.RequirePermissions(entities.PermissionSellerApplicationsReview))
+		protected.GET("/roles", handler.ListRoles, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.POST("/roles", handler.CreateRole, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.GET("/roles/:name", handler.GetRole, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.PUT("/roles/:name", handler.UpdateRole, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.DELETE("/roles/:name", handler.DeleteRole, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.GET("/permissions", handler.ListPermissions, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.POST("/permissions", handler.CreatePermission, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.DELETE("/permissions/:name", handler.DeletePermission, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.GET("/role-requests", handler.ListRoleGrantRequests, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.POST("/role-requests/:id/approve", handler.ApproveRoleGrantRequest, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.POST("/role-requests/:id/reject", handler.RejectRoleGrantRequest, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.GET("/audit-log", handler.ListAuditLog, denyImpersonation, middlewares.RequirePermissions(entities.PermissionAuditLogRead))
+		protected.GET("/", handler.ListUsers, denyImpersonation, middlewares.RequirePermissions(entities.PermissionUsersRead))
+		protected.GET("/:id", handler.GetUserByID, denyImpersonation, middlewares.RequirePermissions(entities.PermissionUsersRead))
+		protected.DELETE("/:id", handler.DeleteUser, denyImpersonation, middlewares.RequirePermissions(entities.PermissionUsersWrite))
+		protected.POST("/:id/restore", handler.RestoreUser, denyImpersonation, middlewares.RequirePermissions(entities.PermissionUsersWrite))
+		protected.PUT("/:id/status", handler.ChangeUserStatus, denyImpersonation, middlewares.RequirePermissions(entities.PermissionUsersWrite))
+		protected.GET("/:id/sessions", handler.ListUserSessions, denyImpersonation, middlewares.RequirePermissions(entities.PermissionSessionsManage))
+		protected.DELETE("/:id/sessions", handler.RevokeAllUserSessions, denyImpersonation, middlewares.RequirePermissions(entities.PermissionSessionsManage))
+		protected.DELETE("/:id/sessions/:sessionId", handler.RevokeUserSession, denyImpersonation, middlewares.RequirePermissions(entities.PermissionSessionsManage))
+		protected.GET("/:id/roles", handler.GetUserAccess, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.POST("/:id/impersonate", handler.ImpersonateUser, denyImpersonation, middlewares.RequirePermissions(entities.PermissionUsersImpersonate))
+		protected.POST("/:id/roles", handler.GrantUserRole, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
+		protected.DELETE("/:id/roles/:role", handler.RevokeUserRole, denyImpersonation, middlewares.RequirePermissions(entities.PermissionRolesManage))
 	}
 }
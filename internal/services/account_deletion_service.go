@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/messaging/rabbitmq"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+type AccountDeletionConfig struct {
+	GracePeriod     time.Duration
+	RetentionPeriod time.Duration
+	// BatchSize caps the accounts one DeleteScheduled or Purge call handles.
+	BatchSize int
+}
+
+type AccountDeletionService interface {
+	// ScheduleDeletion deletes the user's account once the grace period is
+	// over and signs them out everywhere. It returns when the deletion happens.
+	ScheduleDeletion(ctx context.Context, userID uuid.UUID, req *models.DeleteAccountRequest) (time.Time, error)
+	// Restore brings back a soft-deleted account that has not been purged.
+	Restore(ctx context.Context, adminID, userID uuid.UUID, req *models.RestoreAccountRequest) (*entities.User, error)
+	// DeleteScheduled soft-deletes the accounts whose grace period is over.
+	DeleteScheduled(ctx context.Context) (int, error)
+	// Purge removes the accounts deleted longer than the retention period ago,
+	// after removing their avatar and data export archives.
+	Purge(ctx context.Context) (int, error)
+}
+
+type AccountDeletionServiceImpl struct {
+	userRepo       repositories.UserRepository
+	statusRepo     repositories.AccountStatusRepository
+	sessionService SessionService
+	exportService  DataExportService
+	avatarService  AvatarService
+	validator      *validator.Validate
+	eventPublisher *rabbitmq.EventPublisher
+	config         AccountDeletionConfig
+	log            *logrus.Logger
+}
+
+func NewAccountDeletionService(
+	userRepo repositories.UserRepository,
+	statusRepo repositories.AccountStatusRepository,
+	sessionService SessionService,
+	exportService DataExportService,
+	avatarService AvatarService,
+	validator *validator.Validate,
+	eventPublisher *rabbitmq.EventPublisher,
+	config AccountDeletionConfig,
+	log *logrus.Logger,
+) AccountDeletionService {
+	return &AccountDeletionServiceImpl{
+		userRepo:       userRepo,
+		statusRepo:     statusRepo,
+		sessionService: sessionService,
+		exportService:  exportService,
+		avatarService:  avatarService,
+		validator:      validator,
+		eventPublisher: eventPublisher,
+		config:         config,
+		log:            log,
+	}
+}
+
+func (s *AccountDeletionServiceImpl) ScheduleDeletion(ctx context.Context, userID uuid.UUID, req *models.DeleteAccountRequest) (time.Time, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", apperrors.ErrInvalidRequestPayload, err)
+	}
+
+	userDB, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("service: failed to schedule deletion: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(userDB.Password), []byte(req.Password)); err != nil {
+		return time.Time{}, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{
+			Field:   "password",
+			Message: "is incorrect",
+		}}}
+	}
+
+	scheduledFor := time.Now().Add(s.config.GracePeriod)
+	if err := s.userRepo.ScheduleDeletion(ctx, userID, scheduledFor); err != nil {
+		return time.Time{}, fmt.Errorf("service: failed to schedule deletion: %w", err)
+	}
+
+	// Logging in again is what cancels the deletion, so no session may
+	// outlive the request.
+	if err := s.sessionService.LogoutEverywhere(ctx, userID); err != nil {
+		return time.Time{}, fmt.Errorf("service: failed to end sessions: %w", err)
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		event := rabbitmq.AccountDeletionScheduledEvent{
+			UserID:       userDB.ID.String(),
+			Email:        userDB.Email,
+			Username:     userDB.Username,
+			ScheduledFor: scheduledFor,
+		}
+		if err := s.eventPublisher.PublishAccountDeletionScheduled(ctx, event); err != nil {
+			s.log.WithError(err).Error("Failed to publish account deletion scheduled event")
+		}
+	}()
+
+	return scheduledFor, nil
+}
+
+func (s *AccountDeletionServiceImpl) Restore(ctx context.Context, adminID, userID uuid.UUID, req *models.RestoreAccountRequest) (*entities.User, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrInvalidRequestPayload, err)
+	}
+
+	restored, err := s.userRepo.RestoreUser(ctx, userID, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	user := toDomainUser(restored)
+
+	if err := s.statusRepo.SetStatus(ctx, userID, &repositories.AccountStatus{Status: user.Status, SuspendedUntil: user.SuspendedUntil}); err != nil {
+		return nil, fmt.Errorf("service: failed to restore user: %w", err)
+	}
+
+	publishStatusChanged(s.eventPublisher, s.log, user, entities.UserStatusDeleted, req.Reason, adminID)
+
+	return user, nil
+}
+
+func (s *AccountDeletionServiceImpl) DeleteScheduled(ctx context.Context) (int, error) {
+	rows, err := s.userRepo.DeleteScheduledUsers(ctx, time.Now(), s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("service: failed to delete scheduled accounts: %w", err)
+	}
+
+	for _, row := range rows {
+		user := &entities.User{ID: row.ID, Status: entities.UserStatusDeleted}
+		publishStatusChanged(s.eventPublisher, s.log, user, row.PreviousStatus, "deletion requested by the user", uuid.Nil)
+	}
+	return len(rows), nil
+}
+
+func (s *AccountDeletionServiceImpl) Purge(ctx context.Context) (int, error) {
+	deletedBefore := time.Now().Add(-s.config.RetentionPeriod)
+	purgeable, err := s.userRepo.ListPurgeableUsers(ctx, deletedBefore, s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("service: failed to purge deleted accounts: %w", err)
+	}
+
+	// The rows point to the files, so the files go first. An account whose
+	// files cannot be removed stays for the next run.
+	ids := make([]uuid.UUID, 0, len(purgeable))
+	for _, user := range purgeable {
+		if err := s.exportService.DeleteUserExports(ctx, user.ID); err != nil {
+			s.log.WithError(err).WithField("user_id", user.ID).Error("Failed to delete data exports of a purged account")
+			continue
+		}
+		if user.AvatarKey != "" {
+			if err := s.avatarService.DeleteFiles(ctx, user.AvatarKey); err != nil {
+				s.log.WithError(err).WithField("user_id", user.ID).Error("Failed to delete avatar of a purged account")
+				continue
+			}
+		}
+		ids = append(ids, user.ID)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	ids, err = s.userRepo.PurgeDeletedUsers(ctx, ids, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("service: failed to purge deleted accounts: %w", err)
+	}
+
+	purgedAt := time.Now()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		for _, id := range ids {
+			event := rabbitmq.UserPurgedEvent{UserID: id.String(), PurgedAt: purgedAt}
+			if err := s.eventPublisher.PublishUserPurged(ctx, event); err != nil {
+				s.log.WithError(err).Error("Failed to publish user purged event")
+			}
+		}
+	}()
+	return len(ids), nil
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/region"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+// maxAddressesPerUser bounds the address book so checkout can list it in one
+// call.
+const maxAddressesPerUser = 20
+
+type AddressService interface {
+	ListAddresses(ctx context.Context, userID uuid.UUID) ([]entities.Address, error)
+	GetAddress(ctx context.Context, userID, id uuid.UUID) (*entities.Address, error)
+	CreateAddress(ctx context.Context, userID uuid.UUID, req *models.AddressRequest) (*entities.Address, error)
+	UpdateAddress(ctx context.Context, userID, id uuid.UUID, req *models.AddressRequest) (*entities.Address, error)
+	SetDefaultAddress(ctx context.Context, userID, id uuid.UUID) (*entities.Address, error)
+	// DeleteAddress removes an address. If it was the default, the most
+	// recently added remaining address becomes the default.
+	DeleteAddress(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type AddressServiceImpl struct {
+	addressRepo repositories.AddressRepository
+	regions     *region.Dataset
+	validator   *validator.Validate
+	log         *logrus.Logger
+}
+
+func NewAddressService(addressRepo repositories.AddressRepository, regions *region.Dataset, validator *validator.Validate, log *logrus.Logger) AddressService {
+	return &AddressServiceImpl{
+		addressRepo: addressRepo,
+		regions:     regions,
+		validator:   validator,
+		log:         log,
+	}
+}
+
+func (s *AddressServiceImpl) ListAddresses(ctx context.Context, userID uuid.UUID) ([]entities.Address, error) {
+	rows, err := s.addressRepo.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list addresses: %w", err)
+	}
+
+	addresses := make([]entities.Address, 0, len(rows))
+	for i := range rows {
+		addresses = append(addresses, *toDomainAddress(&rows[i]))
+	}
+	return addresses, nil
+}
+
+func (s *AddressServiceImpl) GetAddress(ctx context.Context, userID, id uuid.UUID) (*entities.Address, error) {
+	row, err := s.addressRepo.GetAddress(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toDomainAddress(row), nil
+}
+
+func (s *AddressServiceImpl) CreateAddress(ctx context.Context, userID uuid.UUID, req *models.AddressRequest) (*entities.Address, error) {
+	if err := s.prepareRequest(req); err != nil {
+		return nil, err
+	}
+
+	count, err := s.addressRepo.CountAddresses(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to create address: %w", err)
+	}
+	if count >= maxAddressesPerUser {
+		return nil, apperrors.ErrAddressLimitReached
+	}
+
+	row, err := s.addressRepo.CreateAddress(ctx, &db.CreateUserAddressParams{
+		ID:            uuid.New(),
+		UserID:        userID,
+		RecipientName: req.RecipientName,
+		PhoneNumber:   req.PhoneNumber,
+		Street:        req.Street,
+		District:      req.District,
+		City:          req.City,
+		Province:      req.Province,
+		PostalCode:    req.PostalCode,
+		Village:       req.Village,
+		ProvinceCode:  req.ProvinceCode,
+		CityCode:      req.CityCode,
+		DistrictCode:  req.DistrictCode,
+		VillageCode:   req.VillageCode,
+		IsDefault:     req.IsDefault,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to create address: %w", err)
+	}
+	return toDomainAddress(row), nil
+}
+
+func (s *AddressServiceImpl) UpdateAddress(ctx context.Context, userID, id uuid.UUID, req *models.AddressRequest) (*entities.Address, error) {
+	if err := s.prepareRequest(req); err != nil {
+		return nil, err
+	}
+
+	row, err := s.addressRepo.UpdateAddress(ctx, &db.UpdateUserAddressParams{
+		ID:            id,
+		UserID:        userID,
+		RecipientName: req.RecipientName,
+		PhoneNumber:   req.PhoneNumber,
+		Street:        req.Street,
+		District:      req.District,
+		City:          req.City,
+		Province:      req.Province,
+		PostalCode:    req.PostalCode,
+		Village:       req.Village,
+		ProvinceCode:  req.ProvinceCode,
+		CityCode:      req.CityCode,
+		DistrictCode:  req.DistrictCode,
+		VillageCode:   req.VillageCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toDomainAddress(row), nil
+}
+
+func (s *AddressServiceImpl) SetDefaultAddress(ctx context.Context, userID, id uuid.UUID) (*entities.Address, error) {
+	if err := s.addressRepo.SetDefaultAddress(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.GetAddress(ctx, userID, id)
+}
+
+func (s *AddressServiceImpl) DeleteAddress(ctx context.Context, userID, id uuid.UUID) error {
+	return s.addressRepo.DeleteAddress(ctx, userID, id)
+}
+
+// prepareRequest trims the request, checks its regions against the region
+// dataset and replaces them with the dataset's codes and names, then validates
+// it.
+func (s *AddressServiceImpl) prepareRequest(req *models.AddressRequest) error {
+	normalizeAddressRequest(req)
+
+	resolved, err := s.regions.Resolve(region.Address{
+		ProvinceCode: req.ProvinceCode,
+		Province:     req.Province,
+		CityCode:     req.CityCode,
+		City:         req.City,
+		DistrictCode: req.DistrictCode,
+		District:     req.District,
+		VillageCode:  req.VillageCode,
+		Village:      req.Village,
+		PostalCode:   req.PostalCode,
+	})
+	var fieldErr *region.FieldError
+	if errors.As(err, &fieldErr) {
+		return apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{
+			Field:   fieldErr.Field,
+			Message: fieldErr.Message,
+		}}}
+	}
+	if err != nil {
+		return fmt.Errorf("service: failed to resolve address regions: %w", err)
+	}
+
+	req.ProvinceCode, req.Province = resolved.ProvinceCode, resolved.Province
+	req.CityCode, req.City = resolved.CityCode, resolved.City
+	req.DistrictCode, req.District = resolved.DistrictCode, resolved.District
+	req.VillageCode, req.Village = resolved.VillageCode, resolved.Village
+	req.PostalCode = resolved.PostalCode
+
+	if err := s.validator.Struct(req); err != nil {
+		return fmt.Errorf("%w: %s", apperrors.ErrInvalidRequestPayload, err)
+	}
+	return nil
+}
+
+func normalizeAddressRequest(req *models.AddressRequest) {
+	req.RecipientName = strings.TrimSpace(req.RecipientName)
+	req.PhoneNumber = strings.TrimSpace(req.PhoneNumber)
+	req.Street = strings.TrimSpace(req.Street)
+	req.Village = strings.TrimSpace(req.Village)
+	req.VillageCode = strings.TrimSpace(req.VillageCode)
+	req.District = strings.TrimSpace(req.District)
+	req.DistrictCode = strings.TrimSpace(req.DistrictCode)
+	req.City = strings.TrimSpace(req.City)
+	req.CityCode = strings.TrimSpace(req.CityCode)
+	req.Province = strings.TrimSpace(req.Province)
+	req.ProvinceCode = strings.TrimSpace(req.ProvinceCode)
+	req.PostalCode = strings.TrimSpace(req.PostalCode)
+}
+
+func toDomainAddress(row *db.UserAddress) *entities.Address {
+	return &entities.Address{
+		ID:            row.ID,
+		UserID:        row.UserID,
+		RecipientName: row.RecipientName,
+		PhoneNumber:   row.PhoneNumber,
+		Street:        row.Street,
+		Village:       row.Village,
+		VillageCode:   row.VillageCode,
+		District:      row.District,
+		DistrictCode:  row.DistrictCode,
+		City:          row.City,
+		CityCode:      row.CityCode,
+		Province:      row.Province,
+		ProvinceCode:  row.ProvinceCode,
+		PostalCode:    row.PostalCode,
+		IsDefault:     row.IsDefault,
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+	}
+}
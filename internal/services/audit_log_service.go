@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+const (
+	defaultAuditLogPage = 50
+	maxAuditLogPage     = 100
+)
+
+// AuditLogFilter narrows the audit log. Zero values match everything;
+// BeforeID pages back through older entries and Limit defaults to 50, capped
+// at 100.
+type AuditLogFilter struct {
+	TargetUserID uuid.UUID
+	ActorID      uuid.UUID
+	Action       string
+	BeforeID     int64
+	Limit        int
+}
+
+type AuditLogService interface {
+	// Record writes an entry for an event that changes nothing by itself.
+	Record(ctx context.Context, actorID, targetUserID uuid.UUID, action string, details map[string]any) error
+	// List returns entries newest first.
+	List(ctx context.Context, filter AuditLogFilter) ([]entities.AuditLogEntry, error)
+}
+
+type AuditLogServiceImpl struct {
+	auditLogRepo repositories.AuditLogRepository
+	log          *logrus.Logger
+}
+
+func NewAuditLogService(auditLogRepo repositories.AuditLogRepository, log *logrus.Logger) AuditLogService {
+	return &AuditLogServiceImpl{
+		auditLogRepo: auditLogRepo,
+		log:          log,
+	}
+}
+
+func (s *AuditLogServiceImpl) Record(ctx context.Context, actorID, targetUserID uuid.UUID, action string, details map[string]any) error {
+	if err := recordAuditLog(ctx, s.auditLogRepo, actorID, targetUserID, action, details); err != nil {
+		s.log.WithError(err).WithField("action", action).Error("Failed to write audit log")
+		return err
+	}
+	return nil
+}
+
+func (s *AuditLogServiceImpl) List(ctx context.Context, filter AuditLogFilter) ([]entities.AuditLogEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLogPage
+	}
+	if filter.Limit > maxAuditLogPage {
+		filter.Limit = maxAuditLogPage
+	}
+
+	param := &db.ListAuditLogsParams{Limit: int32(filter.Limit)}
+	if filter.TargetUserID != uuid.Nil {
+		param.TargetUserID = uuid.NullUUID{UUID: filter.TargetUserID, Valid: true}
+	}
+	if filter.ActorID != uuid.Nil {
+		param.ActorID = uuid.NullUUID{UUID: filter.ActorID, Valid: true}
+	}
+	if filter.Action != "" {
+		param.Action = sql.NullString{String: filter.Action, Valid: true}
+	}
+	if filter.BeforeID > 0 {
+		param.BeforeID = sql.NullInt64{Int64: filter.BeforeID, Valid: true}
+	}
+
+	rows, err := s.auditLogRepo.ListAuditLogs(ctx, param)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list audit log: %w", err)
+	}
+
+	entries := make([]entities.AuditLogEntry, 0, len(rows))
+	for _, row := range rows {
+		entry := entities.AuditLogEntry{
+			ID:        row.ID,
+			Action:    row.Action,
+			Details:   row.Details,
+			CreatedAt: row.CreatedAt,
+		}
+		if row.ActorID.Valid {
+			actorID := row.ActorID.UUID
+			entry.ActorID = &actorID
+		}
+		if row.TargetUserID.Valid {
+			targetUserID := row.TargetUserID.UUID
+			entry.TargetUserID = &targetUserID
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func recordAuditLog(ctx context.Context, auditLogRepo repositories.AuditLogRepository, actorID, targetUserID uuid.UUID, action string, details map[string]any) error {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("service: failed to encode audit details: %w", err)
+	}
+
+	param := &db.CreateAuditLogParams{Action: action, Details: data}
+	if actorID != uuid.Nil {
+		param.ActorID = uuid.NullUUID{UUID: actorID, Valid: true}
+	}
+	if targetUserID != uuid.Nil {
+		param.TargetUserID = uuid.NullUUID{UUID: targetUserID, Valid: true}
+	}
+	return auditLogRepo.CreateAuditLog(ctx, param)
+}
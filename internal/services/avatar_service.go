@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/imaging"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/storage"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+// AvatarSizes are the square variants stored for every avatar, as
+// <key>/<size>.jpg. AvatarURL points to DefaultAvatarSize.
+var AvatarSizes = []int{64, 128, 256, 512}
+
+const (
+	DefaultAvatarSize = 256
+	avatarJPEGQuality = 85
+)
+
+type AvatarConfig struct {
+	MaxBytes int64
+	// MaxPixels rejects images whose decoded size would be too large.
+	MaxPixels int
+}
+
+type AvatarService interface {
+	// Upload replaces the user's avatar with the image read from r. Uploads
+	// over MaxBytes return ErrFileTooLarge.
+	Upload(ctx context.Context, userID uuid.UUID, r io.Reader) (*entities.User, error)
+	Delete(ctx context.Context, userID uuid.UUID) (*entities.User, error)
+	// DeleteFiles removes every stored variant of an avatar, before its
+	// account is purged. Unlike Delete it reports failures, so the account
+	// can wait for the next purge.
+	DeleteFiles(ctx context.Context, key string) error
+}
+
+type AvatarServiceImpl struct {
+	userRepo repositories.UserRepository
+	storage  storage.Storage
+	config   AvatarConfig
+	log      *logrus.Logger
+}
+
+func NewAvatarService(userRepo repositories.UserRepository, storage storage.Storage, config AvatarConfig, log *logrus.Logger) AvatarService {
+	return &AvatarServiceImpl{
+		userRepo: userRepo,
+		storage:  storage,
+		config:   config,
+		log:      log,
+	}
+}
+
+func avatarVariantKey(key string, size int) string {
+	return key + "/" + strconv.Itoa(size) + ".jpg"
+}
+
+func (s *AvatarServiceImpl) Upload(ctx context.Context, userID uuid.UUID, r io.Reader) (*entities.User, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.config.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to read avatar: %w", err)
+	}
+	if int64(len(data)) > s.config.MaxBytes {
+		return nil, apperrors.ErrFileTooLarge
+	}
+
+	img, _, err := imaging.Decode(data, s.config.MaxPixels)
+	if errors.Is(err, imaging.ErrUnsupportedFormat) {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "avatar", Message: "must be a JPEG, PNG or GIF image"}}}
+	}
+	if errors.Is(err, imaging.ErrTooLarge) {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "avatar", Message: fmt.Sprintf("must be at most %d megapixels", s.config.MaxPixels/1_000_000)}}}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to decode avatar: %w", err)
+	}
+
+	// Every upload gets a new key, so caches never serve an old picture.
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("service: failed to generate avatar key: %w", err)
+	}
+	key := "avatars/" + userID.String() + "/" + hex.EncodeToString(suffix)
+
+	square := imaging.CropSquare(img)
+	for _, size := range AvatarSizes {
+		encoded, err := imaging.EncodeJPEG(imaging.Resize(square, size), avatarJPEGQuality)
+		if err != nil {
+			s.deleteVariants(key)
+			return nil, fmt.Errorf("service: failed to encode avatar: %w", err)
+		}
+		if err := s.storage.Put(ctx, avatarVariantKey(key, size), encoded, "image/jpeg"); err != nil {
+			s.deleteVariants(key)
+			return nil, fmt.Errorf("service: failed to store avatar: %w", err)
+		}
+	}
+
+	previous, err := s.userRepo.SetAvatar(ctx, userID, key, s.storage.URL(avatarVariantKey(key, DefaultAvatarSize)))
+	if err != nil {
+		s.deleteVariants(key)
+		return nil, err
+	}
+	if previous != "" {
+		s.deleteVariants(previous)
+	}
+
+	return s.getUser(ctx, userID)
+}
+
+func (s *AvatarServiceImpl) Delete(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	previous, err := s.userRepo.SetAvatar(ctx, userID, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if previous != "" {
+		s.deleteVariants(previous)
+	}
+
+	return s.getUser(ctx, userID)
+}
+
+func (s *AvatarServiceImpl) DeleteFiles(ctx context.Context, key string) error {
+	for _, size := range AvatarSizes {
+		if err := s.storage.Delete(ctx, avatarVariantKey(key, size)); err != nil {
+			return fmt.Errorf("service: failed to delete avatar file: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *AvatarServiceImpl) getUser(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toDomainUser(user), nil
+}
+
+// deleteVariants removes the files of an avatar that is no longer used. It
+// runs after the request may have been cancelled, and a failure only leaves
+// unreferenced files behind.
+func (s *AvatarServiceImpl) deleteVariants(key string) {
+	ctx := context.Background()
+	for _, size := range AvatarSizes {
+		if err := s.storage.Delete(ctx, avatarVariantKey(key, size)); err != nil {
+			s.log.WithError(err).WithField("key", key).Warn("Failed to delete avatar file")
+		}
+	}
+}
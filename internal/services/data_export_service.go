@@ -0,0 +1,727 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/messaging/rabbitmq"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
+)
+
+type DataExportConfig struct {
+	Dir string
+	// DownloadURL is the download endpoint; id, expires and signature are
+	// added as query parameters.
+	DownloadURL     string
+	LinkSecret      []byte
+	LinkTTL         time.Duration
+	RequestCooldown time.Duration
+	// Issuer goes into the signed manifest, so it can be checked against the
+	// JWKS of the same issuer.
+	Issuer string
+}
+
+type DataExportService interface {
+	// RequestExport queues an export for the export worker, at most once per
+	// cooldown.
+	RequestExport(ctx context.Context, userID uuid.UUID) (*entities.DataExport, error)
+	ListExports(ctx context.Context, userID uuid.UUID) ([]entities.DataExport, error)
+	// Build collects the user's data into the archive. It runs in the export
+	// worker.
+	Build(ctx context.Context, exportID uuid.UUID) error
+	// OpenDownload checks a download link and returns the path of the archive.
+	// Archives of accounts that are deleted or waiting for deletion are not
+	// served.
+	OpenDownload(ctx context.Context, exportID, expires, signature string) (string, error)
+	// DeleteExpired removes the archives whose link has expired.
+	DeleteExpired(ctx context.Context) (int, error)
+	// DeleteUserExports removes all archives of a user, before their account is
+	// purged.
+	DeleteUserExports(ctx context.Context, userID uuid.UUID) error
+}
+
+type DataExportServiceImpl struct {
+	exportRepo      repositories.DataExportRepository
+	userRepo        repositories.UserRepository
+	sessionRepo     repositories.SessionRepository
+	signInRepo      repositories.SignInEventRepository
+	auditLogRepo    repositories.AuditLogRepository
+	mfaRepo         repositories.MFARepository
+	credentialRepo  repositories.WebAuthnCredentialRepository
+	oauthRepo       repositories.OAuthClientRepository
+	addressRepo     repositories.AddressRepository
+	preferencesRepo repositories.PreferencesRepository
+	sellerRepo      repositories.SellerApplicationRepository
+	throttleRepo    repositories.ThrottleRepository
+	keyRing         *token.KeyRing
+	eventPublisher  *rabbitmq.EventPublisher
+	config          DataExportConfig
+	log             *logrus.Logger
+}
+
+func NewDataExportService(
+	exportRepo repositories.DataExportRepository,
+	userRepo repositories.UserRepository,
+	sessionRepo repositories.SessionRepository,
+	signInRepo repositories.SignInEventRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	mfaRepo repositories.MFARepository,
+	credentialRepo repositories.WebAuthnCredentialRepository,
+	oauthRepo repositories.OAuthClientRepository,
+	addressRepo repositories.AddressRepository,
+	preferencesRepo repositories.PreferencesRepository,
+	sellerRepo repositories.SellerApplicationRepository,
+	throttleRepo repositories.ThrottleRepository,
+	keyRing *token.KeyRing,
+	eventPublisher *rabbitmq.EventPublisher,
+	config DataExportConfig,
+	log *logrus.Logger,
+) (DataExportService, error) {
+	if len(config.LinkSecret) < 32 {
+		return nil, errors.New("data export link secret must be at least 32 bytes")
+	}
+
+	return &DataExportServiceImpl{
+		exportRepo:      exportRepo,
+		userRepo:        userRepo,
+		sessionRepo:     sessionRepo,
+		signInRepo:      signInRepo,
+		auditLogRepo:    auditLogRepo,
+		mfaRepo:         mfaRepo,
+		credentialRepo:  credentialRepo,
+		oauthRepo:       oauthRepo,
+		addressRepo:     addressRepo,
+		preferencesRepo: preferencesRepo,
+		sellerRepo:      sellerRepo,
+		throttleRepo:    throttleRepo,
+		keyRing:         keyRing,
+		eventPublisher:  eventPublisher,
+		config:          config,
+		log:             log,
+	}, nil
+}
+
+func (s *DataExportServiceImpl) RequestExport(ctx context.Context, userID uuid.UUID) (*entities.DataExport, error) {
+	allowed, err := s.throttleRepo.Allow(ctx, "data_export:"+userID.String(), s.config.RequestCooldown)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to request data export: %w", err)
+	}
+	if !allowed {
+		return nil, apperrors.ErrTooManyRequests
+	}
+
+	export, err := s.exportRepo.CreateExport(ctx, uuid.New(), userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to request data export: %w", err)
+	}
+
+	// Not in the background like other events: without it the export would
+	// stay pending forever.
+	event := rabbitmq.DataExportRequestedEvent{ExportID: export.ID.String(), UserID: userID.String()}
+	if err := s.eventPublisher.PublishDataExportRequested(ctx, event); err != nil {
+		if err := s.exportRepo.FailExport(ctx, export.ID); err != nil {
+			s.log.WithError(err).Error("Failed to mark data export failed")
+		}
+		return nil, fmt.Errorf("service: failed to queue data export: %w", err)
+	}
+
+	return s.toDomainExport(export), nil
+}
+
+func (s *DataExportServiceImpl) ListExports(ctx context.Context, userID uuid.UUID) ([]entities.DataExport, error) {
+	exports, err := s.exportRepo.ListExports(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list data exports: %w", err)
+	}
+
+	res := make([]entities.DataExport, 0, len(exports))
+	for i := range exports {
+		res = append(res, *s.toDomainExport(&exports[i]))
+	}
+	return res, nil
+}
+
+// exportSection is one JSON file of the archive.
+type exportSection struct {
+	file    string
+	collect func(ctx context.Context, user *db.GetUserByIDRow) (interface{}, error)
+}
+
+func (s *DataExportServiceImpl) sections() []exportSection {
+	return []exportSection{
+		{"profile.json", s.collectProfile},
+		{"sessions.json", s.collectSessions},
+		{"sign_ins.json", s.collectSignIns},
+		{"audit_log.json", s.collectAuditLog},
+		{"two_factor.json", s.collectTwoFactor},
+		{"passkeys.json", s.collectPasskeys},
+		{"oauth_consents.json", s.collectConsents},
+		{"addresses.json", s.collectAddresses},
+		{"preferences.json", s.collectPreferences},
+		{"seller_applications.json", s.collectSellerApplications},
+	}
+}
+
+// exportManifest lists the SHA-256 of every file in the archive. Its signed
+// copy, manifest.jws, can be verified against the issuer's JWKS.
+type exportManifest struct {
+	jwt.RegisteredClaims
+	Files map[string]string `json:"files"`
+}
+
+func (s *DataExportServiceImpl) Build(ctx context.Context, exportID uuid.UUID) error {
+	export, err := s.exportRepo.StartExport(ctx, exportID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		// already finished, e.g. a redelivered message
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	path, user, err := s.writeArchive(ctx, export)
+	if err != nil {
+		if err := s.exportRepo.FailExport(ctx, export.ID); err != nil {
+			s.log.WithError(err).Error("Failed to mark data export failed")
+		}
+		return fmt.Errorf("service: failed to build data export %s: %w", export.ID, err)
+	}
+
+	expiresAt := time.Now().Add(s.config.LinkTTL)
+	if err := s.exportRepo.CompleteExport(ctx, export.ID, path, expiresAt); err != nil {
+		return err
+	}
+
+	event := rabbitmq.DataExportReadyEvent{
+		ExportID:    export.ID.String(),
+		UserID:      user.ID.String(),
+		Email:       user.Email,
+		Username:    user.Username,
+		DownloadURL: s.downloadURL(export.ID, expiresAt),
+		ExpiresAt:   expiresAt,
+	}
+	if err := s.eventPublisher.PublishDataExportReady(ctx, event); err != nil {
+		s.log.WithError(err).Error("Failed to publish data export ready event")
+	}
+	return nil
+}
+
+func (s *DataExportServiceImpl) writeArchive(ctx context.Context, export *db.DataExport) (string, *db.GetUserByIDRow, error) {
+	user, err := s.userRepo.GetUserByID(ctx, export.UserID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := os.MkdirAll(s.config.Dir, 0o700); err != nil {
+		return "", nil, err
+	}
+	path := filepath.Join(s.config.Dir, export.ID.String()+".zip")
+
+	// Written next to the final path and renamed, so a half written archive
+	// is never served.
+	tmp, err := os.CreateTemp(s.config.Dir, export.ID.String()+"-*.tmp")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	archive := zip.NewWriter(tmp)
+	manifest := exportManifest{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:   s.config.Issuer,
+			Subject:  user.ID.String(),
+			ID:       export.ID.String(),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+		Files: make(map[string]string),
+	}
+
+	for _, section := range s.sections() {
+		data, err := section.collect(ctx, user)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", section.file, err)
+		}
+		payload, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", section.file, err)
+		}
+		if err := writeZipFile(archive, section.file, payload); err != nil {
+			return "", nil, err
+		}
+		sum := sha256.Sum256(payload)
+		manifest.Files[section.file] = hex.EncodeToString(sum[:])
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", nil, err
+	}
+	if err := writeZipFile(archive, "manifest.json", manifestJSON); err != nil {
+		return "", nil, err
+	}
+	signature, err := s.keyRing.Sign(manifest)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := writeZipFile(archive, "manifest.jws", []byte(signature)); err != nil {
+		return "", nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return "", nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", nil, err
+	}
+	return path, user, nil
+}
+
+func writeZipFile(archive *zip.Writer, name string, data []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (s *DataExportServiceImpl) OpenDownload(ctx context.Context, exportID, expires, signature string) (string, error) {
+	id, err := uuid.Parse(exportID)
+	if err != nil {
+		return "", apperrors.ErrInvalidToken
+	}
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return "", apperrors.ErrInvalidToken
+	}
+
+	expected := s.linkSignature(id, expiresUnix)
+	if !hmac.Equal([]byte(signature), []byte(expected)) || time.Now().Unix() >= expiresUnix {
+		return "", apperrors.ErrInvalidToken
+	}
+
+	export, err := s.exportRepo.GetExport(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if export.Status != entities.DataExportReady {
+		return "", apperrors.ErrNotFound
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, export.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", apperrors.ErrNotFound
+		}
+		return "", fmt.Errorf("service: failed to load user: %w", err)
+	}
+	if user.DeletionScheduledAt.Valid {
+		return "", apperrors.ErrNotFound
+	}
+	return export.FilePath, nil
+}
+
+func (s *DataExportServiceImpl) DeleteExpired(ctx context.Context) (int, error) {
+	exports, err := s.exportRepo.ListExpiredExports(ctx, time.Now(), 100)
+	if err != nil {
+		return 0, fmt.Errorf("service: failed to list expired data exports: %w", err)
+	}
+
+	for _, export := range exports {
+		if err := os.Remove(export.FilePath); err != nil && !os.IsNotExist(err) {
+			return 0, fmt.Errorf("service: failed to delete data export: %w", err)
+		}
+		if err := s.exportRepo.ExpireExport(ctx, export.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(exports), nil
+}
+
+func (s *DataExportServiceImpl) DeleteUserExports(ctx context.Context, userID uuid.UUID) error {
+	exports, err := s.exportRepo.ListExports(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service: failed to list data exports: %w", err)
+	}
+
+	for _, export := range exports {
+		if export.FilePath == "" {
+			continue
+		}
+		if err := os.Remove(export.FilePath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("service: failed to delete data export: %w", err)
+		}
+		if err := s.exportRepo.ExpireExport(ctx, export.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadURL is a link that works without a login until expiresAt, so it can
+// be emailed.
+func (s *DataExportServiceImpl) downloadURL(id uuid.UUID, expiresAt time.Time) string {
+	return withQuery(s.config.DownloadURL, url.Values{
+		"id":        {id.String()},
+		"expires":   {strconv.FormatInt(expiresAt.Unix(), 10)},
+		"signature": {s.linkSignature(id, expiresAt.Unix())},
+	})
+}
+
+func (s *DataExportServiceImpl) linkSignature(id uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, s.config.LinkSecret)
+	fmt.Fprintf(mac, "%s.%d", id, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *DataExportServiceImpl) toDomainExport(export *db.DataExport) *entities.DataExport {
+	res := &entities.DataExport{
+		ID:        export.ID,
+		UserID:    export.UserID,
+		Status:    export.Status,
+		CreatedAt: export.CreatedAt,
+	}
+	if export.CompletedAt.Valid {
+		res.CompletedAt = &export.CompletedAt.Time
+	}
+	if export.ExpiresAt.Valid {
+		res.ExpiresAt = &export.ExpiresAt.Time
+		if export.Status == entities.DataExportReady {
+			res.DownloadURL = s.downloadURL(export.ID, export.ExpiresAt.Time)
+		}
+	}
+	return res
+}
+
+// ------- SECTIONS -------
+
+type profileExport struct {
+	ID              uuid.UUID  `json:"id"`
+	Name            string     `json:"name"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	PhoneNumber     string     `json:"phone_number"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"`
+	AvatarURL       string     `json:"avatar_url"`
+	Address         string     `json:"address"`
+	Role            string     `json:"role"`
+	Status          string     `json:"status"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (s *DataExportServiceImpl) collectProfile(ctx context.Context, userDB *db.GetUserByIDRow) (interface{}, error) {
+	user := toDomainUser(userDB)
+	return profileExport{
+		ID:              user.ID,
+		Name:            user.Name,
+		Username:        user.Username,
+		Email:           user.Email,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		PhoneNumber:     user.PhoneNumber,
+		PhoneVerifiedAt: user.PhoneVerifiedAt,
+		AvatarURL:       user.AvatarURL,
+		Address:         user.Address,
+		Role:            user.Role,
+		Status:          user.Status,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}, nil
+}
+
+type sessionExport struct {
+	ClientID   string    `json:"client_id,omitempty"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"signed_in_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// collectSessions lists the sign-ins that have not expired or been revoked;
+// sign_ins.json has the history.
+func (s *DataExportServiceImpl) collectSessions(ctx context.Context, user *db.GetUserByIDRow) (interface{}, error) {
+	records, err := s.sessionRepo.ListSessions(ctx, user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]sessionExport, 0, len(records))
+	for _, record := range records {
+		res = append(res, sessionExport{
+			ClientID:   record.ClientID,
+			Device:     record.Device,
+			IPAddress:  record.IPAddress,
+			UserAgent:  record.UserAgent,
+			CreatedAt:  record.CreatedAt,
+			LastUsedAt: record.LastUsedAt,
+		})
+	}
+	return res, nil
+}
+
+type signInExport struct {
+	SessionID string    `json:"session_id"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Device    string    `json:"device"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"signed_in_at"`
+}
+
+func (s *DataExportServiceImpl) collectSignIns(ctx context.Context, user *db.GetUserByIDRow) (interface{}, error) {
+	events, err := s.signInRepo.List(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]signInExport, 0, len(events))
+	for _, event := range events {
+		res = append(res, signInExport{
+			SessionID: event.SessionID,
+			ClientID:  event.ClientID,
+			Device:    event.Device,
+			IPAddress: event.IpAddress,
+			UserAgent: event.UserAgent,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	return res, nil
+}
+
+type auditLogExport struct {
+	Action       string          `json:"action"`
+	ActorID      *uuid.UUID      `json:"actor_id,omitempty"`
+	TargetUserID *uuid.UUID      `json:"target_user_id,omitempty"`
+	Details      json.RawMessage `json:"details"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// collectAuditLog covers what staff did to the account and what the user did
+// as staff, impersonation included.
+func (s *DataExportServiceImpl) collectAuditLog(ctx context.Context, user *db.GetUserByIDRow) (interface{}, error) {
+	rows, err := s.auditLogRepo.ListUserAuditLogs(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]auditLogExport, 0, len(rows))
+	for _, row := range rows {
+		entry := auditLogExport{Action: row.Action, Details: row.Details, CreatedAt: row.CreatedAt}
+		if row.ActorID.Valid {
+			entry.ActorID = &row.ActorID.UUID
+		}
+		if row.TargetUserID.Valid {
+			entry.TargetUserID = &row.TargetUserID.UUID
+		}
+		res = append(res, entry)
+	}
+	return res, nil
+}
+
+type twoFactorExport struct {
+	Enabled   bool       `json:"enabled"`
+	EnabledAt *time.Time `json:"enabled_at,omitempty"`
+}
+
+func (s *DataExportServiceImpl) collectTwoFactor(ctx context.Context, user *db.GetUserByIDRow) (interface{}, error) {
+	mfa, err := s.mfaRepo.GetMFA(ctx, user.ID)
+	if errors.Is(err, apperrors.ErrMFANotEnrolled) {
+		return twoFactorExport{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := twoFactorExport{Enabled: mfa.EnabledAt.Valid}
+	if mfa.EnabledAt.Valid {
+		res.EnabledAt = &mfa.EnabledAt.Time
+	}
+	return res, nil
+}
+
+type passkeyExport struct {
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	Attachment string     `json:"attachment"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func (s *DataExportServiceImpl) collectPasskeys(ctx context.Context, user *db.GetUserByIDRow) (interface{}, error) {
+	creds, err := s.credentialRepo.ListCredentials(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]passkeyExport, 0, len(creds))
+	for _, cred := range creds {
+		passkey := passkeyExport{
+			Name:       cred.Name,
+			Transports: cred.Transports,
+			Attachment: cred.Attachment,
+			CreatedAt:  cred.CreatedAt,
+		}
+		if cred.LastUsedAt.Valid {
+			passkey.LastUsedAt = &cred.LastUsedAt.Time
+		}
+		res = append(res, passkey)
+	}
+	return res, nil
+}
+
+type consentExport struct {
+	ClientID  string    `json:"client_id"`
+	Scopes    []string  `json:"scopes"`
+	GrantedAt time.Time `json:"granted_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s *DataExportServiceImpl) collectConsents(ctx context.Context, user *db.GetUserByIDRow) (interface{}, error) {
+	consents, err := s.oauthRepo.ListConsents(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]consentExport, 0, len(consents))
+	for _, consent := range consents {
+		res = append(res, consentExport{
+			ClientID:  consent.ClientID,
+			Scopes:    consent.Scopes,
+			GrantedAt: consent.CreatedAt,
+			UpdatedAt: consent.UpdatedAt,
+		})
+	}
+	return res, nil
+}
+
+type addressExport struct {
+	RecipientName string    `json:"recipient_name"`
+	PhoneNumber   string    `json:"phone_number"`
+	Street        string    `json:"street"`
+	Village       string    `json:"village"`
+	District      string    `json:"district"`
+	City          string    `json:"city"`
+	Province      string    `json:"province"`
+	PostalCode    string    `json:"postal_code"`
+	IsDefault     bool      `json:"is_default"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (s *DataExportServiceImpl) collectAddresses(ctx context.Context, user *db.GetUserByIDRow) (interface{}, error) {
+	addresses, err := s.addressRepo.ListAddresses(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]addressExport, 0, len(addresses))
+	for _, address := range addresses {
+		res = append(res, addressExport{
+			RecipientName: address.RecipientName,
+			PhoneNumber:   address.PhoneNumber,
+			Street:        address.Street,
+			Village:       address.Village,
+			District:      address.District,
+			City:          address.City,
+			Province:      address.Province,
+			PostalCode:    address.PostalCode,
+			IsDefault:     address.IsDefault,
+			CreatedAt:     address.CreatedAt,
+			UpdatedAt:     address.UpdatedAt,
+		})
+	}
+	return res, nil
+}
+
+type preferencesExport struct {
+	Locale               string    `json:"locale"`
+	Timezone             string    `json:"timezone"`
+	Currency             string    `json:"currency"`
+	EmailNewsletter      bool      `json:"email_newsletter"`
+	EmailPromotions      bool      `json:"email_promotions"`
+	EmailRecommendations bool      `json:"email_recommendations"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// collectPreferences exports null for users who never changed the defaults,
+// since nothing is stored for them.
+func (s *DataExportServiceImpl) collectPreferences(ctx context.Context, user *db.GetUserByIDRow) (interface{}, error) {
+	preferences, err := s.preferencesRepo.GetPreferences(ctx, user.ID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return preferencesExport{
+		Locale:               preferences.Locale,
+		Timezone:             preferences.Timezone,
+		Currency:             preferences.Currency,
+		EmailNewsletter:      preferences.EmailNewsletter,
+		EmailPromotions:      preferences.EmailPromotions,
+		EmailRecommendations: preferences.EmailRecommendations,
+		UpdatedAt:            preferences.UpdatedAt,
+	}, nil
+}
+
+type sellerApplicationExport struct {
+	ShopName    string     `json:"shop_name"`
+	Status      string     `json:"status"`
+	ReviewNotes string     `json:"review_notes,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// collectSellerApplications leaves out the NIK, NPWP and documents. They are
+// encrypted with a key the export worker does not hold, and the archive
+// should not carry identity documents around.
+func (s *DataExportServiceImpl) collectSellerApplications(ctx context.Context, user *db.GetUserByIDRow) (interface{}, error) {
+	applications, err := s.sellerRepo.ListUserApplications(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]sellerApplicationExport, 0, len(applications))
+	for _, application := range applications {
+		export := sellerApplicationExport{
+			ShopName:    application.ShopName,
+			Status:      application.Status,
+			ReviewNotes: application.ReviewNotes,
+			CreatedAt:   application.CreatedAt,
+		}
+		if application.ReviewedAt.Valid {
+			reviewedAt := application.ReviewedAt.Time
+			export.ReviewedAt = &reviewedAt
+		}
+		res = append(res, export)
+	}
+	return res, nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/messaging/rabbitmq"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+type EmailVerificationConfig struct {
+	// URL is the page the emailed link points to; the token is added as ?token=.
+	URL            string
+	TokenTTL       time.Duration
+	ResendCooldown time.Duration
+}
+
+// VerificationLink is a freshly issued link. Issuing one invalidates the
+// earlier links of the same user.
+type VerificationLink struct {
+	URL       string
+	ExpiresAt time.Time
+}
+
+type EmailVerificationService interface {
+	CreateLink(ctx context.Context, user *entities.User) (*VerificationLink, error)
+	// SendLink creates a link and publishes it for the email worker.
+	SendLink(ctx context.Context, user *entities.User) error
+	// Resend is SendLink for the user asking for it, limited to once per
+	// cooldown.
+	Resend(ctx context.Context, user *entities.User) error
+	Verify(ctx context.Context, token string) error
+}
+
+type EmailVerificationServiceImpl struct {
+	verificationRepo repositories.EmailVerificationRepository
+	throttleRepo     repositories.ThrottleRepository
+	eventPublisher   *rabbitmq.EventPublisher
+	config           EmailVerificationConfig
+	log              *logrus.Logger
+}
+
+func NewEmailVerificationService(
+	verificationRepo repositories.EmailVerificationRepository,
+	throttleRepo repositories.ThrottleRepository,
+	eventPublisher *rabbitmq.EventPublisher,
+	config EmailVerificationConfig,
+	log *logrus.Logger,
+) EmailVerificationService {
+	return &EmailVerificationServiceImpl{
+		verificationRepo: verificationRepo,
+		throttleRepo:     throttleRepo,
+		eventPublisher:   eventPublisher,
+		config:           config,
+		log:              log,
+	}
+}
+
+func (s *EmailVerificationServiceImpl) CreateLink(ctx context.Context, user *entities.User) (*VerificationLink, error) {
+	token, err := randomURLToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to generate verification token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(s.config.TokenTTL)
+	err = s.verificationRepo.ReplaceToken(ctx, &db.CreateEmailVerificationTokenParams{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to create verification link: %w", err)
+	}
+
+	return &VerificationLink{
+		URL:       withQuery(s.config.URL, url.Values{"token": {token}}),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *EmailVerificationServiceImpl) SendLink(ctx context.Context, user *entities.User) error {
+	link, err := s.CreateLink(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		event := rabbitmq.EmailVerificationRequestedEvent{
+			UserID:          user.ID.String(),
+			Email:           user.Email,
+			Username:        user.Username,
+			VerificationURL: link.URL,
+			ExpiresAt:       link.ExpiresAt,
+		}
+		if err := s.eventPublisher.PublishEmailVerificationRequested(ctx, event); err != nil {
+			s.log.WithError(err).Error("Failed to publish email verification requested event")
+		}
+	}()
+
+	return nil
+}
+
+func (s *EmailVerificationServiceImpl) Resend(ctx context.Context, user *entities.User) error {
+	if user.EmailVerifiedAt != nil {
+		return apperrors.ErrEmailAlreadyVerified
+	}
+
+	allowed, err := s.throttleRepo.Allow(ctx, "email_verification:"+user.ID.String(), s.config.ResendCooldown)
+	if err != nil {
+		return fmt.Errorf("service: failed to resend verification email: %w", err)
+	}
+	if !allowed {
+		return apperrors.ErrTooManyRequests
+	}
+
+	return s.SendLink(ctx, user)
+}
+
+func (s *EmailVerificationServiceImpl) Verify(ctx context.Context, token string) error {
+	if token == "" {
+		return apperrors.ErrInvalidToken
+	}
+
+	issued, err := s.verificationRepo.UseToken(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+
+	if err := s.verificationRepo.MarkVerified(ctx, issued.UserID, issued.Email); err != nil {
+		return err
+	}
+
+	activated, err := s.verificationRepo.ActivatePendingUser(ctx, issued.UserID)
+	if err != nil {
+		return fmt.Errorf("service: failed to activate user: %w", err)
+	}
+	if activated {
+		user := &entities.User{ID: issued.UserID, Status: entities.UserStatusActive}
+		publishStatusChanged(s.eventPublisher, s.log, user, entities.UserStatusPendingVerification, "email verified", uuid.Nil)
+	}
+	return nil
+}
+
+// hashToken is how single-use tokens sent to the user are stored. They are
+// random 256 bit values, so a plain SHA-256 is enough.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
+)
+
+const (
+	impersonationTokenTTL  = 15 * time.Minute
+	maxImpersonationReason = 500
+)
+
+// ImpersonationService lets support staff see what a user sees.
+type ImpersonationService interface {
+	// Impersonate issues a short-lived access token for the user that names
+	// the admin in its act claim. There is no refresh token; the admin asks
+	// again when it expires. Users with a permission the admin lacks cannot
+	// be impersonated, nor can staff.
+	Impersonate(ctx context.Context, userID, actorID uuid.UUID, reason string) (*token.AccessToken, error)
+}
+
+type ImpersonationServiceImpl struct {
+	userRepo     repositories.UserRepository
+	roleRepo     repositories.RoleRepository
+	auditLogRepo repositories.AuditLogRepository
+	tokenService token.TokenService
+	log          *logrus.Logger
+}
+
+func NewImpersonationService(
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	tokenService token.TokenService,
+	log *logrus.Logger,
+) ImpersonationService {
+	return &ImpersonationServiceImpl{
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		auditLogRepo: auditLogRepo,
+		tokenService: tokenService,
+		log:          log,
+	}
+}
+
+func (s *ImpersonationServiceImpl) Impersonate(ctx context.Context, userID, actorID uuid.UUID, reason string) (*token.AccessToken, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "reason", Message: "is required"}}}
+	}
+	if utf8.RuneCountInString(reason) > maxImpersonationReason {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "reason", Message: fmt.Sprintf("must be at most %d characters", maxImpersonationReason)}}}
+	}
+	if userID == actorID {
+		return nil, apperrors.ErrCannotImpersonate
+	}
+
+	actorDB, err := s.userRepo.GetUserByID(ctx, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to load admin: %w", err)
+	}
+	userDB, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrNotFound
+		}
+		return nil, fmt.Errorf("service: failed to load user: %w", err)
+	}
+	user := toDomainUser(userDB)
+	if entities.StatusBlocksAccess(user.Status, user.SuspendedUntil, time.Now()) {
+		return nil, apperrors.ErrCannotImpersonate
+	}
+
+	// The token carries the user's permissions, so impersonating must not
+	// give the admin any they do not have. Staff are never impersonated, or
+	// one admin could act in the name of another, e.g. to approve their own
+	// role grant.
+	userPermissions, err := s.roleRepo.ListUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if entities.GrantsStaffAccess(userPermissions) {
+		return nil, apperrors.ErrCannotImpersonate
+	}
+	actorPermissions, err := s.roleRepo.ListUserPermissions(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !token.HasPermissions(actorPermissions, userPermissions...) {
+		return nil, apperrors.ErrCannotImpersonate
+	}
+
+	accessToken, err := s.tokenService.GenerateAccessToken(ctx, user, token.AccessTokenOptions{
+		Actor: toDomainUser(actorDB),
+		TTL:   impersonationTokenTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrFailedToGenerateToken, err)
+	}
+
+	// The token is only handed out once the audit log has it.
+	if err := recordAuditLog(ctx, s.auditLogRepo, actorID, userID, entities.AuditImpersonationStarted, map[string]any{
+		"reason":     reason,
+		"token_id":   accessToken.ID,
+		"expires_at": accessToken.ExpiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{"user_id": userID, "actor_id": actorID, "token_id": accessToken.ID}).Info("Impersonation started")
+	return accessToken, nil
+}
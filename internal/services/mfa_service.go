@@ -0,0 +1,423 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/secretbox"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/totp"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+const recoveryCodeCount = 10
+
+type MFAConfig struct {
+	Issuer       string
+	ChallengeTTL time.Duration
+	MaxAttempts  int64
+}
+
+// MFAVerification is the second factor presented by the user: either a TOTP
+// code or one of the recovery codes.
+type MFAVerification struct {
+	Code         string
+	RecoveryCode string
+}
+
+type MFAService interface {
+	// BeginLogin is called after the password check. It returns nil when the
+	// user can be issued tokens right away.
+	BeginLogin(ctx context.Context, user *entities.User) (*entities.MFAChallenge, error)
+	// CompleteLogin consumes the challenge once the second factor checks out.
+	// When the login also finished a forced enrollment, the new recovery codes
+	// are returned and must be shown to the user.
+	CompleteLogin(ctx context.Context, challengeToken string, verification MFAVerification) (*entities.User, []string, error)
+	// StartChallengeEnrollment lets a user whose role requires MFA set it up
+	// with the challenge token, since they cannot get a full session yet.
+	StartChallengeEnrollment(ctx context.Context, challengeToken string) (*entities.TOTPEnrollment, error)
+
+	GetStatus(ctx context.Context, user *entities.User) (*entities.MFAStatus, error)
+	StartEnrollment(ctx context.Context, user *entities.User) (*entities.TOTPEnrollment, error)
+	ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	Disable(ctx context.Context, user *entities.User, verification MFAVerification) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+
+	ListRequiredRoles(ctx context.Context) ([]string, error)
+	RequireForRole(ctx context.Context, role string) error
+	UnrequireForRole(ctx context.Context, role string) error
+}
+
+type MFAServiceImpl struct {
+	mfaRepo       repositories.MFARepository
+	challengeRepo repositories.MFAChallengeRepository
+	userRepo      repositories.UserRepository
+	secretBox     *secretbox.Box
+	config        MFAConfig
+	log           *logrus.Logger
+}
+
+func NewMFAService(
+	mfaRepo repositories.MFARepository,
+	challengeRepo repositories.MFAChallengeRepository,
+	userRepo repositories.UserRepository,
+	secretBox *secretbox.Box,
+	config MFAConfig,
+	log *logrus.Logger,
+) MFAService {
+	return &MFAServiceImpl{
+		mfaRepo:       mfaRepo,
+		challengeRepo: challengeRepo,
+		userRepo:      userRepo,
+		secretBox:     secretBox,
+		config:        config,
+		log:           log,
+	}
+}
+
+func (s *MFAServiceImpl) BeginLogin(ctx context.Context, user *entities.User) (*entities.MFAChallenge, error) {
+	enabled := false
+	mfa, err := s.mfaRepo.GetMFA(ctx, user.ID)
+	switch {
+	case err == nil:
+		enabled = mfa.EnabledAt.Valid
+	case !errors.Is(err, apperrors.ErrMFANotEnrolled):
+		return nil, fmt.Errorf("service: failed to check mfa: %w", err)
+	}
+
+	required, err := s.mfaRepo.IsRequiredForUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to check mfa: %w", err)
+	}
+
+	if !enabled && !required {
+		return nil, nil
+	}
+
+	challengeToken, err := randomURLToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to generate mfa challenge: %w", err)
+	}
+
+	if err := s.challengeRepo.CreateChallenge(ctx, challengeToken, user.ID.String(), s.config.ChallengeTTL); err != nil {
+		return nil, fmt.Errorf("service: failed to create mfa challenge: %w", err)
+	}
+
+	return &entities.MFAChallenge{
+		Token:              challengeToken,
+		ExpiresAt:          time.Now().Add(s.config.ChallengeTTL),
+		EnrollmentRequired: !enabled,
+	}, nil
+}
+
+func (s *MFAServiceImpl) CompleteLogin(ctx context.Context, challengeToken string, verification MFAVerification) (*entities.User, []string, error) {
+	userID, err := s.challengeUserID(ctx, challengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attempts, err := s.challengeRepo.IncrementAttempts(ctx, challengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attempts > s.config.MaxAttempts {
+		if err := s.challengeRepo.DeleteChallenge(ctx, challengeToken); err != nil {
+			s.log.WithError(err).Warn("Failed to delete exhausted mfa challenge")
+		}
+		return nil, nil, apperrors.ErrInvalidToken
+	}
+
+	mfa, err := s.mfaRepo.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var recoveryCodes []string
+	if mfa.EnabledAt.Valid {
+		if err := s.verify(ctx, mfa, verification); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		// Forced enrollment: the first code both confirms the secret and
+		// completes the login.
+		recoveryCodes, err = s.confirm(ctx, mfa, verification.Code)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := s.challengeRepo.DeleteChallenge(ctx, challengeToken); err != nil {
+		return nil, nil, fmt.Errorf("service: failed to complete mfa login: %w", err)
+	}
+
+	userDB, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, apperrors.ErrInvalidToken
+	}
+
+	return toDomainUser(userDB), recoveryCodes, nil
+}
+
+func (s *MFAServiceImpl) StartChallengeEnrollment(ctx context.Context, challengeToken string) (*entities.TOTPEnrollment, error) {
+	userID, err := s.challengeUserID(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	userDB, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	return s.StartEnrollment(ctx, toDomainUser(userDB))
+}
+
+func (s *MFAServiceImpl) GetStatus(ctx context.Context, user *entities.User) (*entities.MFAStatus, error) {
+	required, err := s.mfaRepo.IsRequiredForUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get mfa status: %w", err)
+	}
+	status := &entities.MFAStatus{Required: required}
+
+	mfa, err := s.mfaRepo.GetMFA(ctx, user.ID)
+	if errors.Is(err, apperrors.ErrMFANotEnrolled) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get mfa status: %w", err)
+	}
+
+	if mfa.EnabledAt.Valid {
+		status.Enabled = true
+		status.EnabledAt = &mfa.EnabledAt.Time
+
+		status.RecoveryCodesRemaining, err = s.mfaRepo.CountUnusedRecoveryCodes(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to get mfa status: %w", err)
+		}
+	}
+	return status, nil
+}
+
+// StartEnrollment generates a new secret. Calling it again before confirming
+// replaces the pending secret; it fails once MFA is enabled.
+func (s *MFAServiceImpl) StartEnrollment(ctx context.Context, user *entities.User) (*entities.TOTPEnrollment, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to start mfa enrollment: %w", err)
+	}
+
+	sealed, err := s.secretBox.Seal(secret)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to start mfa enrollment: %w", err)
+	}
+
+	if _, err := s.mfaRepo.SavePendingMFA(ctx, &db.UpsertPendingUserMFAParams{UserID: user.ID, Secret: sealed}); err != nil {
+		return nil, err
+	}
+
+	return &entities.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.KeyURI(s.config.Issuer, user.Email, secret, totp.DefaultOptions),
+	}, nil
+}
+
+func (s *MFAServiceImpl) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.EnabledAt.Valid {
+		return nil, apperrors.ErrMFAAlreadyEnabled
+	}
+
+	return s.confirm(ctx, mfa, code)
+}
+
+func (s *MFAServiceImpl) Disable(ctx context.Context, user *entities.User, verification MFAVerification) error {
+	required, err := s.mfaRepo.IsRequiredForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("service: failed to disable mfa: %w", err)
+	}
+	if required {
+		return apperrors.ErrMFARequiredByRole
+	}
+
+	mfa, err := s.mfaRepo.GetMFA(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if mfa.EnabledAt.Valid {
+		if err := s.verify(ctx, mfa, verification); err != nil {
+			return err
+		}
+	}
+
+	if err := s.mfaRepo.DeleteMFA(ctx, user.ID); err != nil {
+		return fmt.Errorf("service: failed to disable mfa: %w", err)
+	}
+	return nil
+}
+
+func (s *MFAServiceImpl) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !mfa.EnabledAt.Valid {
+		return nil, apperrors.ErrMFANotEnrolled
+	}
+
+	if err := s.verify(ctx, mfa, MFAVerification{Code: code}); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, userID)
+}
+
+func (s *MFAServiceImpl) ListRequiredRoles(ctx context.Context) ([]string, error) {
+	roles, err := s.mfaRepo.ListRequiredRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list mfa required roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (s *MFAServiceImpl) RequireForRole(ctx context.Context, role string) error {
+	if err := s.mfaRepo.AddRequiredRole(ctx, role); err != nil {
+		return fmt.Errorf("service: failed to require mfa for role: %w", err)
+	}
+	return nil
+}
+
+func (s *MFAServiceImpl) UnrequireForRole(ctx context.Context, role string) error {
+	return s.mfaRepo.RemoveRequiredRole(ctx, role)
+}
+
+func (s *MFAServiceImpl) challengeUserID(ctx context.Context, challengeToken string) (uuid.UUID, error) {
+	if challengeToken == "" {
+		return uuid.Nil, apperrors.ErrInvalidToken
+	}
+
+	rawUserID, err := s.challengeRepo.GetChallengeUserID(ctx, challengeToken)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("service: invalid user id in mfa challenge: %w", err)
+	}
+	return userID, nil
+}
+
+// verify accepts either a TOTP code or an unused recovery code.
+func (s *MFAServiceImpl) verify(ctx context.Context, mfa *db.UserMfa, verification MFAVerification) error {
+	if verification.RecoveryCode != "" {
+		ok, err := s.mfaRepo.UseRecoveryCode(ctx, mfa.UserID, hashRecoveryCode(verification.RecoveryCode))
+		if err != nil {
+			return fmt.Errorf("service: failed to verify recovery code: %w", err)
+		}
+		if !ok {
+			return apperrors.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	step, err := s.checkTOTP(mfa, verification.Code)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.mfaRepo.UseTOTPStep(ctx, mfa.UserID, step)
+	if err != nil {
+		return fmt.Errorf("service: failed to verify totp code: %w", err)
+	}
+	if !ok {
+		return apperrors.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *MFAServiceImpl) confirm(ctx context.Context, mfa *db.UserMfa, code string) ([]string, error) {
+	step, err := s.checkTOTP(mfa, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.mfaRepo.EnableMFA(ctx, &db.EnableUserMFAParams{UserID: mfa.UserID, LastUsedStep: step}); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, mfa.UserID)
+}
+
+func (s *MFAServiceImpl) checkTOTP(mfa *db.UserMfa, code string) (int64, error) {
+	secret, err := s.secretBox.Open(mfa.Secret)
+	if err != nil {
+		return 0, fmt.Errorf("service: failed to read totp secret: %w", err)
+	}
+
+	key, err := totp.DecodeSecret(secret)
+	if err != nil {
+		return 0, fmt.Errorf("service: failed to read totp secret: %w", err)
+	}
+
+	step, ok := totp.Validate(key, code, time.Now(), totp.DefaultOptions)
+	if !ok {
+		return 0, apperrors.ErrInvalidMFACode
+	}
+	return step, nil
+}
+
+// issueRecoveryCodes replaces every recovery code of the user. Only hashes are
+// stored, so the returned codes can be shown exactly once.
+func (s *MFAServiceImpl) issueRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to generate recovery codes: %w", err)
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("service: failed to save recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCode returns 50 random bits formatted as "xxxxx-xxxxx".
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so codes can be typed the
+// way they are read. The codes carry enough entropy for a plain SHA-256.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
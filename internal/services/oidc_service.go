@@ -0,0 +1,505 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
+)
+
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+
+	pkceMethodS256 = "S256"
+)
+
+var supportedScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail}
+
+type OIDCConfig struct {
+	IssuerURL   string
+	AuthCodeTTL time.Duration
+	IDTokenTTL  time.Duration
+}
+
+// ClientCredentials is what a client presented at the token endpoint, either
+// through HTTP Basic auth or the request body.
+type ClientCredentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
+type OIDCService interface {
+	Discovery() *models.OIDCDiscovery
+	// ValidateAuthorizeRequest checks an authorization request. A nil client
+	// means client_id or redirect_uri could not be trusted and the error must
+	// be shown to the user instead of being redirected back to the client.
+	ValidateAuthorizeRequest(ctx context.Context, req *models.AuthorizeRequest) (*entities.OAuthClient, []string, error)
+	GetConsent(ctx context.Context, userID uuid.UUID, req *models.AuthorizeRequest) (*models.ConsentResponse, error)
+	// DecideConsent records the user's answer and returns the client redirect
+	// URL carrying either the authorization code or an access_denied error.
+	DecideConsent(ctx context.Context, userID uuid.UUID, req *models.ConsentDecisionRequest) (string, error)
+	Exchange(ctx context.Context, creds ClientCredentials, req *models.TokenRequest, metadata *ActivityMetadata) (*models.TokenResponse, error)
+	UserInfo(ctx context.Context, userID uuid.UUID, scope string) (*models.UserInfoResponse, error)
+
+	CreateClient(ctx context.Context, req *models.OAuthClientCreateRequest) (*entities.OAuthClient, string, error)
+	ListClients(ctx context.Context) ([]entities.OAuthClient, error)
+	DeleteClient(ctx context.Context, clientID string) error
+}
+
+type OIDCServiceImpl struct {
+	clientRepo     repositories.OAuthClientRepository
+	codeRepo       repositories.AuthorizationCodeRepository
+	userService    UserService
+	sessionService SessionService
+	tokenService   token.TokenService
+	validator      *validator.Validate
+	config         OIDCConfig
+	log            *logrus.Logger
+}
+
+func NewOIDCService(
+	clientRepo repositories.OAuthClientRepository,
+	codeRepo repositories.AuthorizationCodeRepository,
+	userService UserService,
+	sessionService SessionService,
+	tokenService token.TokenService,
+	validator *validator.Validate,
+	config OIDCConfig,
+	log *logrus.Logger,
+) OIDCService {
+	config.IssuerURL = strings.TrimSuffix(config.IssuerURL, "/")
+	return &OIDCServiceImpl{
+		clientRepo:     clientRepo,
+		codeRepo:       codeRepo,
+		userService:    userService,
+		sessionService: sessionService,
+		tokenService:   tokenService,
+		validator:      validator,
+		config:         config,
+		log:            log,
+	}
+}
+
+func (s *OIDCServiceImpl) Discovery() *models.OIDCDiscovery {
+	var algs []string
+	for _, key := range s.tokenService.JWKS().Keys {
+		if !slices.Contains(algs, key.Alg) {
+			algs = append(algs, key.Alg)
+		}
+	}
+
+	issuer := s.config.IssuerURL
+	return &models.OIDCDiscovery{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		UserInfoEndpoint:                  issuer + "/oauth/userinfo",
+		JWKSURI:                           issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  algs,
+		ScopesSupported:                   supportedScopes,
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{pkceMethodS256},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "name", "preferred_username", "email", "email_verified"},
+	}
+}
+
+func (s *OIDCServiceImpl) ValidateAuthorizeRequest(ctx context.Context, req *models.AuthorizeRequest) (*entities.OAuthClient, []string, error) {
+	if req.ClientID == "" {
+		return nil, nil, apperrors.NewOAuthError("invalid_request", "client_id is required")
+	}
+
+	clientDB, err := s.clientRepo.GetClient(ctx, req.ClientID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil, nil, apperrors.NewOAuthError("invalid_client", "unknown client")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("service: failed to validate authorize request: %w", err)
+	}
+
+	// Exact match only; no prefix or wildcard matching of redirect URIs.
+	if !slices.Contains(clientDB.RedirectUris, req.RedirectURI) {
+		return nil, nil, apperrors.NewOAuthError("invalid_request", "redirect_uri is not registered for this client")
+	}
+	client := toDomainOAuthClient(clientDB)
+
+	if req.ResponseType != "code" {
+		return client, nil, apperrors.NewOAuthError("unsupported_response_type", "only response_type=code is supported")
+	}
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != pkceMethodS256 {
+		return client, nil, apperrors.NewOAuthError("invalid_request", "PKCE with code_challenge_method=S256 is required")
+	}
+
+	scopes := strings.Fields(req.Scope)
+	if !slices.Contains(scopes, ScopeOpenID) {
+		return client, nil, apperrors.NewOAuthError("invalid_scope", "the openid scope is required")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(client.AllowedScopes, scope) {
+			return client, nil, apperrors.NewOAuthError("invalid_scope", fmt.Sprintf("scope %q is not allowed for this client", scope))
+		}
+	}
+
+	return client, scopes, nil
+}
+
+func (s *OIDCServiceImpl) GetConsent(ctx context.Context, userID uuid.UUID, req *models.AuthorizeRequest) (*models.ConsentResponse, error) {
+	client, scopes, err := s.ValidateAuthorizeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	required, err := s.consentRequired(ctx, userID, client, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ConsentResponse{
+		ClientID:        client.ID,
+		ClientName:      client.Name,
+		Scopes:          scopes,
+		ConsentRequired: required,
+	}, nil
+}
+
+func (s *OIDCServiceImpl) DecideConsent(ctx context.Context, userID uuid.UUID, req *models.ConsentDecisionRequest) (string, error) {
+	client, scopes, err := s.ValidateAuthorizeRequest(ctx, &req.AuthorizeRequest)
+	if err != nil {
+		return "", err
+	}
+
+	if !req.Approve {
+		return AuthorizeErrorRedirect(req.RedirectURI, req.State, apperrors.NewOAuthError("access_denied", "the user denied the request")), nil
+	}
+
+	if !client.IsFirstParty {
+		if _, err := s.clientRepo.SaveConsent(ctx, &db.UpsertOAuthConsentParams{
+			UserID:   userID,
+			ClientID: client.ID,
+			Scopes:   scopes,
+		}); err != nil {
+			return "", fmt.Errorf("service: failed to save consent: %w", err)
+		}
+	}
+
+	code, err := randomURLToken(32)
+	if err != nil {
+		return "", fmt.Errorf("service: failed to generate authorization code: %w", err)
+	}
+
+	err = s.codeRepo.SaveCode(ctx, code, &repositories.AuthorizationCode{
+		ClientID:            client.ID,
+		UserID:              userID.String(),
+		RedirectURI:         req.RedirectURI,
+		Scopes:              scopes,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		AuthTime:            time.Now(),
+	}, s.config.AuthCodeTTL)
+	if err != nil {
+		return "", fmt.Errorf("service: failed to save authorization code: %w", err)
+	}
+
+	return withQuery(req.RedirectURI, url.Values{"code": {code}, "state": {req.State}}), nil
+}
+
+func (s *OIDCServiceImpl) Exchange(ctx context.Context, creds ClientCredentials, req *models.TokenRequest, metadata *ActivityMetadata) (*models.TokenResponse, error) {
+	client, err := s.authenticateClient(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case "authorization_code":
+		return s.exchangeAuthorizationCode(ctx, client, req, metadata)
+	case "refresh_token":
+		return s.exchangeRefreshToken(ctx, client, req, metadata)
+	default:
+		return nil, apperrors.NewOAuthError("unsupported_grant_type", "")
+	}
+}
+
+func (s *OIDCServiceImpl) exchangeAuthorizationCode(ctx context.Context, client *entities.OAuthClient, req *models.TokenRequest, metadata *ActivityMetadata) (*models.TokenResponse, error) {
+	code, err := s.codeRepo.ConsumeCode(ctx, req.Code)
+	if errors.Is(err, apperrors.ErrInvalidToken) {
+		return nil, apperrors.NewOAuthError("invalid_grant", "authorization code is invalid, expired or already used")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to exchange authorization code: %w", err)
+	}
+
+	if code.ClientID != client.ID || code.RedirectURI != req.RedirectURI {
+		return nil, apperrors.NewOAuthError("invalid_grant", "authorization code was issued to another client or redirect_uri")
+	}
+	if !verifyPKCE(req.CodeVerifier, code.CodeChallenge) {
+		return nil, apperrors.NewOAuthError("invalid_grant", "code_verifier does not match code_challenge")
+	}
+
+	userID, err := uuid.Parse(code.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("service: invalid user id in authorization code: %w", err)
+	}
+
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.NewOAuthError("invalid_grant", "user no longer exists")
+	}
+
+	tokens, err := s.sessionService.CreateSession(ctx, user, metadata, SessionOptions{ClientID: client.ID, Scopes: code.Scopes})
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := s.tokenService.GenerateIDToken(ctx, user, token.IDTokenOptions{
+		Issuer:   s.config.IssuerURL,
+		ClientID: client.ID,
+		Nonce:    code.Nonce,
+		AuthTime: code.AuthTime,
+		Scopes:   code.Scopes,
+		TTL:      s.config.IDTokenTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrFailedToGenerateToken, err)
+	}
+
+	res := toTokenResponse(tokens)
+	res.IDToken = idToken
+	return res, nil
+}
+
+func (s *OIDCServiceImpl) exchangeRefreshToken(ctx context.Context, client *entities.OAuthClient, req *models.TokenRequest, metadata *ActivityMetadata) (*models.TokenResponse, error) {
+	tokens, err := s.sessionService.RefreshSession(ctx, req.RefreshToken, client.ID, metadata)
+	if errors.Is(err, apperrors.ErrInvalidToken) || errors.Is(err, apperrors.ErrRefreshTokenReused) {
+		return nil, apperrors.NewOAuthError("invalid_grant", "refresh token is invalid or revoked")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toTokenResponse(tokens), nil
+}
+
+// authenticateClient requires the secret for confidential clients. Public
+// clients only identify themselves; PKCE is what protects their codes.
+func (s *OIDCServiceImpl) authenticateClient(ctx context.Context, creds ClientCredentials) (*entities.OAuthClient, error) {
+	invalidClient := apperrors.NewOAuthError("invalid_client", "client authentication failed")
+	if creds.ClientID == "" {
+		return nil, invalidClient
+	}
+
+	clientDB, err := s.clientRepo.GetClient(ctx, creds.ClientID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil, invalidClient
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to authenticate client: %w", err)
+	}
+
+	if clientDB.SecretHash != "" {
+		if bcrypt.CompareHashAndPassword([]byte(clientDB.SecretHash), []byte(creds.ClientSecret)) != nil {
+			return nil, invalidClient
+		}
+	}
+
+	return toDomainOAuthClient(clientDB), nil
+}
+
+func (s *OIDCServiceImpl) UserInfo(ctx context.Context, userID uuid.UUID, scope string) (*models.UserInfoResponse, error) {
+	user, err := s.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// First-party tokens carry no scope and see every claim.
+	scopes := strings.Fields(scope)
+	res := &models.UserInfoResponse{Subject: user.ID.String()}
+	if len(scopes) == 0 || slices.Contains(scopes, ScopeProfile) {
+		res.Name = user.Name
+		res.PreferredUsername = user.Username
+	}
+	if len(scopes) == 0 || slices.Contains(scopes, ScopeEmail) {
+		verified := user.EmailVerifiedAt != nil
+		res.Email = user.Email
+		res.EmailVerified = &verified
+	}
+	return res, nil
+}
+
+func (s *OIDCServiceImpl) CreateClient(ctx context.Context, req *models.OAuthClientCreateRequest) (*entities.OAuthClient, string, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", apperrors.ErrInvalidRequestPayload, err)
+	}
+
+	for _, scope := range req.AllowedScopes {
+		if !slices.Contains(supportedScopes, scope) {
+			return nil, "", apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{
+				Field:   "allowed_scopes",
+				Message: fmt.Sprintf("unsupported scope %q", scope),
+			}}}
+		}
+	}
+
+	var secret, secretHash string
+	if req.Confidential {
+		var err error
+		secret, err = randomURLToken(32)
+		if err != nil {
+			return nil, "", fmt.Errorf("service: failed to generate client secret: %w", err)
+		}
+
+		hashed, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, "", fmt.Errorf("service: failed to hash client secret: %w", err)
+		}
+		secretHash = string(hashed)
+	}
+
+	clientDB, err := s.clientRepo.CreateClient(ctx, &db.CreateOAuthClientParams{
+		ID:            uuid.New().String(),
+		Name:          req.Name,
+		SecretHash:    secretHash,
+		RedirectUris:  req.RedirectURIs,
+		AllowedScopes: req.AllowedScopes,
+		IsFirstParty:  req.IsFirstParty,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("service: failed to create oauth client: %w", err)
+	}
+
+	return toDomainOAuthClient(clientDB), secret, nil
+}
+
+func (s *OIDCServiceImpl) ListClients(ctx context.Context) ([]entities.OAuthClient, error) {
+	rows, err := s.clientRepo.ListClients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list oauth clients: %w", err)
+	}
+
+	clients := make([]entities.OAuthClient, 0, len(rows))
+	for i := range rows {
+		clients = append(clients, *toDomainOAuthClient(&rows[i]))
+	}
+	return clients, nil
+}
+
+func (s *OIDCServiceImpl) DeleteClient(ctx context.Context, clientID string) error {
+	if _, err := s.clientRepo.DeleteClient(ctx, clientID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *OIDCServiceImpl) consentRequired(ctx context.Context, userID uuid.UUID, client *entities.OAuthClient, scopes []string) (bool, error) {
+	if client.IsFirstParty {
+		return false, nil
+	}
+
+	consent, err := s.clientRepo.GetConsent(ctx, userID, client.ID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("service: failed to get consent: %w", err)
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(consent.Scopes, scope) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AuthorizeErrorRedirect builds the redirect back to the client for an error
+// raised after the redirect URI was verified.
+func AuthorizeErrorRedirect(redirectURI string, state string, oauthErr *apperrors.OAuthError) string {
+	params := url.Values{"error": {oauthErr.Code}}
+	if oauthErr.Description != "" {
+		params.Set("error_description", oauthErr.Description)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return withQuery(redirectURI, params)
+}
+
+func withQuery(rawURL string, params url.Values) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func verifyPKCE(verifier string, challenge string) bool {
+	// RFC 7636 section 4.1
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+func randomURLToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func toTokenResponse(tokens *TokenPair) *models.TokenResponse {
+	return &models.TokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(time.Until(tokens.ExpiresAt).Seconds()),
+		RefreshToken: tokens.RefreshToken,
+		Scope:        strings.Join(tokens.Scopes, " "),
+	}
+}
+
+func toDomainOAuthClient(client *db.OauthClient) *entities.OAuthClient {
+	return &entities.OAuthClient{
+		ID:             client.ID,
+		Name:           client.Name,
+		RedirectURIs:   client.RedirectUris,
+		AllowedScopes:  client.AllowedScopes,
+		IsFirstParty:   client.IsFirstParty,
+		IsConfidential: client.SecretHash != "",
+		CreatedAt:      client.CreatedAt,
+		UpdatedAt:      client.UpdatedAt,
+	}
+}
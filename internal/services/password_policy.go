@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+// bcrypt ignores everything after 72 bytes.
+const maxPasswordBytes = 72
+
+// PasswordPolicy is what every new password has to satisfy.
+type PasswordPolicy struct {
+	MinLength int
+	// MinCharacterClasses is how many of lowercase letters, uppercase letters,
+	// digits and symbols the password has to mix.
+	MinCharacterClasses int
+}
+
+// Check reports every rule the password breaks under field. A password may not
+// contain any of personal, such as the username or the email's local part.
+func (p PasswordPolicy) Check(field, password string, personal ...string) []apperrors.ValidationError {
+	var errs []apperrors.ValidationError
+	fail := func(message string) {
+		errs = append(errs, apperrors.ValidationError{Field: field, Message: message})
+	}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		fail(fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		fail(fmt.Sprintf("must be at most %d bytes long", maxPasswordBytes))
+	}
+	if characterClasses(password) < p.MinCharacterClasses {
+		fail(fmt.Sprintf("must mix at least %d of lowercase letters, uppercase letters, digits and symbols", p.MinCharacterClasses))
+	}
+
+	lower := strings.ToLower(password)
+	for _, value := range personal {
+		if len(value) >= 3 && strings.Contains(lower, strings.ToLower(value)) {
+			fail("must not contain your username or email")
+			break
+		}
+	}
+	return errs
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// emailLocalPart is the part of an address before the @.
+func emailLocalPart(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+	return local
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/messaging/rabbitmq"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+type PasswordConfig struct {
+	// ResetURL is the page the emailed link points to; the token is added as ?token=.
+	ResetURL             string
+	ResetTokenTTL        time.Duration
+	ResetRequestCooldown time.Duration
+	// HistorySize is how many previous passwords cannot be reused.
+	HistorySize int
+}
+
+type PasswordService interface {
+	// RequestReset emails a reset link when the address belongs to an account.
+	// It returns before any lookup is made, so callers cannot tell whether it
+	// did.
+	RequestReset(ctx context.Context, req *models.ForgotPasswordRequest) error
+	// ResetPassword sets a new password with a reset token and signs the user
+	// out everywhere.
+	ResetPassword(ctx context.Context, req *models.ResetPasswordRequest, metadata *ActivityMetadata) error
+	// ChangePassword sets a new password after checking the current one,
+	// signs the user out everywhere and returns a new session for the caller.
+	ChangePassword(ctx context.Context, userID uuid.UUID, req *models.ChangePasswordRequest, metadata *ActivityMetadata) (*TokenPair, error)
+}
+
+type PasswordServiceImpl struct {
+	userRepo       repositories.UserRepository
+	resetRepo      repositories.PasswordResetRepository
+	historyRepo    repositories.PasswordHistoryRepository
+	throttleRepo   repositories.ThrottleRepository
+	sessionService SessionService
+	validator      *validator.Validate
+	eventPublisher *rabbitmq.EventPublisher
+	policy         PasswordPolicy
+	config         PasswordConfig
+	log            *logrus.Logger
+}
+
+func NewPasswordService(
+	userRepo repositories.UserRepository,
+	resetRepo repositories.PasswordResetRepository,
+	historyRepo repositories.PasswordHistoryRepository,
+	throttleRepo repositories.ThrottleRepository,
+	sessionService SessionService,
+	validator *validator.Validate,
+	eventPublisher *rabbitmq.EventPublisher,
+	policy PasswordPolicy,
+	config PasswordConfig,
+	log *logrus.Logger,
+) PasswordService {
+	return &PasswordServiceImpl{
+		userRepo:       userRepo,
+		resetRepo:      resetRepo,
+		historyRepo:    historyRepo,
+		throttleRepo:   throttleRepo,
+		sessionService: sessionService,
+		validator:      validator,
+		eventPublisher: eventPublisher,
+		policy:         policy,
+		config:         config,
+		log:            log,
+	}
+}
+
+func (s *PasswordServiceImpl) RequestReset(ctx context.Context, req *models.ForgotPasswordRequest) error {
+	if err := s.validator.Struct(req); err != nil {
+		return fmt.Errorf("%w: %s", apperrors.ErrInvalidRequestPayload, err)
+	}
+
+	// Looking the account up in the background keeps the response time the
+	// same for known and unknown addresses.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := s.sendResetLink(ctx, req.Email); err != nil {
+			s.log.WithError(err).Error("Failed to send password reset link")
+		}
+	}()
+
+	return nil
+}
+
+func (s *PasswordServiceImpl) sendResetLink(ctx context.Context, email string) error {
+	userDB, err := s.userRepo.GetUserByEmail(ctx, email)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("service: failed to look up user: %w", err)
+	}
+
+	allowed, err := s.throttleRepo.Allow(ctx, "password_reset:"+userDB.ID.String(), s.config.ResetRequestCooldown)
+	if err != nil {
+		return fmt.Errorf("service: failed to check reset throttle: %w", err)
+	}
+	if !allowed {
+		return nil
+	}
+
+	token, err := randomURLToken(32)
+	if err != nil {
+		return fmt.Errorf("service: failed to generate reset token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(s.config.ResetTokenTTL)
+	err = s.resetRepo.ReplaceToken(ctx, &db.CreatePasswordResetTokenParams{
+		TokenHash: hashToken(token),
+		UserID:    userDB.ID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("service: failed to create reset link: %w", err)
+	}
+
+	event := rabbitmq.PasswordResetRequestedEvent{
+		UserID:    userDB.ID.String(),
+		Email:     userDB.Email,
+		Username:  userDB.Username,
+		ResetURL:  withQuery(s.config.ResetURL, url.Values{"token": {token}}),
+		ExpiresAt: expiresAt,
+	}
+	return s.eventPublisher.PublishPasswordResetRequested(ctx, event)
+}
+
+func (s *PasswordServiceImpl) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest, metadata *ActivityMetadata) error {
+	if err := s.validator.Struct(req); err != nil {
+		return fmt.Errorf("%w: %s", apperrors.ErrInvalidRequestPayload, err)
+	}
+
+	tokenHash := hashToken(req.Token)
+
+	// The token is only used up once the new password is accepted, so a
+	// rejected password can be retried with the same link.
+	userID, err := s.resetRepo.FindToken(ctx, tokenHash)
+	if err != nil {
+		return err
+	}
+
+	userDB, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service: failed to reset password: %w", err)
+	}
+
+	if err := s.checkNewPassword(ctx, userDB, req.NewPassword); err != nil {
+		return err
+	}
+
+	if usedBy, err := s.resetRepo.UseToken(ctx, tokenHash); err != nil {
+		return err
+	} else if usedBy != userID {
+		return apperrors.ErrInvalidToken
+	}
+
+	if err := s.storePassword(ctx, userDB, req.NewPassword); err != nil {
+		return err
+	}
+
+	if err := s.sessionService.LogoutEverywhere(ctx, userID); err != nil {
+		return fmt.Errorf("service: failed to revoke sessions after password reset: %w", err)
+	}
+
+	s.notifyPasswordChanged(toDomainUser(userDB), metadata)
+	return nil
+}
+
+func (s *PasswordServiceImpl) ChangePassword(ctx context.Context, userID uuid.UUID, req *models.ChangePasswordRequest, metadata *ActivityMetadata) (*TokenPair, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, fmt.Errorf("%w: %s", apperrors.ErrInvalidRequestPayload, err)
+	}
+
+	userDB, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to change password: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(userDB.Password), []byte(req.CurrentPassword)); err != nil {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{
+			Field:   "current_password",
+			Message: "is incorrect",
+		}}}
+	}
+
+	if err := s.checkNewPassword(ctx, userDB, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	if err := s.storePassword(ctx, userDB, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	// Bumping the token epoch also kills the caller's access token, so they
+	// get a new session in place of the one they used.
+	if err := s.sessionService.LogoutEverywhere(ctx, userID); err != nil {
+		return nil, fmt.Errorf("service: failed to revoke sessions after password change: %w", err)
+	}
+
+	user := toDomainUser(userDB)
+	tokens, err := s.sessionService.CreateSession(ctx, user, metadata, SessionOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyPasswordChanged(user, metadata)
+	return tokens, nil
+}
+
+// checkNewPassword runs the policy and rejects the current password and the
+// ones in the user's history.
+func (s *PasswordServiceImpl) checkNewPassword(ctx context.Context, userDB *db.GetUserByIDRow, password string) error {
+	errs := s.policy.Check("new_password", password, userDB.Username, emailLocalPart(userDB.Email))
+	if len(errs) > 0 {
+		return apperrors.ValidationErrors{Errors: errs}
+	}
+
+	previous := []string{userDB.Password}
+	if s.config.HistorySize > 0 {
+		history, err := s.historyRepo.Recent(ctx, userDB.ID, s.config.HistorySize)
+		if err != nil {
+			return fmt.Errorf("service: failed to check password history: %w", err)
+		}
+		previous = append(previous, history...)
+	}
+
+	for _, hash := range previous {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{
+				Field:   "new_password",
+				Message: "must not be one of your recent passwords",
+			}}}
+		}
+	}
+	return nil
+}
+
+// storePassword saves the new hash, moves the old one into the history and
+// invalidates outstanding reset links.
+func (s *PasswordServiceImpl) storePassword(ctx context.Context, userDB *db.GetUserByIDRow, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("service: failed to generate password hash: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userDB.ID, string(hashedPassword)); err != nil {
+		return fmt.Errorf("service: failed to update password: %w", err)
+	}
+
+	if s.config.HistorySize > 0 {
+		if err := s.historyRepo.Add(ctx, userDB.ID, userDB.Password, s.config.HistorySize); err != nil {
+			s.log.WithError(err).Error("Failed to record password history")
+		}
+	}
+
+	if err := s.resetRepo.DeleteTokens(ctx, userDB.ID); err != nil {
+		s.log.WithError(err).Error("Failed to delete password reset tokens")
+	}
+	return nil
+}
+
+func (s *PasswordServiceImpl) notifyPasswordChanged(user *entities.User, metadata *ActivityMetadata) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		event := rabbitmq.PasswordChangedEvent{
+			UserID:    user.ID.String(),
+			Email:     user.Email,
+			Username:  user.Username,
+			ChangedAt: time.Now(),
+		}
+		if metadata != nil {
+			event.IPAddress = metadata.IPAddress
+			event.UserAgent = metadata.UserAgent
+		}
+		if err := s.eventPublisher.PublishPasswordChanged(ctx, event); err != nil {
+			s.log.WithError(err).Error("Failed to publish password changed event")
+		}
+	}()
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"strings"
+
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/phone"
+)
+
+// PhonePolicy is how phone numbers typed by users are read.
+type PhonePolicy struct {
+	// DefaultCountryCode applies to numbers written without one, such as
+	// "0812..." for an Indonesian mobile.
+	DefaultCountryCode string
+}
+
+// Normalize returns number in E.164 form. An empty number stays empty; the
+// caller decides whether it is required.
+func (p PhonePolicy) Normalize(field, number string) (string, []apperrors.ValidationError) {
+	if strings.TrimSpace(number) == "" {
+		return "", nil
+	}
+
+	normalized, err := phone.Normalize(number, p.DefaultCountryCode)
+	if err != nil {
+		return "", []apperrors.ValidationError{{Field: field, Message: "must be a valid phone number, such as +6281234567890"}}
+	}
+	return normalized, nil
+}
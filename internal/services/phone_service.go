@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/sms"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+type PhoneConfig struct {
+	OTPLength int
+	OTPTTL    time.Duration
+	// MaxAttempts is how often a code may be entered before it is discarded.
+	MaxAttempts int64
+	// MaxFailures wrong codes within FailureWindow lock the phone number until
+	// the window is over, however many new codes are sent to it.
+	MaxFailures   int64
+	FailureWindow time.Duration
+	// ResendCooldown applies per phone number, so nobody can flood a number
+	// with texts.
+	ResendCooldown time.Duration
+}
+
+// PhoneOTP describes a code that was just texted.
+type PhoneOTP struct {
+	ExpiresAt time.Time
+}
+
+type PhoneService interface {
+	// SendVerificationCode texts a code to the number on the user's profile.
+	SendVerificationCode(ctx context.Context, user *entities.User) (*PhoneOTP, error)
+	// Verify marks the profile's number as verified if code is the one sent
+	// to it.
+	Verify(ctx context.Context, userID uuid.UUID, code string) (*entities.User, error)
+	// SendLoginCode texts a login code to a verified number. It answers the
+	// same whether or not an account uses the number.
+	SendLoginCode(ctx context.Context, phoneNumber string) (*PhoneOTP, error)
+	// Login returns the user whose verified number received code.
+	Login(ctx context.Context, phoneNumber string, code string) (*entities.User, error)
+}
+
+type PhoneServiceImpl struct {
+	userRepo     repositories.UserRepository
+	otpRepo      repositories.PhoneOTPRepository
+	throttleRepo repositories.ThrottleRepository
+	sender       sms.Sender
+	policy       PhonePolicy
+	config       PhoneConfig
+	log          *logrus.Logger
+}
+
+func NewPhoneService(
+	userRepo repositories.UserRepository,
+	otpRepo repositories.PhoneOTPRepository,
+	throttleRepo repositories.ThrottleRepository,
+	sender sms.Sender,
+	policy PhonePolicy,
+	config PhoneConfig,
+	log *logrus.Logger,
+) PhoneService {
+	return &PhoneServiceImpl{
+		userRepo:     userRepo,
+		otpRepo:      otpRepo,
+		throttleRepo: throttleRepo,
+		sender:       sender,
+		policy:       policy,
+		config:       config,
+		log:          log,
+	}
+}
+
+func verifyPhoneKey(userID uuid.UUID) string {
+	return "verify:" + userID.String()
+}
+
+func loginPhoneKey(phoneNumber string) string {
+	return "login:" + phoneNumber
+}
+
+func (s *PhoneServiceImpl) SendVerificationCode(ctx context.Context, user *entities.User) (*PhoneOTP, error) {
+	if user.PhoneNumber == "" {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "phone_number", Message: "add a phone number to your profile first"}}}
+	}
+	if user.PhoneVerifiedAt != nil {
+		return nil, apperrors.ErrPhoneAlreadyVerified
+	}
+
+	// Numbers saved before normalization cannot be verified as they are.
+	normalized, phoneErrors := s.policy.Normalize("phone_number", user.PhoneNumber)
+	if len(phoneErrors) > 0 || normalized != user.PhoneNumber {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "phone_number", Message: "update your profile to a valid phone number, such as +6281234567890"}}}
+	}
+
+	owner, err := s.userRepo.GetUserByPhoneNumber(ctx, user.PhoneNumber)
+	switch {
+	case err == nil && owner.ID != user.ID:
+		return nil, apperrors.ErrPhoneNumberTaken
+	case err != nil && !errors.Is(err, apperrors.ErrUserNotFound):
+		return nil, fmt.Errorf("service: failed to send phone verification code: %w", err)
+	}
+
+	code, otp, err := s.issueCode(ctx, verifyPhoneKey(user.ID), user.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sender.Send(ctx, user.PhoneNumber, s.message(code)); err != nil {
+		return nil, fmt.Errorf("service: failed to send phone verification code: %w", err)
+	}
+	return otp, nil
+}
+
+func (s *PhoneServiceImpl) Verify(ctx context.Context, userID uuid.UUID, code string) (*entities.User, error) {
+	record, err := s.checkCode(ctx, verifyPhoneKey(userID), code)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.MarkPhoneVerified(ctx, userID, record.PhoneNumber)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		// The profile got another number after the code was sent.
+		return nil, apperrors.ErrInvalidOTP
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toDomainUser(user), nil
+}
+
+func (s *PhoneServiceImpl) SendLoginCode(ctx context.Context, phoneNumber string) (*PhoneOTP, error) {
+	phoneNumber, phoneErrors := s.policy.Normalize("phone_number", phoneNumber)
+	if len(phoneErrors) > 0 {
+		return nil, apperrors.ValidationErrors{Errors: phoneErrors}
+	}
+	if phoneNumber == "" {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "phone_number", Message: "is required"}}}
+	}
+
+	_, err := s.userRepo.GetUserByPhoneNumber(ctx, phoneNumber)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		// Same answer as for a known number, cooldown included.
+		if err := s.throttle(ctx, phoneNumber); err != nil {
+			return nil, err
+		}
+		return &PhoneOTP{ExpiresAt: time.Now().Add(s.config.OTPTTL)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to send login code: %w", err)
+	}
+
+	code, otp, err := s.issueCode(ctx, loginPhoneKey(phoneNumber), phoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sent in the background so the response time does not tell whether the
+	// number belongs to an account.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := s.sender.Send(ctx, phoneNumber, s.message(code)); err != nil {
+			s.log.WithError(err).Error("Failed to send login code")
+		}
+	}()
+
+	return otp, nil
+}
+
+func (s *PhoneServiceImpl) Login(ctx context.Context, phoneNumber string, code string) (*entities.User, error) {
+	phoneNumber, phoneErrors := s.policy.Normalize("phone_number", phoneNumber)
+	if phoneNumber == "" || len(phoneErrors) > 0 {
+		return nil, apperrors.ErrInvalidOTP
+	}
+
+	if _, err := s.checkCode(ctx, loginPhoneKey(phoneNumber), code); err != nil {
+		return nil, err
+	}
+
+	userDB, err := s.userRepo.GetUserByPhoneNumber(ctx, phoneNumber)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return nil, apperrors.ErrInvalidOTP
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to login: %w", err)
+	}
+
+	user := toDomainUser(userDB)
+	if err := accountStatusError(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// throttle reports ErrTooManyRequests while phoneNumber is in its cooldown.
+func (s *PhoneServiceImpl) throttle(ctx context.Context, phoneNumber string) error {
+	allowed, err := s.throttleRepo.Allow(ctx, "phone_otp:"+phoneNumber, s.config.ResendCooldown)
+	if err != nil {
+		return fmt.Errorf("service: failed to check phone otp cooldown: %w", err)
+	}
+	if !allowed {
+		return apperrors.ErrTooManyRequests
+	}
+	return nil
+}
+
+// issueCode stores a new code under key, replacing any earlier one.
+func (s *PhoneServiceImpl) issueCode(ctx context.Context, key, phoneNumber string) (string, *PhoneOTP, error) {
+	if err := s.throttle(ctx, phoneNumber); err != nil {
+		return "", nil, err
+	}
+
+	code, err := generateOTP(s.config.OTPLength)
+	if err != nil {
+		return "", nil, fmt.Errorf("service: failed to generate phone otp: %w", err)
+	}
+
+	if err := s.otpRepo.CreateCode(ctx, key, phoneNumber, hashOTP(key, code), s.config.OTPTTL); err != nil {
+		return "", nil, fmt.Errorf("service: failed to store phone otp: %w", err)
+	}
+	return code, &PhoneOTP{ExpiresAt: time.Now().Add(s.config.OTPTTL)}, nil
+}
+
+// checkCode consumes the code under key if it matches. Each entry counts
+// against MaxAttempts, after which the code is discarded, and each wrong one
+// against MaxFailures for the phone number, which no new code resets.
+func (s *PhoneServiceImpl) checkCode(ctx context.Context, key, code string) (*repositories.PhoneOTPRecord, error) {
+	if code == "" {
+		return nil, apperrors.ErrInvalidOTP
+	}
+
+	record, err := s.otpRepo.GetCode(ctx, key)
+	if errors.Is(err, apperrors.ErrInvalidToken) {
+		return nil, apperrors.ErrInvalidOTP
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	failures, err := s.otpRepo.Failures(ctx, record.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if failures >= s.config.MaxFailures {
+		return nil, apperrors.ErrTooManyRequests
+	}
+
+	attempts, err := s.otpRepo.IncrementAttempts(ctx, key)
+	if errors.Is(err, apperrors.ErrInvalidToken) {
+		return nil, apperrors.ErrInvalidOTP
+	}
+	if err != nil {
+		return nil, err
+	}
+	if attempts > s.config.MaxAttempts {
+		if err := s.otpRepo.DeleteCode(ctx, key); err != nil {
+			s.log.WithError(err).Warn("Failed to delete exhausted phone otp")
+		}
+		return nil, apperrors.ErrInvalidOTP
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashOTP(key, code)), []byte(record.CodeHash)) != 1 {
+		if _, err := s.otpRepo.CountFailure(ctx, record.PhoneNumber, s.config.FailureWindow); err != nil {
+			return nil, err
+		}
+		return nil, apperrors.ErrInvalidOTP
+	}
+
+	if err := s.otpRepo.DeleteCode(ctx, key); err != nil {
+		return nil, fmt.Errorf("service: failed to consume phone otp: %w", err)
+	}
+	return record, nil
+}
+
+func (s *PhoneServiceImpl) message(code string) string {
+	return fmt.Sprintf("Your verification code is %s. It expires in %d minutes. Never share this code with anyone.", code, int(s.config.OTPTTL.Minutes()))
+}
+
+// generateOTP returns length random decimal digits.
+func generateOTP(length int) (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", length, n), nil
+}
+
+// hashOTP binds the code to its key, so equal codes hash differently.
+func hashOTP(key, code string) string {
+	return hashToken(key + ":" + code)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// Timezones are checked against the embedded database, so they validate
+	// the same on hosts without zoneinfo.
+	_ "time/tzdata"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+// PreferencesPolicy is what users may choose for their display preferences,
+// and what applies until they do.
+type PreferencesPolicy struct {
+	Locales         []string
+	Currencies      []string
+	DefaultLocale   string
+	DefaultTimezone string
+	DefaultCurrency string
+}
+
+// Validate checks that the defaults are among the choices.
+func (p PreferencesPolicy) Validate() error {
+	if _, ok := matchFold(p.Locales, p.DefaultLocale); !ok {
+		return fmt.Errorf("default locale %q is not one of %v", p.DefaultLocale, p.Locales)
+	}
+	if _, ok := matchFold(p.Currencies, p.DefaultCurrency); !ok {
+		return fmt.Errorf("default currency %q is not one of %v", p.DefaultCurrency, p.Currencies)
+	}
+	if _, ok := normalizeTimezone(p.DefaultTimezone); !ok {
+		return fmt.Errorf("default timezone %q is not an IANA time zone", p.DefaultTimezone)
+	}
+	return nil
+}
+
+// Normalize rewrites the values in req to their canonical spelling, such as
+// "en_us" to "en-US", and reports the ones that are not allowed.
+func (p PreferencesPolicy) Normalize(req *models.PreferencesPatchRequest) []apperrors.ValidationError {
+	var errs []apperrors.ValidationError
+
+	if req.Locale != nil {
+		locale, ok := matchFold(p.Locales, strings.ReplaceAll(strings.TrimSpace(*req.Locale), "_", "-"))
+		if !ok {
+			errs = append(errs, apperrors.ValidationError{Field: "locale", Message: "must be one of " + strings.Join(p.Locales, ", ")})
+		}
+		req.Locale = &locale
+	}
+	if req.Timezone != nil {
+		timezone, ok := normalizeTimezone(*req.Timezone)
+		if !ok {
+			errs = append(errs, apperrors.ValidationError{Field: "timezone", Message: "must be an IANA time zone, such as Asia/Jakarta"})
+		}
+		req.Timezone = &timezone
+	}
+	if req.Currency != nil {
+		currency, ok := matchFold(p.Currencies, strings.TrimSpace(*req.Currency))
+		if !ok {
+			errs = append(errs, apperrors.ValidationError{Field: "currency", Message: "must be one of " + strings.Join(p.Currencies, ", ")})
+		}
+		req.Currency = &currency
+	}
+
+	return errs
+}
+
+// matchFold returns the entry of choices that equals value ignoring case.
+func matchFold(choices []string, value string) (string, bool) {
+	for _, choice := range choices {
+		if strings.EqualFold(choice, value) {
+			return choice, true
+		}
+	}
+	return "", false
+}
+
+// normalizeTimezone accepts IANA zone names. "Local" and the empty name are
+// refused, since they mean whatever zone the server runs in.
+func normalizeTimezone(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "Local" {
+		return "", false
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return "", false
+	}
+	return location.String(), true
+}
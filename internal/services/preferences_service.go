@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+type PreferencesService interface {
+	// GetPreferences returns the defaults for users who never changed them.
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*entities.UserPreferences, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, req *models.PreferencesPatchRequest) (*entities.UserPreferences, error)
+	// EmailRecipient returns the user and preferences to send a
+	// non-transactional email of category with, or a nil user when the email
+	// must not be sent: the user did not opt in, is gone or blocked, or has
+	// not verified the address.
+	EmailRecipient(ctx context.Context, userID uuid.UUID, category string) (*entities.User, *entities.UserPreferences, error)
+}
+
+type PreferencesServiceImpl struct {
+	preferencesRepo repositories.PreferencesRepository
+	userRepo        repositories.UserRepository
+	policy          PreferencesPolicy
+	log             *logrus.Logger
+}
+
+func NewPreferencesService(preferencesRepo repositories.PreferencesRepository, userRepo repositories.UserRepository, policy PreferencesPolicy, log *logrus.Logger) PreferencesService {
+	return &PreferencesServiceImpl{
+		preferencesRepo: preferencesRepo,
+		userRepo:        userRepo,
+		policy:          policy,
+		log:             log,
+	}
+}
+
+func (s *PreferencesServiceImpl) GetPreferences(ctx context.Context, userID uuid.UUID) (*entities.UserPreferences, error) {
+	row, err := s.preferencesRepo.GetPreferences(ctx, userID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return &entities.UserPreferences{
+			UserID:   userID,
+			Locale:   s.policy.DefaultLocale,
+			Timezone: s.policy.DefaultTimezone,
+			Currency: s.policy.DefaultCurrency,
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to get preferences: %w", err)
+	}
+	return toDomainPreferences(row), nil
+}
+
+func (s *PreferencesServiceImpl) UpdatePreferences(ctx context.Context, userID uuid.UUID, req *models.PreferencesPatchRequest) (*entities.UserPreferences, error) {
+	if validationErrors := s.policy.Normalize(req); len(validationErrors) > 0 {
+		return nil, apperrors.ValidationErrors{Errors: validationErrors}
+	}
+
+	params := &db.UpsertUserPreferencesParams{
+		UserID:          userID,
+		Locale:          nullString(req.Locale),
+		DefaultLocale:   s.policy.DefaultLocale,
+		Timezone:        nullString(req.Timezone),
+		DefaultTimezone: s.policy.DefaultTimezone,
+		Currency:        nullString(req.Currency),
+		DefaultCurrency: s.policy.DefaultCurrency,
+	}
+	if req.Email != nil {
+		params.EmailNewsletter = nullBool(req.Email.Newsletter)
+		params.EmailPromotions = nullBool(req.Email.Promotions)
+		params.EmailRecommendations = nullBool(req.Email.Recommendations)
+	}
+
+	row, err := s.preferencesRepo.UpsertPreferences(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to update preferences: %w", err)
+	}
+	return toDomainPreferences(row), nil
+}
+
+func (s *PreferencesServiceImpl) EmailRecipient(ctx context.Context, userID uuid.UUID, category string) (*entities.User, *entities.UserPreferences, error) {
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !preferences.AllowsEmail(category) {
+		return nil, preferences, nil
+	}
+
+	userDB, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, preferences, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("service: failed to get email recipient: %w", err)
+	}
+
+	user := toDomainUser(userDB)
+	if user.EmailVerifiedAt == nil || user.BlocksAccess(time.Now()) {
+		return nil, preferences, nil
+	}
+	return user, preferences, nil
+}
+
+func toDomainPreferences(row *db.UserPreference) *entities.UserPreferences {
+	updatedAt := row.UpdatedAt
+	return &entities.UserPreferences{
+		UserID:               row.UserID,
+		Locale:               row.Locale,
+		Timezone:             row.Timezone,
+		Currency:             row.Currency,
+		EmailNewsletter:      row.EmailNewsletter,
+		EmailPromotions:      row.EmailPromotions,
+		EmailRecommendations: row.EmailRecommendations,
+		UpdatedAt:            &updatedAt,
+	}
+}
+
+func nullString(value *string) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *value, Valid: true}
+}
+
+func nullBool(value *bool) sql.NullBool {
+	if value == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *value, Valid: true}
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
+)
+
+const (
+	maxRoleGrantReason = 500
+
+	defaultRoleGrantRequestPage = 20
+	maxRoleGrantRequestPage     = 100
+)
+
+// RoleGrantRequestFilter narrows the approval queue. An empty Status lists
+// every request; Limit defaults to 20 and is capped at 100.
+type RoleGrantRequestFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+// RoleAssignmentService grants and revokes the roles of users. Every change is
+// written to the audit log and bumps the user's token epoch, so the new roles
+// apply from their next token refresh instead of within the hour.
+type RoleAssignmentService interface {
+	// GrantRole grants a role right away and returns nil, or, for privileged
+	// roles, returns the request a second admin has to approve.
+	GrantRole(ctx context.Context, userID, actorID uuid.UUID, req *models.RoleGrantRequest) (*entities.RoleGrantRequest, error)
+	// RevokeRole removes a granted role. Revoking the user's primary role sets
+	// it back to user.
+	RevokeRole(ctx context.Context, userID, actorID uuid.UUID, role, reason string) error
+
+	// ListRequests is the approval queue, oldest first.
+	ListRequests(ctx context.Context, filter RoleGrantRequestFilter) ([]entities.RoleGrantRequest, error)
+	// ApproveRequest grants the role. The approver has to be someone other
+	// than the admin who asked for the grant and the user receiving it.
+	ApproveRequest(ctx context.Context, id, approverID uuid.UUID, notes string) (*entities.RoleGrantRequest, error)
+	// RejectRequest needs notes saying why.
+	RejectRequest(ctx context.Context, id, approverID uuid.UUID, notes string) (*entities.RoleGrantRequest, error)
+}
+
+type RoleAssignmentServiceImpl struct {
+	grantRepo    repositories.RoleGrantRepository
+	roleRepo     repositories.RoleRepository
+	userRepo     repositories.UserRepository
+	tokenService token.TokenService
+	log          *logrus.Logger
+}
+
+func NewRoleAssignmentService(
+	grantRepo repositories.RoleGrantRepository,
+	roleRepo repositories.RoleRepository,
+	userRepo repositories.UserRepository,
+	tokenService token.TokenService,
+	log *logrus.Logger,
+) RoleAssignmentService {
+	return &RoleAssignmentServiceImpl{
+		grantRepo:    grantRepo,
+		roleRepo:     roleRepo,
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		log:          log,
+	}
+}
+
+func (s *RoleAssignmentServiceImpl) GrantRole(ctx context.Context, userID, actorID uuid.UUID, req *models.RoleGrantRequest) (*entities.RoleGrantRequest, error) {
+	roleName := strings.TrimSpace(req.Role)
+	reason := strings.TrimSpace(req.Reason)
+
+	var validationErrors []apperrors.ValidationError
+	if roleName == "" {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "role", Message: "is required"})
+	} else if roleName == entities.RoleUser {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "role", Message: "every account has the user role"})
+	}
+	if reason == "" {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "reason", Message: "is required"})
+	} else if utf8.RuneCountInString(reason) > maxRoleGrantReason {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "reason", Message: fmt.Sprintf("must be at most %d characters", maxRoleGrantReason)})
+	}
+	if len(validationErrors) > 0 {
+		return nil, apperrors.ValidationErrors{Errors: validationErrors}
+	}
+
+	role, err := s.roleRepo.GetRole(ctx, roleName)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "role", Message: "does not exist"}}}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	roles, err := s.roleRepo.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(roles, roleName) {
+		return nil, apperrors.ErrRoleAlreadyGranted
+	}
+
+	if role.IsPrivileged {
+		row, err := s.grantRepo.CreateRequest(ctx, &db.CreateRoleGrantRequestParams{
+			UserID:      userID,
+			Role:        roleName,
+			RequestedBy: uuid.NullUUID{UUID: actorID, Valid: true},
+			Reason:      reason,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		s.log.WithFields(logrus.Fields{"user_id": userID, "role": roleName, "requested_by": actorID}).Info("Role grant waiting for approval")
+		return toDomainRoleGrantRequest(row), nil
+	}
+
+	if _, err := s.grantRepo.GrantRole(ctx, &db.GrantUserRoleParams{
+		UserID:    userID,
+		Role:      roleName,
+		GrantedBy: uuid.NullUUID{UUID: actorID, Valid: true},
+		Reason:    reason,
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.tokenService.RevokeUserTokens(ctx, userID); err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrFailedToRevokeToken, err)
+	}
+
+	s.log.WithFields(logrus.Fields{"user_id": userID, "role": roleName, "granted_by": actorID}).Info("Role granted")
+	return nil, nil
+}
+
+func (s *RoleAssignmentServiceImpl) RevokeRole(ctx context.Context, userID, actorID uuid.UUID, role, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if role == entities.RoleUser {
+		return apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "role", Message: "every account has the user role"}}}
+	}
+	if utf8.RuneCountInString(reason) > maxRoleGrantReason {
+		return apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "reason", Message: fmt.Sprintf("must be at most %d characters", maxRoleGrantReason)}}}
+	}
+
+	if err := s.checkUser(ctx, userID); err != nil {
+		return err
+	}
+	revoked, err := s.grantRepo.RevokeRole(ctx, &db.RevokeUserRoleParams{
+		UserID:  userID,
+		Role:    role,
+		ActorID: actorID,
+		Reason:  reason,
+	})
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return apperrors.ErrNotFound
+	}
+	if err := s.tokenService.RevokeUserTokens(ctx, userID); err != nil {
+		return fmt.Errorf("%w: %v", apperrors.ErrFailedToRevokeToken, err)
+	}
+
+	s.log.WithFields(logrus.Fields{"user_id": userID, "role": role, "revoked_by": actorID}).Info("Role revoked")
+	return nil
+}
+
+func (s *RoleAssignmentServiceImpl) ListRequests(ctx context.Context, filter RoleGrantRequestFilter) ([]entities.RoleGrantRequest, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultRoleGrantRequestPage
+	}
+	if filter.Limit > maxRoleGrantRequestPage {
+		filter.Limit = maxRoleGrantRequestPage
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	param := &db.ListRoleGrantRequestsParams{
+		Limit:  int32(filter.Limit),
+		Offset: int32(filter.Offset),
+	}
+	if filter.Status != "" {
+		switch filter.Status {
+		case entities.RoleGrantPending, entities.RoleGrantApproved, entities.RoleGrantRejected:
+		default:
+			return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "status", Message: "must be pending, approved or rejected"}}}
+		}
+		param.Status = sql.NullString{String: filter.Status, Valid: true}
+	}
+
+	rows, err := s.grantRepo.ListRequests(ctx, param)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list role grant requests: %w", err)
+	}
+
+	requests := make([]entities.RoleGrantRequest, 0, len(rows))
+	for i := range rows {
+		requests = append(requests, *toDomainRoleGrantRequest(&rows[i]))
+	}
+	return requests, nil
+}
+
+func (s *RoleAssignmentServiceImpl) ApproveRequest(ctx context.Context, id, approverID uuid.UUID, notes string) (*entities.RoleGrantRequest, error) {
+	notes = strings.TrimSpace(notes)
+	if utf8.RuneCountInString(notes) > maxReviewNotes {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "notes", Message: fmt.Sprintf("must be at most %d characters", maxReviewNotes)}}}
+	}
+
+	request, err := s.grantRepo.GetRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != entities.RoleGrantPending {
+		return nil, apperrors.ErrRoleGrantDecided
+	}
+	if request.UserID == approverID || (request.RequestedBy.Valid && request.RequestedBy.UUID == approverID) {
+		return nil, apperrors.ErrSelfApproval
+	}
+
+	row, err := s.grantRepo.ApproveRequest(ctx, &db.ApproveRoleGrantRequestParams{
+		DecisionNotes: notes,
+		DecidedBy:     uuid.NullUUID{UUID: approverID, Valid: true},
+		ID:            id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokenService.RevokeUserTokens(ctx, row.UserID); err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrFailedToRevokeToken, err)
+	}
+
+	s.log.WithFields(logrus.Fields{"user_id": row.UserID, "role": row.Role, "approved_by": approverID}).Info("Role grant approved")
+	return toDomainRoleGrantRequest(row), nil
+}
+
+func (s *RoleAssignmentServiceImpl) RejectRequest(ctx context.Context, id, approverID uuid.UUID, notes string) (*entities.RoleGrantRequest, error) {
+	notes = strings.TrimSpace(notes)
+	if notes == "" {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "notes", Message: "say why the grant is rejected"}}}
+	}
+	if utf8.RuneCountInString(notes) > maxReviewNotes {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "notes", Message: fmt.Sprintf("must be at most %d characters", maxReviewNotes)}}}
+	}
+
+	row, err := s.grantRepo.RejectRequest(ctx, &db.RejectRoleGrantRequestParams{
+		DecisionNotes: notes,
+		DecidedBy:     uuid.NullUUID{UUID: approverID, Valid: true},
+		ID:            id,
+	})
+	if errors.Is(err, apperrors.ErrRoleGrantDecided) {
+		// Tell a missing request apart from one decided already.
+		if _, getErr := s.grantRepo.GetRequest(ctx, id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{"user_id": row.UserID, "role": row.Role, "rejected_by": approverID}).Info("Role grant rejected")
+	return toDomainRoleGrantRequest(row), nil
+}
+
+func (s *RoleAssignmentServiceImpl) checkUser(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperrors.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func toDomainRoleGrantRequest(row *db.RoleGrantRequest) *entities.RoleGrantRequest {
+	request := &entities.RoleGrantRequest{
+		ID:            row.ID,
+		UserID:        row.UserID,
+		Role:          row.Role,
+		Reason:        row.Reason,
+		Status:        row.Status,
+		DecisionNotes: row.DecisionNotes,
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+	}
+	if row.RequestedBy.Valid {
+		requestedBy := row.RequestedBy.UUID
+		request.RequestedBy = &requestedBy
+	}
+	if row.DecidedBy.Valid {
+		decidedBy := row.DecidedBy.UUID
+		request.DecidedBy = &decidedBy
+	}
+	if row.DecidedAt.Valid {
+		decidedAt := row.DecidedAt.Time
+		request.DecidedAt = &decidedAt
+	}
+	return request
+}
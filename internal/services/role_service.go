@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
+)
+
+const maxRoleDescription = 200
+
+// RoleService manages roles and permissions. Every change is written to the
+// audit log. Access tokens embed the permissions of their user, so changes
+// bump the token epoch of everyone holding the role or permission.
+type RoleService interface {
+	ListRoles(ctx context.Context) ([]entities.Role, error)
+	GetRole(ctx context.Context, name string) (*entities.Role, error)
+	CreateRole(ctx context.Context, actorID uuid.UUID, req *models.RoleRequest) (*entities.Role, error)
+	// UpdateRole replaces the description and permissions of a role. The
+	// admin role always has every permission and cannot be changed, and a
+	// role someone holds can lose permissions but not gain any: that would
+	// hand them out without the approval a grant needs.
+	UpdateRole(ctx context.Context, name string, actorID uuid.UUID, req *models.RoleUpdateRequest) (*entities.Role, error)
+	DeleteRole(ctx context.Context, name string, actorID uuid.UUID) error
+
+	ListPermissions(ctx context.Context) ([]entities.Permission, error)
+	CreatePermission(ctx context.Context, actorID uuid.UUID, req *models.PermissionRequest) (*entities.Permission, error)
+	DeletePermission(ctx context.Context, name string, actorID uuid.UUID) error
+
+	// GetUserAccess returns the effective roles and permissions of a user.
+	GetUserAccess(ctx context.Context, userID uuid.UUID) (roles []string, permissions []string, err error)
+}
+
+type RoleServiceImpl struct {
+	roleRepo     repositories.RoleRepository
+	userRepo     repositories.UserRepository
+	tokenService token.TokenService
+	log          *logrus.Logger
+}
+
+func NewRoleService(roleRepo repositories.RoleRepository, userRepo repositories.UserRepository, tokenService token.TokenService, log *logrus.Logger) RoleService {
+	return &RoleServiceImpl{
+		roleRepo:     roleRepo,
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		log:          log,
+	}
+}
+
+func (s *RoleServiceImpl) ListRoles(ctx context.Context) ([]entities.Role, error) {
+	rows, err := s.roleRepo.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list roles: %w", err)
+	}
+
+	roles := make([]entities.Role, 0, len(rows))
+	for _, row := range rows {
+		roles = append(roles, entities.Role{
+			Name:         row.Name,
+			Description:  row.Description,
+			IsSystem:     row.IsSystem,
+			IsPrivileged: row.IsPrivileged,
+			Permissions:  row.Permissions,
+			CreatedAt:    row.CreatedAt,
+			UpdatedAt:    row.UpdatedAt,
+		})
+	}
+	return roles, nil
+}
+
+func (s *RoleServiceImpl) GetRole(ctx context.Context, name string) (*entities.Role, error) {
+	row, err := s.roleRepo.GetRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.Role{
+		Name:         row.Name,
+		Description:  row.Description,
+		IsSystem:     row.IsSystem,
+		IsPrivileged: row.IsPrivileged,
+		Permissions:  row.Permissions,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+	}, nil
+}
+
+func (s *RoleServiceImpl) CreateRole(ctx context.Context, actorID uuid.UUID, req *models.RoleRequest) (*entities.Role, error) {
+	name := strings.TrimSpace(req.Name)
+	description := strings.TrimSpace(req.Description)
+
+	var validationErrors []apperrors.ValidationError
+	if !entities.ValidRoleName(name) {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "name", Message: "must be 2 to 50 lower case letters, digits or underscores, starting with a letter"})
+	}
+	if len(description) > maxRoleDescription {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "description", Message: fmt.Sprintf("must be at most %d characters", maxRoleDescription)})
+	}
+	permissions, err := s.checkPermissions(ctx, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "permissions", Message: "contains unknown permissions"})
+	}
+	if len(validationErrors) > 0 {
+		return nil, apperrors.ValidationErrors{Errors: validationErrors}
+	}
+
+	row, err := s.roleRepo.CreateRole(ctx, &db.CreateRoleParams{
+		Name:         name,
+		Description:  description,
+		IsPrivileged: req.Privileged || entities.GrantsStaffAccess(permissions),
+		Permissions:  permissions,
+		ActorID:      actorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.WithField("role", name).Info("Role created")
+	return &entities.Role{
+		Name:         row.Name,
+		Description:  row.Description,
+		IsSystem:     row.IsSystem,
+		IsPrivileged: row.IsPrivileged,
+		Permissions:  permissions,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+	}, nil
+}
+
+func (s *RoleServiceImpl) UpdateRole(ctx context.Context, name string, actorID uuid.UUID, req *models.RoleUpdateRequest) (*entities.Role, error) {
+	if name == entities.RoleAdmin {
+		return nil, apperrors.ErrRoleProtected
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if len(description) > maxRoleDescription {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "description", Message: fmt.Sprintf("must be at most %d characters", maxRoleDescription)}}}
+	}
+	permissions, err := s.checkPermissions(ctx, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "permissions", Message: "contains unknown permissions"}}}
+	}
+
+	current, err := s.roleRepo.GetRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	holders, err := s.roleRepo.ListRoleHolders(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	privileged := req.Privileged || entities.GrantsStaffAccess(permissions)
+	if len(holders) > 0 {
+		for _, p := range permissions {
+			if !slices.Contains(current.Permissions, p) {
+				return nil, apperrors.ErrRoleHeld
+			}
+		}
+		// Its holders got it with a second admin's approval; dropping the
+		// flag would let the next grant skip it.
+		if current.IsPrivileged && !privileged {
+			return nil, apperrors.ErrRolePrivilegeHeld
+		}
+	}
+
+	row, err := s.roleRepo.UpdateRole(ctx, &db.UpdateRoleParams{
+		Description:  description,
+		IsPrivileged: privileged,
+		Name:         name,
+		Permissions:  permissions,
+		ActorID:      actorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.revokeTokens(ctx, holders); err != nil {
+		return nil, err
+	}
+
+	s.log.WithFields(logrus.Fields{"role": name, "permissions": permissions}).Info("Role updated")
+	return &entities.Role{
+		Name:         row.Name,
+		Description:  row.Description,
+		IsSystem:     row.IsSystem,
+		IsPrivileged: row.IsPrivileged,
+		Permissions:  permissions,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+	}, nil
+}
+
+func (s *RoleServiceImpl) DeleteRole(ctx context.Context, name string, actorID uuid.UUID) error {
+	role, err := s.roleRepo.GetRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return apperrors.ErrRoleProtected
+	}
+
+	// Grants of the role go with it, so find their holders first.
+	holders, err := s.roleRepo.ListRoleHolders(ctx, name)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.roleRepo.DeleteRole(ctx, &db.DeleteRoleParams{Name: name, ActorID: actorID})
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return apperrors.ErrRoleInUse
+	}
+	if err := s.revokeTokens(ctx, holders); err != nil {
+		return err
+	}
+
+	s.log.WithField("role", name).Info("Role deleted")
+	return nil
+}
+
+func (s *RoleServiceImpl) ListPermissions(ctx context.Context) ([]entities.Permission, error) {
+	rows, err := s.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list permissions: %w", err)
+	}
+
+	permissions := make([]entities.Permission, 0, len(rows))
+	for _, row := range rows {
+		permissions = append(permissions, entities.Permission{
+			Name:        row.Name,
+			Description: row.Description,
+			IsSystem:    row.IsSystem,
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	return permissions, nil
+}
+
+func (s *RoleServiceImpl) CreatePermission(ctx context.Context, actorID uuid.UUID, req *models.PermissionRequest) (*entities.Permission, error) {
+	name := strings.TrimSpace(req.Name)
+	description := strings.TrimSpace(req.Description)
+
+	var validationErrors []apperrors.ValidationError
+	if !entities.ValidPermissionName(name) {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "name", Message: "must look like resource:action, in lower case"})
+	}
+	if len(description) > maxRoleDescription {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "description", Message: fmt.Sprintf("must be at most %d characters", maxRoleDescription)})
+	}
+	if len(validationErrors) > 0 {
+		return nil, apperrors.ValidationErrors{Errors: validationErrors}
+	}
+
+	row, err := s.roleRepo.CreatePermission(ctx, &db.CreatePermissionParams{Name: name, Description: description, ActorID: actorID})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.WithField("permission", name).Info("Permission created")
+	return &entities.Permission{
+		Name:        row.Name,
+		Description: row.Description,
+		IsSystem:    row.IsSystem,
+		CreatedAt:   row.CreatedAt,
+	}, nil
+}
+
+func (s *RoleServiceImpl) DeletePermission(ctx context.Context, name string, actorID uuid.UUID) error {
+	permission, err := s.roleRepo.GetPermission(ctx, name)
+	if err != nil {
+		return err
+	}
+	if permission.IsSystem {
+		return apperrors.ErrPermissionProtected
+	}
+
+	holders, err := s.roleRepo.ListPermissionHolders(ctx, name)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.roleRepo.DeletePermission(ctx, &db.DeletePermissionParams{Name: name, ActorID: actorID})
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return apperrors.ErrNotFound
+	}
+	if err := s.revokeTokens(ctx, holders); err != nil {
+		return err
+	}
+
+	s.log.WithField("permission", name).Info("Permission deleted")
+	return nil
+}
+
+func (s *RoleServiceImpl) GetUserAccess(ctx context.Context, userID uuid.UUID) ([]string, []string, error) {
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, apperrors.ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	roles, err := s.roleRepo.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	permissions, err := s.roleRepo.ListUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return roles, permissions, nil
+}
+
+// revokeTokens bumps the token epoch of every user, so their next token
+// carries their roles and permissions as they are now.
+func (s *RoleServiceImpl) revokeTokens(ctx context.Context, userIDs []uuid.UUID) error {
+	for _, userID := range userIDs {
+		if err := s.tokenService.RevokeUserTokens(ctx, userID); err != nil {
+			return fmt.Errorf("%w: %v", apperrors.ErrFailedToRevokeToken, err)
+		}
+	}
+	return nil
+}
+
+// checkPermissions returns the requested permissions sorted and without
+// duplicates, or nil when one of them does not exist.
+func (s *RoleServiceImpl) checkPermissions(ctx context.Context, requested []string) ([]string, error) {
+	known, err := s.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list permissions: %w", err)
+	}
+	exists := make(map[string]bool, len(known))
+	for _, p := range known {
+		exists[p.Name] = true
+	}
+
+	seen := make(map[string]bool, len(requested))
+	permissions := make([]string, 0, len(requested))
+	for _, p := range requested {
+		p = strings.TrimSpace(p)
+		if !exists[p] {
+			return nil, nil
+		}
+		if !seen[p] {
+			seen[p] = true
+			permissions = append(permissions, p)
+		}
+	}
+	sort.Strings(permissions)
+	return permissions, nil
+}
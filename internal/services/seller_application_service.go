@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/messaging/rabbitmq"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/kyc"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/region"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/secretbox"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+// sellerDocumentTypes are the formats accepted for documents, sniffed from
+// the data itself.
+var sellerDocumentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"application/pdf": true,
+}
+
+const (
+	maxShopNameLength = 100
+	maxReviewNotes    = 1000
+
+	defaultSellerApplicationPage = 20
+	maxSellerApplicationPage     = 100
+)
+
+type SellerApplicationConfig struct {
+	MaxDocumentBytes int64
+}
+
+// SellerDocumentUpload is one uploaded document of an application.
+type SellerDocumentUpload struct {
+	Kind string
+	File io.Reader
+}
+
+// SellerApplicationFilter narrows the review queue. An empty Status lists
+// every application; Limit defaults to 20 and is capped at 100.
+type SellerApplicationFilter struct {
+	Status string
+	Limit  int
+	Offset int
+}
+
+type SellerApplicationService interface {
+	Submit(ctx context.Context, userID uuid.UUID, req *models.SellerApplicationRequest, documents []SellerDocumentUpload) (*entities.SellerApplication, error)
+	// ListMine returns the user's applications, newest first.
+	ListMine(ctx context.Context, userID uuid.UUID) ([]entities.SellerApplication, error)
+	// List is the admins' review queue, oldest first.
+	List(ctx context.Context, filter SellerApplicationFilter) ([]entities.SellerApplication, error)
+	// Review returns an application with its NIK and NPWP in full and its
+	// document list, for an admin to decide on.
+	Review(ctx context.Context, id uuid.UUID) (*entities.SellerApplication, error)
+	GetDocument(ctx context.Context, id uuid.UUID, kind string) (*entities.SellerApplicationDocument, error)
+	// Approve makes the applicant a seller.
+	Approve(ctx context.Context, id, reviewerID uuid.UUID, notes string) (*entities.SellerApplication, error)
+	// Reject needs notes, which tell the applicant what to fix.
+	Reject(ctx context.Context, id, reviewerID uuid.UUID, notes string) (*entities.SellerApplication, error)
+}
+
+type SellerApplicationServiceImpl struct {
+	applicationRepo repositories.SellerApplicationRepository
+	userRepo        repositories.UserRepository
+	secretBox       *secretbox.Box
+	regions         *region.Dataset
+	eventPublisher  *rabbitmq.EventPublisher
+	config          SellerApplicationConfig
+	log             *logrus.Logger
+}
+
+func NewSellerApplicationService(
+	applicationRepo repositories.SellerApplicationRepository,
+	userRepo repositories.UserRepository,
+	secretBox *secretbox.Box,
+	regions *region.Dataset,
+	eventPublisher *rabbitmq.EventPublisher,
+	config SellerApplicationConfig,
+	log *logrus.Logger,
+) SellerApplicationService {
+	return &SellerApplicationServiceImpl{
+		applicationRepo: applicationRepo,
+		userRepo:        userRepo,
+		secretBox:       secretBox,
+		regions:         regions,
+		eventPublisher:  eventPublisher,
+		config:          config,
+		log:             log,
+	}
+}
+
+func (s *SellerApplicationServiceImpl) Submit(ctx context.Context, userID uuid.UUID, req *models.SellerApplicationRequest, documents []SellerDocumentUpload) (*entities.SellerApplication, error) {
+	userDB, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user := toDomainUser(userDB)
+	if user.Role != entities.RoleUser {
+		return nil, apperrors.ErrAlreadySeller
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, apperrors.ErrEmailNotVerified
+	}
+
+	var validationErrors []apperrors.ValidationError
+
+	shopName := strings.TrimSpace(req.ShopName)
+	if shopName == "" {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "shop_name", Message: "is required"})
+	} else if utf8.RuneCountInString(shopName) > maxShopNameLength {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "shop_name", Message: fmt.Sprintf("must be at most %d characters", maxShopNameLength)})
+	}
+
+	nik, err := kyc.NormalizeNIK(req.NIK)
+	if err == nil {
+		if _, ok := s.regions.Get(kyc.ProvinceCode(nik)); !ok {
+			err = kyc.ErrInvalidNIK
+		}
+	}
+	if err != nil {
+		validationErrors = append(validationErrors, apperrors.ValidationError{Field: "nik", Message: "must be the 16 digit NIK of your KTP"})
+	}
+
+	npwp := ""
+	if strings.TrimSpace(req.NPWP) != "" {
+		if npwp, err = kyc.NormalizeNPWP(req.NPWP); err != nil {
+			validationErrors = append(validationErrors, apperrors.ValidationError{Field: "npwp", Message: "must be a 15 or 16 digit NPWP"})
+		}
+	}
+
+	required := []string{entities.SellerDocumentKTP, entities.SellerDocumentSelfie}
+	if npwp != "" {
+		required = append(required, entities.SellerDocumentNPWP)
+	}
+	uploads := make(map[string]io.Reader, len(documents))
+	for _, document := range documents {
+		uploads[document.Kind] = document.File
+	}
+
+	params := &db.CreateSellerApplicationParams{
+		UserID:   userID,
+		ShopName: shopName,
+	}
+	for _, kind := range required {
+		file, ok := uploads[kind]
+		if !ok {
+			validationErrors = append(validationErrors, apperrors.ValidationError{Field: kind, Message: "is required"})
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(file, s.config.MaxDocumentBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to read %s document: %w", kind, err)
+		}
+		if int64(len(data)) > s.config.MaxDocumentBytes {
+			return nil, apperrors.ErrFileTooLarge
+		}
+		contentType := http.DetectContentType(data)
+		if !sellerDocumentTypes[contentType] {
+			validationErrors = append(validationErrors, apperrors.ValidationError{Field: kind, Message: "must be a JPEG, PNG or PDF file"})
+			continue
+		}
+
+		sealed, err := s.secretBox.Seal(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to encrypt %s document: %w", kind, err)
+		}
+		params.DocumentKinds = append(params.DocumentKinds, kind)
+		params.DocumentContentTypes = append(params.DocumentContentTypes, contentType)
+		params.DocumentSizes = append(params.DocumentSizes, int32(len(data)))
+		params.DocumentData = append(params.DocumentData, sealed)
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, apperrors.ValidationErrors{Errors: validationErrors}
+	}
+
+	if params.Nik, err = s.secretBox.Seal(nik); err != nil {
+		return nil, fmt.Errorf("service: failed to encrypt NIK: %w", err)
+	}
+	if npwp != "" {
+		if params.Npwp, err = s.secretBox.Seal(npwp); err != nil {
+			return nil, fmt.Errorf("service: failed to encrypt NPWP: %w", err)
+		}
+	}
+
+	row, err := s.applicationRepo.CreateApplication(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return s.toDomainApplication(row, false)
+}
+
+func (s *SellerApplicationServiceImpl) ListMine(ctx context.Context, userID uuid.UUID) ([]entities.SellerApplication, error) {
+	rows, err := s.applicationRepo.ListUserApplications(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list seller applications: %w", err)
+	}
+	return s.toDomainApplications(rows)
+}
+
+func (s *SellerApplicationServiceImpl) List(ctx context.Context, filter SellerApplicationFilter) ([]entities.SellerApplication, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSellerApplicationPage
+	}
+	if filter.Limit > maxSellerApplicationPage {
+		filter.Limit = maxSellerApplicationPage
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	param := &db.ListSellerApplicationsParams{
+		Limit:  int32(filter.Limit),
+		Offset: int32(filter.Offset),
+	}
+	if filter.Status != "" {
+		switch filter.Status {
+		case entities.SellerApplicationPending, entities.SellerApplicationApproved, entities.SellerApplicationRejected:
+		default:
+			return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "status", Message: "must be pending, approved or rejected"}}}
+		}
+		param.Status = sql.NullString{String: filter.Status, Valid: true}
+	}
+
+	rows, err := s.applicationRepo.ListApplications(ctx, param)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list seller applications: %w", err)
+	}
+	return s.toDomainApplications(rows)
+}
+
+func (s *SellerApplicationServiceImpl) Review(ctx context.Context, id uuid.UUID) (*entities.SellerApplication, error) {
+	row, err := s.applicationRepo.GetApplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	application, err := s.toDomainApplication(row, true)
+	if err != nil {
+		return nil, err
+	}
+
+	documents, err := s.applicationRepo.ListDocuments(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to review seller application: %w", err)
+	}
+	for _, document := range documents {
+		application.Documents = append(application.Documents, entities.SellerApplicationDocument{
+			Kind:        document.Kind,
+			ContentType: document.ContentType,
+			Size:        int(document.SizeBytes),
+			CreatedAt:   document.CreatedAt,
+		})
+	}
+	return application, nil
+}
+
+func (s *SellerApplicationServiceImpl) GetDocument(ctx context.Context, id uuid.UUID, kind string) (*entities.SellerApplicationDocument, error) {
+	row, err := s.applicationRepo.GetDocument(ctx, id, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.secretBox.Open(row.Data)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to decrypt %s document: %w", kind, err)
+	}
+	return &entities.SellerApplicationDocument{
+		Kind:        row.Kind,
+		ContentType: row.ContentType,
+		Size:        int(row.SizeBytes),
+		Data:        []byte(data),
+		CreatedAt:   row.CreatedAt,
+	}, nil
+}
+
+func (s *SellerApplicationServiceImpl) Approve(ctx context.Context, id, reviewerID uuid.UUID, notes string) (*entities.SellerApplication, error) {
+	return s.decide(ctx, id, reviewerID, entities.SellerApplicationApproved, notes)
+}
+
+func (s *SellerApplicationServiceImpl) Reject(ctx context.Context, id, reviewerID uuid.UUID, notes string) (*entities.SellerApplication, error) {
+	if strings.TrimSpace(notes) == "" {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "notes", Message: "tell the applicant why"}}}
+	}
+	return s.decide(ctx, id, reviewerID, entities.SellerApplicationRejected, notes)
+}
+
+func (s *SellerApplicationServiceImpl) decide(ctx context.Context, id, reviewerID uuid.UUID, status, notes string) (*entities.SellerApplication, error) {
+	notes = strings.TrimSpace(notes)
+	if utf8.RuneCountInString(notes) > maxReviewNotes {
+		return nil, apperrors.ValidationErrors{Errors: []apperrors.ValidationError{{Field: "notes", Message: fmt.Sprintf("must be at most %d characters", maxReviewNotes)}}}
+	}
+
+	row, err := s.applicationRepo.DecideApplication(ctx, &db.DecideSellerApplicationParams{
+		Status:      status,
+		ReviewNotes: notes,
+		ReviewedBy:  uuid.NullUUID{UUID: reviewerID, Valid: true},
+		ID:          id,
+	})
+	if errors.Is(err, apperrors.ErrApplicationDecided) {
+		// Tell a missing application apart from one decided already.
+		if _, getErr := s.applicationRepo.GetApplication(ctx, id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	application, err := s.toDomainApplication(row, false)
+	if err != nil {
+		return nil, err
+	}
+	s.publishDecided(application)
+	return application, nil
+}
+
+func (s *SellerApplicationServiceImpl) publishDecided(application *entities.SellerApplication) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		user, err := s.userRepo.GetUserByID(ctx, application.UserID)
+		if err != nil {
+			s.log.WithError(err).Error("Failed to load applicant for seller application event")
+			return
+		}
+
+		event := rabbitmq.SellerApplicationDecidedEvent{
+			ApplicationID: application.ID.String(),
+			UserID:        application.UserID.String(),
+			Email:         user.Email,
+			Username:      user.Username,
+			ShopName:      application.ShopName,
+			Status:        application.Status,
+			Notes:         application.ReviewNotes,
+			ReviewedBy:    application.ReviewedBy.String(),
+			ReviewedAt:    *application.ReviewedAt,
+		}
+		if err := s.eventPublisher.PublishSellerApplicationDecided(ctx, event); err != nil {
+			s.log.WithError(err).Error("Failed to publish seller application decided event")
+		}
+	}()
+}
+
+func (s *SellerApplicationServiceImpl) toDomainApplications(rows []db.SellerApplication) ([]entities.SellerApplication, error) {
+	applications := make([]entities.SellerApplication, 0, len(rows))
+	for i := range rows {
+		application, err := s.toDomainApplication(&rows[i], false)
+		if err != nil {
+			return nil, err
+		}
+		applications = append(applications, *application)
+	}
+	return applications, nil
+}
+
+// toDomainApplication decrypts NIK and NPWP, masked unless reveal is set.
+func (s *SellerApplicationServiceImpl) toDomainApplication(row *db.SellerApplication, reveal bool) (*entities.SellerApplication, error) {
+	nik, err := s.secretBox.Open(row.Nik)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to decrypt NIK: %w", err)
+	}
+	npwp := ""
+	if row.Npwp != "" {
+		if npwp, err = s.secretBox.Open(row.Npwp); err != nil {
+			return nil, fmt.Errorf("service: failed to decrypt NPWP: %w", err)
+		}
+	}
+	if !reveal {
+		nik, npwp = kyc.Mask(nik), kyc.Mask(npwp)
+	}
+
+	application := &entities.SellerApplication{
+		ID:          row.ID,
+		UserID:      row.UserID,
+		ShopName:    row.ShopName,
+		NIK:         nik,
+		NPWP:        npwp,
+		Status:      row.Status,
+		ReviewNotes: row.ReviewNotes,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	if row.ReviewedBy.Valid {
+		reviewedBy := row.ReviewedBy.UUID
+		application.ReviewedBy = &reviewedBy
+	}
+	if row.ReviewedAt.Valid {
+		reviewedAt := row.ReviewedAt.Time
+		application.ReviewedAt = &reviewedAt
+	}
+	return application, nil
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/helpers"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/messaging/rabbitmq"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
+)
+
+const refreshTokenTTL = 7 * 24 * time.Hour
+
+// signInHistorySize is how many sign-ins are kept per user for the data
+// export.
+const signInHistorySize = 100
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	SessionID    string
+	ExpiresAt    time.Time
+	// Scopes are the scopes in the access token.
+	Scopes []string
+}
+
+// SessionOptions scopes a session to an OAuth client. The zero value is a
+// first-party session with full access.
+type SessionOptions struct {
+	ClientID string
+	Scopes   []string
+}
+
+type SessionService interface {
+	CreateSession(ctx context.Context, user *entities.User, metadata *ActivityMetadata, opts SessionOptions) (*TokenPair, error)
+	// RefreshSession only accepts refresh tokens issued to clientID ("" for
+	// first-party sessions).
+	RefreshSession(ctx context.Context, refreshToken string, clientID string, metadata *ActivityMetadata) (*TokenPair, error)
+	RevokeSession(ctx context.Context, refreshToken string) error
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]entities.Session, error)
+	RevokeUserSession(ctx context.Context, userID uuid.UUID, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID uuid.UUID, exceptSessionID string) error
+	LogoutEverywhere(ctx context.Context, userID uuid.UUID) error
+}
+
+type SessionServiceImpl struct {
+	userRepo         repositories.UserRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	sessionRepo      repositories.SessionRepository
+	signInRepo       repositories.SignInEventRepository
+	tokenService     token.TokenService
+	eventPublisher   *rabbitmq.EventPublisher
+	log              *logrus.Logger
+}
+
+func NewSessionService(
+	userRepo repositories.UserRepository,
+	refreshTokenRepo repositories.RefreshTokenRepository,
+	sessionRepo repositories.SessionRepository,
+	signInRepo repositories.SignInEventRepository,
+	tokenService token.TokenService,
+	eventPublisher *rabbitmq.EventPublisher,
+	log *logrus.Logger,
+) SessionService {
+	return &SessionServiceImpl{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		signInRepo:       signInRepo,
+		tokenService:     tokenService,
+		eventPublisher:   eventPublisher,
+		log:              log,
+	}
+}
+
+// CreateSession starts a new refresh token family for a freshly authenticated
+// user and registers it as a session. The family ID doubles as the session ID.
+func (s *SessionServiceImpl) CreateSession(ctx context.Context, user *entities.User, metadata *ActivityMetadata, opts SessionOptions) (*TokenPair, error) {
+	if err := accountStatusError(user); err != nil {
+		return nil, err
+	}
+
+	// Signing in is how a user takes back a pending self-service deletion.
+	if user.DeletionScheduledAt != nil {
+		cancelled, err := s.userRepo.CancelDeletion(ctx, user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to cancel account deletion: %w", err)
+		}
+		if cancelled {
+			user.DeletionScheduledAt = nil
+			s.log.WithField("user_id", user.ID).Info("Account deletion cancelled by login")
+		}
+	}
+
+	sessionID := uuid.New().String()
+
+	accessToken, err := s.tokenService.GenerateAccessToken(ctx, user, token.AccessTokenOptions{SessionID: sessionID, Scopes: opts.Scopes})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrFailedToGenerateToken, err)
+	}
+
+	refreshToken, err := s.tokenService.GenerateRefreshToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrFailedToGenerateToken, err)
+	}
+
+	now := time.Now()
+	session := &repositories.SessionRecord{
+		ID:         sessionID,
+		UserID:     user.ID.String(),
+		ClientID:   opts.ClientID,
+		Scope:      strings.Join(opts.Scopes, " "),
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+	if metadata != nil {
+		session.Device = metadata.Device
+		session.IPAddress = metadata.IPAddress
+		session.UserAgent = metadata.UserAgent
+	}
+	if session.Device == "" {
+		session.Device = helpers.DeviceFromUserAgent(session.UserAgent)
+	}
+
+	if err := s.sessionRepo.CreateSession(ctx, session, refreshTokenTTL); err != nil {
+		return nil, fmt.Errorf("service: failed to create session: %w", err)
+	}
+
+	if err := s.sessionRepo.TrackAccessToken(ctx, sessionID, accessToken.ID, accessToken.ExpiresAt); err != nil {
+		return nil, fmt.Errorf("service: failed to create session: %w", err)
+	}
+
+	if err := s.refreshTokenRepo.StoreRefreshToken(ctx, user.ID.String(), sessionID, refreshToken, refreshTokenTTL); err != nil {
+		return nil, fmt.Errorf("service: failed to create session: %w", err)
+	}
+
+	// The sign-in history is for the user's records; losing an entry is not
+	// worth failing the sign-in over.
+	err = s.signInRepo.Add(ctx, &db.CreateSignInEventParams{
+		UserID:    user.ID,
+		SessionID: sessionID,
+		ClientID:  session.ClientID,
+		Device:    session.Device,
+		IpAddress: session.IPAddress,
+		UserAgent: session.UserAgent,
+	}, signInHistorySize)
+	if err != nil {
+		s.log.WithError(err).WithField("user_id", user.ID).Error("Failed to record sign-in")
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken.Token,
+		RefreshToken: refreshToken,
+		SessionID:    sessionID,
+		ExpiresAt:    accessToken.ExpiresAt,
+		Scopes:       opts.Scopes,
+	}, nil
+}
+
+// RefreshSession exchanges a refresh token for a new pair. Everything that can
+// fail runs before the atomic rotation, so an error leaves the presented token
+// usable instead of stranding the user without a session.
+func (s *SessionServiceImpl) RefreshSession(ctx context.Context, refreshToken string, clientID string, metadata *ActivityMetadata) (*TokenPair, error) {
+	record, err := s.refreshTokenRepo.GetRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionRepo.GetSession(ctx, record.FamilyID)
+	if errors.Is(err, apperrors.ErrSessionNotFound) {
+		return nil, apperrors.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to refresh session: %w", err)
+	}
+	if session.ClientID != clientID {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(record.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("service: invalid user id in refresh token: %w", err)
+	}
+
+	userDB, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, apperrors.ErrInvalidToken
+	}
+	user := toDomainUser(userDB)
+	if err := accountStatusError(user); err != nil {
+		return nil, err
+	}
+
+	scopes := strings.Fields(session.Scope)
+	accessToken, err := s.tokenService.GenerateAccessToken(ctx, user, token.AccessTokenOptions{
+		SessionID: record.FamilyID,
+		Scopes:    scopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrFailedToGenerateToken, err)
+	}
+
+	newRefreshToken, err := s.tokenService.GenerateRefreshToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrFailedToGenerateToken, err)
+	}
+
+	if err := s.sessionRepo.TrackAccessToken(ctx, record.FamilyID, accessToken.ID, accessToken.ExpiresAt); err != nil {
+		return nil, fmt.Errorf("service: failed to refresh session: %w", err)
+	}
+
+	record, err = s.refreshTokenRepo.RotateRefreshToken(ctx, refreshToken, newRefreshToken, refreshTokenTTL)
+	if errors.Is(err, apperrors.ErrRefreshTokenReused) {
+		s.publishRefreshTokenReused(record, metadata)
+		if err := s.revokeSessionByID(ctx, record.FamilyID); err != nil {
+			s.log.WithError(err).Error("Failed to revoke session after refresh token reuse")
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ipAddress := ""
+	if metadata != nil {
+		ipAddress = metadata.IPAddress
+	}
+	if err := s.sessionRepo.TouchSession(ctx, record.FamilyID, ipAddress, refreshTokenTTL); err != nil && !errors.Is(err, apperrors.ErrSessionNotFound) {
+		s.log.WithError(err).Warn("Failed to update session last used time")
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken.Token,
+		RefreshToken: newRefreshToken,
+		SessionID:    record.FamilyID,
+		ExpiresAt:    accessToken.ExpiresAt,
+		Scopes:       scopes,
+	}, nil
+}
+
+func (s *SessionServiceImpl) RevokeSession(ctx context.Context, refreshToken string) error {
+	record, err := s.refreshTokenRepo.GetRefreshToken(ctx, refreshToken)
+	if errors.Is(err, apperrors.ErrInvalidToken) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("service: failed to revoke session: %w", err)
+	}
+
+	return s.revokeSessionByID(ctx, record.FamilyID)
+}
+
+func (s *SessionServiceImpl) ListSessions(ctx context.Context, userID uuid.UUID) ([]entities.Session, error) {
+	records, err := s.sessionRepo.ListSessions(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list sessions: %w", err)
+	}
+
+	sessions := make([]entities.Session, 0, len(records))
+	for _, record := range records {
+		sessions = append(sessions, entities.Session{
+			ID:         record.ID,
+			UserID:     userID,
+			ClientID:   record.ClientID,
+			Device:     record.Device,
+			IPAddress:  record.IPAddress,
+			UserAgent:  record.UserAgent,
+			CreatedAt:  record.CreatedAt,
+			LastUsedAt: record.LastUsedAt,
+		})
+	}
+	return sessions, nil
+}
+
+func (s *SessionServiceImpl) RevokeUserSession(ctx context.Context, userID uuid.UUID, sessionID string) error {
+	session, err := s.sessionRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	// Treat other users' sessions as missing so ids can't be probed.
+	if session.UserID != userID.String() {
+		return apperrors.ErrSessionNotFound
+	}
+
+	return s.revokeSessionByID(ctx, sessionID)
+}
+
+func (s *SessionServiceImpl) RevokeAllSessions(ctx context.Context, userID uuid.UUID, exceptSessionID string) error {
+	records, err := s.sessionRepo.ListSessions(ctx, userID.String())
+	if err != nil {
+		return fmt.Errorf("service: failed to list sessions: %w", err)
+	}
+
+	for _, record := range records {
+		if record.ID == exceptSessionID {
+			continue
+		}
+		if err := s.revokeSessionByID(ctx, record.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LogoutEverywhere ends every session, the caller's included, and bumps the
+// token epoch so access tokens not tracked by any session die as well.
+func (s *SessionServiceImpl) LogoutEverywhere(ctx context.Context, userID uuid.UUID) error {
+	if err := s.tokenService.RevokeUserTokens(ctx, userID); err != nil {
+		return fmt.Errorf("%w: %v", apperrors.ErrFailedToRevokeToken, err)
+	}
+
+	return s.RevokeAllSessions(ctx, userID, "")
+}
+
+// revokeSessionByID kills the refresh token family, blacklists every access
+// token the session issued that has not expired yet and drops the registry entry.
+func (s *SessionServiceImpl) revokeSessionByID(ctx context.Context, sessionID string) error {
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, sessionID); err != nil {
+		return fmt.Errorf("service: failed to revoke session: %w", err)
+	}
+
+	tokens, err := s.sessionRepo.ListAccessTokens(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("service: failed to revoke session: %w", err)
+	}
+
+	for _, issued := range tokens {
+		remaining := time.Until(issued.ExpiresAt)
+		if remaining <= 0 {
+			continue
+		}
+		if err := s.tokenService.BlacklistToken(ctx, issued.JTI, remaining); err != nil {
+			return fmt.Errorf("%w: %v", apperrors.ErrFailedToRevokeToken, err)
+		}
+	}
+
+	if err := s.sessionRepo.DeleteSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("service: failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+func (s *SessionServiceImpl) publishRefreshTokenReused(record *repositories.RefreshTokenRecord, metadata *ActivityMetadata) {
+	s.log.WithFields(logrus.Fields{
+		"user_id":   record.UserID,
+		"family_id": record.FamilyID,
+	}).Warn("Refresh token reuse detected, token family revoked")
+	if s.eventPublisher == nil {
+		return
+	}
+
+	event := rabbitmq.RefreshTokenReusedEvent{
+		UserID:     record.UserID,
+		FamilyID:   record.FamilyID,
+		DetectedAt: time.Now(),
+	}
+	if metadata != nil {
+		event.IPAddress = metadata.IPAddress
+		event.UserAgent = metadata.UserAgent
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := s.eventPublisher.PublishRefreshTokenReused(ctx, event); err != nil {
+			s.log.WithError(err).Error("Failed to publish refresh token reused event")
+		}
+	}()
+}
@@ -0,0 +1,67 @@
+package token
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+)
+
+const defaultIDTokenTTL = 1 * time.Hour
+
+// IDTokenClaims is the OpenID Connect ID token. Profile claims are only filled
+// for the scopes the user granted.
+type IDTokenClaims struct {
+	AuthTime          *jwt.NumericDate `json:"auth_time,omitempty"`
+	Nonce             string           `json:"nonce,omitempty"`
+	Name              string           `json:"name,omitempty"`
+	PreferredUsername string           `json:"preferred_username,omitempty"`
+	Email             string           `json:"email,omitempty"`
+	EmailVerified     *bool            `json:"email_verified,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type IDTokenOptions struct {
+	// Issuer is the OIDC issuer URL, which differs from the access token issuer.
+	Issuer   string
+	ClientID string
+	Nonce    string
+	AuthTime time.Time
+	Scopes   []string
+	TTL      time.Duration
+}
+
+func (s *jwtTokenService) GenerateIDToken(ctx context.Context, user *entities.User, opts IDTokenOptions) (string, error) {
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = defaultIDTokenTTL
+	}
+
+	now := time.Now()
+	claims := &IDTokenClaims{
+		AuthTime: jwt.NewNumericDate(opts.AuthTime),
+		Nonce:    opts.Nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    opts.Issuer,
+			Subject:   user.ID.String(),
+			Audience:  jwt.ClaimStrings{opts.ClientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	if slices.Contains(opts.Scopes, "profile") {
+		claims.Name = user.Name
+		claims.PreferredUsername = user.Username
+	}
+	if slices.Contains(opts.Scopes, "email") {
+		verified := user.EmailVerifiedAt != nil
+		claims.Email = user.Email
+		claims.EmailVerified = &verified
+	}
+
+	return s.sign(claims)
+}
@@ -2,55 +2,137 @@ package token
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
 )
 
+const accessTokenTTL = 1 * time.Hour // Changed from 15 minutes for better UX
+
 type JWTClaims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Username string    `json:"username"`
-	Role     string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	SessionID string    `json:"sid,omitempty"`
+	// TokenVersion is the user's token epoch at issue time, see RevokeUserTokens.
+	TokenVersion int32 `json:"ver"`
+	// Scope is only set for tokens issued to OAuth clients; first-party tokens
+	// leave it empty.
+	Scope string `json:"scope,omitempty"`
+	// EmailVerified lets resource services apply the verification policy to
+	// first-party tokens, which carry no scope.
+	EmailVerified bool `json:"email_verified"`
+	// Roles and Permissions are the user's effective roles and permissions at
+	// issue time, only set on first-party tokens. Role stays the primary role.
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// Act is set when an admin is acting as the user, see RFC 8693.
+	Act *ActorClaims `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// ActorClaims identify the admin behind an impersonation token. The token
+// dies with the admin's own tokens, so TokenVersion is the admin's epoch.
+type ActorClaims struct {
+	Subject      string    `json:"sub"`
+	UserID       uuid.UUID `json:"user_id"`
+	TokenVersion int32     `json:"ver"`
+}
+
+type AccessTokenOptions struct {
+	// SessionID links the token to a session so revoking the session can
+	// blacklist it.
+	SessionID string
+	Scopes    []string
+	// Actor issues an impersonation token: the user's access with the admin
+	// recorded in the act claim.
+	Actor *entities.User
+	// TTL overrides the default lifetime of an hour.
+	TTL time.Duration
+}
+
+type AccessToken struct {
+	Token     string
+	ID        string
+	ExpiresAt time.Time
+}
+
 type jwtTokenService struct {
-	jwtSecret        string
+	keyRing          *KeyRing
 	jwtIssuer        string
 	jwtAudience      []string
 	jwtBlacklistRepo repositories.JWTBlacklistRepository
+	tokenVersionRepo repositories.TokenVersionRepository
+	statusRepo       repositories.AccountStatusRepository
+	roleRepo         repositories.RoleRepository
 }
 
-func NewJWTTokenService(jwtSecret, jwtIssuer string, jwtAudience []string, jwtBlacklistRepo repositories.JWTBlacklistRepository) TokenService {
+func NewJWTTokenService(
+	keyRing *KeyRing,
+	jwtIssuer string,
+	jwtAudience []string,
+	jwtBlacklistRepo repositories.JWTBlacklistRepository,
+	tokenVersionRepo repositories.TokenVersionRepository,
+	statusRepo repositories.AccountStatusRepository,
+	roleRepo repositories.RoleRepository,
+) TokenService {
 	return &jwtTokenService{
-		jwtSecret:        jwtSecret,
+		keyRing:          keyRing,
 		jwtIssuer:        jwtIssuer,
 		jwtAudience:      jwtAudience,
 		jwtBlacklistRepo: jwtBlacklistRepo,
+		tokenVersionRepo: tokenVersionRepo,
+		statusRepo:       statusRepo,
+		roleRepo:         roleRepo,
 	}
 }
 
 type TokenService interface {
-	GenerateAccessToken(ctx context.Context, user *entities.User) (string, error)
+	GenerateAccessToken(ctx context.Context, user *entities.User, opts AccessTokenOptions) (*AccessToken, error)
 	GenerateRefreshToken(ctx context.Context) (string, error)
-	ValidateToken(ctx context.Context, tokenString string) (isValid bool, userID uuid.UUID, username string, role string, errorMessage string, err error)
+	GenerateIDToken(ctx context.Context, user *entities.User, opts IDTokenOptions) (string, error)
+	// ValidateToken returns nil claims and a client-facing message when the
+	// token is rejected; err is only set for internal failures.
+	ValidateToken(ctx context.Context, tokenString string) (claims *JWTClaims, errorMessage string, err error)
 	BlacklistToken(ctx context.Context, jti string, expiration time.Duration) error
+	// RevokeUserTokens bumps the user's token epoch, instantly invalidating
+	// every access token issued to them so far.
+	RevokeUserTokens(ctx context.Context, userID uuid.UUID) error
+	JWKS() JSONWebKeySet
 }
 
-func (s *jwtTokenService) GenerateAccessToken(ctx context.Context, user *entities.User) (string, error) {
+func (s *jwtTokenService) GenerateAccessToken(ctx context.Context, user *entities.User, opts AccessTokenOptions) (*AccessToken, error) {
+	tokenVersion, err := s.tokenVersionRepo.GetTokenVersion(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := accessTokenTTL
+	if opts.TTL > 0 {
+		ttl = opts.TTL
+	}
+
+	now := time.Now()
 	claims := &JWTClaims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		UserID:        user.ID,
+		Username:      user.Username,
+		Role:          user.Role,
+		SessionID:     opts.SessionID,
+		TokenVersion:  tokenVersion,
+		Scope:         strings.Join(opts.Scopes, " "),
+		EmailVerified: user.EmailVerifiedAt != nil,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)), // Changed from 15 minutes for better UX
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			ID:        uuid.New().String(),
 			Issuer:    s.jwtIssuer,
 			Subject:   user.Username,
@@ -58,12 +140,71 @@ func (s *jwtTokenService) GenerateAccessToken(ctx context.Context, user *entitie
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(s.jwtSecret))
+	if opts.Actor != nil {
+		actorVersion, err := s.tokenVersionRepo.GetTokenVersion(ctx, opts.Actor.ID)
+		if err != nil {
+			return nil, err
+		}
+		claims.Act = &ActorClaims{
+			Subject:      opts.Actor.Username,
+			UserID:       opts.Actor.ID,
+			TokenVersion: actorVersion,
+		}
+	}
+
+	// OAuth clients act within their scopes, not the user's permissions.
+	if len(opts.Scopes) == 0 {
+		if claims.Roles, err = s.roleRepo.ListUserRoles(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		if claims.Permissions, err = s.roleRepo.ListUserPermissions(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	signedToken, err := s.sign(claims)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign JWT: %w", err)
+		return nil, err
+	}
+
+	return &AccessToken{
+		Token:     signedToken,
+		ID:        claims.ID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// HasPermissions reports whether granted contains every required permission.
+func HasPermissions(granted []string, required ...string) bool {
+	for _, r := range required {
+		found := false
+		for _, g := range granted {
+			if g == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *jwtTokenService) sign(claims jwt.Claims) (string, error) {
+	return s.keyRing.Sign(claims)
+}
+
+func (s *jwtTokenService) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keyRing.Key(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 	}
-	return signedToken, nil
+	return key.PublicKey, nil
 }
 
 func (s *jwtTokenService) GenerateRefreshToken(ctx context.Context) (string, error) {
@@ -72,37 +213,111 @@ func (s *jwtTokenService) GenerateRefreshToken(ctx context.Context) (string, err
 	return uuid.New().String(), nil
 }
 
-func (s *jwtTokenService) ValidateToken(ctx context.Context, tokenString string) (isValid bool, userID uuid.UUID, username string, role string, errorMessage string, err error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.jwtSecret), nil
-	}, jwt.WithIssuer(s.jwtIssuer))
-
+func (s *jwtTokenService) ValidateToken(ctx context.Context, tokenString string) (*JWTClaims, string, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, s.keyFunc,
+		jwt.WithIssuer(s.jwtIssuer),
+		jwt.WithValidMethods(s.keyRing.Methods()),
+	)
 	if err != nil {
-		return false, uuid.Nil, "", "", "Token invalid or expired", err
+		return nil, "Token invalid or expired", nil
 	}
 
 	claims, ok := token.Claims.(*JWTClaims)
 	if !ok || !token.Valid {
-		return false, uuid.Nil, "", "", "Invalid token", nil
+		return nil, "Invalid token", nil
 	}
 
 	jti := claims.ID
 	if jti != "" {
 		isBlacklisted, err := s.jwtBlacklistRepo.IsBlacklisted(ctx, jti)
 		if err != nil {
-			return false, uuid.Nil, "", "", "Internal server error during token validation", err
+			return nil, "Internal server error during token validation", err
 		}
 		if isBlacklisted {
-			return false, uuid.Nil, "", "", "Token has been revoked", nil
+			return nil, "Token has been revoked", nil
+		}
+	}
+
+	currentVersion, err := s.tokenVersionRepo.GetTokenVersion(ctx, claims.UserID)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return nil, "Token has been revoked", nil
+	}
+	if err != nil {
+		return nil, "Internal server error during token validation", err
+	}
+	if claims.TokenVersion < currentVersion {
+		return nil, "Token has been revoked", nil
+	}
+
+	// Blocking an account also bumps the token epoch; this catches tokens
+	// whose owner was blocked after a cache failure and suspensions that are
+	// still running.
+	status, err := s.statusRepo.GetStatus(ctx, claims.UserID)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return nil, "Token has been revoked", nil
+	}
+	if err != nil {
+		return nil, "Internal server error during token validation", err
+	}
+	if entities.StatusBlocksAccess(status.Status, status.SuspendedUntil, time.Now()) {
+		switch status.Status {
+		case entities.UserStatusSuspended:
+			return nil, "Account is suspended", nil
+		case entities.UserStatusBanned:
+			return nil, "Account is banned", nil
+		default:
+			return nil, "Token has been revoked", nil
+		}
+	}
+
+	if claims.Act != nil {
+		if errMsg, err := s.validateActor(ctx, claims.Act); errMsg != "" || err != nil {
+			return nil, errMsg, err
 		}
 	}
 
-	return true, claims.UserID, claims.Username, claims.Role, "", nil
+	return claims, "", nil
+}
+
+// validateActor rejects an impersonation token once the admin behind it has
+// lost their own tokens, for example by having a role revoked, or their
+// account blocked.
+func (s *jwtTokenService) validateActor(ctx context.Context, actor *ActorClaims) (string, error) {
+	currentVersion, err := s.tokenVersionRepo.GetTokenVersion(ctx, actor.UserID)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return "Token has been revoked", nil
+	}
+	if err != nil {
+		return "Internal server error during token validation", err
+	}
+	if actor.TokenVersion < currentVersion {
+		return "Token has been revoked", nil
+	}
+
+	status, err := s.statusRepo.GetStatus(ctx, actor.UserID)
+	if errors.Is(err, apperrors.ErrUserNotFound) {
+		return "Token has been revoked", nil
+	}
+	if err != nil {
+		return "Internal server error during token validation", err
+	}
+	if entities.StatusBlocksAccess(status.Status, status.SuspendedUntil, time.Now()) {
+		return "Token has been revoked", nil
+	}
+	return "", nil
 }
 
 func (s *jwtTokenService) BlacklistToken(ctx context.Context, jti string, expiration time.Duration) error {
 	return s.jwtBlacklistRepo.AddToBlacklist(ctx, jti, expiration)
 }
+
+func (s *jwtTokenService) RevokeUserTokens(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.tokenVersionRepo.IncrementTokenVersion(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+func (s *jwtTokenService) JWKS() JSONWebKeySet {
+	return s.keyRing.JWKS()
+}
@@ -0,0 +1,199 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const minRSAKeyBits = 2048
+
+// SigningKey is one entry of the key ring. Keys loaded from a public-only PEM
+// file have a nil PrivateKey and can only be used to verify tokens.
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+func (k *SigningKey) CanSign() bool {
+	return k.PrivateKey != nil
+}
+
+type KeyRing struct {
+	keys   map[string]*SigningKey
+	active *SigningKey
+}
+
+// LoadKeyRing reads every *.pem file in dir. The file name without extension
+// becomes the kid, so naming keys by date (e.g. 2026-10-01.pem) keeps rotation
+// predictable. When activeKeyID is empty the newest private key by name signs.
+func LoadKeyRing(dir string, activeKeyID string) (*KeyRing, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	keys := make([]*SigningKey, 0, len(paths))
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key %s: %w", path, err)
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		key, err := ParseSigningKey(kid, raw)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeyRing(keys, activeKeyID)
+}
+
+func NewKeyRing(keys []*SigningKey, activeKeyID string) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string]*SigningKey, len(keys))}
+
+	var signers []string
+	for _, key := range keys {
+		if _, exists := ring.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate signing key id %q", key.ID)
+		}
+		ring.keys[key.ID] = key
+		if key.CanSign() {
+			signers = append(signers, key.ID)
+		}
+	}
+
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("key ring has no private signing key")
+	}
+
+	if activeKeyID == "" {
+		sort.Strings(signers)
+		activeKeyID = signers[len(signers)-1]
+	}
+
+	active, ok := ring.keys[activeKeyID]
+	if !ok || !active.CanSign() {
+		return nil, fmt.Errorf("active signing key %q not found or has no private key", activeKeyID)
+	}
+	ring.active = active
+
+	return ring, nil
+}
+
+// ParseSigningKey accepts PKCS#8 / PKCS#1 private keys and PKIX public keys
+// holding either an RSA (RS256) or Ed25519 (EdDSA) key.
+func ParseSigningKey(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %q: no PEM block found", kid)
+	}
+
+	var (
+		private crypto.PrivateKey
+		public  crypto.PublicKey
+		err     error
+	)
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("signing key %q: unsupported PEM block %q", kid, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing key %q: %w", kid, err)
+	}
+
+	if signer, ok := private.(crypto.Signer); ok {
+		public = signer.Public()
+	}
+
+	key := &SigningKey{ID: kid, PrivateKey: private, PublicKey: public}
+
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("signing key %q: RSA key must be at least %d bits", kid, minRSAKeyBits)
+		}
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("signing key %q: unsupported key type %T", kid, public)
+	}
+
+	return key, nil
+}
+
+func (r *KeyRing) ActiveKey() *SigningKey {
+	return r.active
+}
+
+func (r *KeyRing) Key(kid string) (*SigningKey, bool) {
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+// Sign signs claims with the active key and stamps its kid into the header so
+// verifiers can pick the matching key from the JWKS.
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(r.active.Method, claims)
+	token.Header["kid"] = r.active.ID
+
+	signed, err := token.SignedString(r.active.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+	return signed, nil
+}
+
+// Methods lists the algorithms present in the ring, used to pin jwt.WithValidMethods.
+func (r *KeyRing) Methods() []string {
+	seen := make(map[string]struct{})
+	var methods []string
+	for _, key := range r.keys {
+		alg := key.Method.Alg()
+		if _, ok := seen[alg]; !ok {
+			seen[alg] = struct{}{}
+			methods = append(methods, alg)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// JWKS returns the public half of every key, including verify-only keys kept
+// around so tokens signed before a rotation stay valid until they expire.
+func (r *KeyRing) JWKS() JSONWebKeySet {
+	ids := make([]string, 0, len(r.keys))
+	for id := range r.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(ids))}
+	for _, id := range ids {
+		set.Keys = append(set.Keys, toJSONWebKey(r.keys[id]))
+	}
+	return set
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+// UserListFilter selects a page of the admin user directory. Zero values mean
+// no filter.
+type UserListFilter struct {
+	Role string
+	// Status is one of the entities.UserStatus* values. Without it every user
+	// that is not deleted is listed.
+	Status        string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	EmailDomain   string
+	Search        string
+	// SortBy is created_at (the default), name, username or email.
+	SortBy     string
+	Descending bool
+	// Cursor is the NextCursor of the previous page.
+	Cursor string
+	Limit  int
+}
+
+type UserPage struct {
+	Users []entities.User
+	// NextCursor is empty on the last page.
+	NextCursor string
+}
+
+// userCursor is the position after the last row of a page. It carries the sort
+// so a cursor cannot be replayed against a different order.
+type userCursor struct {
+	SortBy     string    `json:"s"`
+	Descending bool      `json:"d"`
+	Value      string    `json:"v"`
+	ID         uuid.UUID `json:"id"`
+}
+
+func (s *UserServiceImpl) ListUsers(ctx context.Context, filter UserListFilter) (*UserPage, error) {
+	params := &db.ListUsersParams{
+		Role:        filter.Role,
+		Status:      filter.Status,
+		EmailDomain: filter.EmailDomain,
+		Search:      filter.Search,
+		SortBy:      filter.SortBy,
+		Descending:  filter.Descending,
+	}
+
+	if filter.Status != "" && !isUserStatus(filter.Status) {
+		return nil, fmt.Errorf("%w: unknown status %q", apperrors.ErrInvalidRequestPayload, filter.Status)
+	}
+
+	if params.SortBy == "" {
+		params.SortBy = "created_at"
+	}
+	if _, ok := db.UserSortColumns[params.SortBy]; !ok {
+		return nil, fmt.Errorf("%w: unknown sort %q", apperrors.ErrInvalidRequestPayload, filter.SortBy)
+	}
+
+	if filter.CreatedAfter != nil {
+		params.CreatedAfter.Time, params.CreatedAfter.Valid = *filter.CreatedAfter, true
+	}
+	if filter.CreatedBefore != nil {
+		params.CreatedBefore.Time, params.CreatedBefore.Valid = *filter.CreatedBefore, true
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultUserPageSize
+	}
+	if limit > maxUserPageSize {
+		limit = maxUserPageSize
+	}
+	// one extra row tells whether there is a next page
+	params.Limit = int32(limit + 1)
+
+	if filter.Cursor != "" {
+		cursor, err := decodeUserCursor(filter.Cursor)
+		if err != nil || cursor.SortBy != params.SortBy || cursor.Descending != params.Descending {
+			return nil, fmt.Errorf("%w: invalid cursor", apperrors.ErrInvalidRequestPayload)
+		}
+		params.AfterValue = cursor.Value
+		params.AfterID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	rows, err := s.userRepo.ListUsers(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list users: %w", err)
+	}
+
+	page := &UserPage{}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		page.NextCursor = encodeUserCursor(userCursor{
+			SortBy:     params.SortBy,
+			Descending: params.Descending,
+			Value:      userSortValue(&rows[limit-1], params.SortBy),
+			ID:         rows[limit-1].ID,
+		})
+	}
+	page.Users = toDomainUsers(rows)
+
+	return page, nil
+}
+
+func userSortValue(row *db.ListUsersRow, sortBy string) string {
+	switch sortBy {
+	case "name":
+		return row.Name
+	case "username":
+		return row.Username
+	case "email":
+		return row.Email
+	default:
+		return row.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+func encodeUserCursor(cursor userCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUserCursor(raw string) (*userCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor userCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
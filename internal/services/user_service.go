@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	goerrors "errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -13,6 +15,7 @@ import (
 	"github.com/labstack/gommon/log"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
 	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/messaging/rabbitmq"
@@ -29,6 +32,7 @@ import (
 // ActivityMetadata contains HTTP request metadata for activity tracking
 type ActivityMetadata struct {
 	SessionID string
+	Device    string
 	IPAddress string
 	UserAgent string
 }
@@ -36,7 +40,10 @@ type ActivityMetadata struct {
 type UserSource interface {
 	db.GetAllUsersRow |
 		db.GetUserByIDRow |
+		db.GetUserByEmailRow |
 		db.GetUserByIDsRow |
+		db.GetUserByPhoneNumberRow |
+		db.ListUsersRow |
 		db.User |
 		db.GetUserByUsernameRow
 }
@@ -46,14 +53,22 @@ type UserService interface {
 	Login(ctx context.Context, req *models.UserLoginRequest, metadata *ActivityMetadata) (*entities.User, error)
 	Logout(ctx context.Context, authHeader string) error
 	GetAllUsers(ctx context.Context) ([]entities.User, error)
+	// ListUsers returns one page of the admin directory.
+	ListUsers(ctx context.Context, filter UserListFilter) (*UserPage, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
 	GetUserByIDs(ctx context.Context, IDs []uuid.UUID) ([]entities.User, error)
 	UpdateUser(ctx context.Context, id uuid.UUID, req *models.UserUpdateRequest) (*entities.User, error)
+	// PatchUser applies a merge patch. With ifUpdatedAt set, it fails with
+	// ErrPreconditionFailed unless the user is still at that version.
+	PatchUser(ctx context.Context, id uuid.UUID, req *models.UserPatchRequest, ifUpdatedAt *time.Time) (*entities.User, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) (*entities.User, error)
 }
 
 type UserServiceImpl struct {
 	userRepo         repositories.UserRepository
+	emailVerifier    EmailVerificationService
+	passwordPolicy   PasswordPolicy
+	phonePolicy      PhonePolicy
 	validator        *validator.Validate
 	tokenService     token.TokenService
 	JWTBlacklistRepo repositories.JWTBlacklistRepository
@@ -64,6 +79,9 @@ type UserServiceImpl struct {
 
 func NewUserService(
 	userRepo repositories.UserRepository,
+	emailVerifier EmailVerificationService,
+	passwordPolicy PasswordPolicy,
+	phonePolicy PhonePolicy,
 	validator *validator.Validate,
 	tokenService token.TokenService,
 	JWTBlacklistRepo repositories.JWTBlacklistRepository,
@@ -73,6 +91,9 @@ func NewUserService(
 ) UserService {
 	return &UserServiceImpl{
 		userRepo:         userRepo,
+		emailVerifier:    emailVerifier,
+		passwordPolicy:   passwordPolicy,
+		phonePolicy:      phonePolicy,
 		validator:        validator,
 		tokenService:     tokenService,
 		JWTBlacklistRepo: JWTBlacklistRepo,
@@ -113,10 +134,10 @@ func (s *UserServiceImpl) Register(ctx context.Context, req *models.UserRegister
 		}
 	}
 
-	// check role
-	if req.Role == "" {
-		req.Role = "user"
-	}
+	validationErrors = append(validationErrors, s.passwordPolicy.Check("password", req.Password, req.Username, emailLocalPart(req.Email))...)
+
+	phoneNumber, phoneErrors := s.phonePolicy.Normalize("phone_number", req.PhoneNumber)
+	validationErrors = append(validationErrors, phoneErrors...)
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -127,21 +148,33 @@ func (s *UserServiceImpl) Register(ctx context.Context, req *models.UserRegister
 		return nil, apperrors.ValidationErrors{Errors: validationErrors}
 	}
 
+	// Registration always creates plain users. Other roles are granted by
+	// admins, e.g. by approving a seller application.
 	dbParam := &db.CreateUserParams{
 		ID:          uuid.New(),
 		Name:        req.Name,
 		Username:    req.Username,
 		Email:       req.Email,
 		Password:    string(hashedPassword),
-		PhoneNumber: "",
+		PhoneNumber: phoneNumber,
 		Address:     "",
-		Role:        req.Role,
+		Role:        entities.RoleUser,
+		Status:      entities.UserStatusPendingVerification,
 	}
 
 	userDB, err := s.userRepo.CreateUser(ctx, dbParam)
 	if err != nil {
 		return nil, fmt.Errorf("service: failed to register user: %w", err)
 	}
+	user := toDomainUser(userDB)
+
+	// Registration succeeds without a link; the user can ask for another one.
+	verificationURL := ""
+	if link, err := s.emailVerifier.CreateLink(ctx, user); err != nil {
+		s.log.WithError(err).Error("Failed to create email verification link")
+	} else {
+		verificationURL = link.URL
+	}
 
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -152,6 +185,8 @@ func (s *UserServiceImpl) Register(ctx context.Context, req *models.UserRegister
 			Email:     userDB.Email,
 			Username:  userDB.Username,
 			CreatedAt: time.Now(),
+
+			VerificationURL: verificationURL,
 		}
 		if err := s.eventPublisher.PublishUserRegistered(ctx, event); err != nil {
 			log.Errorf("Failed to publish user registered event: %v", err)
@@ -159,7 +194,7 @@ func (s *UserServiceImpl) Register(ctx context.Context, req *models.UserRegister
 		}
 	}()
 
-	return toDomainUser(userDB), nil
+	return user, nil
 }
 
 func (s *UserServiceImpl) Login(ctx context.Context, req *models.UserLoginRequest, metadata *ActivityMetadata) (*entities.User, error) {
@@ -176,6 +211,11 @@ func (s *UserServiceImpl) Login(ctx context.Context, req *models.UserLoginReques
 	}
 
 	user := toDomainUser(userDB)
+	// Checked after the password so the status of an account is not disclosed
+	// to someone who does not know it.
+	if err := accountStatusError(user); err != nil {
+		return nil, err
+	}
 
 	// Track login activity to Kafka (async, non-blocking)
 	if s.kafkaProducer != nil && metadata != nil {
@@ -271,14 +311,28 @@ func (s *UserServiceImpl) UpdateUser(ctx context.Context, id uuid.UUID, req *mod
 		return nil, fmt.Errorf("%w: %s", apperrors.ErrInvalidRequestPayload, err)
 	}
 
+	current, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("UpdateUser service error: %w", err)
+	}
+
+	// An unchanged number is left as stored, even if it predates the policy.
+	phoneNumber := req.PhoneNumber
+	if phoneNumber != current.PhoneNumber {
+		normalized, phoneErrors := s.phonePolicy.Normalize("phone_number", phoneNumber)
+		if len(phoneErrors) > 0 {
+			return nil, apperrors.ValidationErrors{Errors: phoneErrors}
+		}
+		phoneNumber = normalized
+	}
+
 	dbParams := &db.UpdateUserParams{
 		ID:          id,
 		Name:        req.Name,
 		Username:    req.Username,
 		Email:       req.Email,
-		Password:    req.Password,
 		Address:     req.Address,
-		PhoneNumber: req.PhoneNumber,
+		PhoneNumber: phoneNumber,
 	}
 
 	user, err := s.userRepo.UpdateUser(ctx, dbParams)
@@ -286,16 +340,131 @@ func (s *UserServiceImpl) UpdateUser(ctx context.Context, id uuid.UUID, req *mod
 		return nil, fmt.Errorf("UpdateUser service error: %w", err)
 	}
 
-	return toDomainUser(user), nil
+	updated := toDomainUser(user)
+	if updated.Email != current.Email {
+		if err := s.emailVerifier.SendLink(ctx, updated); err != nil {
+			s.log.WithError(err).Error("Failed to send verification link for the new email")
+		}
+	}
+
+	return updated, nil
+}
+
+func (s *UserServiceImpl) PatchUser(ctx context.Context, id uuid.UUID, req *models.UserPatchRequest, ifUpdatedAt *time.Time) (*entities.User, error) {
+	current, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to patch user: %w", err)
+	}
+	if ifUpdatedAt != nil && !current.UpdatedAt.Equal(*ifUpdatedAt) {
+		return nil, apperrors.ErrPreconditionFailed
+	}
+
+	var validationErrors []errors.ValidationError
+	required := func(field string, value models.OptionalString) sql.NullString {
+		if !value.Set {
+			return sql.NullString{}
+		}
+		if value.Null || strings.TrimSpace(value.Value) == "" {
+			validationErrors = append(validationErrors, errors.ValidationError{Field: field, Message: "is required"})
+			return sql.NullString{}
+		}
+		return sql.NullString{String: value.Value, Valid: true}
+	}
+	// null clears an optional field
+	optional := func(value models.OptionalString) sql.NullString {
+		return sql.NullString{String: value.Value, Valid: value.Set}
+	}
+	phoneNumber := func(value models.OptionalString) sql.NullString {
+		number := optional(value)
+		if !number.Valid || number.String == current.PhoneNumber {
+			return number
+		}
+		normalized, phoneErrors := s.phonePolicy.Normalize("phone_number", number.String)
+		validationErrors = append(validationErrors, phoneErrors...)
+		return sql.NullString{String: normalized, Valid: true}
+	}
+
+	params := &db.PatchUserParams{
+		ID:          id,
+		Name:        required("name", req.Name),
+		Username:    required("username", req.Username),
+		Email:       required("email", req.Email),
+		Address:     optional(req.Address),
+		PhoneNumber: phoneNumber(req.PhoneNumber),
+	}
+	if ifUpdatedAt != nil {
+		params.IfUpdatedAt = sql.NullTime{Time: *ifUpdatedAt, Valid: true}
+	}
+
+	if params.Email.Valid && s.validator.Var(params.Email.String, "email") != nil {
+		validationErrors = append(validationErrors, errors.ValidationError{Field: "email", Message: "must be a valid email address"})
+	}
+
+	// Only values that change are checked, so the user's own row never matches.
+	newUsername, newEmail := "", ""
+	if params.Username.Valid && params.Username.String != current.Username {
+		newUsername = params.Username.String
+	}
+	if params.Email.Valid && params.Email.String != current.Email {
+		newEmail = params.Email.String
+	}
+	if newUsername != "" || newEmail != "" {
+		existingUser, err := s.userRepo.ExistUsernameorEmail(ctx, newUsername, newEmail)
+		if err == nil && existingUser != nil {
+			if newUsername != "" && existingUser.Username == newUsername {
+				validationErrors = append(validationErrors, errors.ValidationError{Field: "username", Message: "username already exists"})
+			}
+			if newEmail != "" && existingUser.Email == newEmail {
+				validationErrors = append(validationErrors, errors.ValidationError{Field: "email", Message: "email already exists"})
+			}
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, apperrors.ValidationErrors{Errors: validationErrors}
+	}
+
+	if !params.Name.Valid && !params.Username.Valid && !params.Email.Valid && !params.Address.Valid && !params.PhoneNumber.Valid {
+		return toDomainUser(current), nil
+	}
+
+	user, err := s.userRepo.PatchUser(ctx, params)
+	if goerrors.Is(err, apperrors.ErrNotFound) && ifUpdatedAt != nil {
+		return nil, apperrors.ErrPreconditionFailed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to patch user: %w", err)
+	}
+
+	updated := toDomainUser(user)
+	if updated.Email != current.Email {
+		if err := s.emailVerifier.SendLink(ctx, updated); err != nil {
+			s.log.WithError(err).Error("Failed to send verification link for the new email")
+		}
+	}
+
+	return updated, nil
 }
 
 func (s *UserServiceImpl) DeleteUser(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	current, err := s.userRepo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("DeleteUser service error: %w", err)
+	}
+
 	user, err := s.userRepo.DeleteUser(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("UpdateUser service error: %w", err)
 	}
 
-	return toDomainUser(user), nil
+	if err := s.tokenService.RevokeUserTokens(ctx, id); err != nil {
+		return nil, fmt.Errorf("DeleteUser service error: %w", err)
+	}
+
+	deleted := toDomainUser(user)
+	publishStatusChanged(s.eventPublisher, s.log, deleted, current.Status, "account deleted", uuid.Nil)
+
+	return deleted, nil
 }
 
 func toDomainUser[T UserSource](dbUser *T) *entities.User {
@@ -306,6 +475,37 @@ func toDomainUser[T UserSource](dbUser *T) *entities.User {
 
 	id := v.FieldByName("ID").Interface().(uuid.UUID)
 
+	var emailVerifiedAt *time.Time
+	if verifiedAt := v.FieldByName("EmailVerifiedAt").Interface().(sql.NullTime); verifiedAt.Valid {
+		emailVerifiedAt = &verifiedAt.Time
+	}
+
+	var suspendedUntil *time.Time
+	if until := v.FieldByName("SuspendedUntil").Interface().(sql.NullTime); until.Valid {
+		suspendedUntil = &until.Time
+	}
+
+	// only some queries select deleted_at
+	var deletedAt gorm.DeletedAt
+	if field := v.FieldByName("DeletedAt"); field.IsValid() {
+		deletedAt = gorm.DeletedAt(field.Interface().(sql.NullTime))
+	}
+
+	// only some queries select phone_verified_at
+	var phoneVerifiedAt *time.Time
+	if field := v.FieldByName("PhoneVerifiedAt"); field.IsValid() {
+		if verifiedAt := field.Interface().(sql.NullTime); verifiedAt.Valid {
+			phoneVerifiedAt = &verifiedAt.Time
+		}
+	}
+
+	var deletionScheduledAt *time.Time
+	if field := v.FieldByName("DeletionScheduledAt"); field.IsValid() {
+		if scheduledAt := field.Interface().(sql.NullTime); scheduledAt.Valid {
+			deletionScheduledAt = &scheduledAt.Time
+		}
+	}
+
 	return &entities.User{
 		ID:          id,
 		Name:        v.FieldByName("Name").Interface().(string),
@@ -316,6 +516,17 @@ func toDomainUser[T UserSource](dbUser *T) *entities.User {
 		PhoneNumber: v.FieldByName("PhoneNumber").Interface().(string),
 		CreatedAt:   v.FieldByName("CreatedAt").Interface().(time.Time),
 		UpdatedAt:   v.FieldByName("UpdatedAt").Interface().(time.Time),
+		DeletedAt:   deletedAt,
+		AvatarURL:   v.FieldByName("AvatarUrl").Interface().(string),
+
+		EmailVerifiedAt: emailVerifiedAt,
+		PhoneVerifiedAt: phoneVerifiedAt,
+
+		Status:         v.FieldByName("Status").Interface().(string),
+		StatusReason:   v.FieldByName("StatusReason").Interface().(string),
+		SuspendedUntil: suspendedUntil,
+
+		DeletionScheduledAt: deletionScheduledAt,
 	}
 }
 
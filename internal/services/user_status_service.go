@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/messaging/rabbitmq"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+// statusTransitions lists the states each state can move to. A suspended user
+// can be suspended again to change the reason or the end date.
+var statusTransitions = map[string][]string{
+	entities.UserStatusPendingVerification: {entities.UserStatusActive, entities.UserStatusSuspended, entities.UserStatusBanned, entities.UserStatusDeleted},
+	entities.UserStatusActive:              {entities.UserStatusSuspended, entities.UserStatusBanned, entities.UserStatusDeleted},
+	entities.UserStatusSuspended:           {entities.UserStatusActive, entities.UserStatusSuspended, entities.UserStatusBanned, entities.UserStatusDeleted},
+	entities.UserStatusBanned:              {entities.UserStatusActive, entities.UserStatusDeleted},
+	entities.UserStatusDeleted:             {},
+}
+
+func isUserStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+func canTransition(from, to string) bool {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange is an admin moving an account to another state.
+type StatusChange struct {
+	Status string
+	Reason string
+	// SuspendedUntil ends a suspension on its own. Only valid with
+	// UserStatusSuspended; without it the suspension lasts until lifted.
+	SuspendedUntil *time.Time
+	ChangedBy      uuid.UUID
+}
+
+type UserStatusService interface {
+	ChangeStatus(ctx context.Context, userID uuid.UUID, change StatusChange) (*entities.User, error)
+}
+
+type UserStatusServiceImpl struct {
+	userRepo       repositories.UserRepository
+	statusRepo     repositories.AccountStatusRepository
+	sessionService SessionService
+	eventPublisher *rabbitmq.EventPublisher
+	log            *logrus.Logger
+}
+
+func NewUserStatusService(
+	userRepo repositories.UserRepository,
+	statusRepo repositories.AccountStatusRepository,
+	sessionService SessionService,
+	eventPublisher *rabbitmq.EventPublisher,
+	log *logrus.Logger,
+) UserStatusService {
+	return &UserStatusServiceImpl{
+		userRepo:       userRepo,
+		statusRepo:     statusRepo,
+		sessionService: sessionService,
+		eventPublisher: eventPublisher,
+		log:            log,
+	}
+}
+
+func (s *UserStatusServiceImpl) ChangeStatus(ctx context.Context, userID uuid.UUID, change StatusChange) (*entities.User, error) {
+	if err := validateStatusChange(change); err != nil {
+		return nil, err
+	}
+
+	current, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canTransition(current.Status, change.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", apperrors.ErrStatusTransition, current.Status, change.Status)
+	}
+
+	param := &db.UpdateUserStatusParams{
+		ID:           userID,
+		Status:       change.Status,
+		StatusReason: change.Reason,
+		FromStatus:   current.Status,
+	}
+	if change.SuspendedUntil != nil {
+		param.SuspendedUntil = sql.NullTime{Time: *change.SuspendedUntil, Valid: true}
+	}
+
+	updated, err := s.userRepo.UpdateStatus(ctx, param)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to change status: %w", err)
+	}
+	user := toDomainUser(updated)
+
+	if err := s.statusRepo.SetStatus(ctx, userID, &repositories.AccountStatus{Status: user.Status, SuspendedUntil: user.SuspendedUntil}); err != nil {
+		return nil, fmt.Errorf("service: failed to change status: %w", err)
+	}
+
+	if user.BlocksAccess(time.Now()) {
+		if err := s.sessionService.LogoutEverywhere(ctx, userID); err != nil {
+			return nil, fmt.Errorf("service: failed to end sessions: %w", err)
+		}
+	}
+
+	publishStatusChanged(s.eventPublisher, s.log, user, current.Status, change.Reason, change.ChangedBy)
+
+	return user, nil
+}
+
+func validateStatusChange(change StatusChange) error {
+	var errs []apperrors.ValidationError
+
+	if !isUserStatus(change.Status) {
+		errs = append(errs, apperrors.ValidationError{Field: "status", Message: "unknown status"})
+	}
+	if change.Reason == "" {
+		errs = append(errs, apperrors.ValidationError{Field: "reason", Message: "a reason is required"})
+	}
+	if change.SuspendedUntil != nil {
+		if change.Status != entities.UserStatusSuspended {
+			errs = append(errs, apperrors.ValidationError{Field: "suspended_until", Message: "only allowed when suspending"})
+		} else if !change.SuspendedUntil.After(time.Now()) {
+			errs = append(errs, apperrors.ValidationError{Field: "suspended_until", Message: "must be in the future"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return apperrors.ValidationErrors{Errors: errs}
+	}
+	return nil
+}
+
+// accountStatusError is the error returned to a user whose account may not be
+// used at the moment, or nil.
+func accountStatusError(user *entities.User) error {
+	if !user.BlocksAccess(time.Now()) {
+		return nil
+	}
+
+	switch user.Status {
+	case entities.UserStatusBanned:
+		return apperrors.ErrAccountBanned
+	case entities.UserStatusDeleted:
+		return apperrors.ErrAccountDeleted
+	default:
+		return apperrors.ErrAccountSuspended
+	}
+}
+
+// publishStatusChanged announces a transition of user, which already holds the
+// new state. changedBy is uuid.Nil for changes the system made.
+func publishStatusChanged(publisher *rabbitmq.EventPublisher, log *logrus.Logger, user *entities.User, from, reason string, changedBy uuid.UUID) {
+	event := rabbitmq.UserStatusChangedEvent{
+		UserID:         user.ID.String(),
+		From:           from,
+		To:             user.Status,
+		Reason:         reason,
+		SuspendedUntil: user.SuspendedUntil,
+		ChangedAt:      time.Now(),
+	}
+	if changedBy != uuid.Nil {
+		event.ChangedBy = changedBy.String()
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := publisher.PublishUserStatusChanged(ctx, event); err != nil {
+			log.WithError(err).Error("Failed to publish user status changed event")
+		}
+	}()
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+)
+
+type WebAuthnConfig struct {
+	RPID          string
+	RPDisplayName string
+	RPOrigins     []string
+	CeremonyTTL   time.Duration
+}
+
+// WebAuthnChallenge is handed to the browser at the start of a ceremony.
+// Options is passed as is to navigator.credentials.create() / get().
+type WebAuthnChallenge struct {
+	CeremonyID string
+	Options    interface{}
+}
+
+type WebAuthnService interface {
+	BeginRegistration(ctx context.Context, user *entities.User) (*WebAuthnChallenge, error)
+	FinishRegistration(ctx context.Context, user *entities.User, ceremonyID, name string, credential []byte) (*entities.WebAuthnCredential, error)
+	// BeginLogin starts a passkey login. No username is asked for: the
+	// authenticator picks a discoverable credential, so the endpoint does not
+	// reveal which accounts exist.
+	BeginLogin(ctx context.Context) (*WebAuthnChallenge, error)
+	FinishLogin(ctx context.Context, ceremonyID string, credential []byte) (*entities.User, error)
+
+	ListCredentials(ctx context.Context, userID uuid.UUID) ([]entities.WebAuthnCredential, error)
+	DeleteCredential(ctx context.Context, userID uuid.UUID, credentialID []byte) error
+}
+
+type WebAuthnServiceImpl struct {
+	webAuthn     *webauthn.WebAuthn
+	credRepo     repositories.WebAuthnCredentialRepository
+	ceremonyRepo repositories.WebAuthnCeremonyRepository
+	userRepo     repositories.UserRepository
+	config       WebAuthnConfig
+	log          *logrus.Logger
+}
+
+func NewWebAuthnService(
+	credRepo repositories.WebAuthnCredentialRepository,
+	ceremonyRepo repositories.WebAuthnCeremonyRepository,
+	userRepo repositories.UserRepository,
+	config WebAuthnConfig,
+	log *logrus.Logger,
+) (WebAuthnService, error) {
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: config.CeremonyTTL, TimeoutUVD: config.CeremonyTTL}
+
+	// User verification is required on every ceremony, so a passkey login
+	// counts as two factors and skips the TOTP step.
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:                  config.RPID,
+		RPDisplayName:         config.RPDisplayName,
+		RPOrigins:             config.RPOrigins,
+		AttestationPreference: protocol.PreferNoAttestation,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{Login: timeout, Registration: timeout},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service: invalid webauthn config: %w", err)
+	}
+
+	return &WebAuthnServiceImpl{
+		webAuthn:     wa,
+		credRepo:     credRepo,
+		ceremonyRepo: ceremonyRepo,
+		userRepo:     userRepo,
+		config:       config,
+		log:          log,
+	}, nil
+}
+
+func (s *WebAuthnServiceImpl) BeginRegistration(ctx context.Context, user *entities.User) (*WebAuthnChallenge, error) {
+	waUser, err := s.loadUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	creation, session, err := s.webAuthn.BeginRegistration(waUser,
+		webauthn.WithExclusions(webauthn.Credentials(waUser.credentials).CredentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to begin passkey registration: %w", err)
+	}
+
+	ceremonyID, err := s.saveCeremony(ctx, &repositories.WebAuthnCeremony{
+		Kind:    repositories.WebAuthnCeremonyRegistration,
+		UserID:  user.ID.String(),
+		Session: *session,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebAuthnChallenge{CeremonyID: ceremonyID, Options: creation}, nil
+}
+
+func (s *WebAuthnServiceImpl) FinishRegistration(ctx context.Context, user *entities.User, ceremonyID, name string, credential []byte) (*entities.WebAuthnCredential, error) {
+	ceremony, err := s.ceremonyRepo.ConsumeCeremony(ctx, ceremonyID)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.Kind != repositories.WebAuthnCeremonyRegistration || ceremony.UserID != user.ID.String() {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(credential)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrPasskeyVerification, err)
+	}
+
+	waUser, err := s.loadUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := s.webAuthn.CreateCredential(waUser, ceremony.Session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrPasskeyVerification, err)
+	}
+
+	transports := make([]string, 0, len(cred.Transport))
+	for _, transport := range cred.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	row, err := s.credRepo.CreateCredential(ctx, &db.CreateWebAuthnCredentialParams{
+		ID:              cred.ID,
+		UserID:          user.ID,
+		Name:            name,
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		Transports:      transports,
+		Flags:           int16(cred.Flags.ProtocolValue()),
+		Aaguid:          cred.Authenticator.AAGUID,
+		SignCount:       int64(cred.Authenticator.SignCount),
+		Attachment:      string(cred.Authenticator.Attachment),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to save passkey: %w", err)
+	}
+
+	return toDomainWebAuthnCredential(row), nil
+}
+
+func (s *WebAuthnServiceImpl) BeginLogin(ctx context.Context) (*WebAuthnChallenge, error) {
+	assertion, session, err := s.webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to begin passkey login: %w", err)
+	}
+
+	ceremonyID, err := s.saveCeremony(ctx, &repositories.WebAuthnCeremony{
+		Kind:    repositories.WebAuthnCeremonyLogin,
+		Session: *session,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebAuthnChallenge{CeremonyID: ceremonyID, Options: assertion}, nil
+}
+
+func (s *WebAuthnServiceImpl) FinishLogin(ctx context.Context, ceremonyID string, credential []byte) (*entities.User, error) {
+	ceremony, err := s.ceremonyRepo.ConsumeCeremony(ctx, ceremonyID)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.Kind != repositories.WebAuthnCeremonyLogin {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(credential)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrPasskeyVerification, err)
+	}
+
+	var owner *entities.User
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, err := uuid.FromBytes(userHandle)
+		if err != nil {
+			return nil, apperrors.ErrPasskeyVerification
+		}
+
+		userDB, err := s.userRepo.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, apperrors.ErrPasskeyVerification
+		}
+		owner = toDomainUser(userDB)
+
+		return s.loadUser(ctx, owner)
+	}
+
+	_, cred, err := s.webAuthn.ValidatePasskeyLogin(handler, ceremony.Session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrPasskeyVerification, err)
+	}
+
+	// A counter that did not move forward means the key may have been cloned.
+	if cred.Authenticator.CloneWarning {
+		s.log.WithField("user_id", owner.ID).Warn("Passkey sign count went backwards, rejecting login")
+		return nil, apperrors.ErrPasskeyVerification
+	}
+
+	err = s.credRepo.UpdateCredentialUsage(ctx, &db.UpdateWebAuthnCredentialUsageParams{
+		ID:        cred.ID,
+		SignCount: int64(cred.Authenticator.SignCount),
+		Flags:     int16(cred.Flags.ProtocolValue()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to record passkey use: %w", err)
+	}
+
+	return owner, nil
+}
+
+func (s *WebAuthnServiceImpl) ListCredentials(ctx context.Context, userID uuid.UUID) ([]entities.WebAuthnCredential, error) {
+	rows, err := s.credRepo.ListCredentials(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list passkeys: %w", err)
+	}
+
+	res := make([]entities.WebAuthnCredential, 0, len(rows))
+	for i := range rows {
+		res = append(res, *toDomainWebAuthnCredential(&rows[i]))
+	}
+	return res, nil
+}
+
+func (s *WebAuthnServiceImpl) DeleteCredential(ctx context.Context, userID uuid.UUID, credentialID []byte) error {
+	return s.credRepo.DeleteCredential(ctx, userID, credentialID)
+}
+
+func (s *WebAuthnServiceImpl) saveCeremony(ctx context.Context, ceremony *repositories.WebAuthnCeremony) (string, error) {
+	ceremonyID, err := randomURLToken(32)
+	if err != nil {
+		return "", fmt.Errorf("service: failed to generate webauthn ceremony: %w", err)
+	}
+
+	if err := s.ceremonyRepo.SaveCeremony(ctx, ceremonyID, ceremony, s.config.CeremonyTTL); err != nil {
+		return "", fmt.Errorf("service: failed to save webauthn ceremony: %w", err)
+	}
+	return ceremonyID, nil
+}
+
+func (s *WebAuthnServiceImpl) loadUser(ctx context.Context, user *entities.User) (*webAuthnUser, error) {
+	rows, err := s.credRepo.ListCredentials(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to load passkeys: %w", err)
+	}
+
+	creds := make([]webauthn.Credential, 0, len(rows))
+	for _, row := range rows {
+		transports := make([]protocol.AuthenticatorTransport, 0, len(row.Transports))
+		for _, transport := range row.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+
+		creds = append(creds, webauthn.Credential{
+			ID:              row.ID,
+			PublicKey:       row.PublicKey,
+			AttestationType: row.AttestationType,
+			Transport:       transports,
+			Flags:           webauthn.NewCredentialFlags(protocol.AuthenticatorFlags(row.Flags)),
+			Authenticator: webauthn.Authenticator{
+				AAGUID:     row.Aaguid,
+				SignCount:  uint32(row.SignCount),
+				Attachment: protocol.AuthenticatorAttachment(row.Attachment),
+			},
+		})
+	}
+
+	return &webAuthnUser{user: user, credentials: creds}, nil
+}
+
+// webAuthnUser adapts a user to the webauthn.User interface. The user handle
+// stored on the authenticator is the raw 16 byte user id.
+type webAuthnUser struct {
+	user        *entities.User
+	credentials []webauthn.Credential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	id := u.user.ID
+	return id[:]
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	if u.user.Name != "" {
+		return u.user.Name
+	}
+	return u.user.Username
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func toDomainWebAuthnCredential(row *db.WebauthnCredential) *entities.WebAuthnCredential {
+	cred := &entities.WebAuthnCredential{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		Name:       row.Name,
+		Transports: row.Transports,
+		Synced:     protocol.AuthenticatorFlags(row.Flags).HasBackupState(),
+		CreatedAt:  row.CreatedAt,
+	}
+	if row.LastUsedAt.Valid {
+		cred.LastUsedAt = &row.LastUsedAt.Time
+	}
+	return cred
+}
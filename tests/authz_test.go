@@ -0,0 +1,174 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/authz"
+)
+
+// authzCase is one entry of policies/authorization_cases.json. Add a case
+// there with every policy change.
+type authzCase struct {
+	Name    string `json:"name"`
+	Subject struct {
+		UserID        string   `json:"user_id"`
+		Roles         []string `json:"roles"`
+		Permissions   []string `json:"permissions"`
+		EmailVerified bool     `json:"email_verified"`
+		Status        string   `json:"status"`
+	} `json:"subject"`
+	Action   string `json:"action"`
+	Resource struct {
+		ID      string `json:"id"`
+		OwnerID string `json:"owner_id"`
+	} `json:"resource"`
+	Allowed bool `json:"allowed"`
+	// Rule is the rule expected to decide, empty when nothing should match.
+	Rule string `json:"rule"`
+}
+
+func TestAuthorizationPolicyCases(t *testing.T) {
+	data, err := os.ReadFile("../policies/authorization.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := authz.Parse(data)
+	if err != nil {
+		t.Fatalf("shipped policy does not parse: %v", err)
+	}
+
+	raw, err := os.ReadFile("../policies/authorization_cases.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cases []authzCase
+	if err := json.Unmarshal(raw, &cases); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			got := policy.Evaluate(authz.Request{
+				Subject: authz.Subject{
+					UserID:        tc.Subject.UserID,
+					Roles:         tc.Subject.Roles,
+					Permissions:   tc.Subject.Permissions,
+					EmailVerified: tc.Subject.EmailVerified,
+					Status:        tc.Subject.Status,
+				},
+				Action:   tc.Action,
+				Resource: authz.Resource{ID: tc.Resource.ID, OwnerID: tc.Resource.OwnerID},
+			})
+			if got.Allowed != tc.Allowed || got.RuleID != tc.Rule {
+				t.Errorf("got allowed=%v rule=%q (%s), want allowed=%v rule=%q", got.Allowed, got.RuleID, got.Reason, tc.Allowed, tc.Rule)
+			}
+			if got.Reason == "" {
+				t.Error("decision has no reason")
+			}
+		})
+	}
+}
+
+func TestAuthzEvaluate(t *testing.T) {
+	policy, err := authz.Parse([]byte(`{
+		"version": "1",
+		"rules": [
+			{"id": "staff", "description": "staff", "effect": "allow", "actions": ["orders:*"], "roles": ["support"]},
+			{"id": "refunds", "description": "refunds", "effect": "allow", "actions": ["orders:refund"], "permissions": ["orders:refund", "payments:write"]},
+			{"id": "no-refunds-unverified", "description": "verify first", "effect": "deny", "actions": ["orders:refund"], "account_statuses": ["pending_verification"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		subject authz.Subject
+		action  string
+		allowed bool
+		rule    string
+	}{
+		{name: "prefix wildcard", subject: authz.Subject{UserID: "u", Roles: []string{"support"}}, action: "orders:read", allowed: true, rule: "staff"},
+		{name: "wildcard stops at the resource", subject: authz.Subject{UserID: "u", Roles: []string{"support"}}, action: "ordersx:read"},
+		{name: "every permission needed", subject: authz.Subject{UserID: "u", Permissions: []string{"orders:refund"}}, action: "orders:refund"},
+		{name: "all permissions held", subject: authz.Subject{UserID: "u", Permissions: []string{"orders:refund", "payments:write"}}, action: "orders:refund", allowed: true, rule: "refunds"},
+		{name: "deny wins over allow", subject: authz.Subject{UserID: "u", Roles: []string{"support"}, Status: "pending_verification"}, action: "orders:refund", rule: "no-refunds-unverified"},
+		{name: "default deny", subject: authz.Subject{UserID: "u"}, action: "orders:read"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := policy.Evaluate(authz.Request{Subject: tc.subject, Action: tc.action})
+			if got.Allowed != tc.allowed || got.RuleID != tc.rule {
+				t.Errorf("got allowed=%v rule=%q, want allowed=%v rule=%q", got.Allowed, got.RuleID, tc.allowed, tc.rule)
+			}
+		})
+	}
+}
+
+func TestAuthzParseRejects(t *testing.T) {
+	tests := map[string]string{
+		"misspelled condition": `{"version": "1", "rules": [{"id": "a", "description": "a", "effect": "allow", "actions": ["x:y"], "owners": true}]}`,
+		"duplicate id":         `{"version": "1", "rules": [{"id": "a", "description": "a", "effect": "allow", "actions": ["x:y"]}, {"id": "a", "description": "a", "effect": "deny", "actions": ["x:y"]}]}`,
+		"unknown effect":       `{"version": "1", "rules": [{"id": "a", "description": "a", "effect": "permit", "actions": ["x:y"]}]}`,
+		"no actions":           `{"version": "1", "rules": [{"id": "a", "description": "a", "effect": "allow"}]}`,
+		"no version":           `{"rules": []}`,
+	}
+
+	for name, policy := range tests {
+		if _, err := authz.Parse([]byte(policy)); err == nil {
+			t.Errorf("%s: policy was accepted", name)
+		}
+	}
+}
+
+func TestAuthzStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"version": "1", "rules": []}`)
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	store, err := authz.Load(path, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store.Watch(ctx, 10*time.Millisecond)
+
+	waitFor := func(version string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for store.Policy().Version != version {
+			if time.Now().After(deadline) {
+				t.Fatalf("policy version is %q, want %q", store.Policy().Version, version)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	write(`{"version": "2", "rules": [{"id": "a", "description": "a", "effect": "allow", "actions": ["*"]}]}`)
+	waitFor("2")
+
+	// A broken file keeps the last good policy.
+	write(`{"version": "3", "rules": [`)
+	time.Sleep(50 * time.Millisecond)
+	if v := store.Policy().Version; v != "2" {
+		t.Fatalf("broken file replaced the policy, version %q", v)
+	}
+}
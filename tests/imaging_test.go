@@ -0,0 +1,165 @@
+package test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/imaging"
+)
+
+// halves returns a w x h image whose left half is left and right half right.
+func halves(w, h int, left, right color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, left)
+			} else {
+				img.Set(x, y, right)
+			}
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withOrientation inserts an EXIF segment carrying orientation right after
+// the start of a JPEG.
+func withOrientation(t *testing.T, img image.Image, orientation byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	data := buf.Bytes()
+
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // header, IFD0 at 8
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, orientation, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, // no next IFD
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func isNear(c color.Color, want color.RGBA) bool {
+	r, g, b, _ := c.RGBA()
+	near := func(got uint32, want uint8) bool {
+		d := int(got>>8) - int(want)
+		return d > -40 && d < 40
+	}
+	return near(r, want.R) && near(g, want.G) && near(b, want.B)
+}
+
+var (
+	red   = color.RGBA{R: 255, A: 255}
+	green = color.RGBA{G: 255, A: 255}
+	blue  = color.RGBA{B: 255, A: 255}
+)
+
+func TestImagingDecodeRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "text", data: []byte("hello, world"), want: imaging.ErrUnsupportedFormat},
+		{name: "svg", data: []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), want: imaging.ErrUnsupportedFormat},
+		{name: "truncated gif", data: []byte("GIF89a\x01\x00"), want: imaging.ErrUnsupportedFormat},
+		{name: "too many pixels", data: encodePNG(t, image.NewRGBA(image.Rect(0, 0, 200, 100))), want: imaging.ErrTooLarge},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := imaging.Decode(tc.data, 10_000); !errors.Is(err, tc.want) {
+				t.Fatalf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestImagingSquareCropsCentre(t *testing.T) {
+	// red | green | blue thirds; the centre square is green only
+	img := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 300; x++ {
+			switch {
+			case x < 100:
+				img.Set(x, y, red)
+			case x < 200:
+				img.Set(x, y, green)
+			default:
+				img.Set(x, y, blue)
+			}
+		}
+	}
+
+	decoded, contentType, err := imaging.Decode(encodePNG(t, img), 1_000_000)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if contentType != "image/png" {
+		t.Errorf("content type %q, want image/png", contentType)
+	}
+
+	for _, size := range []int{64, 512} {
+		square := imaging.Square(decoded, size)
+		if got := square.Bounds(); got.Dx() != size || got.Dy() != size {
+			t.Fatalf("size %d: got bounds %v", size, got)
+		}
+		for _, p := range []image.Point{{0, 0}, {size / 2, size / 2}, {size - 1, size - 1}} {
+			if c := square.At(p.X, p.Y); !isNear(c, green) {
+				t.Errorf("size %d: pixel %v is %v, want green", size, p, c)
+			}
+		}
+	}
+}
+
+func TestImagingAppliesOrientationAndStripsEXIF(t *testing.T) {
+	// Stored sideways: orientation 6 turns it a quarter clockwise, so the
+	// red left half ends up on top.
+	data := withOrientation(t, halves(80, 40, red, blue), 6)
+
+	img, contentType, err := imaging.Decode(data, 1_000_000)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if contentType != "image/jpeg" {
+		t.Errorf("content type %q, want image/jpeg", contentType)
+	}
+	if b := img.Bounds(); b.Dx() != 40 || b.Dy() != 80 {
+		t.Fatalf("got bounds %v, want 40x80", b)
+	}
+	if c := img.At(20, 10); !isNear(c, red) {
+		t.Errorf("top is %v, want red", c)
+	}
+	if c := img.At(20, 70); !isNear(c, blue) {
+		t.Errorf("bottom is %v, want blue", c)
+	}
+
+	out, err := imaging.EncodeJPEG(imaging.Square(img, 64), 85)
+	if err != nil {
+		t.Fatalf("EncodeJPEG: %v", err)
+	}
+	if bytes.Contains(out, []byte("Exif")) {
+		t.Error("output still carries EXIF data")
+	}
+}
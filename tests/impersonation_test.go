@@ -0,0 +1,103 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/middlewares"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+type recordedAudit struct {
+	actorID, targetUserID uuid.UUID
+	action                string
+	details               map[string]any
+}
+
+type fakeAuditLog struct {
+	services.AuditLogService
+	records []recordedAudit
+	err     error
+}
+
+func (f *fakeAuditLog) Record(ctx context.Context, actorID, targetUserID uuid.UUID, action string, details map[string]any) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.records = append(f.records, recordedAudit{actorID, targetUserID, action, details})
+	return nil
+}
+
+// serveImpersonated runs handler behind the given middleware, as a request
+// made with an impersonation token when actorID is set.
+func serveImpersonated(t *testing.T, actorID, userID uuid.UUID, mw echo.MiddlewareFunc) (status int, handled bool) {
+	t.Helper()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/api/accounts/password?x=1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("userID", userID)
+	if actorID != uuid.Nil {
+		c.Set("actorID", actorID)
+	}
+
+	err := mw(func(c echo.Context) error {
+		handled = true
+		return c.NoContent(http.StatusNoContent)
+	})(c)
+	if err != nil {
+		t.Fatalf("middleware: %v", err)
+	}
+	return rec.Code, handled
+}
+
+func TestDenyImpersonation(t *testing.T) {
+	userID, adminID := uuid.New(), uuid.New()
+
+	if status, handled := serveImpersonated(t, uuid.Nil, userID, middlewares.DenyImpersonation()); !handled || status != http.StatusNoContent {
+		t.Errorf("the user's own token got %d, handled %v", status, handled)
+	}
+	if status, handled := serveImpersonated(t, adminID, userID, middlewares.DenyImpersonation()); handled || status != http.StatusForbidden {
+		t.Errorf("an impersonation token got %d, handled %v; want 403", status, handled)
+	}
+}
+
+func TestAuditImpersonation(t *testing.T) {
+	userID, adminID := uuid.New(), uuid.New()
+
+	audit := &fakeAuditLog{}
+	if _, handled := serveImpersonated(t, uuid.Nil, userID, middlewares.AuditImpersonation(audit)); !handled {
+		t.Fatal("the user's own request was not handled")
+	}
+	if len(audit.records) != 0 {
+		t.Fatalf("the user's own request was audited: %+v", audit.records)
+	}
+
+	if _, handled := serveImpersonated(t, adminID, userID, middlewares.AuditImpersonation(audit)); !handled {
+		t.Fatal("the impersonated request was not handled")
+	}
+	if len(audit.records) != 1 {
+		t.Fatalf("got %d audit records, want 1", len(audit.records))
+	}
+	got := audit.records[0]
+	if got.actorID != adminID || got.targetUserID != userID || got.action != entities.AuditImpersonatedRequest {
+		t.Errorf("got %+v", got)
+	}
+	if got.details["method"] != http.MethodPut || got.details["path"] != "/api/accounts/password" || got.details["query"] != "x=1" {
+		t.Errorf("got details %v", got.details)
+	}
+
+	// A request that cannot be recorded must not run.
+	failing := &fakeAuditLog{err: errors.New("database is down")}
+	if status, handled := serveImpersonated(t, adminID, userID, middlewares.AuditImpersonation(failing)); handled || status != http.StatusInternalServerError {
+		t.Errorf("unaudited request got %d, handled %v; want 500 and not handled", status, handled)
+	}
+}
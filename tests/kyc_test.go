@@ -0,0 +1,86 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/kyc"
+)
+
+func TestKYCNormalizeNIK(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "3273011505900001", want: "3273011505900001"},
+		{in: "3273 0155 0590 0001", want: "3273015505900001"},
+		{in: "32.73.01-150590-0001", want: "3273011505900001"},
+		{in: "3273015505900001", want: "3273015505900001"}, // women: day + 40
+		{in: "3273013205900001", wantErr: true},            // day 32
+		{in: "3273017205900001", wantErr: true},            // day 72
+		{in: "3273011513900001", wantErr: true},            // month 13
+		{in: "3273011505900000", wantErr: true},            // serial 0000
+		{in: "327301150590001", wantErr: true},
+		{in: "327301150590000A", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := kyc.NormalizeNIK(tc.in)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("got %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeNIK: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestKYCNormalizeNPWP(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "01.234.567.8-901.000", want: "012345678901000"},
+		{in: "3273011505900001", want: "3273011505900001"},
+		{in: "000.000.000.0-000.000", wantErr: true},
+		{in: "01.234.567.8-901", wantErr: true},
+		{in: "01/234/567", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := kyc.NormalizeNPWP(tc.in)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("got %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeNPWP: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestKYCMask(t *testing.T) {
+	if got := kyc.Mask("3273011505900001"); got != "************0001" {
+		t.Errorf("got %q", got)
+	}
+	if got := kyc.Mask(""); got != "" {
+		t.Errorf("got %q for an empty number", got)
+	}
+}
@@ -0,0 +1,265 @@
+package test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
+)
+
+const (
+	testClientID    = "shop-app"
+	testRedirectURI = "https://shop.tokohobby.test/callback"
+	// testVerifier is the RFC 7636 appendix B example verifier.
+	testVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+func TestAuthorizeRequestValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(req *models.AuthorizeRequest)
+		// wantCode is the OAuth error, "" when the request is valid.
+		wantCode string
+		// wantClient is false when the error must not be redirected to the
+		// client.
+		wantClient bool
+	}{
+		{name: "valid", modify: func(req *models.AuthorizeRequest) {}, wantClient: true},
+		{name: "unknown client", modify: func(req *models.AuthorizeRequest) { req.ClientID = "other-app" }, wantCode: "invalid_client"},
+		{name: "unregistered redirect_uri", modify: func(req *models.AuthorizeRequest) { req.RedirectURI = "https://evil.test/callback" }, wantCode: "invalid_request"},
+		{name: "redirect_uri with an extra path", modify: func(req *models.AuthorizeRequest) { req.RedirectURI = testRedirectURI + "/evil" }, wantCode: "invalid_request"},
+		{name: "redirect_uri with a query", modify: func(req *models.AuthorizeRequest) { req.RedirectURI = testRedirectURI + "?next=/evil" }, wantCode: "invalid_request"},
+		{name: "no code_challenge", modify: func(req *models.AuthorizeRequest) { req.CodeChallenge = "" }, wantCode: "invalid_request", wantClient: true},
+		{name: "plain code_challenge_method", modify: func(req *models.AuthorizeRequest) { req.CodeChallengeMethod = "plain" }, wantCode: "invalid_request", wantClient: true},
+		{name: "no openid scope", modify: func(req *models.AuthorizeRequest) { req.Scope = "profile" }, wantCode: "invalid_scope", wantClient: true},
+		{name: "scope not allowed", modify: func(req *models.AuthorizeRequest) { req.Scope = "openid admin" }, wantCode: "invalid_scope", wantClient: true},
+		{name: "token response type", modify: func(req *models.AuthorizeRequest) { req.ResponseType = "token" }, wantCode: "unsupported_response_type", wantClient: true},
+	}
+
+	svc, _ := newOIDCFixture()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := validAuthorizeRequest()
+			tc.modify(req)
+
+			client, _, err := svc.ValidateAuthorizeRequest(context.Background(), req)
+			if got := oauthErrorCode(t, err); got != tc.wantCode {
+				t.Fatalf("got error %q, want %q", got, tc.wantCode)
+			}
+			if (client != nil) != tc.wantClient {
+				t.Fatalf("got client %v, want one: %v", client, tc.wantClient)
+			}
+		})
+	}
+}
+
+func TestAuthorizationCodeExchange(t *testing.T) {
+	tests := []struct {
+		name     string
+		creds    services.ClientCredentials
+		modify   func(req *models.TokenRequest)
+		wantCode string
+	}{
+		{name: "valid", creds: services.ClientCredentials{ClientID: testClientID}, modify: func(req *models.TokenRequest) {}},
+		{name: "wrong code_verifier", creds: services.ClientCredentials{ClientID: testClientID}, modify: func(req *models.TokenRequest) { req.CodeVerifier = strings.Repeat("a", 43) }, wantCode: "invalid_grant"},
+		{name: "no code_verifier", creds: services.ClientCredentials{ClientID: testClientID}, modify: func(req *models.TokenRequest) { req.CodeVerifier = "" }, wantCode: "invalid_grant"},
+		{name: "challenge sent as verifier", creds: services.ClientCredentials{ClientID: testClientID}, modify: func(req *models.TokenRequest) { req.CodeVerifier = pkceChallenge(testVerifier) }, wantCode: "invalid_grant"},
+		{name: "other redirect_uri", creds: services.ClientCredentials{ClientID: testClientID}, modify: func(req *models.TokenRequest) { req.RedirectURI = "https://shop.tokohobby.test/other" }, wantCode: "invalid_grant"},
+		{name: "other client", creds: services.ClientCredentials{ClientID: "partner-app"}, modify: func(req *models.TokenRequest) {}, wantCode: "invalid_grant"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, codes := newOIDCFixture()
+			code := authorizationCode(t, svc)
+
+			req := &models.TokenRequest{
+				GrantType:    "authorization_code",
+				Code:         code,
+				RedirectURI:  testRedirectURI,
+				CodeVerifier: testVerifier,
+			}
+			tc.modify(req)
+
+			res, err := svc.Exchange(ctx, tc.creds, req, nil)
+			if got := oauthErrorCode(t, err); got != tc.wantCode {
+				t.Fatalf("got error %q, want %q", got, tc.wantCode)
+			}
+			if tc.wantCode == "" && (res.AccessToken == "" || res.IDToken == "") {
+				t.Fatalf("incomplete token response: %+v", res)
+			}
+
+			// Whatever the outcome, the code cannot be redeemed again.
+			if _, ok := codes.codes[code]; ok {
+				t.Fatal("authorization code is still stored")
+			}
+			req.Code = code
+			req.CodeVerifier = testVerifier
+			req.RedirectURI = testRedirectURI
+			if _, err := svc.Exchange(ctx, services.ClientCredentials{ClientID: testClientID}, req, nil); oauthErrorCode(t, err) != "invalid_grant" {
+				t.Fatalf("redeemed code again: got %v, want invalid_grant", err)
+			}
+		})
+	}
+}
+
+func validAuthorizeRequest() *models.AuthorizeRequest {
+	return &models.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            testClientID,
+		RedirectURI:         testRedirectURI,
+		Scope:               "openid profile",
+		State:               "xyz",
+		CodeChallenge:       pkceChallenge(testVerifier),
+		CodeChallengeMethod: "S256",
+	}
+}
+
+// authorizationCode approves a valid request and returns the code from the
+// redirect.
+func authorizationCode(t *testing.T, svc services.OIDCService) string {
+	t.Helper()
+
+	redirect, err := svc.DecideConsent(context.Background(), uuid.New(), &models.ConsentDecisionRequest{
+		AuthorizeRequest: *validAuthorizeRequest(),
+		Approve:          true,
+	})
+	if err != nil {
+		t.Fatalf("DecideConsent: %v", err)
+	}
+	u, err := url.Parse(redirect)
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+	if !strings.HasPrefix(redirect, testRedirectURI+"?") || u.Query().Get("state") != "xyz" {
+		t.Fatalf("unexpected redirect %q", redirect)
+	}
+	return u.Query().Get("code")
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// oauthErrorCode returns the OAuth error code of err, "" for nil.
+func oauthErrorCode(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	var oauthErr *apperrors.OAuthError
+	if !errors.As(err, &oauthErr) {
+		t.Fatalf("got %v, want an OAuth error", err)
+	}
+	return oauthErr.Code
+}
+
+func newOIDCFixture() (services.OIDCService, *fakeAuthorizationCodeRepo) {
+	clients := &fakeOAuthClientRepo{clients: map[string]*db.OauthClient{
+		testClientID: {
+			ID:            testClientID,
+			Name:          "Shop",
+			RedirectUris:  []string{testRedirectURI},
+			AllowedScopes: []string{"openid", "profile", "email"},
+			IsFirstParty:  true,
+		},
+		"partner-app": {
+			ID:            "partner-app",
+			Name:          "Partner",
+			RedirectUris:  []string{testRedirectURI},
+			AllowedScopes: []string{"openid"},
+		},
+	}}
+	codes := &fakeAuthorizationCodeRepo{codes: map[string]*repositories.AuthorizationCode{}}
+
+	svc := services.NewOIDCService(
+		clients,
+		codes,
+		fakeOIDCUserService{},
+		fakeOIDCSessionService{},
+		fakeIDTokenService{},
+		validator.New(),
+		services.OIDCConfig{IssuerURL: "https://accounts.tokohobby.test", AuthCodeTTL: time.Minute, IDTokenTTL: time.Hour},
+		logrus.New(),
+	)
+	return svc, codes
+}
+
+type fakeOAuthClientRepo struct {
+	repositories.OAuthClientRepository
+	clients map[string]*db.OauthClient
+}
+
+func (r *fakeOAuthClientRepo) GetClient(ctx context.Context, clientID string) (*db.OauthClient, error) {
+	client, ok := r.clients[clientID]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return client, nil
+}
+
+type fakeAuthorizationCodeRepo struct {
+	codes map[string]*repositories.AuthorizationCode
+}
+
+func (r *fakeAuthorizationCodeRepo) SaveCode(ctx context.Context, code string, data *repositories.AuthorizationCode, ttl time.Duration) error {
+	r.codes[code] = data
+	return nil
+}
+
+func (r *fakeAuthorizationCodeRepo) ConsumeCode(ctx context.Context, code string) (*repositories.AuthorizationCode, error) {
+	data, ok := r.codes[code]
+	if !ok {
+		return nil, apperrors.ErrInvalidToken
+	}
+	delete(r.codes, code)
+	return data, nil
+}
+
+type fakeOIDCUserService struct {
+	services.UserService
+}
+
+func (fakeOIDCUserService) GetUserByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	return &entities.User{ID: id, Username: "buyer", Status: entities.UserStatusActive}, nil
+}
+
+type fakeOIDCSessionService struct {
+	services.SessionService
+}
+
+func (fakeOIDCSessionService) CreateSession(ctx context.Context, user *entities.User, metadata *services.ActivityMetadata, opts services.SessionOptions) (*services.TokenPair, error) {
+	return &services.TokenPair{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		SessionID:    uuid.NewString(),
+		ExpiresAt:    time.Now().Add(15 * time.Minute),
+		Scopes:       opts.Scopes,
+	}, nil
+}
+
+type fakeIDTokenService struct {
+	token.TokenService
+}
+
+func (fakeIDTokenService) GenerateIDToken(ctx context.Context, user *entities.User, opts token.IDTokenOptions) (string, error) {
+	return "id-token", nil
+}
@@ -0,0 +1,190 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/phone"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+func TestPhoneNormalize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "081234567890", want: "+6281234567890"},
+		{in: "0812-3456-7890", want: "+6281234567890"},
+		{in: "(021) 555 1234", want: "+62215551234"},
+		{in: "6281234567890", want: "+6281234567890"},
+		{in: "81234567890", want: "+6281234567890"},
+		{in: "+62 812 3456 7890", want: "+6281234567890"},
+		{in: "+62 0812 3456 7890", want: "+6281234567890"},
+		{in: "0062 812 3456 7890", want: "+6281234567890"},
+		{in: "+65 6123 4567", want: "+6561234567"},
+		{in: "+1 (415) 555-0100", want: "+14155550100"},
+		{in: "0812", wantErr: true},
+		{in: "+62 812 3456 7890 1234", wantErr: true},
+		{in: "0812-3456-789O", wantErr: true},
+		{in: "+0812345678", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := phone.Normalize(tc.in, "62")
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("got %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPhoneLoginLocksAfterRepeatedFailures(t *testing.T) {
+	const number = "+6281234567890"
+	ctx := context.Background()
+
+	user := &db.GetUserByPhoneNumberRow{ID: uuid.New(), Username: "buyer", PhoneNumber: number, Status: "active"}
+	sender := &fakeSMSSender{sent: make(chan string, 10)}
+	svc := services.NewPhoneService(
+		&fakePhoneUserRepo{users: map[string]*db.GetUserByPhoneNumberRow{number: user}},
+		&fakePhoneOTPRepo{codes: map[string]*fakePhoneOTP{}, failures: map[string]int64{}},
+		allowAllThrottle{},
+		sender,
+		services.PhonePolicy{DefaultCountryCode: "62"},
+		services.PhoneConfig{OTPLength: 6, OTPTTL: 5 * time.Minute, MaxAttempts: 5, MaxFailures: 3, FailureWindow: time.Hour},
+		logrus.New(),
+	)
+
+	sendCode := func() string {
+		t.Helper()
+		if _, err := svc.SendLoginCode(ctx, number); err != nil {
+			t.Fatalf("SendLoginCode: %v", err)
+		}
+		select {
+		case message := <-sender.sent:
+			return regexp.MustCompile(`\d{6}`).FindString(message)
+		case <-time.After(time.Second):
+			t.Fatal("no code was sent")
+			return ""
+		}
+	}
+	wrong := func(code string) string {
+		if code == "000000" {
+			return "111111"
+		}
+		return "000000"
+	}
+
+	code := sendCode()
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Login(ctx, number, wrong(code)); !errors.Is(err, apperrors.ErrInvalidOTP) {
+			t.Fatalf("wrong code %d: got %v, want ErrInvalidOTP", i+1, err)
+		}
+	}
+
+	// A new code keeps the failures of the old one.
+	code = sendCode()
+	if _, err := svc.Login(ctx, number, wrong(code)); !errors.Is(err, apperrors.ErrInvalidOTP) {
+		t.Fatalf("third wrong code: got %v, want ErrInvalidOTP", err)
+	}
+
+	code = sendCode()
+	if _, err := svc.Login(ctx, number, code); !errors.Is(err, apperrors.ErrTooManyRequests) {
+		t.Fatalf("right code on a locked number: got %v, want ErrTooManyRequests", err)
+	}
+}
+
+type fakePhoneUserRepo struct {
+	repositories.UserRepository
+	users map[string]*db.GetUserByPhoneNumberRow
+}
+
+func (r *fakePhoneUserRepo) GetUserByPhoneNumber(ctx context.Context, phoneNumber string) (*db.GetUserByPhoneNumberRow, error) {
+	user, ok := r.users[phoneNumber]
+	if !ok {
+		return nil, apperrors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+type fakePhoneOTP struct {
+	record   repositories.PhoneOTPRecord
+	attempts int64
+}
+
+type fakePhoneOTPRepo struct {
+	codes    map[string]*fakePhoneOTP
+	failures map[string]int64
+}
+
+func (r *fakePhoneOTPRepo) CreateCode(ctx context.Context, key string, phoneNumber string, codeHash string, ttl time.Duration) error {
+	r.codes[key] = &fakePhoneOTP{record: repositories.PhoneOTPRecord{PhoneNumber: phoneNumber, CodeHash: codeHash}}
+	return nil
+}
+
+func (r *fakePhoneOTPRepo) GetCode(ctx context.Context, key string) (*repositories.PhoneOTPRecord, error) {
+	otp, ok := r.codes[key]
+	if !ok {
+		return nil, apperrors.ErrInvalidToken
+	}
+	record := otp.record
+	return &record, nil
+}
+
+func (r *fakePhoneOTPRepo) IncrementAttempts(ctx context.Context, key string) (int64, error) {
+	otp, ok := r.codes[key]
+	if !ok {
+		return 0, apperrors.ErrInvalidToken
+	}
+	otp.attempts++
+	return otp.attempts, nil
+}
+
+func (r *fakePhoneOTPRepo) DeleteCode(ctx context.Context, key string) error {
+	delete(r.codes, key)
+	return nil
+}
+
+func (r *fakePhoneOTPRepo) CountFailure(ctx context.Context, phoneNumber string, window time.Duration) (int64, error) {
+	r.failures[phoneNumber]++
+	return r.failures[phoneNumber], nil
+}
+
+func (r *fakePhoneOTPRepo) Failures(ctx context.Context, phoneNumber string) (int64, error) {
+	return r.failures[phoneNumber], nil
+}
+
+type allowAllThrottle struct{}
+
+func (allowAllThrottle) Allow(ctx context.Context, key string, cooldown time.Duration) (bool, error) {
+	return true, nil
+}
+
+type fakeSMSSender struct {
+	sent chan string
+}
+
+func (s *fakeSMSSender) Send(ctx context.Context, to string, message string) error {
+	s.sent <- message
+	return nil
+}
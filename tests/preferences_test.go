@@ -0,0 +1,123 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+func preferencesPolicy() services.PreferencesPolicy {
+	return services.PreferencesPolicy{
+		Locales:         []string{"id-ID", "en-US"},
+		Currencies:      []string{"IDR", "USD"},
+		DefaultLocale:   "id-ID",
+		DefaultTimezone: "Asia/Jakarta",
+		DefaultCurrency: "IDR",
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestPreferencesNormalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        models.PreferencesPatchRequest
+		want       models.PreferencesPatchRequest
+		wantFields []string
+	}{
+		{
+			name: "canonical spelling",
+			req:  models.PreferencesPatchRequest{Locale: strPtr("en_us"), Timezone: strPtr(" Asia/Makassar "), Currency: strPtr("usd")},
+			want: models.PreferencesPatchRequest{Locale: strPtr("en-US"), Timezone: strPtr("Asia/Makassar"), Currency: strPtr("USD")},
+		},
+		{
+			name: "absent fields stay absent",
+			req:  models.PreferencesPatchRequest{Currency: strPtr("IDR")},
+			want: models.PreferencesPatchRequest{Currency: strPtr("IDR")},
+		},
+		{
+			name:       "not allowed",
+			req:        models.PreferencesPatchRequest{Locale: strPtr("fr-FR"), Timezone: strPtr("Mars/Olympus"), Currency: strPtr("EUR")},
+			wantFields: []string{"locale", "timezone", "currency"},
+		},
+		{
+			name:       "server zone",
+			req:        models.PreferencesPatchRequest{Timezone: strPtr("Local")},
+			wantFields: []string{"timezone"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := preferencesPolicy().Normalize(&tc.req)
+			if len(errs) != len(tc.wantFields) {
+				t.Fatalf("got errors %v, want fields %v", errs, tc.wantFields)
+			}
+			for i, field := range tc.wantFields {
+				if errs[i].Field != field {
+					t.Errorf("error %d on %q, want %q", i, errs[i].Field, field)
+				}
+			}
+			if len(tc.wantFields) > 0 {
+				return
+			}
+			for _, pair := range [][2]*string{
+				{tc.req.Locale, tc.want.Locale},
+				{tc.req.Timezone, tc.want.Timezone},
+				{tc.req.Currency, tc.want.Currency},
+			} {
+				got, want := pair[0], pair[1]
+				if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+					t.Errorf("got %v, want %v", deref(got), deref(want))
+				}
+			}
+		})
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
+
+func TestPreferencesPolicyValidate(t *testing.T) {
+	if err := preferencesPolicy().Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	policy := preferencesPolicy()
+	policy.DefaultCurrency = "EUR"
+	if err := policy.Validate(); err == nil {
+		t.Error("default currency outside the list was accepted")
+	}
+
+	policy = preferencesPolicy()
+	policy.DefaultTimezone = "WIB"
+	if err := policy.Validate(); err == nil {
+		t.Error("non-IANA default timezone was accepted")
+	}
+}
+
+func TestPreferencesAllowsEmail(t *testing.T) {
+	preferences := entities.UserPreferences{EmailPromotions: true}
+
+	tests := []struct {
+		category string
+		want     bool
+	}{
+		{category: entities.EmailCategoryPromotions, want: true},
+		{category: entities.EmailCategoryNewsletter, want: false},
+		{category: entities.EmailCategoryRecommendations, want: false},
+		{category: "transactional", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := preferences.AllowsEmail(tc.category); got != tc.want {
+			t.Errorf("AllowsEmail(%q) = %v, want %v", tc.category, got, tc.want)
+		}
+	}
+}
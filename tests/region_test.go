@@ -0,0 +1,175 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/region"
+)
+
+func loadRegions(t *testing.T) *region.Dataset {
+	t.Helper()
+
+	d, err := region.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return d
+}
+
+func TestRegionEmbeddedDataset(t *testing.T) {
+	d := loadRegions(t)
+
+	if d.Version == "" {
+		t.Error("dataset has no version")
+	}
+	if got := len(d.Children("")); got != 38 {
+		t.Errorf("got %d provinces, want 38", got)
+	}
+
+	path := d.Path("31.74.01.1003")
+	if len(path) != 4 {
+		t.Fatalf("got path of %d regions, want 4", len(path))
+	}
+	if path[0].Name != "Kebon Baru" || path[0].PostalCode != "12830" || path[3].Name != "DKI Jakarta" {
+		t.Errorf("unexpected path %+v", path)
+	}
+}
+
+func TestRegionParseRejectsOrphans(t *testing.T) {
+	_, err := region.Parse("test", []byte("31,DKI Jakarta\n32.01,Kab. Bogor\n"), nil)
+	if err == nil {
+		t.Fatal("expected an error for a regency without its province")
+	}
+}
+
+func TestRegionResolve(t *testing.T) {
+	d := loadRegions(t)
+
+	tests := []struct {
+		name      string
+		in        region.Address
+		want      region.Address
+		wantField string
+	}{
+		{
+			name: "names are normalized and coded",
+			in:   region.Address{Province: "dki jakarta", City: "Jakarta Selatan", District: "TEBET", Village: "kel. kebon baru"},
+			want: region.Address{
+				ProvinceCode: "31", Province: "DKI Jakarta",
+				CityCode: "31.74", City: "Kota Adm. Jakarta Selatan",
+				DistrictCode: "31.74.01", District: "Tebet",
+				VillageCode: "31.74.01.1003", Village: "Kebon Baru",
+				PostalCode: "12830",
+			},
+		},
+		{
+			name: "codes win over names",
+			in:   region.Address{ProvinceCode: "31", CityCode: "31.74", DistrictCode: "31.74.02", District: "Tebet", PostalCode: "12940"},
+			want: region.Address{
+				ProvinceCode: "31", Province: "DKI Jakarta",
+				CityCode: "31.74", City: "Kota Adm. Jakarta Selatan",
+				DistrictCode: "31.74.02", District: "Setiabudi",
+				PostalCode: "12940",
+			},
+		},
+		{
+			name: "levels the dataset does not cover are kept as given",
+			in:   region.Address{Province: "Jawa Barat", City: "Kota Bandung", District: "Coblong", PostalCode: "40132"},
+			want: region.Address{
+				ProvinceCode: "32", Province: "Jawa Barat",
+				City: "Kota Bandung", District: "Coblong", PostalCode: "40132",
+			},
+		},
+		{
+			name:      "unknown province",
+			in:        region.Address{Province: "Jawa Barrat", City: "Bandung"},
+			wantField: "province",
+		},
+		{
+			name:      "city in another province",
+			in:        region.Address{Province: "Bali", CityCode: "31.74"},
+			wantField: "city",
+		},
+		{
+			name:      "postal code outside the district",
+			in:        region.Address{Province: "DKI Jakarta", City: "Jakarta Selatan", District: "Tebet", PostalCode: "12910"},
+			wantField: "postal_code",
+		},
+		{
+			name:      "postal code of another village",
+			in:        region.Address{Province: "DKI Jakarta", City: "Jakarta Selatan", District: "Tebet", Village: "Manggarai", PostalCode: "12810"},
+			wantField: "postal_code",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := d.Resolve(tc.in)
+
+			if tc.wantField != "" {
+				var fieldErr *region.FieldError
+				if !errors.As(err, &fieldErr) {
+					t.Fatalf("got %v, want a field error", err)
+				}
+				if fieldErr.Field != tc.wantField {
+					t.Fatalf("got error on %s (%v), want %s", fieldErr.Field, err, tc.wantField)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got  %+v\nwant %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRegionResolveCompleteDataset(t *testing.T) {
+	wilayah := []byte("31,DKI Jakarta\n31.74,Kota Adm. Jakarta Selatan\n31.74.01,Tebet\n31.74.01.1003,Kebon Baru\n32,Jawa Barat\n32.73,Kota Bandung\n32.73.02,Coblong\n32.73.02.1001,Dago\n")
+	d, err := region.Parse("test", wilayah, nil)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if !d.Complete() {
+		t.Fatal("dataset listing every level is not complete")
+	}
+
+	// Every level is listed, so a district missing from it is wrong rather
+	// than uncovered.
+	_, err = d.Resolve(region.Address{Province: "Jawa Barat", City: "Bandung", District: "Cibeunying"})
+	var fieldErr *region.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "district" {
+		t.Fatalf("got %v, want an error on district", err)
+	}
+
+	got, err := d.Resolve(region.Address{Province: "Jawa Barat", City: "Bandung", District: "Coblong"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got.DistrictCode != "32.73.02" {
+		t.Errorf("got district code %q, want 32.73.02", got.DistrictCode)
+	}
+}
+
+func TestRegionSearch(t *testing.T) {
+	d := loadRegions(t)
+
+	res := d.Search("jakarta sel", region.LevelRegency, "", 10)
+	if len(res) != 1 || res[0].Code != "31.74" {
+		t.Fatalf("got %+v, want Jakarta Selatan", res)
+	}
+
+	res = d.Search("manggarai", 0, "31.74.01", 10)
+	if len(res) != 2 || res[0].Name != "Manggarai" {
+		t.Fatalf("got %+v, want Manggarai then Manggarai Selatan", res)
+	}
+
+	if res := d.Search("a", 0, "", 3); len(res) != 3 {
+		t.Fatalf("got %d results, want the limit of 3", len(res))
+	}
+}
@@ -0,0 +1,267 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/models"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
+)
+
+func TestGrantRoleRouting(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        string
+		held        []string
+		wantErr     error
+		wantPending bool
+		wantGranted bool
+	}{
+		{name: "unprivileged role is granted right away", role: "seller", wantGranted: true},
+		{name: "staff role waits for approval", role: "support", wantPending: true},
+		{name: "admin waits for approval", role: "admin", wantPending: true},
+		{name: "held role is refused", role: "support", held: []string{"support"}, wantErr: apperrors.ErrRoleAlreadyGranted},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newRoleGrantFixture()
+			f.grants.roles[f.userID] = tc.held
+
+			request, err := f.svc.GrantRole(context.Background(), f.userID, f.adminID, &models.RoleGrantRequest{Role: tc.role, Reason: "ticket 42"})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got %v, want %v", err, tc.wantErr)
+			}
+
+			if (request != nil) != tc.wantPending {
+				t.Fatalf("got request %+v, want one: %v", request, tc.wantPending)
+			}
+			if tc.wantPending && (request.Status != entities.RoleGrantPending || request.RequestedBy == nil || *request.RequestedBy != f.adminID) {
+				t.Fatalf("unexpected request %+v", request)
+			}
+			granted := slices.Contains(f.grants.roles[f.userID], tc.role) && !slices.Contains(tc.held, tc.role)
+			if granted != tc.wantGranted {
+				t.Fatalf("role granted = %v, want %v", granted, tc.wantGranted)
+			}
+			// Only a grant that took effect ends the user's current tokens.
+			if revoked := slices.Contains(f.tokens.revoked, f.userID); revoked != tc.wantGranted {
+				t.Fatalf("tokens revoked = %v, want %v", revoked, tc.wantGranted)
+			}
+		})
+	}
+}
+
+func TestApproveRoleGrantRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		// approver picks who approves, given the requesting admin and the
+		// user receiving the role.
+		approver func(requester, user uuid.UUID) uuid.UUID
+		decided  bool
+		wantErr  error
+	}{
+		{
+			name:     "second admin approves",
+			approver: func(requester, user uuid.UUID) uuid.UUID { return uuid.New() },
+		},
+		{
+			name:     "requester cannot approve",
+			approver: func(requester, user uuid.UUID) uuid.UUID { return requester },
+			wantErr:  apperrors.ErrSelfApproval,
+		},
+		{
+			name:     "receiving user cannot approve",
+			approver: func(requester, user uuid.UUID) uuid.UUID { return user },
+			wantErr:  apperrors.ErrSelfApproval,
+		},
+		{
+			name:     "decided request cannot be approved",
+			approver: func(requester, user uuid.UUID) uuid.UUID { return uuid.New() },
+			decided:  true,
+			wantErr:  apperrors.ErrRoleGrantDecided,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newRoleGrantFixture()
+
+			request, err := f.svc.GrantRole(ctx, f.userID, f.adminID, &models.RoleGrantRequest{Role: "support", Reason: "joins the support team"})
+			if err != nil || request == nil {
+				t.Fatalf("GrantRole: got %+v, %v, want a pending request", request, err)
+			}
+			if tc.decided {
+				f.grants.requests[request.ID].Status = entities.RoleGrantRejected
+			}
+
+			approved, err := f.svc.ApproveRequest(ctx, request.ID, tc.approver(f.adminID, f.userID), "")
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got %v, want %v", err, tc.wantErr)
+			}
+
+			hasRole := slices.Contains(f.grants.roles[f.userID], "support")
+			if hasRole != (tc.wantErr == nil) {
+				t.Fatalf("user has the role = %v, want %v", hasRole, tc.wantErr == nil)
+			}
+			if tc.wantErr == nil && approved.Status != entities.RoleGrantApproved {
+				t.Fatalf("request status %q, want approved", approved.Status)
+			}
+		})
+	}
+}
+
+type roleGrantFixture struct {
+	svc     services.RoleAssignmentService
+	userID  uuid.UUID
+	adminID uuid.UUID
+	grants  *fakeRoleGrantRepo
+	tokens  *fakeTokenEpochs
+}
+
+func newRoleGrantFixture() *roleGrantFixture {
+	f := &roleGrantFixture{
+		userID:  uuid.New(),
+		adminID: uuid.New(),
+		grants:  &fakeRoleGrantRepo{roles: map[uuid.UUID][]string{}, requests: map[uuid.UUID]*db.RoleGrantRequest{}},
+		tokens:  &fakeTokenEpochs{},
+	}
+	roles := &fakeRoleRepo{
+		grants: f.grants,
+		roles: map[string]*db.GetRoleRow{
+			"seller":  {Name: "seller", Permissions: []string{"products:write"}},
+			"support": {Name: "support", IsPrivileged: true, Permissions: []string{"users:read", "sessions:manage"}},
+			"admin":   {Name: "admin", IsSystem: true, IsPrivileged: true, Permissions: entities.StaffPermissions},
+		},
+	}
+	users := &fakeUserRepo{users: map[uuid.UUID]*db.GetUserByIDRow{
+		f.userID:  {ID: f.userID, Username: "buyer", Status: "active"},
+		f.adminID: {ID: f.adminID, Username: "admin", Status: "active"},
+	}}
+
+	f.svc = services.NewRoleAssignmentService(f.grants, roles, users, f.tokens, logrus.New())
+	return f
+}
+
+type fakeRoleRepo struct {
+	repositories.RoleRepository
+	roles  map[string]*db.GetRoleRow
+	grants *fakeRoleGrantRepo
+}
+
+func (r *fakeRoleRepo) GetRole(ctx context.Context, name string) (*db.GetRoleRow, error) {
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return role, nil
+}
+
+func (r *fakeRoleRepo) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return r.grants.roles[userID], nil
+}
+
+type fakeRoleGrantRepo struct {
+	roles    map[uuid.UUID][]string
+	requests map[uuid.UUID]*db.RoleGrantRequest
+}
+
+func (r *fakeRoleGrantRepo) GrantRole(ctx context.Context, param *db.GrantUserRoleParams) (*db.UserRole, error) {
+	if slices.Contains(r.roles[param.UserID], param.Role) {
+		return nil, apperrors.ErrRoleAlreadyGranted
+	}
+	r.roles[param.UserID] = append(r.roles[param.UserID], param.Role)
+	return &db.UserRole{UserID: param.UserID, Role: param.Role, GrantedBy: param.GrantedBy, CreatedAt: time.Now()}, nil
+}
+
+func (r *fakeRoleGrantRepo) RevokeRole(ctx context.Context, param *db.RevokeUserRoleParams) (bool, error) {
+	roles := r.roles[param.UserID]
+	i := slices.Index(roles, param.Role)
+	if i < 0 {
+		return false, nil
+	}
+	r.roles[param.UserID] = slices.Delete(roles, i, i+1)
+	return true, nil
+}
+
+func (r *fakeRoleGrantRepo) CreateRequest(ctx context.Context, param *db.CreateRoleGrantRequestParams) (*db.RoleGrantRequest, error) {
+	for _, request := range r.requests {
+		if request.UserID == param.UserID && request.Role == param.Role && request.Status == entities.RoleGrantPending {
+			return nil, apperrors.ErrRoleGrantPending
+		}
+	}
+	request := &db.RoleGrantRequest{
+		ID:          uuid.New(),
+		UserID:      param.UserID,
+		Role:        param.Role,
+		RequestedBy: param.RequestedBy,
+		Reason:      param.Reason,
+		Status:      entities.RoleGrantPending,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	r.requests[request.ID] = request
+	return request, nil
+}
+
+func (r *fakeRoleGrantRepo) GetRequest(ctx context.Context, id uuid.UUID) (*db.RoleGrantRequest, error) {
+	request, ok := r.requests[id]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return request, nil
+}
+
+func (r *fakeRoleGrantRepo) ListRequests(ctx context.Context, param *db.ListRoleGrantRequestsParams) ([]db.RoleGrantRequest, error) {
+	var requests []db.RoleGrantRequest
+	for _, request := range r.requests {
+		if !param.Status.Valid || request.Status == param.Status.String {
+			requests = append(requests, *request)
+		}
+	}
+	return requests, nil
+}
+
+func (r *fakeRoleGrantRepo) ApproveRequest(ctx context.Context, param *db.ApproveRoleGrantRequestParams) (*db.RoleGrantRequest, error) {
+	request, ok := r.requests[param.ID]
+	if !ok || request.Status != entities.RoleGrantPending {
+		return nil, apperrors.ErrRoleGrantDecided
+	}
+	request.Status = entities.RoleGrantApproved
+	request.DecidedBy = param.DecidedBy
+	request.DecisionNotes = param.DecisionNotes
+	r.roles[request.UserID] = append(r.roles[request.UserID], request.Role)
+	return request, nil
+}
+
+func (r *fakeRoleGrantRepo) RejectRequest(ctx context.Context, param *db.RejectRoleGrantRequestParams) (*db.RoleGrantRequest, error) {
+	request, ok := r.requests[param.ID]
+	if !ok || request.Status != entities.RoleGrantPending {
+		return nil, apperrors.ErrRoleGrantDecided
+	}
+	request.Status = entities.RoleGrantRejected
+	request.DecidedBy = param.DecidedBy
+	request.DecisionNotes = param.DecisionNotes
+	return request, nil
+}
+
+type fakeTokenEpochs struct {
+	token.TokenService
+	revoked []uuid.UUID
+}
+
+func (s *fakeTokenEpochs) RevokeUserTokens(ctx context.Context, userID uuid.UUID) error {
+	s.revoked = append(s.revoked, userID)
+	return nil
+}
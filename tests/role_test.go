@@ -0,0 +1,71 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
+)
+
+func TestValidRoleName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "admin", want: true},
+		{name: "support_lead", want: true},
+		{name: "tier2", want: true},
+		{name: "a", want: false},
+		{name: "Admin", want: false},
+		{name: "2nd_line", want: false},
+		{name: "support-lead", want: false},
+		{name: "", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := entities.ValidRoleName(tc.name); got != tc.want {
+			t.Errorf("ValidRoleName(%q) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestValidPermissionName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "users:read", want: true},
+		{name: "seller_applications:review", want: true},
+		{name: "users", want: false},
+		{name: "users:", want: false},
+		{name: ":read", want: false},
+		{name: "users:read:all", want: false},
+		{name: "Users:Read", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := entities.ValidPermissionName(tc.name); got != tc.want {
+			t.Errorf("ValidPermissionName(%q) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestHasPermissions(t *testing.T) {
+	granted := []string{"sessions:manage", "users:read"}
+
+	if !token.HasPermissions(granted, "users:read") {
+		t.Error("users:read should be granted")
+	}
+	if !token.HasPermissions(granted, "users:read", "sessions:manage") {
+		t.Error("users:read and sessions:manage should be granted")
+	}
+	if token.HasPermissions(granted, "users:read", "users:write") {
+		t.Error("users:write is missing and should deny")
+	}
+	if token.HasPermissions(nil, "users:read") {
+		t.Error("a token without permissions should be denied")
+	}
+	if !token.HasPermissions(nil) {
+		t.Error("requiring nothing should always pass")
+	}
+}
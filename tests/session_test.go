@@ -0,0 +1,264 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services/token"
+)
+
+func TestRefreshSessionDetectsReuse(t *testing.T) {
+	tests := []struct {
+		name string
+		// present picks the token sent after one rotation.
+		present     func(rotated, current string) string
+		wantErr     error
+		wantRevoked bool
+	}{
+		{
+			name:    "current token rotates",
+			present: func(rotated, current string) string { return current },
+		},
+		{
+			name:        "rotated token revokes the family",
+			present:     func(rotated, current string) string { return rotated },
+			wantErr:     apperrors.ErrRefreshTokenReused,
+			wantRevoked: true,
+		},
+		{
+			name:    "unknown token is rejected",
+			present: func(rotated, current string) string { return "unknown" },
+			wantErr: apperrors.ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newSessionFixture()
+
+			pair, err := f.svc.CreateSession(ctx, f.user, nil, services.SessionOptions{})
+			if err != nil {
+				t.Fatalf("CreateSession: %v", err)
+			}
+			rotated, err := f.svc.RefreshSession(ctx, pair.RefreshToken, "", nil)
+			if err != nil {
+				t.Fatalf("first RefreshSession: %v", err)
+			}
+
+			_, err = f.svc.RefreshSession(ctx, tc.present(pair.RefreshToken, rotated.RefreshToken), "", nil)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got %v, want %v", err, tc.wantErr)
+			}
+
+			_, sessionLeft := f.sessions.sessions[pair.SessionID]
+			if sessionLeft == tc.wantRevoked {
+				t.Fatalf("session kept = %v, want %v", sessionLeft, !tc.wantRevoked)
+			}
+			if !tc.wantRevoked {
+				return
+			}
+
+			// Neither the thief nor the user can go on with the family, and
+			// every access token issued to it is blacklisted.
+			if _, err := f.svc.RefreshSession(ctx, rotated.RefreshToken, "", nil); !errors.Is(err, apperrors.ErrInvalidToken) {
+				t.Fatalf("current token after reuse: got %v, want ErrInvalidToken", err)
+			}
+			for _, jti := range f.tokens.issued {
+				if !f.tokens.blacklisted[jti] {
+					t.Fatalf("access token %s is not blacklisted", jti)
+				}
+			}
+		})
+	}
+}
+
+type sessionFixture struct {
+	svc      services.SessionService
+	user     *entities.User
+	sessions *fakeSessionRepo
+	tokens   *fakeTokenService
+}
+
+func newSessionFixture() *sessionFixture {
+	user := &db.GetUserByIDRow{ID: uuid.New(), Username: "buyer", Role: "user", Status: "active"}
+	f := &sessionFixture{
+		user:     &entities.User{ID: user.ID, Username: user.Username, Role: user.Role, Status: entities.UserStatusActive},
+		sessions: &fakeSessionRepo{sessions: map[string]*repositories.SessionRecord{}, tokens: map[string][]repositories.TrackedAccessToken{}},
+		tokens:   &fakeTokenService{blacklisted: map[string]bool{}},
+	}
+	f.svc = services.NewSessionService(
+		&fakeUserRepo{users: map[uuid.UUID]*db.GetUserByIDRow{user.ID: user}},
+		&fakeRefreshTokenRepo{tokens: map[string]*fakeRefreshToken{}, families: map[string]*fakeRefreshFamily{}},
+		f.sessions,
+		fakeSignInRepo{},
+		f.tokens,
+		nil,
+		logrus.New(),
+	)
+	return f
+}
+
+// fakeRefreshTokenRepo follows the rotation script of the Redis repository:
+// rotated tokens are kept so that presenting one again revokes the family.
+type fakeRefreshToken struct {
+	userID   string
+	familyID string
+	active   bool
+}
+
+type fakeRefreshFamily struct {
+	current string
+	revoked bool
+}
+
+type fakeRefreshTokenRepo struct {
+	tokens   map[string]*fakeRefreshToken
+	families map[string]*fakeRefreshFamily
+}
+
+func (r *fakeRefreshTokenRepo) StoreRefreshToken(ctx context.Context, userID string, familyID string, refreshToken string, ttl time.Duration) error {
+	r.tokens[refreshToken] = &fakeRefreshToken{userID: userID, familyID: familyID, active: true}
+	r.families[familyID] = &fakeRefreshFamily{current: refreshToken}
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) GetRefreshToken(ctx context.Context, refreshToken string) (*repositories.RefreshTokenRecord, error) {
+	stored, ok := r.tokens[refreshToken]
+	if !ok {
+		return nil, apperrors.ErrInvalidToken
+	}
+	return &repositories.RefreshTokenRecord{UserID: stored.userID, FamilyID: stored.familyID}, nil
+}
+
+func (r *fakeRefreshTokenRepo) RotateRefreshToken(ctx context.Context, oldToken string, newToken string, ttl time.Duration) (*repositories.RefreshTokenRecord, error) {
+	stored, ok := r.tokens[oldToken]
+	if !ok {
+		return nil, apperrors.ErrInvalidToken
+	}
+	family := r.families[stored.familyID]
+	if family == nil || family.revoked {
+		return nil, apperrors.ErrInvalidToken
+	}
+
+	record := &repositories.RefreshTokenRecord{UserID: stored.userID, FamilyID: stored.familyID}
+	if !stored.active {
+		family.revoked = true
+		delete(r.tokens, family.current)
+		return record, apperrors.ErrRefreshTokenReused
+	}
+
+	stored.active = false
+	r.tokens[newToken] = &fakeRefreshToken{userID: stored.userID, familyID: stored.familyID, active: true}
+	family.current = newToken
+	return record, nil
+}
+
+func (r *fakeRefreshTokenRepo) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	stored, ok := r.tokens[refreshToken]
+	if !ok {
+		return nil
+	}
+	return r.RevokeFamily(ctx, stored.familyID)
+}
+
+func (r *fakeRefreshTokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	family, ok := r.families[familyID]
+	if !ok {
+		return nil
+	}
+	family.revoked = true
+	delete(r.tokens, family.current)
+	return nil
+}
+
+type fakeSessionRepo struct {
+	sessions map[string]*repositories.SessionRecord
+	tokens   map[string][]repositories.TrackedAccessToken
+}
+
+func (r *fakeSessionRepo) CreateSession(ctx context.Context, session *repositories.SessionRecord, ttl time.Duration) error {
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *fakeSessionRepo) GetSession(ctx context.Context, sessionID string) (*repositories.SessionRecord, error) {
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return nil, apperrors.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (r *fakeSessionRepo) TouchSession(ctx context.Context, sessionID string, ipAddress string, ttl time.Duration) error {
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		return apperrors.ErrSessionNotFound
+	}
+	session.LastUsedAt = time.Now()
+	return nil
+}
+
+func (r *fakeSessionRepo) ListSessions(ctx context.Context, userID string) ([]repositories.SessionRecord, error) {
+	var sessions []repositories.SessionRecord
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, *session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeSessionRepo) DeleteSession(ctx context.Context, sessionID string) error {
+	delete(r.sessions, sessionID)
+	delete(r.tokens, sessionID)
+	return nil
+}
+
+func (r *fakeSessionRepo) TrackAccessToken(ctx context.Context, sessionID string, jti string, expiresAt time.Time) error {
+	r.tokens[sessionID] = append(r.tokens[sessionID], repositories.TrackedAccessToken{JTI: jti, ExpiresAt: expiresAt})
+	return nil
+}
+
+func (r *fakeSessionRepo) ListAccessTokens(ctx context.Context, sessionID string) ([]repositories.TrackedAccessToken, error) {
+	return r.tokens[sessionID], nil
+}
+
+type fakeSignInRepo struct {
+	repositories.SignInEventRepository
+}
+
+func (fakeSignInRepo) Add(ctx context.Context, param *db.CreateSignInEventParams, keep int) error {
+	return nil
+}
+
+type fakeTokenService struct {
+	token.TokenService
+	issued      []string
+	blacklisted map[string]bool
+}
+
+func (s *fakeTokenService) GenerateAccessToken(ctx context.Context, user *entities.User, opts token.AccessTokenOptions) (*token.AccessToken, error) {
+	jti := uuid.NewString()
+	s.issued = append(s.issued, jti)
+	return &token.AccessToken{Token: "access-" + jti, ID: jti, ExpiresAt: time.Now().Add(15 * time.Minute)}, nil
+}
+
+func (s *fakeTokenService) GenerateRefreshToken(ctx context.Context) (string, error) {
+	return uuid.NewString(), nil
+}
+
+func (s *fakeTokenService) BlacklistToken(ctx context.Context, jti string, expiration time.Duration) error {
+	s.blacklisted[jti] = true
+	return nil
+}
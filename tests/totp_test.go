@@ -0,0 +1,107 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/totp"
+)
+
+// RFC 6238 Appendix B. The seed for each algorithm is the ASCII string
+// "1234567890" repeated up to the hash output size.
+var (
+	rfc6238SeedSHA1   = []byte("12345678901234567890")
+	rfc6238SeedSHA256 = []byte("12345678901234567890123456789012")
+	rfc6238SeedSHA512 = []byte("1234567890123456789012345678901234567890123456789012345678901234")
+)
+
+func TestTOTPRFC6238Vectors(t *testing.T) {
+	vectors := []struct {
+		unix   int64
+		sha1   string
+		sha256 string
+		sha512 string
+	}{
+		{59, "94287082", "46119246", "90693936"},
+		{1111111109, "07081804", "68084774", "25091201"},
+		{1111111111, "14050471", "67062674", "99943326"},
+		{1234567890, "89005924", "91819424", "93441116"},
+		{2000000000, "69279037", "90698825", "38618901"},
+		{20000000000, "65353130", "77737706", "47863826"},
+	}
+
+	for _, v := range vectors {
+		at := time.Unix(v.unix, 0).UTC()
+		cases := []struct {
+			alg  totp.Algorithm
+			seed []byte
+			want string
+		}{
+			{totp.AlgorithmSHA1, rfc6238SeedSHA1, v.sha1},
+			{totp.AlgorithmSHA256, rfc6238SeedSHA256, v.sha256},
+			{totp.AlgorithmSHA512, rfc6238SeedSHA512, v.sha512},
+		}
+
+		for _, tc := range cases {
+			opts := totp.Options{Period: 30 * time.Second, Digits: 8, Algorithm: tc.alg}
+
+			got, err := totp.GenerateCode(tc.seed, at, opts)
+			if err != nil {
+				t.Fatalf("%s at %d: %v", tc.alg, v.unix, err)
+			}
+			if got != tc.want {
+				t.Errorf("%s at %d: got %s, want %s", tc.alg, v.unix, got, tc.want)
+			}
+
+			if _, ok := totp.Validate(tc.seed, tc.want, at, opts); !ok {
+				t.Errorf("%s at %d: Validate rejected the RFC code", tc.alg, v.unix)
+			}
+		}
+	}
+}
+
+func TestTOTPValidateSkew(t *testing.T) {
+	opts := totp.DefaultOptions
+	now := time.Unix(1111111111, 0)
+
+	code, err := totp.GenerateCode(rfc6238SeedSHA1, now.Add(-30*time.Second), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step, ok := totp.Validate(rfc6238SeedSHA1, code, now, opts)
+	if !ok {
+		t.Fatal("code from the previous period should be accepted")
+	}
+	if want := totp.Step(now, opts) - 1; step != want {
+		t.Errorf("matched step = %d, want %d", step, want)
+	}
+
+	if _, ok := totp.Validate(rfc6238SeedSHA1, code, now.Add(60*time.Second), opts); ok {
+		t.Error("code three periods old should be rejected")
+	}
+	if _, ok := totp.Validate(rfc6238SeedSHA1, "12345", now, opts); ok {
+		t.Error("code with the wrong length should be rejected")
+	}
+}
+
+func TestTOTPSecretRoundTrip(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := totp.DecodeSecret(strings.ToLower(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != 20 {
+		t.Errorf("decoded key is %d bytes, want 20", len(key))
+	}
+
+	uri := totp.KeyURI("TokoHobby", "alice@example.com", secret, totp.DefaultOptions)
+	if !strings.HasPrefix(uri, "otpauth://totp/TokoHobby:alice@example.com?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("unexpected key URI %q", uri)
+	}
+}
@@ -0,0 +1,398 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/db"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/entities"
+	apperrors "github.com/RehanAthallahAzhar/tokohobby-accounts/internal/pkg/errors"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/repositories"
+	"github.com/RehanAthallahAzhar/tokohobby-accounts/internal/services"
+)
+
+const (
+	testRPID   = "tokohobby.test"
+	testOrigin = "https://tokohobby.test"
+)
+
+var b64 = base64.RawURLEncoding
+
+func TestWebAuthnRegisterAndLogin(t *testing.T) {
+	ctx := context.Background()
+	svc, user, creds := newWebAuthnFixture(t)
+	authenticator := newSoftAuthenticator(t)
+
+	registered := registerPasskey(t, svc, user, authenticator)
+	if registered.Name != "Laptop" || !bytes.Equal(registered.ID, authenticator.credentialID) {
+		t.Fatalf("unexpected registered credential: %+v", registered)
+	}
+
+	challenge, err := svc.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	assertion := authenticator.get(t, optionsChallenge(t, challenge.Options))
+
+	loggedIn, err := svc.FinishLogin(ctx, challenge.CeremonyID, assertion)
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+	if loggedIn.ID != user.ID {
+		t.Fatalf("logged in as %s, want %s", loggedIn.ID, user.ID)
+	}
+
+	stored := creds.rows[string(authenticator.credentialID)]
+	if stored.SignCount != int64(authenticator.signCount) || !stored.LastUsedAt.Valid {
+		t.Fatalf("credential usage not recorded: %+v", stored)
+	}
+
+	// The ceremony is single use.
+	if _, err := svc.FinishLogin(ctx, challenge.CeremonyID, assertion); !errors.Is(err, apperrors.ErrInvalidToken) {
+		t.Fatalf("replayed ceremony: got %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestWebAuthnLoginRejectsClonedAuthenticator(t *testing.T) {
+	ctx := context.Background()
+	svc, user, _ := newWebAuthnFixture(t)
+	authenticator := newSoftAuthenticator(t)
+	registerPasskey(t, svc, user, authenticator)
+
+	login := func() error {
+		challenge, err := svc.BeginLogin(ctx)
+		if err != nil {
+			t.Fatalf("BeginLogin: %v", err)
+		}
+		_, err = svc.FinishLogin(ctx, challenge.CeremonyID, authenticator.get(t, optionsChallenge(t, challenge.Options)))
+		return err
+	}
+
+	if err := login(); err != nil {
+		t.Fatalf("first login: %v", err)
+	}
+
+	// A copy of the key that missed the last signature reports an old counter.
+	authenticator.signCount--
+	if err := login(); !errors.Is(err, apperrors.ErrPasskeyVerification) {
+		t.Fatalf("cloned authenticator: got %v, want ErrPasskeyVerification", err)
+	}
+}
+
+func TestWebAuthnLoginRejectsWrongOrigin(t *testing.T) {
+	ctx := context.Background()
+	svc, user, _ := newWebAuthnFixture(t)
+	authenticator := newSoftAuthenticator(t)
+	registerPasskey(t, svc, user, authenticator)
+
+	challenge, err := svc.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	authenticator.origin = "https://phishing.test"
+
+	_, err = svc.FinishLogin(ctx, challenge.CeremonyID, authenticator.get(t, optionsChallenge(t, challenge.Options)))
+	if !errors.Is(err, apperrors.ErrPasskeyVerification) {
+		t.Fatalf("wrong origin: got %v, want ErrPasskeyVerification", err)
+	}
+}
+
+func registerPasskey(t *testing.T, svc services.WebAuthnService, user *entities.User, authenticator *softAuthenticator) *entities.WebAuthnCredential {
+	t.Helper()
+	ctx := context.Background()
+
+	challenge, err := svc.BeginRegistration(ctx, user)
+	if err != nil {
+		t.Fatalf("BeginRegistration: %v", err)
+	}
+
+	cred, err := svc.FinishRegistration(ctx, user, challenge.CeremonyID, "Laptop", authenticator.create(t, optionsChallenge(t, challenge.Options), user.ID))
+	if err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	return cred
+}
+
+func newWebAuthnFixture(t *testing.T) (services.WebAuthnService, *entities.User, *fakeWebAuthnCredentialRepo) {
+	t.Helper()
+
+	user := &entities.User{ID: uuid.New(), Name: "Budi", Username: "budi", Email: "budi@example.com", Role: "user"}
+	users := &fakeUserRepo{users: map[uuid.UUID]*db.GetUserByIDRow{
+		user.ID: {ID: user.ID, Name: user.Name, Username: user.Username, Email: user.Email, Role: user.Role},
+	}}
+	creds := &fakeWebAuthnCredentialRepo{rows: map[string]*db.WebauthnCredential{}}
+	ceremonies := &fakeWebAuthnCeremonyRepo{ceremonies: map[string]*repositories.WebAuthnCeremony{}}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	svc, err := services.NewWebAuthnService(creds, ceremonies, users, services.WebAuthnConfig{
+		RPID:          testRPID,
+		RPDisplayName: "TokoHobby",
+		RPOrigins:     []string{testOrigin},
+		CeremonyTTL:   time.Minute,
+	}, log)
+	if err != nil {
+		t.Fatalf("NewWebAuthnService: %v", err)
+	}
+	return svc, user, creds
+}
+
+// optionsChallenge reads publicKey.challenge the way a browser would, from the
+// JSON sent to the client.
+func optionsChallenge(t *testing.T, options interface{}) []byte {
+	t.Helper()
+
+	payload, err := json.Marshal(options)
+	if err != nil {
+		t.Fatalf("marshal options: %v", err)
+	}
+	var decoded struct {
+		PublicKey struct {
+			Challenge string `json:"challenge"`
+		} `json:"publicKey"`
+	}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("unmarshal options: %v", err)
+	}
+	challenge, err := b64.DecodeString(decoded.PublicKey.Challenge)
+	if err != nil {
+		t.Fatalf("decode challenge: %v", err)
+	}
+	return challenge
+}
+
+// softAuthenticator is a platform authenticator with a single ES256 passkey.
+// It always reports user presence and user verification and uses "none"
+// attestation.
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+	origin       string
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	credentialID := make([]byte, 32)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatalf("generate credential id: %v", err)
+	}
+	return &softAuthenticator{key: key, credentialID: credentialID, origin: testOrigin}
+}
+
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttestedData = 0x40
+)
+
+func (a *softAuthenticator) create(t *testing.T, challenge []byte, userID uuid.UUID) []byte {
+	t.Helper()
+	a.userHandle = userID[:]
+
+	coseKey, err := cbor.Marshal(map[int]interface{}{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		-3: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatalf("encode cose key: %v", err)
+	}
+
+	authData := a.authData(flagUserPresent | flagUserVerified | flagAttestedData)
+	authData = append(authData, make([]byte, 16)...) // aaguid
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, coseKey...)
+
+	attestationObject, err := cbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatalf("encode attestation object: %v", err)
+	}
+
+	return a.marshal(t, map[string]interface{}{
+		"clientDataJSON":    b64.EncodeToString(a.clientData(t, "webauthn.create", challenge)),
+		"attestationObject": b64.EncodeToString(attestationObject),
+		"transports":        []string{"internal"},
+	})
+}
+
+func (a *softAuthenticator) get(t *testing.T, challenge []byte) []byte {
+	t.Helper()
+	a.signCount++
+
+	clientData := a.clientData(t, "webauthn.get", challenge)
+	authData := a.authData(flagUserPresent | flagUserVerified)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("sign assertion: %v", err)
+	}
+
+	return a.marshal(t, map[string]interface{}{
+		"clientDataJSON":    b64.EncodeToString(clientData),
+		"authenticatorData": b64.EncodeToString(authData),
+		"signature":         b64.EncodeToString(signature),
+		"userHandle":        b64.EncodeToString(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) authData(flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	return binary.BigEndian.AppendUint32(data, a.signCount)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremonyType string, challenge []byte) []byte {
+	t.Helper()
+
+	clientData, err := json.Marshal(map[string]interface{}{
+		"type":      ceremonyType,
+		"challenge": b64.EncodeToString(challenge),
+		"origin":    a.origin,
+	})
+	if err != nil {
+		t.Fatalf("encode client data: %v", err)
+	}
+	return clientData
+}
+
+func (a *softAuthenticator) marshal(t *testing.T, response map[string]interface{}) []byte {
+	t.Helper()
+
+	credential, err := json.Marshal(map[string]interface{}{
+		"id":       b64.EncodeToString(a.credentialID),
+		"rawId":    b64.EncodeToString(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		t.Fatalf("encode credential: %v", err)
+	}
+	return credential
+}
+
+// In-memory repositories backing the service in these tests.
+
+type fakeUserRepo struct {
+	repositories.UserRepository
+	users map[uuid.UUID]*db.GetUserByIDRow
+}
+
+func (r *fakeUserRepo) GetUserByID(ctx context.Context, id uuid.UUID) (*db.GetUserByIDRow, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, apperrors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+type fakeWebAuthnCredentialRepo struct {
+	rows map[string]*db.WebauthnCredential
+}
+
+func (r *fakeWebAuthnCredentialRepo) CreateCredential(ctx context.Context, param *db.CreateWebAuthnCredentialParams) (*db.WebauthnCredential, error) {
+	row := &db.WebauthnCredential{
+		ID:              param.ID,
+		UserID:          param.UserID,
+		Name:            param.Name,
+		PublicKey:       param.PublicKey,
+		AttestationType: param.AttestationType,
+		Transports:      param.Transports,
+		Flags:           param.Flags,
+		Aaguid:          param.Aaguid,
+		SignCount:       param.SignCount,
+		Attachment:      param.Attachment,
+		CreatedAt:       time.Now(),
+	}
+	r.rows[string(param.ID)] = row
+	return row, nil
+}
+
+func (r *fakeWebAuthnCredentialRepo) ListCredentials(ctx context.Context, userID uuid.UUID) ([]db.WebauthnCredential, error) {
+	var res []db.WebauthnCredential
+	for _, row := range r.rows {
+		if row.UserID == userID {
+			res = append(res, *row)
+		}
+	}
+	return res, nil
+}
+
+func (r *fakeWebAuthnCredentialRepo) UpdateCredentialUsage(ctx context.Context, param *db.UpdateWebAuthnCredentialUsageParams) error {
+	row, ok := r.rows[string(param.ID)]
+	if !ok {
+		return apperrors.ErrNotFound
+	}
+	row.SignCount = param.SignCount
+	row.Flags = param.Flags
+	row.LastUsedAt.Time, row.LastUsedAt.Valid = time.Now(), true
+	return nil
+}
+
+func (r *fakeWebAuthnCredentialRepo) DeleteCredential(ctx context.Context, userID uuid.UUID, credentialID []byte) error {
+	row, ok := r.rows[string(credentialID)]
+	if !ok || row.UserID != userID {
+		return apperrors.ErrNotFound
+	}
+	delete(r.rows, string(credentialID))
+	return nil
+}
+
+type fakeWebAuthnCeremonyRepo struct {
+	ceremonies map[string]*repositories.WebAuthnCeremony
+}
+
+func (r *fakeWebAuthnCeremonyRepo) SaveCeremony(ctx context.Context, ceremonyID string, ceremony *repositories.WebAuthnCeremony, ttl time.Duration) error {
+	// Round trip through JSON like the Redis implementation does.
+	payload, err := json.Marshal(ceremony)
+	if err != nil {
+		return err
+	}
+	var stored repositories.WebAuthnCeremony
+	if err := json.Unmarshal(payload, &stored); err != nil {
+		return err
+	}
+	r.ceremonies[ceremonyID] = &stored
+	return nil
+}
+
+func (r *fakeWebAuthnCeremonyRepo) ConsumeCeremony(ctx context.Context, ceremonyID string) (*repositories.WebAuthnCeremony, error) {
+	ceremony, ok := r.ceremonies[ceremonyID]
+	if !ok {
+		return nil, apperrors.ErrInvalidToken
+	}
+	delete(r.ceremonies, ceremonyID)
+	return ceremony, nil
+}